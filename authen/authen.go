@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
 
 	"github.com/yzf120/elysia-backend/dao"
 	"github.com/yzf120/elysia-backend/utils"
@@ -17,7 +20,9 @@ type UserContextKey string
 const (
 	UserIDKey   UserContextKey = "user_id"
 	UserTypeKey UserContextKey = "user_type"
-	RoleIDKey   UserContextKey = "role_id" // 学生ID/教师ID/管理员ID
+	RoleIDKey   UserContextKey = "role_id"   // 学生ID/教师ID/管理员ID
+	TokenKey    UserContextKey = "token"     // 当前请求使用的 JWT
+	ClientIPKey UserContextKey = "client_ip" // 客户端IP
 )
 
 // UserInfo 用户信息结构
@@ -128,6 +133,8 @@ func Authen(next http.Handler) http.Handler {
 		ctx := context.WithValue(r.Context(), UserIDKey, userInfo.UserID)
 		ctx = context.WithValue(ctx, UserTypeKey, userInfo.UserType)
 		ctx = context.WithValue(ctx, RoleIDKey, userInfo.RoleID)
+		ctx = context.WithValue(ctx, TokenKey, tokenString)
+		ctx = context.WithValue(ctx, ClientIPKey, getClientIP(r))
 
 		// 继续处理请求
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -150,6 +157,72 @@ func isAPIRequest(r *http.Request) bool {
 	return false
 }
 
+// getClientIP 获取客户端IP
+// 默认使用连接的 RemoteAddr；只有请求来自 TRUSTED_PROXIES 中的反向代理时才采信 X-Forwarded-For / X-Real-IP
+func getClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host) {
+		return host
+	}
+	// 从右向左跳过可信代理，第一个不可信的地址即为客户端
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		parts := strings.Split(forwarded, ",")
+		for i := len(parts) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(parts[i])
+			if net.ParseIP(ip) == nil {
+				break
+			}
+			if i == 0 || !isTrustedProxy(ip) {
+				return ip
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return host
+}
+
+var (
+	trustedProxiesOnce sync.Once
+	trustedProxies     []*net.IPNet
+)
+
+// isTrustedProxy 判断地址是否属于可信反向代理（TRUSTED_PROXIES 为逗号分隔的 IP 或 CIDR）
+func isTrustedProxy(host string) bool {
+	trustedProxiesOnce.Do(func() {
+		for _, item := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			if !strings.Contains(item, "/") {
+				if ip := net.ParseIP(item); ip != nil && ip.To4() != nil {
+					item += "/32"
+				} else {
+					item += "/128"
+				}
+			}
+			if _, ipNet, err := net.ParseCIDR(item); err == nil {
+				trustedProxies = append(trustedProxies, ipNet)
+			}
+		}
+	})
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // respondError 返回错误响应
 func respondError(w http.ResponseWriter, statusCode int, message string) {
 	w.WriteHeader(statusCode)
@@ -178,6 +251,18 @@ func GetRoleIDFromContext(ctx context.Context) (string, bool) {
 	return roleID, ok
 }
 
+// GetTokenFromContext 从上下文中获取当前请求的 JWT
+func GetTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(TokenKey).(string)
+	return token, ok
+}
+
+// GetClientIPFromContext 从上下文中获取客户端IP
+func GetClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(ClientIPKey).(string)
+	return ip, ok
+}
+
 // GetUserInfoFromContext 从上下文中获取完整的用户信息
 func GetUserInfoFromContext(ctx context.Context) (*UserInfo, bool) {
 	userID, ok1 := GetUserIDFromContext(ctx)
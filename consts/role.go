@@ -57,6 +57,54 @@ const (
 	ClassMemberStatusActive = 1 // 正常
 )
 
+// 班级排行榜展示方式常量（学生按班级设置）
+const (
+	LeaderboardVisibilityPublic    = 0 // 实名展示
+	LeaderboardVisibilityAnonymous = 1 // 匿名展示
+	LeaderboardVisibilityHidden    = 2 // 不参与排行
+)
+
+// 班级邀请状态常量
+const (
+	ClassInviteStatusRevoked = 0 // 已撤销
+	ClassInviteStatusActive  = 1 // 有效
+)
+
+// 班级加入策略常量
+const (
+	ClassJoinPolicyOpen     = 0 // 开放加入
+	ClassJoinPolicyApproval = 1 // 需教师审批
+	ClassJoinPolicyClosed   = 2 // 关闭加入
+)
+
+// 班级教学团队角色常量
+const (
+	ClassStaffRoleOwner     = "owner"      // 创建者（拥有全部权限）
+	ClassStaffRoleCoTeacher = "co_teacher" // 协同教师
+	ClassStaffRoleTA        = "ta"         // 助教（可为教师或学生）
+)
+
+// 班级教学团队权限常量
+const (
+	ClassPermClassManage  = "class_manage" // 班级设置、邀请、加入审批与成员管理
+	ClassPermChapterEdit  = "chapter_edit" // 章节、小节与作业设置
+	ClassPermAnnouncement = "announcement" // 公告发布与管理
+	ClassPermGradeView    = "grade_view"   // 查看与导出成绩册
+	ClassPermGradeEdit    = "grade_edit"   // 设置成绩权重
+	ClassPermContest      = "contest"      // 比赛与考试管理
+	ClassPermDiscussion   = "discussion"   // 讨论区查看与管理
+	ClassPermAttendance   = "attendance"   // 课堂签到发起与考勤管理
+)
+
+// 加入班级申请状态常量
+const (
+	ClassJoinRequestPending    = 0 // 待审批
+	ClassJoinRequestApproved   = 1 // 已通过
+	ClassJoinRequestRejected   = 2 // 已拒绝
+	ClassJoinRequestWaitlisted = 3 // 候补中
+	ClassJoinRequestCancelled  = 4 // 已取消
+)
+
 // 编程水平常量
 const (
 	ProgrammingLevelBeginner     = "beginner"     // 初学者
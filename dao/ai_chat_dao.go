@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/yzf120/elysia-backend/model/conversation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AIChatDAO AI 对话数据访问对象
+type AIChatDAO interface {
+	// CreateSession 写入会话元数据（会话已存在时忽略）
+	CreateSession(session *conversation.AIChatSession) error
+	// GetSessionById 根据会话ID查询会话元数据
+	GetSessionById(sessionId string) (*conversation.AIChatSession, error)
+	// ReserveSeq 为会话分配 n 个连续的消息序号，返回分配后的最大序号
+	ReserveSeq(sessionId string, n int32) (int32, error)
+	// UpdateSummary 更新会话滚动摘要（仅当新摘要覆盖的序号更大时更新）
+	UpdateSummary(sessionId, summary string, summarySeq int32) error
+	// CreateOutbox 写入待投递消息
+	CreateOutbox(item *conversation.AIChatOutbox) error
+	// DeleteOutbox 删除已投递成功的消息
+	DeleteOutbox(id int64) error
+	// UpdateOutbox 更新待投递消息（记录重试次数、失败原因等）
+	UpdateOutbox(id int64, updates map[string]interface{}) error
+	// ListDueOutbox 查询到达重试时间的待投递消息（按消息序号升序，保证同一会话内按顺序投递）
+	ListDueOutbox(now time.Time, limit int) ([]*conversation.AIChatOutbox, error)
+	// ListOutboxBySessionId 查询会话下尚未投递的消息
+	ListOutboxBySessionId(sessionId string) ([]*conversation.AIChatOutbox, error)
+}
+
+type aiChatDAOImpl struct{}
+
+// NewAIChatDAO 创建 AI 对话DAO
+func NewAIChatDAO() AIChatDAO {
+	return &aiChatDAOImpl{}
+}
+
+// CreateSession 写入会话元数据
+func (d *aiChatDAOImpl) CreateSession(session *conversation.AIChatSession) error {
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).Create(session).Error
+}
+
+// GetSessionById 根据会话ID查询会话元数据
+func (d *aiChatDAOImpl) GetSessionById(sessionId string) (*conversation.AIChatSession, error) {
+	var session conversation.AIChatSession
+	err := DB.Where("session_id = ?", sessionId).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ReserveSeq 为会话分配 n 个连续的消息序号
+func (d *aiChatDAOImpl) ReserveSeq(sessionId string, n int32) (int32, error) {
+	var lastSeq int32
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&conversation.AIChatSession{}).Where("session_id = ?", sessionId).
+			Update("last_seq", gorm.Expr("last_seq + ?", n)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&conversation.AIChatSession{}).Where("session_id = ?", sessionId).
+			Select("last_seq").Scan(&lastSeq).Error
+	})
+	return lastSeq, err
+}
+
+// UpdateSummary 更新会话滚动摘要
+func (d *aiChatDAOImpl) UpdateSummary(sessionId, summary string, summarySeq int32) error {
+	return DB.Model(&conversation.AIChatSession{}).
+		Where("session_id = ? AND summary_seq < ?", sessionId, summarySeq).
+		Updates(map[string]interface{}{
+			"summary":     summary,
+			"summary_seq": summarySeq,
+		}).Error
+}
+
+// CreateOutbox 写入待投递消息
+func (d *aiChatDAOImpl) CreateOutbox(item *conversation.AIChatOutbox) error {
+	return DB.Create(item).Error
+}
+
+// DeleteOutbox 删除已投递成功的消息
+func (d *aiChatDAOImpl) DeleteOutbox(id int64) error {
+	return DB.Where("id = ?", id).Delete(&conversation.AIChatOutbox{}).Error
+}
+
+// UpdateOutbox 更新待投递消息
+func (d *aiChatDAOImpl) UpdateOutbox(id int64, updates map[string]interface{}) error {
+	return DB.Model(&conversation.AIChatOutbox{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListDueOutbox 查询到达重试时间的待投递消息
+func (d *aiChatDAOImpl) ListDueOutbox(now time.Time, limit int) ([]*conversation.AIChatOutbox, error) {
+	var items []*conversation.AIChatOutbox
+	err := DB.Where("status = ? AND next_retry_time <= ?", conversation.ChatOutboxPending, now).
+		Order("session_id ASC, message_seq ASC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// ListOutboxBySessionId 查询会话下尚未投递的消息
+func (d *aiChatDAOImpl) ListOutboxBySessionId(sessionId string) ([]*conversation.AIChatOutbox, error) {
+	var items []*conversation.AIChatOutbox
+	err := DB.Where("session_id = ?", sessionId).Order("message_seq ASC").Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,170 @@
+package dao
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/model/conversation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AIUsageFilter AI 用量统计筛选条件（日期含首尾，空字符串表示不限）
+type AIUsageFilter struct {
+	StartDate time.Time
+	EndDate   time.Time
+	StudentId string
+	ClassId   string
+	ModelId   string
+}
+
+// AIUsageStat AI 用量聚合结果（未参与分组的维度为空）
+type AIUsageStat struct {
+	StudentId        string
+	ClassId          string
+	ModelId          string
+	UsageDate        time.Time
+	Requests         int64
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+}
+
+// AIUsageDAO AI 用量数据访问对象
+type AIUsageDAO interface {
+	// CreateLedger 写入用量流水
+	CreateLedger(entry *conversation.AIUsageLedger) error
+	// SumStudentTokens 统计学生在日期范围内的 token 用量（classId 为空表示全部用量）
+	SumStudentTokens(studentId, classId string, startDate, endDate time.Time) (int64, error)
+	// ListUsageStats 按指定维度分组统计用量（groupBy 为列名，由调用方保证合法）
+	ListUsageStats(filter AIUsageFilter, groupBy []string, limit, offset int) ([]*AIUsageStat, error)
+	// CountUsageGroups 统计分组数
+	CountUsageGroups(filter AIUsageFilter, groupBy []string) (int64, error)
+	// SumUsage 统计筛选范围内的用量合计
+	SumUsage(filter AIUsageFilter) (*AIUsageStat, error)
+
+	// GetQuota 查询额度配置
+	GetQuota(scope, scopeId string) (*conversation.AIUsageQuota, error)
+	// SaveQuota 保存额度配置（已存在时更新）
+	SaveQuota(quota *conversation.AIUsageQuota) error
+	// DeleteQuota 删除额度配置
+	DeleteQuota(scope, scopeId string) error
+	// ListQuotasByScope 分页查询某范围下的额度配置
+	ListQuotasByScope(scope string, limit, offset int) ([]*conversation.AIUsageQuota, error)
+	// CountQuotasByScope 统计某范围下的额度配置数
+	CountQuotasByScope(scope string) (int64, error)
+}
+
+type aiUsageDAOImpl struct{}
+
+// NewAIUsageDAO 创建 AI 用量DAO
+func NewAIUsageDAO() AIUsageDAO {
+	return &aiUsageDAOImpl{}
+}
+
+// CreateLedger 写入用量流水
+func (d *aiUsageDAOImpl) CreateLedger(entry *conversation.AIUsageLedger) error {
+	return DB.Create(entry).Error
+}
+
+// SumStudentTokens 统计学生在日期范围内的 token 用量
+func (d *aiUsageDAOImpl) SumStudentTokens(studentId, classId string, startDate, endDate time.Time) (int64, error) {
+	var total int64
+	query := DB.Model(&conversation.AIUsageLedger{}).
+		Where("student_id = ? AND usage_date BETWEEN ? AND ?", studentId, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if classId != "" {
+		query = query.Where("class_id = ?", classId)
+	}
+	err := query.Select("COALESCE(SUM(total_tokens), 0)").Scan(&total).Error
+	return total, err
+}
+
+// usageQuery 按筛选条件构建流水查询
+func usageQuery(filter AIUsageFilter) *gorm.DB {
+	query := DB.Model(&conversation.AIUsageLedger{}).
+		Where("usage_date BETWEEN ? AND ?", filter.StartDate.Format("2006-01-02"), filter.EndDate.Format("2006-01-02"))
+	if filter.StudentId != "" {
+		query = query.Where("student_id = ?", filter.StudentId)
+	}
+	if filter.ClassId != "" {
+		query = query.Where("class_id = ?", filter.ClassId)
+	}
+	if filter.ModelId != "" {
+		query = query.Where("model_id = ?", filter.ModelId)
+	}
+	return query
+}
+
+const aiUsageSumColumns = "COUNT(*) AS requests, COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, " +
+	"COALESCE(SUM(completion_tokens), 0) AS completion_tokens, COALESCE(SUM(total_tokens), 0) AS total_tokens"
+
+// ListUsageStats 按指定维度分组统计用量
+func (d *aiUsageDAOImpl) ListUsageStats(filter AIUsageFilter, groupBy []string, limit, offset int) ([]*AIUsageStat, error) {
+	var stats []*AIUsageStat
+	columns := strings.Join(groupBy, ", ")
+	order := "total_tokens DESC"
+	if len(groupBy) == 1 && groupBy[0] == "usage_date" {
+		order = "usage_date ASC"
+	}
+	err := usageQuery(filter).Select(columns + ", " + aiUsageSumColumns).
+		Group(columns).Order(order).
+		Limit(limit).Offset(offset).
+		Scan(&stats).Error
+	return stats, err
+}
+
+// CountUsageGroups 统计分组数
+func (d *aiUsageDAOImpl) CountUsageGroups(filter AIUsageFilter, groupBy []string) (int64, error) {
+	var total int64
+	columns := strings.Join(groupBy, ", ")
+	sub := usageQuery(filter).Select(columns).Group(columns)
+	err := DB.Table("(?) AS t", sub).Count(&total).Error
+	return total, err
+}
+
+// SumUsage 统计筛选范围内的用量合计
+func (d *aiUsageDAOImpl) SumUsage(filter AIUsageFilter) (*AIUsageStat, error) {
+	var stat AIUsageStat
+	err := usageQuery(filter).Select(aiUsageSumColumns).Scan(&stat).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stat, nil
+}
+
+// GetQuota 查询额度配置
+func (d *aiUsageDAOImpl) GetQuota(scope, scopeId string) (*conversation.AIUsageQuota, error) {
+	var quota conversation.AIUsageQuota
+	err := DB.Where("scope = ? AND scope_id = ?", scope, scopeId).First(&quota).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// SaveQuota 保存额度配置
+func (d *aiUsageDAOImpl) SaveQuota(quota *conversation.AIUsageQuota) error {
+	return DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}, {Name: "scope_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"daily_tokens", "monthly_tokens", "updated_by", "update_time"}),
+	}).Create(quota).Error
+}
+
+// DeleteQuota 删除额度配置
+func (d *aiUsageDAOImpl) DeleteQuota(scope, scopeId string) error {
+	return DB.Where("scope = ? AND scope_id = ?", scope, scopeId).Delete(&conversation.AIUsageQuota{}).Error
+}
+
+// ListQuotasByScope 分页查询某范围下的额度配置
+func (d *aiUsageDAOImpl) ListQuotasByScope(scope string, limit, offset int) ([]*conversation.AIUsageQuota, error) {
+	var quotas []*conversation.AIUsageQuota
+	err := DB.Where("scope = ?", scope).Order("update_time DESC").Limit(limit).Offset(offset).Find(&quotas).Error
+	return quotas, err
+}
+
+// CountQuotasByScope 统计某范围下的额度配置数
+func (d *aiUsageDAOImpl) CountQuotasByScope(scope string) (int64, error) {
+	var total int64
+	err := DB.Model(&conversation.AIUsageQuota{}).Where("scope = ?", scope).Count(&total).Error
+	return total, err
+}
@@ -0,0 +1,166 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/yzf120/elysia-backend/model/class"
+)
+
+// AnnouncementDAO 班级公告数据访问对象
+type AnnouncementDAO interface {
+	CreateAnnouncement(a *class.ClassAnnouncement) error
+	GetAnnouncementById(announcementId string) (*class.ClassAnnouncement, error)
+	UpdateAnnouncement(announcementId string, updates map[string]interface{}) error
+	// DeleteAnnouncement 删除公告及其已读回执
+	DeleteAnnouncement(announcementId string) error
+	// ListAnnouncementsByClassId 查询班级公告（置顶优先、发布时间倒序），activeAt 非空时过滤已过期公告
+	ListAnnouncementsByClassId(classId string, activeAt *time.Time) ([]*class.ClassAnnouncement, error)
+
+	// MarkRead 记录已读回执（重复标记忽略）
+	MarkRead(r *class.ClassAnnouncementRead) error
+	ListReadsByAnnouncementId(announcementId string) ([]*class.ClassAnnouncementRead, error)
+	// ListReadAnnouncementIds 查询学生在指定公告中已读的公告ID
+	ListReadAnnouncementIds(studentId string, announcementIds []string) (map[string]bool, error)
+	// CountReadsByAnnouncementIds 统计公告的已读人数（只统计仍在班的成员）
+	CountReadsByAnnouncementIds(announcementIds []string) (map[string]int32, error)
+	// CountUnreadByStudentId 按班级统计学生未读的有效公告数
+	CountUnreadByStudentId(studentId string, now time.Time) (map[string]int32, error)
+}
+
+type announcementDAOImpl struct{}
+
+// NewAnnouncementDAO 创建班级公告DAO
+func NewAnnouncementDAO() AnnouncementDAO {
+	return &announcementDAOImpl{}
+}
+
+// CreateAnnouncement 创建公告
+func (d *announcementDAOImpl) CreateAnnouncement(a *class.ClassAnnouncement) error {
+	return DB.Create(a).Error
+}
+
+// GetAnnouncementById 根据公告ID查询公告
+func (d *announcementDAOImpl) GetAnnouncementById(announcementId string) (*class.ClassAnnouncement, error) {
+	var a class.ClassAnnouncement
+	err := DB.Where("announcement_id = ?", announcementId).First(&a).Error
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// UpdateAnnouncement 更新公告
+func (d *announcementDAOImpl) UpdateAnnouncement(announcementId string, updates map[string]interface{}) error {
+	return DB.Model(&class.ClassAnnouncement{}).Where("announcement_id = ?", announcementId).Updates(updates).Error
+}
+
+// DeleteAnnouncement 删除公告及其已读回执
+func (d *announcementDAOImpl) DeleteAnnouncement(announcementId string) error {
+	if err := DB.Where("announcement_id = ?", announcementId).Delete(&class.ClassAnnouncementRead{}).Error; err != nil {
+		return err
+	}
+	return DB.Where("announcement_id = ?", announcementId).Delete(&class.ClassAnnouncement{}).Error
+}
+
+// ListAnnouncementsByClassId 查询班级公告（置顶优先、发布时间倒序）
+func (d *announcementDAOImpl) ListAnnouncementsByClassId(classId string, activeAt *time.Time) ([]*class.ClassAnnouncement, error) {
+	var list []*class.ClassAnnouncement
+	query := DB.Where("class_id = ?", classId)
+	if activeAt != nil {
+		query = query.Where("expire_time IS NULL OR expire_time > ?", *activeAt)
+	}
+	err := query.Order("is_pinned DESC, publish_time DESC, id DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// MarkRead 记录已读回执（重复标记忽略）
+func (d *announcementDAOImpl) MarkRead(r *class.ClassAnnouncementRead) error {
+	var count int64
+	err := DB.Model(&class.ClassAnnouncementRead{}).
+		Where("announcement_id = ? AND student_id = ?", r.AnnouncementId, r.StudentId).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return DB.Create(r).Error
+}
+
+// ListReadsByAnnouncementId 查询公告的已读回执（按阅读时间正序）
+func (d *announcementDAOImpl) ListReadsByAnnouncementId(announcementId string) ([]*class.ClassAnnouncementRead, error) {
+	var list []*class.ClassAnnouncementRead
+	err := DB.Where("announcement_id = ?", announcementId).Order("read_time ASC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListReadAnnouncementIds 查询学生在指定公告中已读的公告ID
+func (d *announcementDAOImpl) ListReadAnnouncementIds(studentId string, announcementIds []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(announcementIds) == 0 {
+		return result, nil
+	}
+	var ids []string
+	err := DB.Model(&class.ClassAnnouncementRead{}).
+		Where("student_id = ? AND announcement_id IN ?", studentId, announcementIds).
+		Pluck("announcement_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// CountReadsByAnnouncementIds 统计公告的已读人数（只统计仍在班的成员）
+func (d *announcementDAOImpl) CountReadsByAnnouncementIds(announcementIds []string) (map[string]int32, error) {
+	result := make(map[string]int32)
+	if len(announcementIds) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		AnnouncementId string
+		Cnt            int32
+	}
+	err := DB.Table("class_announcement_read r").
+		Select("r.announcement_id AS announcement_id, COUNT(*) AS cnt").
+		Joins("JOIN class_member m ON m.class_id = r.class_id AND m.student_id = r.student_id AND m.status = 1").
+		Where("r.announcement_id IN ?", announcementIds).
+		Group("r.announcement_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.AnnouncementId] = row.Cnt
+	}
+	return result, nil
+}
+
+// CountUnreadByStudentId 按班级统计学生未读的有效公告数
+func (d *announcementDAOImpl) CountUnreadByStudentId(studentId string, now time.Time) (map[string]int32, error) {
+	var rows []struct {
+		ClassId string
+		Cnt     int32
+	}
+	err := DB.Table("class_announcement a").
+		Select("a.class_id AS class_id, COUNT(*) AS cnt").
+		Joins("JOIN class_member m ON m.class_id = a.class_id AND m.student_id = ? AND m.status = 1", studentId).
+		Joins("LEFT JOIN class_announcement_read r ON r.announcement_id = a.announcement_id AND r.student_id = ?", studentId).
+		Where("r.id IS NULL AND (a.expire_time IS NULL OR a.expire_time > ?)", now).
+		Group("a.class_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int32, len(rows))
+	for _, row := range rows {
+		result[row.ClassId] = row.Cnt
+	}
+	return result, nil
+}
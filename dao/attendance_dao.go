@@ -0,0 +1,190 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/yzf120/elysia-backend/model/class"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttendanceDAO 课堂签到数据访问对象
+type AttendanceDAO interface {
+	// 签到场次操作
+	CreateSession(session *class.ClassAttendanceSession) error
+	GetSessionById(sessionId string) (*class.ClassAttendanceSession, error)
+	// GetOpenSession 查询班级当前未结束的签到场次（不存在返回 nil）
+	GetOpenSession(classId string) (*class.ClassAttendanceSession, error)
+	// ListSessionsByClassId 分页查询班级签到场次（最新在前）
+	ListSessionsByClassId(classId string, limit, offset int32) ([]*class.ClassAttendanceSession, error)
+	CountSessionsByClassId(classId string) (int64, error)
+	// ListClosedSessionsByClassId 查询班级全部已结束的签到场次（按开始时间升序）
+	ListClosedSessionsByClassId(classId string) ([]*class.ClassAttendanceSession, error)
+	// ListExpiredOpenSessions 查询截止时间已过但仍未结束的签到场次
+	ListExpiredOpenSessions(classId string, now time.Time) ([]*class.ClassAttendanceSession, error)
+	// CloseSession 结束签到场次并为未签到学生写入缺勤记录（已有记录的学生跳过）
+	CloseSession(sessionId string, closedAt time.Time, absentRecords []*class.ClassAttendanceRecord) error
+
+	// 考勤记录操作
+	// CreateRecord 写入考勤记录（已存在返回 false）
+	CreateRecord(record *class.ClassAttendanceRecord) (bool, error)
+	// SaveRecord 写入或覆盖考勤记录（教师手动标记）
+	SaveRecord(record *class.ClassAttendanceRecord) error
+	GetRecord(sessionId, studentId string) (*class.ClassAttendanceRecord, error)
+	ListRecordsBySessionId(sessionId string) ([]*class.ClassAttendanceRecord, error)
+	ListRecordsByClassId(classId string) ([]*class.ClassAttendanceRecord, error)
+	ListRecordsByStudentId(classId, studentId string) ([]*class.ClassAttendanceRecord, error)
+	// CountRecordsBySessionIds 统计各签到场次已出勤（出勤+迟到）的人数
+	CountRecordsBySessionIds(sessionIds []string) (map[string]int64, error)
+}
+
+type attendanceDAOImpl struct{}
+
+// NewAttendanceDAO 创建课堂签到DAO
+func NewAttendanceDAO() AttendanceDAO {
+	return &attendanceDAOImpl{}
+}
+
+// CreateSession 创建签到场次
+func (d *attendanceDAOImpl) CreateSession(session *class.ClassAttendanceSession) error {
+	return DB.Create(session).Error
+}
+
+// GetSessionById 根据场次ID查询签到场次
+func (d *attendanceDAOImpl) GetSessionById(sessionId string) (*class.ClassAttendanceSession, error) {
+	var session class.ClassAttendanceSession
+	err := DB.Where("session_id = ?", sessionId).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetOpenSession 查询班级当前未结束的签到场次
+func (d *attendanceDAOImpl) GetOpenSession(classId string) (*class.ClassAttendanceSession, error) {
+	var list []*class.ClassAttendanceSession
+	err := DB.Where("class_id = ? AND status = ?", classId, class.AttendanceSessionOpen).
+		Order("start_time DESC, id DESC").Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// ListSessionsByClassId 分页查询班级签到场次
+func (d *attendanceDAOImpl) ListSessionsByClassId(classId string, limit, offset int32) ([]*class.ClassAttendanceSession, error) {
+	var list []*class.ClassAttendanceSession
+	err := DB.Where("class_id = ?", classId).Order("start_time DESC, id DESC").
+		Limit(int(limit)).Offset(int(offset)).Find(&list).Error
+	return list, err
+}
+
+// CountSessionsByClassId 统计班级签到场次数量
+func (d *attendanceDAOImpl) CountSessionsByClassId(classId string) (int64, error) {
+	var count int64
+	err := DB.Model(&class.ClassAttendanceSession{}).Where("class_id = ?", classId).Count(&count).Error
+	return count, err
+}
+
+// ListClosedSessionsByClassId 查询班级全部已结束的签到场次
+func (d *attendanceDAOImpl) ListClosedSessionsByClassId(classId string) ([]*class.ClassAttendanceSession, error) {
+	var list []*class.ClassAttendanceSession
+	err := DB.Where("class_id = ? AND status = ?", classId, class.AttendanceSessionClosed).
+		Order("start_time ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ListExpiredOpenSessions 查询截止时间已过但仍未结束的签到场次
+func (d *attendanceDAOImpl) ListExpiredOpenSessions(classId string, now time.Time) ([]*class.ClassAttendanceSession, error) {
+	var list []*class.ClassAttendanceSession
+	err := DB.Where("class_id = ? AND status = ? AND end_time <= ?", classId, class.AttendanceSessionOpen, now).
+		Find(&list).Error
+	return list, err
+}
+
+// CloseSession 结束签到场次并写入缺勤记录
+func (d *attendanceDAOImpl) CloseSession(sessionId string, closedAt time.Time, absentRecords []*class.ClassAttendanceRecord) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&class.ClassAttendanceSession{}).
+			Where("session_id = ? AND status = ?", sessionId, class.AttendanceSessionOpen).
+			Updates(map[string]interface{}{
+				"status":    class.AttendanceSessionClosed,
+				"closed_at": closedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 已被其他请求结束
+		if result.RowsAffected == 0 || len(absentRecords) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(absentRecords, 200).Error
+	})
+}
+
+// CreateRecord 写入考勤记录
+func (d *attendanceDAOImpl) CreateRecord(record *class.ClassAttendanceRecord) (bool, error) {
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SaveRecord 写入或覆盖考勤记录
+func (d *attendanceDAOImpl) SaveRecord(record *class.ClassAttendanceRecord) error {
+	return DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "checkin_time", "source", "marked_by", "update_time"}),
+	}).Create(record).Error
+}
+
+// GetRecord 查询学生在签到场次的考勤记录
+func (d *attendanceDAOImpl) GetRecord(sessionId, studentId string) (*class.ClassAttendanceRecord, error) {
+	var record class.ClassAttendanceRecord
+	err := DB.Where("session_id = ? AND student_id = ?", sessionId, studentId).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListRecordsBySessionId 查询签到场次的全部考勤记录
+func (d *attendanceDAOImpl) ListRecordsBySessionId(sessionId string) ([]*class.ClassAttendanceRecord, error) {
+	var list []*class.ClassAttendanceRecord
+	err := DB.Where("session_id = ?", sessionId).Order("checkin_time ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ListRecordsByClassId 查询班级全部考勤记录
+func (d *attendanceDAOImpl) ListRecordsByClassId(classId string) ([]*class.ClassAttendanceRecord, error) {
+	var list []*class.ClassAttendanceRecord
+	err := DB.Where("class_id = ?", classId).Find(&list).Error
+	return list, err
+}
+
+// ListRecordsByStudentId 查询学生在班级内的全部考勤记录
+func (d *attendanceDAOImpl) ListRecordsByStudentId(classId, studentId string) ([]*class.ClassAttendanceRecord, error) {
+	var list []*class.ClassAttendanceRecord
+	err := DB.Where("class_id = ? AND student_id = ?", classId, studentId).Find(&list).Error
+	return list, err
+}
+
+// CountRecordsBySessionIds 统计各签到场次已出勤的人数
+func (d *attendanceDAOImpl) CountRecordsBySessionIds(sessionIds []string) (map[string]int64, error) {
+	result := make(map[string]int64)
+	if len(sessionIds) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		SessionId string
+		Cnt       int64
+	}
+	err := DB.Model(&class.ClassAttendanceRecord{}).Select("session_id, COUNT(*) AS cnt").
+		Where("session_id IN ? AND status IN ?", sessionIds, []int32{class.AttendancePresent, class.AttendanceLate}).
+		Group("session_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		result[r.SessionId] = r.Cnt
+	}
+	return result, nil
+}
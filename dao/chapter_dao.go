@@ -20,8 +20,12 @@ type ChapterDAO interface {
 	UpdateSection(sectionId string, updates map[string]interface{}) error
 	DeleteSection(sectionId string) error
 	ListSectionsByChapterId(chapterId string) ([]*class.ClassSection, error)
+	ListSectionsByClassId(classId string) ([]*class.ClassSection, error)
 	DeleteSectionsByChapterId(chapterId string) error
 	BatchUpdateSectionOrder(chapterId string, orders []SectionOrder) error
+
+	// CreateContent 在同一事务中批量创建章节、小节及测验题目（用于导入课程模板）
+	CreateContent(chapters []*class.ClassChapter, sections []*class.ClassSection, questions []*class.ClassQuizQuestion) error
 }
 
 // ChapterOrder 章节排序项
@@ -125,6 +129,13 @@ func (d *chapterDAOImpl) ListSectionsByChapterId(chapterId string) ([]*class.Cla
 	return sections, err
 }
 
+// ListSectionsByClassId 查询班级下所有小节
+func (d *chapterDAOImpl) ListSectionsByClassId(classId string) ([]*class.ClassSection, error) {
+	var sections []*class.ClassSection
+	err := DB.Where("class_id = ? AND status = 1", classId).Order("sort_order ASC, id ASC").Find(&sections).Error
+	return sections, err
+}
+
 // DeleteSectionsByChapterId 删除章节下所有小节
 func (d *chapterDAOImpl) DeleteSectionsByChapterId(chapterId string) error {
 	return DB.Where("chapter_id = ?", chapterId).Delete(&class.ClassSection{}).Error
@@ -143,3 +154,27 @@ func (d *chapterDAOImpl) BatchUpdateSectionOrder(chapterId string, orders []Sect
 	}
 	return tx.Commit().Error
 }
+
+// CreateContent 在同一事务中批量创建章节、小节及测验题目
+func (d *chapterDAOImpl) CreateContent(chapters []*class.ClassChapter, sections []*class.ClassSection, questions []*class.ClassQuizQuestion) error {
+	tx := DB.Begin()
+	if len(chapters) > 0 {
+		if err := tx.Create(&chapters).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if len(sections) > 0 {
+		if err := tx.Create(&sections).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if len(questions) > 0 {
+		if err := tx.Create(&questions).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
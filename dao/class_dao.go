@@ -1,7 +1,12 @@
 package dao
 
 import (
+	"errors"
+	"time"
+
 	"github.com/yzf120/elysia-backend/model/class"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ClassDAO 班级数据访问对象
@@ -11,6 +16,8 @@ type ClassDAO interface {
 	GetClassByCode(classCode string) (*class.Class, error)
 	UpdateClass(classId string, updates map[string]interface{}) error
 	DeleteClass(classId string) error
+	UpdateClassStatusBySemesters(semesters []string, fromStatus, toStatus int32) (int64, error)
+	CreateClassWithContent(c *class.Class, owner *class.ClassStaff, chapters []*class.ClassChapter, sections []*class.ClassSection, questions []*class.ClassQuizQuestion) error
 	ListClasses(whereClause string, args []interface{}, limit, offset int32) ([]*class.Class, error)
 	CountClasses(whereClause string, args []interface{}) (int32, error)
 	ListClassesByTeacherId(teacherId string, limit, offset int32) ([]*class.Class, error)
@@ -31,6 +38,49 @@ func (d *classDAOImpl) CreateClass(class *class.Class) error {
 	return db.Create(class).Error
 }
 
+// UpdateClassStatusBySemesters 批量变更指定学期内处于 fromStatus 的班级状态，返回变更数量
+func (d *classDAOImpl) UpdateClassStatusBySemesters(semesters []string, fromStatus, toStatus int32) (int64, error) {
+	if len(semesters) == 0 {
+		return 0, nil
+	}
+	result := DB.Model(&class.Class{}).
+		Where("semester IN ? AND status = ?", semesters, fromStatus).
+		Update("status", toStatus)
+	return result.RowsAffected, result.Error
+}
+
+// CreateClassWithContent 在同一事务中创建班级、创建者记录、章节小节及测验题目（用于克隆班级）
+func (d *classDAOImpl) CreateClassWithContent(c *class.Class, owner *class.ClassStaff, chapters []*class.ClassChapter, sections []*class.ClassSection, questions []*class.ClassQuizQuestion) error {
+	tx := DB.Begin()
+	if err := tx.Create(c).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Create(owner).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if len(chapters) > 0 {
+		if err := tx.Create(&chapters).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if len(sections) > 0 {
+		if err := tx.Create(&sections).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if len(questions) > 0 {
+		if err := tx.Create(&questions).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit().Error
+}
+
 // GetClassById 根据班级ID查询班级
 func (d *classDAOImpl) GetClassById(classId string) (*class.Class, error) {
 	db := DB
@@ -123,11 +173,26 @@ type ClassMemberDAO interface {
 	RemoveMember(classId, studentId string) error
 	GetMember(classId, studentId string) (*class.ClassMember, error)
 	ListMembersByClassId(classId string, limit, offset int32) ([]*class.ClassMember, error)
+	ListAllMembersByClassId(classId string) ([]*class.ClassMember, error)
 	ListClassesByStudentId(studentId string, limit, offset int32) ([]*class.ClassMember, error)
+	ListAllClassesByStudentId(studentId string) ([]*class.ClassMember, error)
 	CountMembersByClassId(classId string) (int32, error)
 	UpdateMemberStatus(classId, studentId string, status int32) error
+	UpdateMember(classId, studentId string, updates map[string]interface{}) error
+	// EnrollMember 在同一事务内占用邀请链接次数（inviteCode 不为空时）、条件递增班级人数并写入或恢复成员
+	EnrollMember(member *class.ClassMember, inviteCode string) (int, error)
 }
 
+// EnrollMember 写入结果
+const (
+	EnrollResultOK            = 0 // 已写入（或已是在读成员）
+	EnrollResultClassFull     = 1 // 班级人数已满
+	EnrollResultInviteInvalid = 2 // 邀请链接已失效或使用次数已达上限
+)
+
+// errEnrollAborted 用于回滚 EnrollMember 事务
+var errEnrollAborted = errors.New("enroll aborted")
+
 type classMemberDAOImpl struct{}
 
 // NewClassMemberDAO 创建班级成员DAO
@@ -166,6 +231,14 @@ func (d *classMemberDAOImpl) ListMembersByClassId(classId string, limit, offset
 	return members, err
 }
 
+// ListAllMembersByClassId 查询班级全部正常成员（按加入时间排序，不分页）
+func (d *classMemberDAOImpl) ListAllMembersByClassId(classId string) ([]*class.ClassMember, error) {
+	db := DB
+	var members []*class.ClassMember
+	err := db.Where("class_id = ? AND status = 1", classId).Order("join_time ASC").Find(&members).Error
+	return members, err
+}
+
 // ListClassesByStudentId 根据学生ID查询班级列表
 func (d *classMemberDAOImpl) ListClassesByStudentId(studentId string, limit, offset int32) ([]*class.ClassMember, error) {
 	db := DB
@@ -174,6 +247,14 @@ func (d *classMemberDAOImpl) ListClassesByStudentId(studentId string, limit, off
 	return members, err
 }
 
+// ListAllClassesByStudentId 查询学生加入的全部班级（按加入时间排序，不分页）
+func (d *classMemberDAOImpl) ListAllClassesByStudentId(studentId string) ([]*class.ClassMember, error) {
+	db := DB
+	var members []*class.ClassMember
+	err := db.Where("student_id = ? AND status = 1", studentId).Order("join_time ASC").Find(&members).Error
+	return members, err
+}
+
 // CountMembersByClassId 统计班级成员数量
 func (d *classMemberDAOImpl) CountMembersByClassId(classId string) (int32, error) {
 	db := DB
@@ -187,3 +268,65 @@ func (d *classMemberDAOImpl) UpdateMemberStatus(classId, studentId string, statu
 	db := DB
 	return db.Model(&class.ClassMember{}).Where("class_id = ? AND student_id = ?", classId, studentId).Update("status", status).Error
 }
+
+// UpdateMember 更新成员信息
+func (d *classMemberDAOImpl) UpdateMember(classId, studentId string, updates map[string]interface{}) error {
+	db := DB
+	return db.Model(&class.ClassMember{}).Where("class_id = ? AND student_id = ?", classId, studentId).Updates(updates).Error
+}
+
+// EnrollMember 写入班级成员
+// 先锁定成员行（已是在读成员时直接返回），再依次占用邀请次数、以 current_students < max_students 为条件递增人数，任一步失败整体回滚
+func (d *classMemberDAOImpl) EnrollMember(member *class.ClassMember, inviteCode string) (int, error) {
+	result := EnrollResultOK
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var existing class.ClassMember
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("class_id = ? AND student_id = ?", member.ClassId, member.StudentId).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		found := err == nil
+		if found && existing.Status == member.Status {
+			return nil
+		}
+
+		if inviteCode != "" {
+			res := tx.Model(&class.ClassInvite{}).
+				Where("invite_code = ? AND status = 1 AND (max_uses = 0 OR used_count < max_uses)", inviteCode).
+				Update("used_count", gorm.Expr("used_count + 1"))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				result = EnrollResultInviteInvalid
+				return errEnrollAborted
+			}
+		}
+
+		res := tx.Model(&class.Class{}).
+			Where("class_id = ? AND current_students < max_students", member.ClassId).
+			Update("current_students", gorm.Expr("current_students + 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			result = EnrollResultClassFull
+			return errEnrollAborted
+		}
+
+		if !found {
+			return tx.Create(member).Error
+		}
+		// 曾退出的学生重新加入：恢复成员状态（class_id + student_id 唯一）
+		return tx.Model(&class.ClassMember{}).Where("id = ?", existing.Id).Updates(map[string]interface{}{
+			"status":      member.Status,
+			"invite_code": member.InviteCode,
+			"join_time":   time.Now(),
+		}).Error
+	})
+	if errors.Is(err, errEnrollAborted) {
+		return result, nil
+	}
+	return result, err
+}
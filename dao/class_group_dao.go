@@ -0,0 +1,208 @@
+package dao
+
+import (
+	"errors"
+
+	"github.com/yzf120/elysia-backend/model/class"
+	"gorm.io/gorm"
+)
+
+// ClassGroupDAO 班级小组数据访问对象
+type ClassGroupDAO interface {
+	// CreateGroup 创建小组并写入初始成员（第一个成员为组长；学生已在其他小组时因唯一键冲突整体失败）
+	CreateGroup(group *class.ClassGroup, members []*class.ClassGroupMember) error
+	GetGroupById(groupId string) (*class.ClassGroup, error)
+	ListGroupsByClassId(classId string) ([]*class.ClassGroup, error)
+	UpdateGroup(groupId string, updates map[string]interface{}) error
+	// DeleteGroup 删除小组及其全部成员记录
+	DeleteGroup(groupId string) error
+	// DeleteEmptyGroup 删除已无成员的小组（仍有成员时不删除）
+	DeleteEmptyGroup(groupId string) error
+
+	// 成员操作
+	// AddMembers 向同一小组添加成员：按人数上限条件累加人数，小组无组长时第一个成员成为组长
+	// 超出人数上限（或小组不存在）返回 false；学生已在其他小组时因唯一键冲突整体失败
+	AddMembers(groupId string, members []*class.ClassGroupMember) (bool, error)
+	// RemoveMember 移除小组成员并扣减小组人数，移除的是组长时由最早加入的组员接任
+	RemoveMember(groupId, studentId string) error
+	// GetMemberByStudentId 查询学生在班级内所属的小组成员记录
+	GetMemberByStudentId(classId, studentId string) (*class.ClassGroupMember, error)
+	ListMembersByGroupId(groupId string) ([]*class.ClassGroupMember, error)
+	ListMembersByClassId(classId string) ([]*class.ClassGroupMember, error)
+	// SetLeader 设置组长（原组长降为组员，leaderId 为空表示清空组长）
+	SetLeader(groupId, leaderId string) error
+}
+
+type classGroupDAOImpl struct{}
+
+// NewClassGroupDAO 创建班级小组DAO
+func NewClassGroupDAO() ClassGroupDAO {
+	return &classGroupDAOImpl{}
+}
+
+// CreateGroup 创建小组
+func (d *classGroupDAOImpl) CreateGroup(group *class.ClassGroup, members []*class.ClassGroupMember) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		group.MemberCount = int32(len(members))
+		if len(members) > 0 {
+			group.LeaderId = members[0].StudentId
+		}
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		for i, m := range members {
+			m.GroupId = group.GroupId
+			m.ClassId = group.ClassId
+			m.Role = class.GroupRoleMember
+			if i == 0 {
+				m.Role = class.GroupRoleLeader
+			}
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		return tx.Create(&members).Error
+	})
+}
+
+// GetGroupById 根据小组ID查询小组
+func (d *classGroupDAOImpl) GetGroupById(groupId string) (*class.ClassGroup, error) {
+	var group class.ClassGroup
+	err := DB.Where("group_id = ?", groupId).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ListGroupsByClassId 查询班级全部小组（按创建时间排序）
+func (d *classGroupDAOImpl) ListGroupsByClassId(classId string) ([]*class.ClassGroup, error) {
+	var list []*class.ClassGroup
+	err := DB.Where("class_id = ?", classId).Order("create_time ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// UpdateGroup 更新小组信息
+func (d *classGroupDAOImpl) UpdateGroup(groupId string, updates map[string]interface{}) error {
+	return DB.Model(&class.ClassGroup{}).Where("group_id = ?", groupId).Updates(updates).Error
+}
+
+// DeleteGroup 删除小组及其成员
+func (d *classGroupDAOImpl) DeleteGroup(groupId string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupId).Delete(&class.ClassGroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("group_id = ?", groupId).Delete(&class.ClassGroup{}).Error
+	})
+}
+
+// DeleteEmptyGroup 删除已无成员的小组
+func (d *classGroupDAOImpl) DeleteEmptyGroup(groupId string) error {
+	return DB.Where("group_id = ? AND member_count = 0", groupId).Delete(&class.ClassGroup{}).Error
+}
+
+// AddMembers 添加小组成员（人数以 member_count + n <= max_size 条件更新，不依赖进程内锁）
+func (d *classGroupDAOImpl) AddMembers(groupId string, members []*class.ClassGroupMember) (bool, error) {
+	if len(members) == 0 {
+		return true, nil
+	}
+	added := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		n := len(members)
+		result := tx.Model(&class.ClassGroup{}).Where("group_id = ? AND member_count + ? <= max_size", groupId, n).
+			Update("member_count", gorm.Expr("member_count + ?", n))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		for _, m := range members {
+			m.GroupId = groupId
+			m.Role = class.GroupRoleMember
+		}
+		if err := tx.Create(&members).Error; err != nil {
+			return err
+		}
+		// 空小组加入成员后自动指定组长
+		result = tx.Model(&class.ClassGroup{}).Where("group_id = ? AND leader_id = ''", groupId).
+			Update("leader_id", members[0].StudentId)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := tx.Model(&class.ClassGroupMember{}).Where("group_id = ? AND student_id = ?", groupId, members[0].StudentId).
+				Update("role", class.GroupRoleLeader).Error; err != nil {
+				return err
+			}
+		}
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+// RemoveMember 移除小组成员
+func (d *classGroupDAOImpl) RemoveMember(groupId, studentId string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("group_id = ? AND student_id = ?", groupId, studentId).Delete(&class.ClassGroupMember{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Model(&class.ClassGroup{}).Where("group_id = ? AND member_count > 0", groupId).
+			Update("member_count", gorm.Expr("member_count - 1")).Error; err != nil {
+			return err
+		}
+		// 组长离开时由最早加入的组员接任（无组员时清空组长）
+		var next class.ClassGroupMember
+		err := tx.Where("group_id = ?", groupId).Order("join_time ASC, id ASC").First(&next).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		result = tx.Model(&class.ClassGroup{}).Where("group_id = ? AND leader_id = ?", groupId, studentId).
+			Update("leader_id", next.StudentId)
+		if result.Error != nil || result.RowsAffected == 0 || next.StudentId == "" {
+			return result.Error
+		}
+		return tx.Model(&class.ClassGroupMember{}).Where("id = ?", next.Id).Update("role", class.GroupRoleLeader).Error
+	})
+}
+
+// GetMemberByStudentId 查询学生所属的小组成员记录
+func (d *classGroupDAOImpl) GetMemberByStudentId(classId, studentId string) (*class.ClassGroupMember, error) {
+	var member class.ClassGroupMember
+	err := DB.Where("class_id = ? AND student_id = ?", classId, studentId).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListMembersByGroupId 查询小组成员（按加入时间排序）
+func (d *classGroupDAOImpl) ListMembersByGroupId(groupId string) ([]*class.ClassGroupMember, error) {
+	var list []*class.ClassGroupMember
+	err := DB.Where("group_id = ?", groupId).Order("join_time ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ListMembersByClassId 查询班级内全部小组成员
+func (d *classGroupDAOImpl) ListMembersByClassId(classId string) ([]*class.ClassGroupMember, error) {
+	var list []*class.ClassGroupMember
+	err := DB.Where("class_id = ?", classId).Order("join_time ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// SetLeader 设置组长
+func (d *classGroupDAOImpl) SetLeader(groupId, leaderId string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&class.ClassGroupMember{}).Where("group_id = ? AND role = ?", groupId, class.GroupRoleLeader).
+			Update("role", class.GroupRoleMember).Error; err != nil {
+			return err
+		}
+		if leaderId != "" {
+			if err := tx.Model(&class.ClassGroupMember{}).Where("group_id = ? AND student_id = ?", groupId, leaderId).
+				Update("role", class.GroupRoleLeader).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&class.ClassGroup{}).Where("group_id = ?", groupId).Update("leader_id", leaderId).Error
+	})
+}
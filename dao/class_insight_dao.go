@@ -0,0 +1,100 @@
+package dao
+
+import (
+	"github.com/yzf120/elysia-backend/model/class"
+	"gorm.io/gorm/clause"
+)
+
+// ClassInsightDAO 教学洞察摘要数据访问对象
+type ClassInsightDAO interface {
+	// 订阅操作
+	// Subscribe 订阅班级每日摘要（已订阅时忽略）
+	Subscribe(classId, teacherId string) error
+	Unsubscribe(classId, teacherId string) error
+	IsSubscribed(classId, teacherId string) (bool, error)
+	ListAllSubscriptions() ([]*class.ClassInsightSubscription, error)
+
+	// 摘要操作
+	// CreateDigest 创建每日摘要（同一天已生成时忽略，返回是否新建）
+	CreateDigest(digest *class.ClassInsightDigest) (bool, error)
+	ListDigests(teacherId string, unreadOnly bool, limit, offset int32) ([]*class.ClassInsightDigest, error)
+	CountDigests(teacherId string, unreadOnly bool) (int64, error)
+	// MarkDigestsRead 标记摘要已读（ids 为空表示全部）
+	MarkDigestsRead(teacherId string, ids []int64) error
+}
+
+type classInsightDAOImpl struct{}
+
+// NewClassInsightDAO 创建教学洞察摘要DAO
+func NewClassInsightDAO() ClassInsightDAO {
+	return &classInsightDAOImpl{}
+}
+
+// ==================== 订阅操作 ====================
+
+// Subscribe 订阅班级每日摘要
+func (d *classInsightDAOImpl) Subscribe(classId, teacherId string) error {
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&class.ClassInsightSubscription{ClassId: classId, TeacherId: teacherId}).Error
+}
+
+// Unsubscribe 取消订阅
+func (d *classInsightDAOImpl) Unsubscribe(classId, teacherId string) error {
+	return DB.Where("class_id = ? AND teacher_id = ?", classId, teacherId).Delete(&class.ClassInsightSubscription{}).Error
+}
+
+// IsSubscribed 查询是否已订阅
+func (d *classInsightDAOImpl) IsSubscribed(classId, teacherId string) (bool, error) {
+	var count int64
+	err := DB.Model(&class.ClassInsightSubscription{}).Where("class_id = ? AND teacher_id = ?", classId, teacherId).Count(&count).Error
+	return count > 0, err
+}
+
+// ListAllSubscriptions 查询全部订阅（按班级排序）
+func (d *classInsightDAOImpl) ListAllSubscriptions() ([]*class.ClassInsightSubscription, error) {
+	var list []*class.ClassInsightSubscription
+	err := DB.Order("class_id ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ==================== 摘要操作 ====================
+
+// CreateDigest 创建每日摘要
+func (d *classInsightDAOImpl) CreateDigest(digest *class.ClassInsightDigest) (bool, error) {
+	result := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(digest)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListDigests 分页查询摘要（最新在前）
+func (d *classInsightDAOImpl) ListDigests(teacherId string, unreadOnly bool, limit, offset int32) ([]*class.ClassInsightDigest, error) {
+	var list []*class.ClassInsightDigest
+	query := DB.Where("teacher_id = ?", teacherId)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	err := query.Order("digest_date DESC, id DESC").Limit(int(limit)).Offset(int(offset)).Find(&list).Error
+	return list, err
+}
+
+// CountDigests 统计摘要数量
+func (d *classInsightDAOImpl) CountDigests(teacherId string, unreadOnly bool) (int64, error) {
+	var count int64
+	query := DB.Model(&class.ClassInsightDigest{}).Where("teacher_id = ?", teacherId)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// MarkDigestsRead 标记摘要已读（ids 为空表示全部）
+func (d *classInsightDAOImpl) MarkDigestsRead(teacherId string, ids []int64) error {
+	query := DB.Model(&class.ClassInsightDigest{}).Where("teacher_id = ? AND is_read = ?", teacherId, false)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	return query.Update("is_read", true).Error
+}
@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/yzf120/elysia-backend/model/class"
+)
+
+// ClassInviteDAO 班级邀请链接数据访问对象
+type ClassInviteDAO interface {
+	CreateInvite(invite *class.ClassInvite) error
+	GetInviteByCode(inviteCode string) (*class.ClassInvite, error)
+	UpdateInvite(inviteCode string, updates map[string]interface{}) error
+	ListInvitesByClassId(classId string) ([]*class.ClassInvite, error)
+	// IncrUsedCount 原子递增使用次数（仅当邀请有效且未达上限时生效），返回是否递增成功
+	IncrUsedCount(inviteCode string) (bool, error)
+}
+
+type classInviteDAOImpl struct{}
+
+// NewClassInviteDAO 创建班级邀请链接DAO
+func NewClassInviteDAO() ClassInviteDAO {
+	return &classInviteDAOImpl{}
+}
+
+// CreateInvite 创建邀请链接
+func (d *classInviteDAOImpl) CreateInvite(invite *class.ClassInvite) error {
+	return DB.Create(invite).Error
+}
+
+// GetInviteByCode 根据邀请码查询邀请链接
+func (d *classInviteDAOImpl) GetInviteByCode(inviteCode string) (*class.ClassInvite, error) {
+	var invite class.ClassInvite
+	err := DB.Where("invite_code = ?", inviteCode).First(&invite).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// UpdateInvite 更新邀请链接
+func (d *classInviteDAOImpl) UpdateInvite(inviteCode string, updates map[string]interface{}) error {
+	return DB.Model(&class.ClassInvite{}).Where("invite_code = ?", inviteCode).Updates(updates).Error
+}
+
+// ListInvitesByClassId 查询班级全部邀请链接（创建时间倒序）
+func (d *classInviteDAOImpl) ListInvitesByClassId(classId string) ([]*class.ClassInvite, error) {
+	var invites []*class.ClassInvite
+	err := DB.Where("class_id = ?", classId).Order("create_time DESC").Find(&invites).Error
+	return invites, err
+}
+
+// IncrUsedCount 原子递增使用次数
+func (d *classInviteDAOImpl) IncrUsedCount(inviteCode string) (bool, error) {
+	result := DB.Model(&class.ClassInvite{}).
+		Where("invite_code = ? AND status = 1 AND (max_uses = 0 OR used_count < max_uses)", inviteCode).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
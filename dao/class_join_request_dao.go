@@ -0,0 +1,119 @@
+package dao
+
+import (
+	"github.com/yzf120/elysia-backend/model/class"
+)
+
+// ClassJoinRequestDAO 加入班级申请数据访问对象
+type ClassJoinRequestDAO interface {
+	CreateRequest(r *class.ClassJoinRequest) error
+	GetRequestById(requestId string) (*class.ClassJoinRequest, error)
+	// GetOpenRequest 查询学生在班级中待审批或候补中的申请
+	GetOpenRequest(classId, studentId string) (*class.ClassJoinRequest, error)
+	// UpdateRequestIfStatus 仅当申请处于 fromStatus 时更新（并发下保证同一申请只被处理一次），返回是否更新成功
+	UpdateRequestIfStatus(requestId string, fromStatus []int32, updates map[string]interface{}) (bool, error)
+	// ListRequestsByClassId 查询班级申请（status 为 -1 时查询全部，创建时间倒序）
+	ListRequestsByClassId(classId string, status int32, limit, offset int) ([]*class.ClassJoinRequest, error)
+	CountRequestsByClassId(classId string, status int32) (int64, error)
+	ListRequestsByStudentId(studentId string) ([]*class.ClassJoinRequest, error)
+	// ListWaitlist 按候补先后查询班级候补队列（limit<=0 时不限制）
+	ListWaitlist(classId string, limit int) ([]*class.ClassJoinRequest, error)
+	// CountWaitlistAhead 统计排在指定申请之前的候补人数
+	CountWaitlistAhead(r *class.ClassJoinRequest) (int64, error)
+}
+
+type classJoinRequestDAOImpl struct{}
+
+// NewClassJoinRequestDAO 创建加入班级申请DAO
+func NewClassJoinRequestDAO() ClassJoinRequestDAO {
+	return &classJoinRequestDAOImpl{}
+}
+
+// CreateRequest 创建申请
+func (d *classJoinRequestDAOImpl) CreateRequest(r *class.ClassJoinRequest) error {
+	return DB.Create(r).Error
+}
+
+// GetRequestById 根据申请ID查询申请
+func (d *classJoinRequestDAOImpl) GetRequestById(requestId string) (*class.ClassJoinRequest, error) {
+	var r class.ClassJoinRequest
+	err := DB.Where("request_id = ?", requestId).First(&r).Error
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetOpenRequest 查询学生在班级中待审批或候补中的申请
+func (d *classJoinRequestDAOImpl) GetOpenRequest(classId, studentId string) (*class.ClassJoinRequest, error) {
+	var r class.ClassJoinRequest
+	// status：0-待审批，3-候补中
+	err := DB.Where("class_id = ? AND student_id = ? AND status IN (0, 3)", classId, studentId).
+		Order("id DESC").First(&r).Error
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UpdateRequestIfStatus 按状态条件更新申请
+func (d *classJoinRequestDAOImpl) UpdateRequestIfStatus(requestId string, fromStatus []int32, updates map[string]interface{}) (bool, error) {
+	result := DB.Model(&class.ClassJoinRequest{}).
+		Where("request_id = ? AND status IN ?", requestId, fromStatus).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListRequestsByClassId 查询班级申请
+func (d *classJoinRequestDAOImpl) ListRequestsByClassId(classId string, status int32, limit, offset int) ([]*class.ClassJoinRequest, error) {
+	var list []*class.ClassJoinRequest
+	query := DB.Where("class_id = ?", classId)
+	if status >= 0 {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("create_time DESC, id DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, err
+}
+
+// CountRequestsByClassId 统计班级申请数量
+func (d *classJoinRequestDAOImpl) CountRequestsByClassId(classId string, status int32) (int64, error) {
+	var count int64
+	query := DB.Model(&class.ClassJoinRequest{}).Where("class_id = ?", classId)
+	if status >= 0 {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// ListRequestsByStudentId 查询学生的全部申请（创建时间倒序）
+func (d *classJoinRequestDAOImpl) ListRequestsByStudentId(studentId string) ([]*class.ClassJoinRequest, error) {
+	var list []*class.ClassJoinRequest
+	err := DB.Where("student_id = ?", studentId).Order("create_time DESC, id DESC").Find(&list).Error
+	return list, err
+}
+
+// ListWaitlist 按候补先后查询班级候补队列
+func (d *classJoinRequestDAOImpl) ListWaitlist(classId string, limit int) ([]*class.ClassJoinRequest, error) {
+	var list []*class.ClassJoinRequest
+	query := DB.Where("class_id = ? AND status = 3", classId).
+		Order("waitlist_time ASC, id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&list).Error
+	return list, err
+}
+
+// CountWaitlistAhead 统计排在指定申请之前的候补人数
+func (d *classJoinRequestDAOImpl) CountWaitlistAhead(r *class.ClassJoinRequest) (int64, error) {
+	var count int64
+	err := DB.Model(&class.ClassJoinRequest{}).
+		Where("class_id = ? AND status = 3", r.ClassId).
+		Where("waitlist_time < ? OR (waitlist_time = ? AND id < ?)", r.WaitlistTime, r.WaitlistTime, r.Id).
+		Count(&count).Error
+	return count, err
+}
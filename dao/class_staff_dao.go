@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"github.com/yzf120/elysia-backend/model/class"
+)
+
+// ClassStaffDAO 班级教学团队数据访问对象
+type ClassStaffDAO interface {
+	AddStaff(staff *class.ClassStaff) error
+	GetStaff(classId, staffId string) (*class.ClassStaff, error)
+	UpdateStaff(classId, staffId string, updates map[string]interface{}) error
+	RemoveStaff(classId, staffId string) error
+	ListStaffByClassId(classId string) ([]*class.ClassStaff, error)
+}
+
+type classStaffDAOImpl struct{}
+
+// NewClassStaffDAO 创建班级教学团队DAO
+func NewClassStaffDAO() ClassStaffDAO {
+	return &classStaffDAOImpl{}
+}
+
+// AddStaff 添加教学团队成员
+func (d *classStaffDAOImpl) AddStaff(staff *class.ClassStaff) error {
+	return DB.Create(staff).Error
+}
+
+// GetStaff 查询教学团队成员
+func (d *classStaffDAOImpl) GetStaff(classId, staffId string) (*class.ClassStaff, error) {
+	var staff class.ClassStaff
+	err := DB.Where("class_id = ? AND staff_id = ?", classId, staffId).First(&staff).Error
+	if err != nil {
+		return nil, err
+	}
+	return &staff, nil
+}
+
+// UpdateStaff 更新教学团队成员
+func (d *classStaffDAOImpl) UpdateStaff(classId, staffId string, updates map[string]interface{}) error {
+	return DB.Model(&class.ClassStaff{}).Where("class_id = ? AND staff_id = ?", classId, staffId).Updates(updates).Error
+}
+
+// RemoveStaff 移除教学团队成员
+func (d *classStaffDAOImpl) RemoveStaff(classId, staffId string) error {
+	return DB.Where("class_id = ? AND staff_id = ?", classId, staffId).Delete(&class.ClassStaff{}).Error
+}
+
+// ListStaffByClassId 查询班级教学团队（按加入时间排序）
+func (d *classStaffDAOImpl) ListStaffByClassId(classId string) ([]*class.ClassStaff, error) {
+	var list []*class.ClassStaff
+	err := DB.Where("class_id = ?", classId).Order("id ASC").Find(&list).Error
+	return list, err
+}
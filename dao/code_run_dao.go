@@ -1,7 +1,12 @@
 package dao
 
 import (
+	"time"
+
+	"github.com/yzf120/elysia-backend/model/class"
 	"github.com/yzf120/elysia-backend/model/code"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CodeRunDAO 代码运行记录数据访问对象
@@ -12,6 +17,14 @@ type CodeRunDAO interface {
 	ListCodeRunsByStudent(studentId string, problemId int64, limit int) ([]*code.CodeRun, error)
 	// BatchGetAcceptedProblems 批量查询学生已完全通过（accepted）的题目ID集合
 	BatchGetAcceptedProblems(studentId string, problemIds []int64) (map[int64]bool, error)
+	// CountSubmitsBySection 统计学生在某班级小节下的提交次数
+	CountSubmitsBySection(studentId, sectionId string) (int64, error)
+	// CreateLimitedSubmit 在提交次数上限内创建作业提交记录（studentIds 为共享次数的学生，达到上限时返回 false）
+	CreateLimitedSubmit(r *code.CodeRun, studentIds []string, maxAttempts int32) (bool, error)
+	// ListClassSubmitsSince 查询班级内指定时间之后的提交记录（不含代码与输出，按提交时间升序）
+	ListClassSubmitsSince(classId string, since time.Time) ([]*code.CodeRun, error)
+	// ListClassJudgedSubmitsSince 查询班级内指定时间之后已评测的提交（含输出，studentId 为空表示全部学生）
+	ListClassJudgedSubmitsSince(classId, studentId string, since time.Time) ([]*code.CodeRun, error)
 }
 
 type codeRunDAOImpl struct{}
@@ -55,7 +68,7 @@ func (d *codeRunDAOImpl) ListCodeRunsByStudent(studentId string, problemId int64
 }
 
 // BatchGetAcceptedProblems 批量查询学生已完全通过（accepted）的题目ID集合
-// 只查 run_type='submit' 且 status='accepted' 的记录
+// 只查 run_type='submit' 且 status='accepted' 的记录，比赛内提交不计入（避免 OI 比赛期间泄露评测结果）
 func (d *codeRunDAOImpl) BatchGetAcceptedProblems(studentId string, problemIds []int64) (map[int64]bool, error) {
 	result := make(map[int64]bool)
 	if len(problemIds) == 0 {
@@ -64,7 +77,7 @@ func (d *codeRunDAOImpl) BatchGetAcceptedProblems(studentId string, problemIds [
 	var acceptedIds []int64
 	err := DB.Model(&code.CodeRun{}).
 		Select("DISTINCT problem_id").
-		Where("student_id = ? AND problem_id IN ? AND run_type = 'submit' AND status = 'accepted'", studentId, problemIds).
+		Where("student_id = ? AND problem_id IN ? AND run_type = 'submit' AND status = 'accepted' AND contest_id = ''", studentId, problemIds).
 		Pluck("problem_id", &acceptedIds).Error
 	if err != nil {
 		return nil, err
@@ -74,3 +87,63 @@ func (d *codeRunDAOImpl) BatchGetAcceptedProblems(studentId string, problemIds [
 	}
 	return result, nil
 }
+
+// CountSubmitsBySection 统计学生在某班级小节下的提交次数（只统计 submit 类型）
+func (d *codeRunDAOImpl) CountSubmitsBySection(studentId, sectionId string) (int64, error) {
+	var count int64
+	err := DB.Model(&code.CodeRun{}).
+		Where("student_id = ? AND section_id = ? AND run_type = 'submit'", studentId, sectionId).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateLimitedSubmit 在提交次数上限内创建作业提交记录
+// 先锁定相关学生的班级成员行，再统计次数并插入，保证并发提交不会超出上限
+func (d *codeRunDAOImpl) CreateLimitedSubmit(r *code.CodeRun, studentIds []string, maxAttempts int32) (bool, error) {
+	created := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var memberIds []int64
+		if err := tx.Model(&class.ClassMember{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("class_id = ? AND student_id IN ?", r.ClassId, studentIds).
+			Order("student_id ASC").Pluck("id", &memberIds).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&code.CodeRun{}).
+			Where("student_id IN ? AND section_id = ? AND run_type = 'submit'", studentIds, r.SectionId).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(maxAttempts) {
+			return nil
+		}
+		if err := tx.Create(r).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// ListClassSubmitsSince 查询班级内指定时间之后的提交记录（只查 submit 类型，不含代码与输出，按提交时间升序）
+func (d *codeRunDAOImpl) ListClassSubmitsSince(classId string, since time.Time) ([]*code.CodeRun, error) {
+	var records []*code.CodeRun
+	err := DB.Select("id, problem_id, student_id, class_id, section_id, run_type, status, error_msg, created_at").
+		Where("class_id = ? AND run_type = 'submit' AND contest_id = '' AND created_at >= ?", classId, since).
+		Order("created_at ASC, id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// ListClassJudgedSubmitsSince 查询班级内指定时间之后已评测的提交（含输出用于计算得分，不含代码，按提交时间升序）
+func (d *codeRunDAOImpl) ListClassJudgedSubmitsSince(classId, studentId string, since time.Time) ([]*code.CodeRun, error) {
+	var records []*code.CodeRun
+	query := DB.Select("id, problem_id, student_id, class_id, section_id, run_type, status, output, created_at").
+		Where("class_id = ? AND run_type = 'submit' AND contest_id = '' AND status NOT IN ('pending', 'running') AND created_at >= ?", classId, since)
+	if studentId != "" {
+		query = query.Where("student_id = ?", studentId)
+	}
+	err := query.Order("created_at ASC, id ASC").Find(&records).Error
+	return records, err
+}
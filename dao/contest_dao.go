@@ -0,0 +1,144 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/yzf120/elysia-backend/model/code"
+	"github.com/yzf120/elysia-backend/model/contest"
+	"gorm.io/gorm"
+)
+
+// ContestDAO 比赛数据访问对象
+type ContestDAO interface {
+	CreateContest(c *contest.Contest) error
+	GetContestById(contestId string) (*contest.Contest, error)
+	UpdateContest(contestId string, updates map[string]interface{}) error
+	DeleteContest(contestId string) error
+	ListContestsByClassId(classId string) ([]*contest.Contest, error)
+	// ListRunningExamsByStudentId 查询学生所在班级中正在进行的考试模式比赛
+	ListRunningExamsByStudentId(studentId string, now time.Time) ([]*contest.Contest, error)
+
+	// 比赛提交
+	CreateSubmission(s *contest.ContestSubmission) error
+	// CreateRunWithSubmission 在同一事务内创建运行记录与比赛提交记录（sub 的 RunId/SubmitTime 由运行记录回填）
+	CreateRunWithSubmission(run *code.CodeRun, sub *contest.ContestSubmission) error
+	UpdateSubmissionByRunId(runId int64, updates map[string]interface{}) error
+	GetSubmissionByRunId(runId int64) (*contest.ContestSubmission, error)
+	ListSubmissionsByContestId(contestId string) ([]*contest.ContestSubmission, error)
+	ListSubmissionsByStudent(contestId, studentId string) ([]*contest.ContestSubmission, error)
+	DeleteSubmissionsByContestId(contestId string) error
+}
+
+type contestDAOImpl struct{}
+
+// NewContestDAO 创建比赛DAO
+func NewContestDAO() ContestDAO {
+	return &contestDAOImpl{}
+}
+
+// CreateContest 创建比赛
+func (d *contestDAOImpl) CreateContest(c *contest.Contest) error {
+	return DB.Create(c).Error
+}
+
+// GetContestById 根据比赛ID查询
+func (d *contestDAOImpl) GetContestById(contestId string) (*contest.Contest, error) {
+	var c contest.Contest
+	err := DB.Where("contest_id = ?", contestId).First(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// UpdateContest 更新比赛
+func (d *contestDAOImpl) UpdateContest(contestId string, updates map[string]interface{}) error {
+	return DB.Model(&contest.Contest{}).Where("contest_id = ?", contestId).Updates(updates).Error
+}
+
+// DeleteContest 删除比赛
+func (d *contestDAOImpl) DeleteContest(contestId string) error {
+	return DB.Where("contest_id = ?", contestId).Delete(&contest.Contest{}).Error
+}
+
+// ListContestsByClassId 查询班级下的比赛（按开始时间倒序）
+func (d *contestDAOImpl) ListContestsByClassId(classId string) ([]*contest.Contest, error) {
+	var list []*contest.Contest
+	err := DB.Where("class_id = ?", classId).Order("start_time DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListRunningExamsByStudentId 查询学生所在班级中正在进行的考试模式比赛
+func (d *contestDAOImpl) ListRunningExamsByStudentId(studentId string, now time.Time) ([]*contest.Contest, error) {
+	var list []*contest.Contest
+	err := DB.Where("class_id IN (SELECT class_id FROM class_member WHERE student_id = ? AND status = 1)", studentId).
+		Where("is_exam = ? AND start_time <= ? AND end_time > ?", true, now, now).
+		Order("end_time ASC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreateSubmission 创建比赛提交记录
+func (d *contestDAOImpl) CreateSubmission(s *contest.ContestSubmission) error {
+	return DB.Create(s).Error
+}
+
+// CreateRunWithSubmission 在同一事务内创建运行记录与比赛提交记录
+func (d *contestDAOImpl) CreateRunWithSubmission(run *code.CodeRun, sub *contest.ContestSubmission) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		sub.RunId = run.Id
+		sub.SubmitTime = run.CreatedAt
+		if sub.SubmitTime.IsZero() {
+			sub.SubmitTime = time.Now()
+		}
+		return tx.Create(sub).Error
+	})
+}
+
+// UpdateSubmissionByRunId 根据运行记录ID更新比赛提交
+func (d *contestDAOImpl) UpdateSubmissionByRunId(runId int64, updates map[string]interface{}) error {
+	return DB.Model(&contest.ContestSubmission{}).Where("run_id = ?", runId).Updates(updates).Error
+}
+
+// GetSubmissionByRunId 根据运行记录ID查询比赛提交
+func (d *contestDAOImpl) GetSubmissionByRunId(runId int64) (*contest.ContestSubmission, error) {
+	var s contest.ContestSubmission
+	err := DB.Where("run_id = ?", runId).First(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSubmissionsByContestId 查询比赛全部提交（按提交时间正序）
+func (d *contestDAOImpl) ListSubmissionsByContestId(contestId string) ([]*contest.ContestSubmission, error) {
+	var list []*contest.ContestSubmission
+	err := DB.Where("contest_id = ?", contestId).Order("submit_time ASC, id ASC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListSubmissionsByStudent 查询学生在比赛中的提交（按提交时间倒序）
+func (d *contestDAOImpl) ListSubmissionsByStudent(contestId, studentId string) ([]*contest.ContestSubmission, error) {
+	var list []*contest.ContestSubmission
+	err := DB.Where("contest_id = ? AND student_id = ?", contestId, studentId).Order("submit_time DESC, id DESC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteSubmissionsByContestId 删除比赛的全部提交记录
+func (d *contestDAOImpl) DeleteSubmissionsByContestId(contestId string) error {
+	return DB.Where("contest_id = ?", contestId).Delete(&contest.ContestSubmission{}).Error
+}
@@ -1,10 +1,12 @@
 package dao
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/yzf120/elysia-backend/config"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -68,3 +70,9 @@ func CloseDB() error {
 func GetDB() *gorm.DB {
 	return DB
 }
+
+// IsDuplicateKeyError 判断错误是否为唯一键冲突（MySQL 1062）
+func IsDuplicateKeyError(err error) bool {
+	var mysqlErr *mysqlDriver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
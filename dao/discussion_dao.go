@@ -0,0 +1,264 @@
+package dao
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yzf120/elysia-backend/model/discussion"
+)
+
+// DiscussionDAO 讨论区数据访问对象
+type DiscussionDAO interface {
+	// 帖子操作
+	CreatePost(p *discussion.DiscussionPost) error
+	GetPostById(postId string) (*discussion.DiscussionPost, error)
+	UpdatePost(postId string, updates map[string]interface{}) error
+	IncrReplyCount(rootId string, delta int) error
+	// ListThreads 分页查询小节下的主题帖（置顶优先、最新在前），statuses 为可见状态，groupIds 不为空时只查询这些小组的帖子
+	ListThreads(sectionId string, groupIds []string, statuses []int32, limit, offset int32) ([]*discussion.DiscussionPost, error)
+	CountThreads(sectionId string, groupIds []string, statuses []int32) (int64, error)
+	// ListReplies 分页查询主题帖下的回复（按时间正序）
+	ListReplies(rootId string, statuses []int32, limit, offset int32) ([]*discussion.DiscussionPost, error)
+	CountReplies(rootId string, statuses []int32) (int64, error)
+	ClearAnswer(rootId string) error
+	// ListPostedSectionIds 查询用户发过帖的小节ID（用于判断讨论小节是否完成）
+	ListPostedSectionIds(authorId string, sectionIds []string) (map[string]bool, error)
+	// ListActiveStudentIdsSince 查询指定时间之后在班级内发过帖的学生ID
+	ListActiveStudentIdsSince(classId string, since time.Time) (map[string]bool, error)
+
+	// 点赞操作
+	// AddLike 点赞（已点赞返回 false）
+	AddLike(postId, userId string) (bool, error)
+	// RemoveLike 取消点赞（未点赞返回 false）
+	RemoveLike(postId, userId string) (bool, error)
+	ListLikedPostIds(userId string, postIds []string) (map[string]bool, error)
+
+	// 通知操作
+	BatchCreateNotifications(list []*discussion.DiscussionNotification) error
+	ListNotifications(recipientId string, unreadOnly bool, limit, offset int32) ([]*discussion.DiscussionNotification, error)
+	CountNotifications(recipientId string, unreadOnly bool) (int64, error)
+	// MarkNotificationsRead 标记通知已读（ids 为空表示全部）
+	MarkNotificationsRead(recipientId string, ids []int64) error
+}
+
+type discussionDAOImpl struct{}
+
+// NewDiscussionDAO 创建讨论区DAO
+func NewDiscussionDAO() DiscussionDAO {
+	return &discussionDAOImpl{}
+}
+
+// CreatePost 创建帖子
+func (d *discussionDAOImpl) CreatePost(p *discussion.DiscussionPost) error {
+	return DB.Create(p).Error
+}
+
+// GetPostById 根据帖子ID查询帖子
+func (d *discussionDAOImpl) GetPostById(postId string) (*discussion.DiscussionPost, error) {
+	var p discussion.DiscussionPost
+	err := DB.Where("post_id = ?", postId).First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdatePost 更新帖子
+func (d *discussionDAOImpl) UpdatePost(postId string, updates map[string]interface{}) error {
+	return DB.Model(&discussion.DiscussionPost{}).Where("post_id = ?", postId).Updates(updates).Error
+}
+
+// IncrReplyCount 增减主题帖回复数
+func (d *discussionDAOImpl) IncrReplyCount(rootId string, delta int) error {
+	return DB.Model(&discussion.DiscussionPost{}).Where("post_id = ?", rootId).
+		Update("reply_count", gorm.Expr("GREATEST(reply_count + ?, 0)", delta)).Error
+}
+
+// ListThreads 分页查询小节下的主题帖
+func (d *discussionDAOImpl) ListThreads(sectionId string, groupIds []string, statuses []int32, limit, offset int32) ([]*discussion.DiscussionPost, error) {
+	var list []*discussion.DiscussionPost
+	err := threadQuery(DB, sectionId, groupIds, statuses).
+		Order("is_pinned DESC, create_time DESC, id DESC").
+		Limit(int(limit)).Offset(int(offset)).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountThreads 统计小节下的主题帖数量
+func (d *discussionDAOImpl) CountThreads(sectionId string, groupIds []string, statuses []int32) (int64, error) {
+	var count int64
+	err := threadQuery(DB.Model(&discussion.DiscussionPost{}), sectionId, groupIds, statuses).Count(&count).Error
+	return count, err
+}
+
+// threadQuery 构造小节主题帖查询条件
+func threadQuery(db *gorm.DB, sectionId string, groupIds []string, statuses []int32) *gorm.DB {
+	db = db.Where("section_id = ? AND parent_id = '' AND status IN ?", sectionId, statuses)
+	if len(groupIds) > 0 {
+		db = db.Where("group_id IN ?", groupIds)
+	}
+	return db
+}
+
+// ListReplies 分页查询主题帖下的回复
+func (d *discussionDAOImpl) ListReplies(rootId string, statuses []int32, limit, offset int32) ([]*discussion.DiscussionPost, error) {
+	var list []*discussion.DiscussionPost
+	err := DB.Where("root_id = ? AND parent_id <> '' AND status IN ?", rootId, statuses).
+		Order("create_time ASC, id ASC").
+		Limit(int(limit)).Offset(int(offset)).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountReplies 统计主题帖下的回复数量
+func (d *discussionDAOImpl) CountReplies(rootId string, statuses []int32) (int64, error) {
+	var count int64
+	err := DB.Model(&discussion.DiscussionPost{}).
+		Where("root_id = ? AND parent_id <> '' AND status IN ?", rootId, statuses).Count(&count).Error
+	return count, err
+}
+
+// ClearAnswer 清除主题帖下已标记的答案
+func (d *discussionDAOImpl) ClearAnswer(rootId string) error {
+	return DB.Model(&discussion.DiscussionPost{}).Where("root_id = ? AND is_answer = ?", rootId, true).
+		Update("is_answer", false).Error
+}
+
+// AddLike 点赞（已点赞返回 false），同时增加帖子点赞数
+func (d *discussionDAOImpl) AddLike(postId, userId string) (bool, error) {
+	added := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&discussion.DiscussionLike{}).Where("post_id = ? AND user_id = ?", postId, userId).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Create(&discussion.DiscussionLike{PostId: postId, UserId: userId}).Error; err != nil {
+			return err
+		}
+		added = true
+		return tx.Model(&discussion.DiscussionPost{}).Where("post_id = ?", postId).
+			Update("like_count", gorm.Expr("like_count + 1")).Error
+	})
+	return added, err
+}
+
+// RemoveLike 取消点赞（未点赞返回 false），同时减少帖子点赞数
+func (d *discussionDAOImpl) RemoveLike(postId, userId string) (bool, error) {
+	removed := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("post_id = ? AND user_id = ?", postId, userId).Delete(&discussion.DiscussionLike{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		removed = true
+		return tx.Model(&discussion.DiscussionPost{}).Where("post_id = ?", postId).
+			Update("like_count", gorm.Expr("GREATEST(like_count - 1, 0)")).Error
+	})
+	return removed, err
+}
+
+// ListLikedPostIds 查询用户在指定帖子中已点赞的帖子ID
+func (d *discussionDAOImpl) ListLikedPostIds(userId string, postIds []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(postIds) == 0 {
+		return result, nil
+	}
+	var ids []string
+	err := DB.Model(&discussion.DiscussionLike{}).Where("user_id = ? AND post_id IN ?", userId, postIds).
+		Pluck("post_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// ListActiveStudentIdsSince 查询指定时间之后在班级内发过帖（含回复，不含已删除）的学生ID
+func (d *discussionDAOImpl) ListActiveStudentIdsSince(classId string, since time.Time) (map[string]bool, error) {
+	result := make(map[string]bool)
+	var ids []string
+	err := DB.Model(&discussion.DiscussionPost{}).
+		Where("class_id = ? AND author_type = 'student' AND status <> 2 AND create_time >= ?", classId, since).
+		Distinct("author_id").Pluck("author_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// ListPostedSectionIds 查询用户在指定小节中发过帖（含回复，不含已删除）的小节ID
+func (d *discussionDAOImpl) ListPostedSectionIds(authorId string, sectionIds []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(sectionIds) == 0 {
+		return result, nil
+	}
+	var ids []string
+	err := DB.Model(&discussion.DiscussionPost{}).
+		Where("author_id = ? AND section_id IN ? AND status <> 2", authorId, sectionIds).
+		Distinct("section_id").Pluck("section_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// BatchCreateNotifications 批量创建通知
+func (d *discussionDAOImpl) BatchCreateNotifications(list []*discussion.DiscussionNotification) error {
+	if len(list) == 0 {
+		return nil
+	}
+	return DB.Create(&list).Error
+}
+
+// ListNotifications 分页查询通知（最新在前）
+func (d *discussionDAOImpl) ListNotifications(recipientId string, unreadOnly bool, limit, offset int32) ([]*discussion.DiscussionNotification, error) {
+	var list []*discussion.DiscussionNotification
+	query := DB.Where("recipient_id = ?", recipientId)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	err := query.Order("create_time DESC, id DESC").Limit(int(limit)).Offset(int(offset)).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountNotifications 统计通知数量
+func (d *discussionDAOImpl) CountNotifications(recipientId string, unreadOnly bool) (int64, error) {
+	var count int64
+	query := DB.Model(&discussion.DiscussionNotification{}).Where("recipient_id = ?", recipientId)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// MarkNotificationsRead 标记通知已读（ids 为空表示全部）
+func (d *discussionDAOImpl) MarkNotificationsRead(recipientId string, ids []int64) error {
+	query := DB.Model(&discussion.DiscussionNotification{}).Where("recipient_id = ? AND is_read = ?", recipientId, false)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	return query.Update("is_read", true).Error
+}
@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"github.com/yzf120/elysia-backend/model/contest"
+)
+
+// ExamDAO 考试会话与事件日志数据访问对象
+type ExamDAO interface {
+	CreateSession(s *contest.ExamSession) error
+	GetSession(contestId, studentId string) (*contest.ExamSession, error)
+	UpdateSession(id int64, updates map[string]interface{}) error
+	DeleteSession(contestId, studentId string) error
+	ListSessionsByContestId(contestId string) ([]*contest.ExamSession, error)
+
+	BatchCreateEvents(events []*contest.ExamEvent) error
+	// ListEvents 查询考试事件（studentId 为空表示全部学生）
+	ListEvents(contestId, studentId string) ([]*contest.ExamEvent, error)
+	// DeleteByContestId 删除比赛的全部考试会话与事件
+	DeleteByContestId(contestId string) error
+}
+
+type examDAOImpl struct{}
+
+// NewExamDAO 创建考试DAO
+func NewExamDAO() ExamDAO {
+	return &examDAOImpl{}
+}
+
+// CreateSession 创建考试会话
+func (d *examDAOImpl) CreateSession(s *contest.ExamSession) error {
+	return DB.Create(s).Error
+}
+
+// GetSession 查询学生的考试会话
+func (d *examDAOImpl) GetSession(contestId, studentId string) (*contest.ExamSession, error) {
+	var s contest.ExamSession
+	err := DB.Where("contest_id = ? AND student_id = ?", contestId, studentId).First(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UpdateSession 更新考试会话
+func (d *examDAOImpl) UpdateSession(id int64, updates map[string]interface{}) error {
+	return DB.Model(&contest.ExamSession{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteSession 删除考试会话（教师解除绑定）
+func (d *examDAOImpl) DeleteSession(contestId, studentId string) error {
+	return DB.Where("contest_id = ? AND student_id = ?", contestId, studentId).Delete(&contest.ExamSession{}).Error
+}
+
+// ListSessionsByContestId 查询考试的全部会话
+func (d *examDAOImpl) ListSessionsByContestId(contestId string) ([]*contest.ExamSession, error) {
+	var list []*contest.ExamSession
+	err := DB.Where("contest_id = ?", contestId).Order("enter_time ASC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// BatchCreateEvents 批量写入考试事件
+func (d *examDAOImpl) BatchCreateEvents(events []*contest.ExamEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return DB.Create(&events).Error
+}
+
+// ListEvents 查询考试事件（按时间正序）
+func (d *examDAOImpl) ListEvents(contestId, studentId string) ([]*contest.ExamEvent, error) {
+	var list []*contest.ExamEvent
+	query := DB.Where("contest_id = ?", contestId)
+	if studentId != "" {
+		query = query.Where("student_id = ?", studentId)
+	}
+	err := query.Order("create_time ASC, id ASC").Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteByContestId 删除比赛的全部考试会话与事件
+func (d *examDAOImpl) DeleteByContestId(contestId string) error {
+	if err := DB.Where("contest_id = ?", contestId).Delete(&contest.ExamEvent{}).Error; err != nil {
+		return err
+	}
+	return DB.Where("contest_id = ?", contestId).Delete(&contest.ExamSession{}).Error
+}
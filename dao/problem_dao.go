@@ -8,6 +8,7 @@ import (
 type ProblemDAO interface {
 	CreateProblem(p *problem.Problem) error
 	GetProblemById(id int64) (*problem.Problem, error)
+	GetProblemBySlug(titleSlug string) (*problem.Problem, error)
 	UpdateProblem(id int64, updates map[string]interface{}) error
 	DeleteProblem(id int64) error
 	ListProblems(keyword, difficulty string, page, pageSize int) ([]*problem.Problem, int64, error)
@@ -35,6 +36,16 @@ func (d *problemDAOImpl) GetProblemById(id int64) (*problem.Problem, error) {
 	return &p, nil
 }
 
+// GetProblemBySlug 根据题目标识（title_slug）查询题目
+func (d *problemDAOImpl) GetProblemBySlug(titleSlug string) (*problem.Problem, error) {
+	var p problem.Problem
+	err := DB.Where("title_slug = ?", titleSlug).First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
 // UpdateProblem 更新题目信息
 func (d *problemDAOImpl) UpdateProblem(id int64, updates map[string]interface{}) error {
 	return DB.Model(&problem.Problem{}).Where("id = ?", id).Updates(updates).Error
@@ -0,0 +1,170 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/yzf120/elysia-backend/model/class"
+)
+
+// QuizDAO 测验数据访问对象
+type QuizDAO interface {
+	// 题目操作
+	CreateQuestion(q *class.ClassQuizQuestion) error
+	GetQuestionById(questionId string) (*class.ClassQuizQuestion, error)
+	UpdateQuestion(questionId string, updates map[string]interface{}) error
+	DeleteQuestion(questionId string) error
+	ListQuestionsBySectionId(sectionId string) ([]*class.ClassQuizQuestion, error)
+	ListQuestionsBySectionIds(sectionIds []string) ([]*class.ClassQuizQuestion, error)
+
+	// 作答操作
+	CreateAttempt(a *class.ClassQuizAttempt) error
+	GetAttemptById(attemptId string) (*class.ClassQuizAttempt, error)
+	UpdateAttempt(attemptId string, updates map[string]interface{}) error
+	// SubmitAttempt 交卷（仅更新作答中的记录，已交卷返回 false）
+	SubmitAttempt(attemptId string, updates map[string]interface{}) (bool, error)
+	ListAttemptsByStudent(sectionId, studentId string) ([]*class.ClassQuizAttempt, error)
+	ListAttemptsBySectionId(sectionId string) ([]*class.ClassQuizAttempt, error)
+	CountAttemptsByStudent(sectionId, studentId string) (int64, error)
+	// ListSubmittedSectionIds 查询学生已交卷的测验小节ID
+	ListSubmittedSectionIds(studentId string, sectionIds []string) (map[string]bool, error)
+	// ListActiveStudentIdsSince 查询指定时间之后在班级内开始过测验的学生ID
+	ListActiveStudentIdsSince(classId string, since time.Time) (map[string]bool, error)
+}
+
+type quizDAOImpl struct{}
+
+// NewQuizDAO 创建测验DAO
+func NewQuizDAO() QuizDAO {
+	return &quizDAOImpl{}
+}
+
+// ==================== 题目操作 ====================
+
+// CreateQuestion 创建题目
+func (d *quizDAOImpl) CreateQuestion(q *class.ClassQuizQuestion) error {
+	return DB.Create(q).Error
+}
+
+// GetQuestionById 根据题目ID查询
+func (d *quizDAOImpl) GetQuestionById(questionId string) (*class.ClassQuizQuestion, error) {
+	var q class.ClassQuizQuestion
+	err := DB.Where("question_id = ?", questionId).First(&q).Error
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// UpdateQuestion 更新题目
+func (d *quizDAOImpl) UpdateQuestion(questionId string, updates map[string]interface{}) error {
+	return DB.Model(&class.ClassQuizQuestion{}).Where("question_id = ?", questionId).Updates(updates).Error
+}
+
+// DeleteQuestion 删除题目（物理删除）
+func (d *quizDAOImpl) DeleteQuestion(questionId string) error {
+	return DB.Where("question_id = ?", questionId).Delete(&class.ClassQuizQuestion{}).Error
+}
+
+// ListQuestionsBySectionId 查询测验小节下所有题目（按 sort_order 升序）
+func (d *quizDAOImpl) ListQuestionsBySectionId(sectionId string) ([]*class.ClassQuizQuestion, error) {
+	var list []*class.ClassQuizQuestion
+	err := DB.Where("section_id = ?", sectionId).Order("sort_order ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ListQuestionsBySectionIds 批量查询多个测验小节的题目
+func (d *quizDAOImpl) ListQuestionsBySectionIds(sectionIds []string) ([]*class.ClassQuizQuestion, error) {
+	var list []*class.ClassQuizQuestion
+	if len(sectionIds) == 0 {
+		return list, nil
+	}
+	err := DB.Where("section_id IN ?", sectionIds).Order("sort_order ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ==================== 作答操作 ====================
+
+// CreateAttempt 创建作答记录
+func (d *quizDAOImpl) CreateAttempt(a *class.ClassQuizAttempt) error {
+	return DB.Create(a).Error
+}
+
+// GetAttemptById 根据作答ID查询
+func (d *quizDAOImpl) GetAttemptById(attemptId string) (*class.ClassQuizAttempt, error) {
+	var a class.ClassQuizAttempt
+	err := DB.Where("attempt_id = ?", attemptId).First(&a).Error
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// UpdateAttempt 更新作答记录
+func (d *quizDAOImpl) UpdateAttempt(attemptId string, updates map[string]interface{}) error {
+	return DB.Model(&class.ClassQuizAttempt{}).Where("attempt_id = ?", attemptId).Updates(updates).Error
+}
+
+// SubmitAttempt 交卷（条件更新，避免重复交卷）
+func (d *quizDAOImpl) SubmitAttempt(attemptId string, updates map[string]interface{}) (bool, error) {
+	result := DB.Model(&class.ClassQuizAttempt{}).Where("attempt_id = ? AND status = 0", attemptId).Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListAttemptsByStudent 查询学生在测验小节的作答记录（按作答次序升序）
+func (d *quizDAOImpl) ListAttemptsByStudent(sectionId, studentId string) ([]*class.ClassQuizAttempt, error) {
+	var list []*class.ClassQuizAttempt
+	err := DB.Where("section_id = ? AND student_id = ?", sectionId, studentId).Order("attempt_no ASC").Find(&list).Error
+	return list, err
+}
+
+// ListAttemptsBySectionId 查询测验小节的所有作答记录
+func (d *quizDAOImpl) ListAttemptsBySectionId(sectionId string) ([]*class.ClassQuizAttempt, error) {
+	var list []*class.ClassQuizAttempt
+	err := DB.Where("section_id = ?", sectionId).Order("student_id ASC, attempt_no ASC").Find(&list).Error
+	return list, err
+}
+
+// CountAttemptsByStudent 统计学生在测验小节的作答次数
+func (d *quizDAOImpl) CountAttemptsByStudent(sectionId, studentId string) (int64, error) {
+	var count int64
+	err := DB.Model(&class.ClassQuizAttempt{}).Where("section_id = ? AND student_id = ?", sectionId, studentId).Count(&count).Error
+	return count, err
+}
+
+// ListSubmittedSectionIds 查询学生已交卷的测验小节ID
+func (d *quizDAOImpl) ListSubmittedSectionIds(studentId string, sectionIds []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(sectionIds) == 0 {
+		return result, nil
+	}
+	var ids []string
+	err := DB.Model(&class.ClassQuizAttempt{}).
+		Where("student_id = ? AND section_id IN ? AND status = 1", studentId, sectionIds).
+		Distinct("section_id").Pluck("section_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// ListActiveStudentIdsSince 查询指定时间之后在班级内开始过测验的学生ID
+func (d *quizDAOImpl) ListActiveStudentIdsSince(classId string, since time.Time) (map[string]bool, error) {
+	result := make(map[string]bool)
+	var ids []string
+	err := DB.Model(&class.ClassQuizAttempt{}).
+		Where("class_id = ? AND start_time >= ?", classId, since).
+		Distinct("student_id").Pluck("student_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
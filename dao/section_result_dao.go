@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"github.com/yzf120/elysia-backend/model/class"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SectionResultDAO 作业小节成绩数据访问对象
+type SectionResultDAO interface {
+	CreateResult(r *class.ClassSectionResult) error
+	GetResult(sectionId, studentId string) (*class.ClassSectionResult, error)
+	UpdateResult(id int64, updates map[string]interface{}) error
+	// ApplyResult 写入一次评测结果：记录不存在时插入 r，已存在时锁定该行并按 merge 返回的字段更新
+	ApplyResult(r *class.ClassSectionResult, merge func(existing *class.ClassSectionResult) map[string]interface{}) error
+	ListResultsByClassId(classId string) ([]*class.ClassSectionResult, error)
+	ListResultsBySectionId(sectionId string) ([]*class.ClassSectionResult, error)
+	ListResultsByStudent(classId, studentId string) ([]*class.ClassSectionResult, error)
+}
+
+type sectionResultDAOImpl struct{}
+
+// NewSectionResultDAO 创建作业小节成绩DAO
+func NewSectionResultDAO() SectionResultDAO {
+	return &sectionResultDAOImpl{}
+}
+
+// CreateResult 创建成绩记录
+func (d *sectionResultDAOImpl) CreateResult(r *class.ClassSectionResult) error {
+	return DB.Create(r).Error
+}
+
+// GetResult 查询学生在某小节的成绩
+func (d *sectionResultDAOImpl) GetResult(sectionId, studentId string) (*class.ClassSectionResult, error) {
+	var r class.ClassSectionResult
+	err := DB.Where("section_id = ? AND student_id = ?", sectionId, studentId).First(&r).Error
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UpdateResult 更新成绩记录
+func (d *sectionResultDAOImpl) UpdateResult(id int64, updates map[string]interface{}) error {
+	return DB.Model(&class.ClassSectionResult{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ApplyResult 写入一次评测结果（INSERT IGNORE 后对已有行 SELECT ... FOR UPDATE，避免并发判题撞唯一键或丢失更新）
+func (d *sectionResultDAOImpl) ApplyResult(r *class.ClassSectionResult, merge func(existing *class.ClassSectionResult) map[string]interface{}) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(r)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+		var existing class.ClassSectionResult
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("section_id = ? AND student_id = ?", r.SectionId, r.StudentId).First(&existing).Error; err != nil {
+			return err
+		}
+		return tx.Model(&class.ClassSectionResult{}).Where("id = ?", existing.Id).Updates(merge(&existing)).Error
+	})
+}
+
+// ListResultsByClassId 查询班级下所有成绩记录
+func (d *sectionResultDAOImpl) ListResultsByClassId(classId string) ([]*class.ClassSectionResult, error) {
+	var results []*class.ClassSectionResult
+	err := DB.Where("class_id = ?", classId).Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ListResultsBySectionId 查询某小节所有学生的成绩记录
+func (d *sectionResultDAOImpl) ListResultsBySectionId(sectionId string) ([]*class.ClassSectionResult, error) {
+	var results []*class.ClassSectionResult
+	err := DB.Where("section_id = ?", sectionId).Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ListResultsByStudent 查询学生在班级内的所有成绩记录
+func (d *sectionResultDAOImpl) ListResultsByStudent(classId, studentId string) ([]*class.ClassSectionResult, error) {
+	var results []*class.ClassSectionResult
+	err := DB.Where("class_id = ? AND student_id = ?", classId, studentId).Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/yzf120/elysia-backend/model/class"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SectionViewDAO 学习资源查看记录数据访问对象
+type SectionViewDAO interface {
+	// RecordView 记录一次查看（首次查看创建记录并返回 true，之后累加查看次数）
+	RecordView(classId, sectionId, studentId string, now time.Time) (bool, error)
+	ListViewsBySectionId(sectionId string) ([]*class.ClassSectionView, error)
+	// ListViewedSectionIds 查询学生已查看过的小节ID
+	ListViewedSectionIds(studentId string, sectionIds []string) (map[string]bool, error)
+	// ListActiveStudentIdsSince 查询指定时间之后在班级内查看过学习资源的学生ID
+	ListActiveStudentIdsSince(classId string, since time.Time) (map[string]bool, error)
+}
+
+type sectionViewDAOImpl struct{}
+
+// NewSectionViewDAO 创建学习资源查看记录DAO
+func NewSectionViewDAO() SectionViewDAO {
+	return &sectionViewDAOImpl{}
+}
+
+// RecordView 记录一次查看（INSERT ... ON DUPLICATE KEY UPDATE，并发首次查看不会撞唯一键）
+// MySQL 对 upsert 插入返回影响行数 1，更新返回 2，据此判断是否首次查看
+func (d *sectionViewDAOImpl) RecordView(classId, sectionId, studentId string, now time.Time) (bool, error) {
+	result := DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "section_id"}, {Name: "student_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + 1"),
+			"last_view_time": now,
+		}),
+	}).Create(&class.ClassSectionView{
+		SectionId:     sectionId,
+		StudentId:     studentId,
+		ClassId:       classId,
+		ViewCount:     1,
+		FirstViewTime: now,
+		LastViewTime:  now,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ListViewsBySectionId 查询小节的所有查看记录（按首次查看时间升序）
+func (d *sectionViewDAOImpl) ListViewsBySectionId(sectionId string) ([]*class.ClassSectionView, error) {
+	var list []*class.ClassSectionView
+	err := DB.Where("section_id = ?", sectionId).Order("first_view_time ASC, id ASC").Find(&list).Error
+	return list, err
+}
+
+// ListViewedSectionIds 查询学生已查看过的小节ID
+func (d *sectionViewDAOImpl) ListViewedSectionIds(studentId string, sectionIds []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(sectionIds) == 0 {
+		return result, nil
+	}
+	var ids []string
+	err := DB.Model(&class.ClassSectionView{}).
+		Where("student_id = ? AND section_id IN ?", studentId, sectionIds).
+		Pluck("section_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// ListActiveStudentIdsSince 查询指定时间之后在班级内查看过学习资源的学生ID
+func (d *sectionViewDAOImpl) ListActiveStudentIdsSince(classId string, since time.Time) (map[string]bool, error) {
+	result := make(map[string]bool)
+	var ids []string
+	err := DB.Model(&class.ClassSectionView{}).
+		Where("class_id = ? AND last_view_time >= ?", classId, since).
+		Distinct("student_id").Pluck("student_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
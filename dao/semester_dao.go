@@ -1,6 +1,8 @@
 package dao
 
 import (
+	"time"
+
 	"github.com/yzf120/elysia-backend/model/subject"
 )
 
@@ -9,6 +11,8 @@ type SemesterDAO interface {
 	GetSemesterById(semesterId string) (*subject.Semester, error)
 	GetSemesterByName(semesterName string) (*subject.Semester, error)
 	ListSemesters(status int32) ([]*subject.Semester, error)
+	GetSemesterByDate(date time.Time) (*subject.Semester, error)
+	ListSemestersEndedBefore(date time.Time) ([]*subject.Semester, error)
 }
 
 type semesterDAOImpl struct{}
@@ -51,3 +55,23 @@ func (d *semesterDAOImpl) ListSemesters(status int32) ([]*subject.Semester, erro
 	err := query.Order("year DESC, term DESC").Find(&semesters).Error
 	return semesters, err
 }
+
+// GetSemesterByDate 查询日期所在的启用学期（开始日期与结束日期均包含在内）
+func (d *semesterDAOImpl) GetSemesterByDate(date time.Time) (*subject.Semester, error) {
+	db := DB
+	var semester subject.Semester
+	day := date.Format("2006-01-02")
+	err := db.Where("start_date <= ? AND end_date >= ? AND status = 1", day, day).Order("start_date DESC").First(&semester).Error
+	if err != nil {
+		return nil, err
+	}
+	return &semester, nil
+}
+
+// ListSemestersEndedBefore 查询结束日期早于指定日期的学期（含已禁用学期）
+func (d *semesterDAOImpl) ListSemestersEndedBefore(date time.Time) ([]*subject.Semester, error) {
+	db := DB
+	var semesters []*subject.Semester
+	err := db.Where("end_date < ?", date.Format("2006-01-02")).Find(&semesters).Error
+	return semesters, err
+}
@@ -0,0 +1,108 @@
+package dao
+
+import (
+	"github.com/yzf120/elysia-backend/model/mistake"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StudentMistakeDAO 错题本数据访问对象
+type StudentMistakeDAO interface {
+	CreateMistake(m *mistake.StudentMistake) error
+	GetMistakeById(mistakeId string) (*mistake.StudentMistake, error)
+	GetMistakeByStudentProblem(studentId string, problemId int64) (*mistake.StudentMistake, error)
+	UpdateMistake(mistakeId string, updates map[string]interface{}) error
+	// RecordFailure 记录一次失败：不存在时插入，已存在时累加次数并重新打开（依赖 uk_student_problem 保证并发安全）
+	RecordFailure(m *mistake.StudentMistake) error
+	// ListMistakesByStudent 分页查询学生错题（status<0 表示不过滤状态，reason 为空表示不过滤原因）
+	ListMistakesByStudent(studentId string, status int32, reason string, limit, offset int32) ([]*mistake.StudentMistake, error)
+	CountMistakesByStudent(studentId string, status int32, reason string) (int64, error)
+}
+
+type studentMistakeDAOImpl struct{}
+
+// NewStudentMistakeDAO 创建错题本DAO
+func NewStudentMistakeDAO() StudentMistakeDAO {
+	return &studentMistakeDAOImpl{}
+}
+
+// CreateMistake 创建错题记录
+func (d *studentMistakeDAOImpl) CreateMistake(m *mistake.StudentMistake) error {
+	return DB.Create(m).Error
+}
+
+// GetMistakeById 根据错题ID查询
+func (d *studentMistakeDAOImpl) GetMistakeById(mistakeId string) (*mistake.StudentMistake, error) {
+	var m mistake.StudentMistake
+	err := DB.Where("mistake_id = ?", mistakeId).First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMistakeByStudentProblem 根据学生和题目查询错题
+func (d *studentMistakeDAOImpl) GetMistakeByStudentProblem(studentId string, problemId int64) (*mistake.StudentMistake, error) {
+	var m mistake.StudentMistake
+	err := DB.Where("student_id = ? AND problem_id = ?", studentId, problemId).First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// UpdateMistake 更新错题记录
+func (d *studentMistakeDAOImpl) UpdateMistake(mistakeId string, updates map[string]interface{}) error {
+	return DB.Model(&mistake.StudentMistake{}).Where("mistake_id = ?", mistakeId).Updates(updates).Error
+}
+
+// RecordFailure 记录一次失败（INSERT ... ON DUPLICATE KEY UPDATE）
+func (d *studentMistakeDAOImpl) RecordFailure(m *mistake.StudentMistake) error {
+	reasonPath := "$." + m.FailReason
+	return DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "student_id"}, {Name: "problem_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"fail_reason": m.FailReason,
+			"reason_stats": gorm.Expr("JSON_SET(COALESCE(reason_stats, JSON_OBJECT()), ?, COALESCE(JSON_EXTRACT(reason_stats, ?), 0) + 1)",
+				reasonPath, reasonPath),
+			"fail_count":     gorm.Expr("fail_count + 1"),
+			"failed_run_id":  m.FailedRunId,
+			"last_fail_time": m.LastFailTime,
+			"status":         mistake.MistakeStatusUnresolved,
+			"passed_run_id":  0,
+			"fixed_time":     nil,
+			"mastered_time":  nil,
+		}),
+	}).Create(m).Error
+}
+
+// ListMistakesByStudent 分页查询学生错题（按最近失败时间倒序）
+func (d *studentMistakeDAOImpl) ListMistakesByStudent(studentId string, status int32, reason string, limit, offset int32) ([]*mistake.StudentMistake, error) {
+	var list []*mistake.StudentMistake
+	query := DB.Where("student_id = ?", studentId)
+	if status >= 0 {
+		query = query.Where("status = ?", status)
+	}
+	if reason != "" {
+		query = query.Where("fail_reason = ?", reason)
+	}
+	err := query.Order("last_fail_time DESC").Limit(int(limit)).Offset(int(offset)).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountMistakesByStudent 统计学生错题数量
+func (d *studentMistakeDAOImpl) CountMistakesByStudent(studentId string, status int32, reason string) (int64, error) {
+	var count int64
+	query := DB.Model(&mistake.StudentMistake{}).Where("student_id = ?", studentId)
+	if status >= 0 {
+		query = query.Where("status = ?", status)
+	}
+	if reason != "" {
+		query = query.Where("fail_reason = ?", reason)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
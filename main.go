@@ -8,6 +8,7 @@ import (
 	"github.com/yzf120/elysia-backend/middleware"
 	"github.com/yzf120/elysia-backend/router"
 	"github.com/yzf120/elysia-backend/rpc"
+	"github.com/yzf120/elysia-backend/service"
 	"log"
 	"trpc.group/trpc-go/trpc-go"
 	thttp "trpc.group/trpc-go/trpc-go/http"
@@ -47,6 +48,15 @@ func main() {
 
 	router.RegisterRouter(r)
 
+	// 启动班级生命周期后台任务（按学期结束日期自动结束、归档班级）
+	service.StartClassLifecycleScheduler()
+
+	// 启动教学洞察每日摘要后台任务（为订阅的教师生成班级学情摘要）
+	service.StartClassInsightDigestScheduler()
+
+	// 启动 AI 对话记录补写后台任务（重试写入 session 服务失败的对话记录）
+	service.StartAIChatOutboxScheduler()
+
 	// 创建带 CORS 的 handler（包装整个路由器）
 	corsHandler := middleware.CORS(r)
 
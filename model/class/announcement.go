@@ -0,0 +1,37 @@
+package class
+
+import "time"
+
+// ClassAnnouncement 班级公告数据模型
+type ClassAnnouncement struct {
+	Id             int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AnnouncementId string     `gorm:"column:announcement_id;type:varchar(64);uniqueIndex;not null" json:"announcement_id"`
+	ClassId        string     `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_id" json:"class_id"`
+	TeacherId      string     `gorm:"column:teacher_id;type:varchar(64);not null" json:"teacher_id"`
+	Title          string     `gorm:"column:title;type:varchar(256);not null" json:"title"`
+	Content        string     `gorm:"column:content;type:text" json:"content"`
+	IsPinned       bool       `gorm:"column:is_pinned;not null;default:false" json:"is_pinned"` // 是否置顶
+	ExpireTime     *time.Time `gorm:"column:expire_time;type:datetime" json:"expire_time"`      // 过期时间（为空表示不过期，过期后学生不可见）
+	PublishTime    time.Time  `gorm:"column:publish_time;type:datetime;not null" json:"publish_time"`
+	CreateTime     time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime     time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassAnnouncement) TableName() string {
+	return "class_announcement"
+}
+
+// ClassAnnouncementRead 公告已读回执数据模型
+type ClassAnnouncementRead struct {
+	Id             int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AnnouncementId string    `gorm:"column:announcement_id;type:varchar(64);not null;uniqueIndex:uk_announcement_student" json:"announcement_id"`
+	ClassId        string    `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_student" json:"class_id"`
+	StudentId      string    `gorm:"column:student_id;type:varchar(64);not null;uniqueIndex:uk_announcement_student;index:idx_class_student" json:"student_id"`
+	ReadTime       time.Time `gorm:"column:read_time;type:datetime;not null" json:"read_time"`
+}
+
+// TableName 指定表名
+func (ClassAnnouncementRead) TableName() string {
+	return "class_announcement_read"
+}
@@ -0,0 +1,65 @@
+package class
+
+import "time"
+
+// 签到场次状态
+const (
+	AttendanceSessionOpen   = 0 // 签到中
+	AttendanceSessionClosed = 1 // 已结束（未签到的学生已记为缺勤）
+)
+
+// 考勤状态
+const (
+	AttendancePresent = 1 // 出勤
+	AttendanceLate    = 2 // 迟到
+	AttendanceAbsent  = 3 // 缺勤
+	AttendanceExcused = 4 // 请假
+)
+
+// 考勤记录来源
+const (
+	AttendanceSourceCode    = "code"    // 学生输入签到码
+	AttendanceSourceTeacher = "teacher" // 教师手动标记
+	AttendanceSourceSystem  = "system"  // 签到结束后系统记为缺勤
+)
+
+// ClassAttendanceSession 课堂签到场次
+type ClassAttendanceSession struct {
+	Id           int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	SessionId    string     `gorm:"column:session_id;type:varchar(64);uniqueIndex;not null" json:"session_id"`
+	ClassId      string     `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_status" json:"class_id"`
+	Title        string     `gorm:"column:title;type:varchar(128);not null;default:''" json:"title"`
+	OpenedBy     string     `gorm:"column:opened_by;type:varchar(64);not null" json:"opened_by"`                        // 发起签到的教学团队成员ID
+	StartTime    time.Time  `gorm:"column:start_time;type:datetime;not null" json:"start_time"`                         // 签到开始时间
+	LateTime     time.Time  `gorm:"column:late_time;type:datetime;not null" json:"late_time"`                           // 此时间之后签到记为迟到
+	EndTime      time.Time  `gorm:"column:end_time;type:datetime;not null" json:"end_time"`                             // 签到截止时间（教师提前结束时更新为结束时间）
+	CodeInterval int32      `gorm:"column:code_interval;type:int;not null;default:30" json:"code_interval"`             // 签到码轮换间隔（秒）
+	Status       int32      `gorm:"column:status;type:tinyint;not null;default:0;index:idx_class_status" json:"status"` // 0-签到中，1-已结束
+	ClosedAt     *time.Time `gorm:"column:closed_at;type:datetime" json:"closed_at"`
+	CreateTime   time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime   time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassAttendanceSession) TableName() string {
+	return "class_attendance_session"
+}
+
+// ClassAttendanceRecord 学生考勤记录（每个学生每个签到场次一条）
+type ClassAttendanceRecord struct {
+	Id          int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	SessionId   string     `gorm:"column:session_id;type:varchar(64);not null;uniqueIndex:uk_session_student" json:"session_id"`
+	ClassId     string     `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_student" json:"class_id"`
+	StudentId   string     `gorm:"column:student_id;type:varchar(64);not null;uniqueIndex:uk_session_student;index:idx_class_student" json:"student_id"`
+	Status      int32      `gorm:"column:status;type:tinyint;not null" json:"status"`                      // 1-出勤，2-迟到，3-缺勤，4-请假
+	CheckinTime *time.Time `gorm:"column:checkin_time;type:datetime" json:"checkin_time"`                  // 签到时间（缺勤、请假为空）
+	Source      string     `gorm:"column:source;type:varchar(16);not null;default:''" json:"source"`       // code / teacher / system
+	MarkedBy    string     `gorm:"column:marked_by;type:varchar(64);not null;default:''" json:"marked_by"` // 手动标记的教学团队成员ID
+	CreateTime  time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime  time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassAttendanceRecord) TableName() string {
+	return "class_attendance_record"
+}
@@ -6,19 +6,37 @@ import "time"
 const (
 	SectionTypeProblem    = 1 // 算法题
 	SectionTypeDiscussion = 2 // 讨论话题
+	SectionTypeQuiz       = 3 // 客观题测验
+	SectionTypeDocument   = 4 // 阅读资料
+	SectionTypeVideo      = 5 // 视频
+)
+
+// ResourceSource 阅读资料来源
+const (
+	ResourceSourceBookshelf = "bookshelf" // 引用平台书架条目
+	ResourceSourceUpload    = "upload"    // 班级私有上传文件
+)
+
+// LatePolicy 作业迟交策略
+const (
+	LatePolicyAllow     = 0 // 允许迟交，不扣分
+	LatePolicyPenalty   = 1 // 允许迟交，按天扣分
+	LatePolicyHardClose = 2 // 截止后禁止提交
 )
 
 // ClassChapter 班级章节数据模型
 type ClassChapter struct {
-	Id          int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	ChapterId   string    `gorm:"column:chapter_id;type:varchar(64);uniqueIndex;not null" json:"chapter_id"`
-	ClassId     string    `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_id" json:"class_id"`
-	Title       string    `gorm:"column:title;type:varchar(256);not null" json:"title"`
-	Description string    `gorm:"column:description;type:text" json:"description"`
-	SortOrder   int32     `gorm:"column:sort_order;type:int;not null;default:0" json:"sort_order"`
-	Status      int32     `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`
-	CreateTime  time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
-	UpdateTime  time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+	Id          int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ChapterId   string     `gorm:"column:chapter_id;type:varchar(64);uniqueIndex;not null" json:"chapter_id"`
+	ClassId     string     `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_id" json:"class_id"`
+	Title       string     `gorm:"column:title;type:varchar(256);not null" json:"title"`
+	Description string     `gorm:"column:description;type:text" json:"description"`
+	SortOrder   int32      `gorm:"column:sort_order;type:int;not null;default:0" json:"sort_order"`
+	Weight      int32      `gorm:"column:weight;type:int;not null;default:1" json:"weight"` // 成绩权重（成绩册按章节加权计算总评）
+	ReleaseAt   *time.Time `gorm:"column:release_at;type:datetime" json:"release_at"`       // 定时发布时间（为空表示立即对学生可见）
+	Status      int32      `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`
+	CreateTime  time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime  time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
 }
 
 // TableName 指定表名
@@ -38,12 +56,34 @@ type ClassSection struct {
 	// 算法题关联字段（section_type=1 时使用，关联题库）
 	ProblemId string `gorm:"column:problem_id;type:varchar(64);not null;default:''" json:"problem_id"`
 	// 讨论内容字段（section_type=2 时使用）
-	DiscussionTitle   string    `gorm:"column:discussion_title;type:varchar(256);not null;default:''" json:"discussion_title"`
-	DiscussionContent string    `gorm:"column:discussion_content;type:text" json:"discussion_content"`
-	SortOrder         int32     `gorm:"column:sort_order;type:int;not null;default:0" json:"sort_order"`
-	Status            int32     `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`
-	CreateTime        time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
-	UpdateTime        time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+	DiscussionTitle   string `gorm:"column:discussion_title;type:varchar(256);not null;default:''" json:"discussion_title"`
+	DiscussionContent string `gorm:"column:discussion_content;type:text" json:"discussion_content"`
+	// 作业设置字段（section_type=1、3 时使用，均为空/0 表示不限制；测验不使用迟交策略）
+	OpenTime    *time.Time `gorm:"column:open_time;type:datetime" json:"open_time"`                       // 开放时间
+	DueTime     *time.Time `gorm:"column:due_time;type:datetime" json:"due_time"`                         // 截止时间
+	LatePolicy  int32      `gorm:"column:late_policy;type:tinyint;not null;default:0" json:"late_policy"` // 迟交策略：0-允许迟交，1-按天扣分，2-截止后禁止提交
+	LatePenalty int32      `gorm:"column:late_penalty;type:int;not null;default:0" json:"late_penalty"`   // 每迟交一天扣除的分数百分比（late_policy=1 时使用）
+	MaxAttempts int32      `gorm:"column:max_attempts;type:int;not null;default:0" json:"max_attempts"`   // 最大提交次数（0-不限）
+	// 测验设置字段（section_type=3 时使用，提交次数使用 max_attempts）
+	QuizShuffle   bool  `gorm:"column:quiz_shuffle;not null;default:false" json:"quiz_shuffle"`            // 每次作答是否打乱题目顺序
+	QuizTimeLimit int32 `gorm:"column:quiz_time_limit;type:int;not null;default:0" json:"quiz_time_limit"` // 作答时限（分钟，0-不限）
+	// 学习资源字段（section_type=4、5 时使用）
+	ResourceSource        string `gorm:"column:resource_source;type:varchar(16);not null;default:''" json:"resource_source"`            // 阅读资料来源：bookshelf-平台书架，upload-班级上传
+	BookshelfItemId       string `gorm:"column:bookshelf_item_id;type:varchar(64);not null;default:''" json:"bookshelf_item_id"`        // 引用的平台书架条目ID
+	AttachmentName        string `gorm:"column:attachment_name;type:varchar(255);not null;default:''" json:"attachment_name"`           // 上传文件原始文件名
+	AttachmentStorageName string `gorm:"column:attachment_storage_name;type:varchar(255);not null;default:''" json:"-"`                 // 上传文件存储名
+	AttachmentMimeType    string `gorm:"column:attachment_mime_type;type:varchar(128);not null;default:''" json:"attachment_mime_type"` // 上传文件 MIME 类型
+	AttachmentSize        int64  `gorm:"column:attachment_size;type:bigint;not null;default:0" json:"attachment_size"`                  // 上传文件大小（字节）
+	VideoUrl              string `gorm:"column:video_url;type:varchar(1024);not null;default:''" json:"video_url"`                      // 外部视频链接
+	// 小组设置（section_type=1、2 时使用）
+	GroupMode bool `gorm:"column:group_mode;not null;default:false" json:"group_mode"` // 小组模式：算法题的提交计入全组成员，讨论仅组内可见
+	// 发布与解锁设置
+	ReleaseAt       *time.Time `gorm:"column:release_at;type:datetime" json:"release_at"`         // 定时发布时间（为空表示立即对学生可见）
+	PrerequisiteIds string     `gorm:"column:prerequisite_ids;type:json" json:"prerequisite_ids"` // 前置小节ID列表（JSON数组，全部完成后解锁）
+	SortOrder       int32      `gorm:"column:sort_order;type:int;not null;default:0" json:"sort_order"`
+	Status          int32      `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`
+	CreateTime      time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime      time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
 }
 
 // TableName 指定表名
@@ -17,9 +17,14 @@ type Class struct {
 	MaxStudents     int32     `gorm:"column:max_students;type:int;not null;default:100" json:"max_students"`
 	CurrentStudents int32     `gorm:"column:current_students;type:int;not null;default:0" json:"current_students"`
 	Description     string    `gorm:"column:description;type:text" json:"description"`
-	Announcement    string    `gorm:"column:announcement;type:text" json:"announcement"`
+	Announcement    string    `gorm:"column:announcement;type:text" json:"announcement"` // 已废弃：班级公告已迁移至 class_announcement 表
 	QrCodeUrl       string    `gorm:"column:qr_code_url;type:varchar(512)" json:"qr_code_url"`
-	ChapterIds      string    `gorm:"column:chapter_ids;type:json" json:"chapter_ids"` // 章节id列表（有序JSON数组）
+	JoinPolicy      int32     `gorm:"column:join_policy;type:tinyint;not null;default:0" json:"join_policy"`    // 加入策略：0-开放，1-需审批，2-关闭
+	WaitlistEnabled bool      `gorm:"column:waitlist_enabled;not null;default:false" json:"waitlist_enabled"`   // 满员后是否开启候补
+	LeaderboardOff  bool      `gorm:"column:leaderboard_off;not null;default:false" json:"leaderboard_off"`     // 教师是否关闭班级排行榜
+	GroupSelfEnroll bool      `gorm:"column:group_self_enroll;not null;default:false" json:"group_self_enroll"` // 是否允许学生自建/自选小组
+	GroupMaxSize    int32     `gorm:"column:group_max_size;type:int;not null;default:5" json:"group_max_size"`  // 学生自建小组的人数上限
+	ChapterIds      string    `gorm:"column:chapter_ids;type:json" json:"chapter_ids"`                          // 章节id列表（有序JSON数组）
 	Status          int32     `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`
 	CreateTime      time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
 	UpdateTime      time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
@@ -32,14 +37,16 @@ func (Class) TableName() string {
 
 // ClassMember 班级成员关联数据模型
 type ClassMember struct {
-	Id         int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
-	ClassId    string    `gorm:"column:class_id;type:varchar(64);not null" json:"class_id"`
-	StudentId  string    `gorm:"column:student_id;type:varchar(64);not null" json:"student_id"`
-	JoinTime   time.Time `gorm:"column:join_time;type:datetime;autoCreateTime" json:"join_time"`
-	Status     int32     `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`
-	Remark     string    `gorm:"column:remark;type:varchar(512)" json:"remark"`
-	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
-	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+	Id                    int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ClassId               string    `gorm:"column:class_id;type:varchar(64);not null" json:"class_id"`
+	StudentId             string    `gorm:"column:student_id;type:varchar(64);not null" json:"student_id"`
+	JoinTime              time.Time `gorm:"column:join_time;type:datetime;autoCreateTime" json:"join_time"`
+	Status                int32     `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`
+	Remark                string    `gorm:"column:remark;type:varchar(512)" json:"remark"`
+	InviteCode            string    `gorm:"column:invite_code;type:varchar(32);not null;default:''" json:"invite_code"`                  // 加入时使用的邀请码（班级验证码或邀请链接码）
+	LeaderboardVisibility int32     `gorm:"column:leaderboard_visibility;type:tinyint;not null;default:0" json:"leaderboard_visibility"` // 排行榜展示方式：0-实名，1-匿名，2-不参与
+	CreateTime            time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime            time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
 }
 
 // TableName 指定表名
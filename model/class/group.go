@@ -0,0 +1,51 @@
+package class
+
+import "time"
+
+// 小组成员角色
+const (
+	GroupRoleLeader = "leader" // 组长
+	GroupRoleMember = "member" // 组员
+)
+
+// 小组创建方式
+const (
+	GroupCreatorTeacher = "teacher" // 教师创建
+	GroupCreatorStudent = "student" // 学生自建
+)
+
+// ClassGroup 班级学生小组（项目制课程分组）
+type ClassGroup struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	GroupId     string    `gorm:"column:group_id;type:varchar(64);uniqueIndex;not null" json:"group_id"`
+	ClassId     string    `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_id" json:"class_id"`
+	Name        string    `gorm:"column:name;type:varchar(128);not null" json:"name"`
+	Description string    `gorm:"column:description;type:varchar(512);not null;default:''" json:"description"`
+	MaxSize     int32     `gorm:"column:max_size;type:int;not null;default:5" json:"max_size"`              // 人数上限
+	LeaderId    string    `gorm:"column:leader_id;type:varchar(64);not null;default:''" json:"leader_id"`   // 组长学生ID（无成员时为空）
+	CreatorType string    `gorm:"column:creator_type;type:varchar(16);not null" json:"creator_type"`        // 创建方式：teacher/student
+	CreatedBy   string    `gorm:"column:created_by;type:varchar(64);not null;default:''" json:"created_by"` // 创建人ID
+	MemberCount int32     `gorm:"column:member_count;type:int;not null;default:0" json:"member_count"`      // 当前人数
+	CreateTime  time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime  time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassGroup) TableName() string {
+	return "class_group"
+}
+
+// ClassGroupMember 小组成员（一个学生在一个班级内最多属于一个小组）
+type ClassGroupMember struct {
+	Id        int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	GroupId   string    `gorm:"column:group_id;type:varchar(64);not null;index:idx_group_id" json:"group_id"`
+	ClassId   string    `gorm:"column:class_id;type:varchar(64);not null;uniqueIndex:uk_class_student" json:"class_id"`
+	StudentId string    `gorm:"column:student_id;type:varchar(64);not null;uniqueIndex:uk_class_student" json:"student_id"`
+	Role      string    `gorm:"column:role;type:varchar(16);not null;default:'member'" json:"role"` // 角色：leader/member
+	JoinTime  time.Time `gorm:"column:join_time;type:datetime;autoCreateTime" json:"join_time"`
+}
+
+// TableName 指定表名
+func (ClassGroupMember) TableName() string {
+	return "class_group_member"
+}
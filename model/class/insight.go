@@ -0,0 +1,33 @@
+package class
+
+import "time"
+
+// ClassInsightSubscription 教学洞察每日摘要订阅（教学团队成员按班级订阅）
+type ClassInsightSubscription struct {
+	Id         int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ClassId    string    `gorm:"column:class_id;type:varchar(64);not null;uniqueIndex:uk_class_teacher" json:"class_id"`
+	TeacherId  string    `gorm:"column:teacher_id;type:varchar(64);not null;uniqueIndex:uk_class_teacher" json:"teacher_id"`
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+}
+
+// TableName 指定表名
+func (ClassInsightSubscription) TableName() string {
+	return "class_insight_subscription"
+}
+
+// ClassInsightDigest 教学洞察每日摘要通知（每个订阅者每个班级每天一条）
+type ClassInsightDigest struct {
+	Id         int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ClassId    string    `gorm:"column:class_id;type:varchar(64);not null;uniqueIndex:uk_class_teacher_date" json:"class_id"`
+	TeacherId  string    `gorm:"column:teacher_id;type:varchar(64);not null;uniqueIndex:uk_class_teacher_date;index:idx_teacher" json:"teacher_id"` // 接收教师ID
+	DigestDate string    `gorm:"column:digest_date;type:varchar(10);not null;uniqueIndex:uk_class_teacher_date" json:"digest_date"`                 // 摘要日期（2006-01-02）
+	Summary    string    `gorm:"column:summary;type:varchar(512);not null;default:''" json:"summary"`                                               // 摘要文字
+	Content    string    `gorm:"column:content;type:json" json:"content"`                                                                           // 洞察详情（JSON）
+	IsRead     bool      `gorm:"column:is_read;not null;default:false;index:idx_teacher" json:"is_read"`
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+}
+
+// TableName 指定表名
+func (ClassInsightDigest) TableName() string {
+	return "class_insight_digest"
+}
@@ -0,0 +1,23 @@
+package class
+
+import "time"
+
+// ClassInvite 班级邀请链接数据模型（班级验证码之外的附加邀请，可设置有效期与使用次数）
+type ClassInvite struct {
+	Id         int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	InviteCode string     `gorm:"column:invite_code;type:varchar(32);uniqueIndex;not null" json:"invite_code"`
+	ClassId    string     `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_id" json:"class_id"`
+	TeacherId  string     `gorm:"column:teacher_id;type:varchar(64);not null" json:"teacher_id"`
+	ExpireTime *time.Time `gorm:"column:expire_time;type:datetime" json:"expire_time"`               // 过期时间（为空表示不过期）
+	MaxUses    int32      `gorm:"column:max_uses;type:int;not null;default:0" json:"max_uses"`       // 最大使用次数（0-不限）
+	UsedCount  int32      `gorm:"column:used_count;type:int;not null;default:0" json:"used_count"`   // 已使用次数
+	Status     int32      `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`       // 状态：0-已撤销，1-有效
+	Remark     string     `gorm:"column:remark;type:varchar(256);not null;default:''" json:"remark"` // 备注（如发放渠道）
+	CreateTime time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassInvite) TableName() string {
+	return "class_invite"
+}
@@ -0,0 +1,25 @@
+package class
+
+import "time"
+
+// ClassJoinRequest 加入班级申请数据模型（需审批班级的申请与满员候补队列）
+type ClassJoinRequest struct {
+	Id           int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	RequestId    string     `gorm:"column:request_id;type:varchar(64);uniqueIndex;not null" json:"request_id"`
+	ClassId      string     `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_status" json:"class_id"`
+	StudentId    string     `gorm:"column:student_id;type:varchar(64);not null;index:idx_student_id" json:"student_id"`
+	InviteCode   string     `gorm:"column:invite_code;type:varchar(32);not null;default:''" json:"invite_code"`         // 申请时使用的邀请码
+	Message      string     `gorm:"column:message;type:varchar(512);not null;default:''" json:"message"`                // 申请留言
+	Status       int32      `gorm:"column:status;type:tinyint;not null;default:0;index:idx_class_status" json:"status"` // 状态：0-待审批，1-已通过，2-已拒绝，3-候补中，4-已取消
+	WaitlistTime *time.Time `gorm:"column:waitlist_time;type:datetime" json:"waitlist_time"`                            // 进入候补队列时间（按此先后递补）
+	RejectReason string     `gorm:"column:reject_reason;type:varchar(512);not null;default:''" json:"reject_reason"`
+	HandledBy    string     `gorm:"column:handled_by;type:varchar(64);not null;default:''" json:"handled_by"` // 处理人（教师ID，候补自动递补为 system）
+	HandleTime   *time.Time `gorm:"column:handle_time;type:datetime" json:"handle_time"`
+	CreateTime   time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime   time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassJoinRequest) TableName() string {
+	return "class_join_request"
+}
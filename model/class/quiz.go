@@ -0,0 +1,64 @@
+package class
+
+import "time"
+
+// QuizQuestionType 测验题型
+const (
+	QuizQuestionSingle    = "single"     // 单选题
+	QuizQuestionMultiple  = "multiple"   // 多选题
+	QuizQuestionTrueFalse = "true_false" // 判断题
+	QuizQuestionFillBlank = "fill_blank" // 填空题
+)
+
+// QuizAttemptStatus 测验作答状态
+const (
+	QuizAttemptInProgress = 0 // 作答中
+	QuizAttemptSubmitted  = 1 // 已交卷
+)
+
+// ClassQuizQuestion 测验题目（归属于测验小节）
+type ClassQuizQuestion struct {
+	Id           int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	QuestionId   string    `gorm:"column:question_id;type:varchar(64);uniqueIndex;not null" json:"question_id"`
+	SectionId    string    `gorm:"column:section_id;type:varchar(64);not null;index:idx_section_id" json:"section_id"`
+	ClassId      string    `gorm:"column:class_id;type:varchar(64);not null" json:"class_id"`
+	QuestionType string    `gorm:"column:question_type;type:varchar(16);not null" json:"question_type"` // 题型：single/multiple/true_false/fill_blank
+	Content      string    `gorm:"column:content;type:text" json:"content"`                             // 题干（Markdown，填空题用 ___ 标记空位）
+	Options      string    `gorm:"column:options;type:json" json:"options"`                             // 选项列表（JSON数组，按 A、B、C… 编号，选择题使用）
+	Answer       string    `gorm:"column:answer;type:json" json:"answer"`                               // 标准答案（JSON数组：选择题为选项字母，判断题为 true/false，填空题每空一项，多个可接受答案用 | 分隔）
+	Explanation  string    `gorm:"column:explanation;type:text" json:"explanation"`                     // 答案解析
+	Score        int32     `gorm:"column:score;type:int;not null;default:1" json:"score"`               // 分值
+	SortOrder    int32     `gorm:"column:sort_order;type:int;not null;default:0" json:"sort_order"`
+	CreateTime   time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime   time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassQuizQuestion) TableName() string {
+	return "class_quiz_question"
+}
+
+// ClassQuizAttempt 学生的一次测验作答
+type ClassQuizAttempt struct {
+	Id          int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	AttemptId   string     `gorm:"column:attempt_id;type:varchar(64);uniqueIndex;not null" json:"attempt_id"`
+	SectionId   string     `gorm:"column:section_id;type:varchar(64);not null;uniqueIndex:uk_section_student_no" json:"section_id"`
+	StudentId   string     `gorm:"column:student_id;type:varchar(64);not null;uniqueIndex:uk_section_student_no" json:"student_id"`
+	ClassId     string     `gorm:"column:class_id;type:varchar(64);not null" json:"class_id"`
+	AttemptNo   int32      `gorm:"column:attempt_no;type:int;not null;default:1;uniqueIndex:uk_section_student_no" json:"attempt_no"` // 第几次作答（同一学生同一测验内唯一，防止并发开始作答超出次数）
+	Status      int32      `gorm:"column:status;type:tinyint;not null;default:0" json:"status"`                                       // 0-作答中，1-已交卷
+	QuestionIds string     `gorm:"column:question_ids;type:json" json:"question_ids"`                                                 // 本次作答的题目顺序（JSON数组）
+	Answers     string     `gorm:"column:answers;type:json" json:"answers"`                                                           // 学生答案（JSON对象：question_id -> 答案数组）
+	Score       int32      `gorm:"column:score;type:int;not null;default:0" json:"score"`                                             // 得分
+	TotalScore  int32      `gorm:"column:total_score;type:int;not null;default:0" json:"total_score"`                                 // 满分
+	StartTime   time.Time  `gorm:"column:start_time;type:datetime;not null" json:"start_time"`                                        // 开始作答时间
+	Deadline    *time.Time `gorm:"column:deadline;type:datetime" json:"deadline"`                                                     // 作答截止时间（为空表示不限时）
+	SubmitTime  *time.Time `gorm:"column:submit_time;type:datetime" json:"submit_time"`                                               // 交卷时间
+	CreateTime  time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime  time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassQuizAttempt) TableName() string {
+	return "class_quiz_attempt"
+}
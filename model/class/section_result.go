@@ -0,0 +1,30 @@
+package class
+
+import "time"
+
+// ClassSectionResult 学生在作业小节上的成绩记录（每个学生每个小节一条）
+type ClassSectionResult struct {
+	Id             int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	SectionId      string     `gorm:"column:section_id;type:varchar(64);not null;uniqueIndex:uk_section_student" json:"section_id"`
+	StudentId      string     `gorm:"column:student_id;type:varchar(64);not null;uniqueIndex:uk_section_student" json:"student_id"`
+	ClassId        string     `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_id" json:"class_id"`
+	ChapterId      string     `gorm:"column:chapter_id;type:varchar(64);not null" json:"chapter_id"`
+	ProblemId      int64      `gorm:"column:problem_id;not null" json:"problem_id"`
+	Attempts       int32      `gorm:"column:attempts;type:int;not null;default:0" json:"attempts"`                      // 已评测的提交次数
+	BestRunId      int64      `gorm:"column:best_run_id;not null;default:0" json:"best_run_id"`                         // 最佳提交（按扣分后得分）
+	BestStatus     string     `gorm:"column:best_status;type:varchar(32);not null;default:''" json:"best_status"`       // 最佳提交的评测状态
+	BestScore      int32      `gorm:"column:best_score;type:int;not null;default:0" json:"best_score"`                  // 最佳得分（0-100，已扣除迟交分）
+	BestSubmitTime *time.Time `gorm:"column:best_submit_time;type:datetime" json:"best_submit_time"`                    // 最佳提交时间
+	IsLate         bool       `gorm:"column:is_late;not null;default:false" json:"is_late"`                             // 最佳提交是否迟交
+	LateDays       int32      `gorm:"column:late_days;type:int;not null;default:0" json:"late_days"`                    // 最佳提交迟交天数
+	OnTimeRunId    int64      `gorm:"column:on_time_run_id;not null;default:0" json:"on_time_run_id"`                   // 截止前最佳提交
+	OnTimeStatus   string     `gorm:"column:on_time_status;type:varchar(32);not null;default:''" json:"on_time_status"` // 截止前最佳提交的评测状态
+	OnTimeScore    int32      `gorm:"column:on_time_score;type:int;not null;default:0" json:"on_time_score"`            // 截止前最佳得分
+	CreateTime     time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime     time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassSectionResult) TableName() string {
+	return "class_section_result"
+}
@@ -0,0 +1,19 @@
+package class
+
+import "time"
+
+// ClassSectionView 学生查看学习资源记录（阅读资料、视频小节，每个学生每个小节一条）
+type ClassSectionView struct {
+	Id            int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	SectionId     string    `gorm:"column:section_id;type:varchar(64);not null;uniqueIndex:uk_section_student" json:"section_id"`
+	StudentId     string    `gorm:"column:student_id;type:varchar(64);not null;uniqueIndex:uk_section_student;index:idx_student_id" json:"student_id"`
+	ClassId       string    `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_id" json:"class_id"`
+	ViewCount     int32     `gorm:"column:view_count;type:int;not null;default:1" json:"view_count"`      // 查看次数
+	FirstViewTime time.Time `gorm:"column:first_view_time;type:datetime;not null" json:"first_view_time"` // 首次查看时间（即完成时间）
+	LastViewTime  time.Time `gorm:"column:last_view_time;type:datetime;not null" json:"last_view_time"`   // 最近查看时间
+}
+
+// TableName 指定表名
+func (ClassSectionView) TableName() string {
+	return "class_section_view"
+}
@@ -0,0 +1,21 @@
+package class
+
+import "time"
+
+// ClassStaff 班级教学团队数据模型（创建者、协同教师与助教）
+type ClassStaff struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ClassId     string    `gorm:"column:class_id;type:varchar(64);not null;uniqueIndex:uk_class_staff" json:"class_id"`
+	StaffId     string    `gorm:"column:staff_id;type:varchar(64);not null;uniqueIndex:uk_class_staff;index:idx_staff_id" json:"staff_id"` // 教师ID或学生ID（学生助教）
+	StaffType   string    `gorm:"column:staff_type;type:varchar(16);not null" json:"staff_type"`                                           // 用户类型：teacher/student
+	Role        string    `gorm:"column:role;type:varchar(16);not null" json:"role"`                                                       // 角色：owner/co_teacher/ta
+	Permissions string    `gorm:"column:permissions;type:json" json:"permissions"`                                                         // 权限列表（JSON数组，创建者忽略此字段）
+	AddedBy     string    `gorm:"column:added_by;type:varchar(64);not null;default:''" json:"added_by"`
+	CreateTime  time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime  time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ClassStaff) TableName() string {
+	return "class_staff"
+}
@@ -7,6 +7,9 @@ type CodeRun struct {
 	Id         int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
 	ProblemId  int64     `gorm:"column:problem_id;not null;index" json:"problem_id"`
 	StudentId  string    `gorm:"column:student_id;type:varchar(64);not null;index" json:"student_id"`
+	ClassId    string    `gorm:"column:class_id;type:varchar(64);not null;default:''" json:"class_id"`     // 班级ID（班级内提交时记录）
+	SectionId  string    `gorm:"column:section_id;type:varchar(64);not null;default:''" json:"section_id"` // 小节ID（班级内提交时记录）
+	ContestId  string    `gorm:"column:contest_id;type:varchar(64);not null;default:''" json:"contest_id"` // 比赛ID（比赛内提交时记录）
 	Language   string    `gorm:"column:language;type:varchar(32);not null" json:"language"`
 	Code       string    `gorm:"column:code;type:longtext;not null" json:"code"`
 	RunType    string    `gorm:"column:run_type;type:enum('test','submit');not null;default:'test'" json:"run_type"`
@@ -7,6 +7,8 @@ type CodeRunRequest struct {
 	Code      string `json:"code"`       // 用户代码
 	RunType   string `json:"run_type"`   // test（测试样例）或 submit（提交）
 	TestInput string `json:"test_input"` // 测试模式下直接传入的样例输入（run_type=test 时使用）
+	SectionId string `json:"section_id"` // 班级小节ID（可选，在班级作业中提交时传入）
+	ContestId string `json:"contest_id"` // 比赛ID（可选，在比赛中提交时传入）
 }
 
 // GetCodeRunResultRequest 查询代码运行结果请求
@@ -0,0 +1,97 @@
+package contest
+
+import "time"
+
+// 比赛赛制
+const (
+	RuleTypeACM = "acm" // ACM 赛制：按通过题数排名，罚时少者优先
+	RuleTypeOI  = "oi"  // OI 赛制：按总分排名，比赛结束前不公布评测结果
+)
+
+// Contest 班级比赛/考试
+type Contest struct {
+	Id             int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ContestId      string    `gorm:"column:contest_id;type:varchar(64);uniqueIndex;not null" json:"contest_id"`
+	ClassId        string    `gorm:"column:class_id;type:varchar(64);not null;index:idx_class_id" json:"class_id"`
+	TeacherId      string    `gorm:"column:teacher_id;type:varchar(64);not null" json:"teacher_id"`
+	Title          string    `gorm:"column:title;type:varchar(256);not null" json:"title"`
+	Description    string    `gorm:"column:description;type:text" json:"description"`
+	RuleType       string    `gorm:"column:rule_type;type:varchar(16);not null;default:'acm'" json:"rule_type"`  // 赛制：acm / oi
+	StartTime      time.Time `gorm:"column:start_time;type:datetime;not null" json:"start_time"`                 // 开始时间
+	EndTime        time.Time `gorm:"column:end_time;type:datetime;not null" json:"end_time"`                     // 结束时间
+	FreezeMinutes  int32     `gorm:"column:freeze_minutes;type:int;not null;default:0" json:"freeze_minutes"`    // 封榜时长（结束前 N 分钟，0-不封榜）
+	PenaltyMinutes int32     `gorm:"column:penalty_minutes;type:int;not null;default:20" json:"penalty_minutes"` // ACM 每次错误提交的罚时（分钟）
+	ProblemIds     string    `gorm:"column:problem_ids;type:json" json:"problem_ids"`                            // 题目ID列表（有序JSON数组）
+	IsExam         bool      `gorm:"column:is_exam;not null;default:false" json:"is_exam"`                       // 考试模式：绑定IP与会话，禁用AI答疑与书架
+	CreateTime     time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime     time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (Contest) TableName() string {
+	return "contest"
+}
+
+// ContestSubmission 比赛提交记录（关联 code_run）
+type ContestSubmission struct {
+	Id         int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ContestId  string    `gorm:"column:contest_id;type:varchar(64);not null;index:idx_contest_student" json:"contest_id"`
+	StudentId  string    `gorm:"column:student_id;type:varchar(64);not null;index:idx_contest_student" json:"student_id"`
+	ProblemId  int64     `gorm:"column:problem_id;not null" json:"problem_id"`
+	RunId      int64     `gorm:"column:run_id;not null;uniqueIndex" json:"run_id"`                        // 关联 code_run.id
+	Status     string    `gorm:"column:status;type:varchar(32);not null;default:'pending'" json:"status"` // 评测状态（同 code_run.status）
+	Score      int32     `gorm:"column:score;type:int;not null;default:0" json:"score"`                   // 得分（0-100）
+	SubmitTime time.Time `gorm:"column:submit_time;type:datetime;not null" json:"submit_time"`            // 提交时间
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ContestSubmission) TableName() string {
+	return "contest_submission"
+}
+
+// 考试事件类型（前端上报）
+const (
+	ExamEventBlur           = "blur"            // 页面失去焦点/切换标签页
+	ExamEventFocus          = "focus"           // 页面重新获得焦点
+	ExamEventPaste          = "paste"           // 粘贴
+	ExamEventCopy           = "copy"            // 复制
+	ExamEventFullscreenExit = "fullscreen_exit" // 退出全屏
+)
+
+// ExamSession 考试会话（学生首次进入考试时绑定 IP 与登录令牌）
+type ExamSession struct {
+	Id             int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ContestId      string    `gorm:"column:contest_id;type:varchar(64);not null;uniqueIndex:uk_contest_student" json:"contest_id"`
+	StudentId      string    `gorm:"column:student_id;type:varchar(64);not null;uniqueIndex:uk_contest_student" json:"student_id"`
+	BindIp         string    `gorm:"column:bind_ip;type:varchar(64);not null;default:''" json:"bind_ip"`     // 绑定的IP
+	TokenHash      string    `gorm:"column:token_hash;type:varchar(64);not null;default:''" json:"-"`        // 绑定的登录令牌（SHA-256）
+	RejectCount    int32     `gorm:"column:reject_count;type:int;not null;default:0" json:"reject_count"`    // 其他设备/IP 被拒绝的次数
+	EnterTime      time.Time `gorm:"column:enter_time;type:datetime;not null" json:"enter_time"`             // 进入考试时间
+	LastActiveTime time.Time `gorm:"column:last_active_time;type:datetime;not null" json:"last_active_time"` // 最近活跃时间
+	CreateTime     time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime     time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (ExamSession) TableName() string {
+	return "exam_session"
+}
+
+// ExamEvent 考试客户端事件日志（切屏、粘贴等，供教师复查）
+type ExamEvent struct {
+	Id         int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ContestId  string     `gorm:"column:contest_id;type:varchar(64);not null;index:idx_contest_student" json:"contest_id"`
+	StudentId  string     `gorm:"column:student_id;type:varchar(64);not null;index:idx_contest_student" json:"student_id"`
+	EventType  string     `gorm:"column:event_type;type:varchar(32);not null" json:"event_type"`      // 事件类型：blur/focus/paste/copy/fullscreen_exit
+	Detail     string     `gorm:"column:detail;type:varchar(1024);not null;default:''" json:"detail"` // 事件详情（如粘贴内容长度）
+	ClientTime *time.Time `gorm:"column:client_time;type:datetime" json:"client_time"`                // 客户端记录时间
+	Ip         string     `gorm:"column:ip;type:varchar(64);not null;default:''" json:"ip"`
+	CreateTime time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+}
+
+// TableName 指定表名
+func (ExamEvent) TableName() string {
+	return "exam_event"
+}
@@ -0,0 +1,47 @@
+package conversation
+
+import "time"
+
+// 额度配置范围
+const (
+	AIQuotaScopeGlobal = "global" // 全平台默认额度（对每个学生的全部用量生效）
+	AIQuotaScopeClass  = "class"  // 班级额度（对学生在该班级内的用量生效）
+)
+
+// AIUsageLedger AI 用量流水（每次模型调用一条）
+type AIUsageLedger struct {
+	Id               int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	StudentId        string    `gorm:"column:student_id;type:varchar(64);not null;index:idx_student_date" json:"student_id"`
+	ClassId          string    `gorm:"column:class_id;type:varchar(64);not null;default:'';index:idx_class_date" json:"class_id"` // 班级内发起的对话记录班级ID
+	ModelId          string    `gorm:"column:model_id;type:varchar(128);not null;default:''" json:"model_id"`
+	SessionId        string    `gorm:"column:session_id;type:varchar(64);not null;default:''" json:"session_id"`
+	Purpose          string    `gorm:"column:purpose;type:varchar(16);not null;default:'chat'" json:"purpose"` // chat-答疑，summary-会话摘要
+	PromptTokens     int64     `gorm:"column:prompt_tokens;not null;default:0" json:"prompt_tokens"`
+	CompletionTokens int64     `gorm:"column:completion_tokens;not null;default:0" json:"completion_tokens"`
+	TotalTokens      int64     `gorm:"column:total_tokens;not null;default:0" json:"total_tokens"`
+	Estimated        bool      `gorm:"column:estimated;not null;default:false" json:"estimated"` // 模型未返回用量时按文本长度估算
+	UsageDate        time.Time `gorm:"column:usage_date;type:date;not null;index:idx_student_date;index:idx_class_date;index:idx_usage_date" json:"usage_date"`
+	CreateTime       time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+}
+
+// TableName 指定表名
+func (AIUsageLedger) TableName() string {
+	return "ai_usage_ledger"
+}
+
+// AIUsageQuota AI 用量额度配置（token 数，0 表示不限）
+type AIUsageQuota struct {
+	Id            int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Scope         string    `gorm:"column:scope;type:varchar(16);not null;uniqueIndex:uk_scope" json:"scope"`                  // global / class
+	ScopeId       string    `gorm:"column:scope_id;type:varchar(64);not null;default:'';uniqueIndex:uk_scope" json:"scope_id"` // 班级额度为班级ID，全局额度为空
+	DailyTokens   int64     `gorm:"column:daily_tokens;not null;default:0" json:"daily_tokens"`                                // 每个学生每日额度
+	MonthlyTokens int64     `gorm:"column:monthly_tokens;not null;default:0" json:"monthly_tokens"`                            // 每个学生每月额度
+	UpdatedBy     string    `gorm:"column:updated_by;type:varchar(64);not null;default:''" json:"updated_by"`                  // 最后修改的管理员ID
+	CreateTime    time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime    time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (AIUsageQuota) TableName() string {
+	return "ai_usage_quota"
+}
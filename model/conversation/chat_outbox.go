@@ -0,0 +1,37 @@
+package conversation
+
+import "time"
+
+// 消息发送方
+const (
+	ChatSenderUser  = 1 // 学生
+	ChatSenderAgent = 2 // AI 助教
+)
+
+// 待投递消息状态
+const (
+	ChatOutboxPending = 0 // 待投递（投递成功后删除）
+	ChatOutboxFailed  = 1 // 超过最大重试次数，需人工处理
+)
+
+// AIChatOutbox AI 对话消息待投递记录（写入 session 服务失败时暂存，由后台任务重试）
+type AIChatOutbox struct {
+	Id            int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	SessionId     string    `gorm:"column:session_id;type:varchar(64);not null;index" json:"session_id"`
+	UserId        string    `gorm:"column:user_id;type:varchar(64);not null" json:"user_id"`
+	ModelId       string    `gorm:"column:model_id;type:varchar(128);not null;default:''" json:"model_id"`
+	SenderType    int32     `gorm:"column:sender_type;type:tinyint;not null" json:"sender_type"` // 1-学生，2-AI 助教
+	Content       string    `gorm:"column:content;type:longtext;not null" json:"content"`
+	MessageSeq    int32     `gorm:"column:message_seq;type:int;not null" json:"message_seq"`
+	Status        int32     `gorm:"column:status;type:tinyint;not null;default:0;index:idx_status_retry" json:"status"` // 0-待投递，1-投递失败
+	RetryCount    int32     `gorm:"column:retry_count;type:int;not null;default:0" json:"retry_count"`
+	NextRetryTime time.Time `gorm:"column:next_retry_time;type:datetime;not null;index:idx_status_retry" json:"next_retry_time"`
+	LastError     string    `gorm:"column:last_error;type:varchar(512);not null;default:''" json:"last_error"`
+	CreateTime    time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime    time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (AIChatOutbox) TableName() string {
+	return "ai_chat_outbox"
+}
@@ -0,0 +1,21 @@
+package conversation
+
+import "time"
+
+// AIChatSession AI 答疑会话的本地元数据（会话归属、消息序号分配与滚动摘要，会话与消息本身存放在 session 服务）
+type AIChatSession struct {
+	Id         int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	SessionId  string    `gorm:"column:session_id;type:varchar(64);uniqueIndex;not null" json:"session_id"`
+	UserId     string    `gorm:"column:user_id;type:varchar(64);not null;index" json:"user_id"`
+	ProblemId  int64     `gorm:"column:problem_id;not null;default:0" json:"problem_id"`
+	LastSeq    int32     `gorm:"column:last_seq;type:int;not null;default:0" json:"last_seq"`       // 已分配的最大消息序号
+	Summary    string    `gorm:"column:summary;type:text" json:"summary"`                           // 早期对话的滚动摘要
+	SummarySeq int32     `gorm:"column:summary_seq;type:int;not null;default:0" json:"summary_seq"` // 摘要覆盖到的消息序号（含）
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (AIChatSession) TableName() string {
+	return "ai_chat_session"
+}
@@ -0,0 +1,82 @@
+package discussion
+
+import "time"
+
+// 帖子状态
+const (
+	PostStatusNormal  = 0 // 正常
+	PostStatusHidden  = 1 // 被教师隐藏（学生不可见）
+	PostStatusDeleted = 2 // 已删除
+)
+
+// 作者类型
+const (
+	AuthorTypeStudent = "student"
+	AuthorTypeTeacher = "teacher"
+)
+
+// 通知类型
+const (
+	NotificationTypeMention = "mention" // 被 @ 提及
+	NotificationTypeReply   = "reply"   // 帖子收到回复
+)
+
+// DiscussionPost 讨论帖（主题帖与回复共用一张表，主题帖 parent_id 为空、root_id 为自身）
+type DiscussionPost struct {
+	Id         int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	PostId     string    `gorm:"column:post_id;type:varchar(64);uniqueIndex;not null" json:"post_id"`
+	ClassId    string    `gorm:"column:class_id;type:varchar(64);not null" json:"class_id"`
+	SectionId  string    `gorm:"column:section_id;type:varchar(64);not null;index:idx_section_parent" json:"section_id"`
+	ParentId   string    `gorm:"column:parent_id;type:varchar(64);not null;default:'';index:idx_section_parent" json:"parent_id"` // 回复的帖子ID（主题帖为空）
+	RootId     string    `gorm:"column:root_id;type:varchar(64);not null;index:idx_root_id" json:"root_id"`                       // 所属主题帖ID
+	GroupId    string    `gorm:"column:group_id;type:varchar(64);not null;default:''" json:"group_id"`                            // 小组模式下所属小组ID（为空表示全班可见）
+	AuthorId   string    `gorm:"column:author_id;type:varchar(64);not null" json:"author_id"`
+	AuthorType string    `gorm:"column:author_type;type:varchar(16);not null" json:"author_type"` // student / teacher
+	Content    string    `gorm:"column:content;type:text" json:"content"`                         // Markdown 正文
+	LikeCount  int32     `gorm:"column:like_count;type:int;not null;default:0" json:"like_count"`
+	ReplyCount int32     `gorm:"column:reply_count;type:int;not null;default:0" json:"reply_count"` // 主题帖下的回复总数
+	IsPinned   bool      `gorm:"column:is_pinned;not null;default:false" json:"is_pinned"`          // 教师置顶（仅主题帖）
+	IsAnswer   bool      `gorm:"column:is_answer;not null;default:false" json:"is_answer"`          // 教师标记为答案（仅回复）
+	Status     int32     `gorm:"column:status;type:tinyint;not null;default:0" json:"status"`       // 0-正常，1-已隐藏，2-已删除
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (DiscussionPost) TableName() string {
+	return "discussion_post"
+}
+
+// DiscussionLike 讨论帖点赞记录
+type DiscussionLike struct {
+	Id         int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	PostId     string    `gorm:"column:post_id;type:varchar(64);not null;uniqueIndex:uk_post_user" json:"post_id"`
+	UserId     string    `gorm:"column:user_id;type:varchar(64);not null;uniqueIndex:uk_post_user" json:"user_id"` // 学生ID/教师ID
+	CreateTime time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+}
+
+// TableName 指定表名
+func (DiscussionLike) TableName() string {
+	return "discussion_like"
+}
+
+// DiscussionNotification 讨论通知（@提及、收到回复）
+type DiscussionNotification struct {
+	Id          int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	RecipientId string    `gorm:"column:recipient_id;type:varchar(64);not null;index:idx_recipient" json:"recipient_id"` // 接收学生ID
+	Type        string    `gorm:"column:type;type:varchar(16);not null" json:"type"`                                     // mention / reply
+	ClassId     string    `gorm:"column:class_id;type:varchar(64);not null" json:"class_id"`
+	SectionId   string    `gorm:"column:section_id;type:varchar(64);not null" json:"section_id"`
+	PostId      string    `gorm:"column:post_id;type:varchar(64);not null" json:"post_id"` // 触发通知的帖子
+	RootId      string    `gorm:"column:root_id;type:varchar(64);not null" json:"root_id"`
+	ActorId     string    `gorm:"column:actor_id;type:varchar(64);not null" json:"actor_id"`
+	ActorName   string    `gorm:"column:actor_name;type:varchar(128);not null;default:''" json:"actor_name"`
+	Excerpt     string    `gorm:"column:excerpt;type:varchar(256);not null;default:''" json:"excerpt"` // 帖子内容摘要
+	IsRead      bool      `gorm:"column:is_read;not null;default:false;index:idx_recipient" json:"is_read"`
+	CreateTime  time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+}
+
+// TableName 指定表名
+func (DiscussionNotification) TableName() string {
+	return "discussion_notification"
+}
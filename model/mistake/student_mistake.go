@@ -0,0 +1,44 @@
+package mistake
+
+import "time"
+
+// 错题状态
+const (
+	MistakeStatusUnresolved = 0 // 未订正（仍未通过）
+	MistakeStatusFixed      = 1 // 已订正（失败后再次提交通过）
+	MistakeStatusMastered   = 2 // 已掌握（学生手动标记）
+)
+
+// 失败原因分类
+const (
+	FailReasonWA  = "WA"  // 答案错误
+	FailReasonTLE = "TLE" // 超时
+	FailReasonMLE = "MLE" // 超内存
+	FailReasonRE  = "RE"  // 运行错误
+	FailReasonCE  = "CE"  // 编译错误
+)
+
+// StudentMistake 学生错题本条目（每个学生每道题一条）
+type StudentMistake struct {
+	Id           int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	MistakeId    string     `gorm:"column:mistake_id;type:varchar(64);uniqueIndex;not null" json:"mistake_id"`
+	StudentId    string     `gorm:"column:student_id;type:varchar(64);not null;uniqueIndex:uk_student_problem" json:"student_id"`
+	ProblemId    int64      `gorm:"column:problem_id;not null;uniqueIndex:uk_student_problem" json:"problem_id"`
+	FailReason   string     `gorm:"column:fail_reason;type:varchar(16);not null;default:''" json:"fail_reason"` // 最近一次失败原因：WA/TLE/MLE/RE/CE
+	ReasonStats  string     `gorm:"column:reason_stats;type:json" json:"reason_stats"`                          // 各失败原因次数（JSON对象）
+	FailCount    int32      `gorm:"column:fail_count;type:int;not null;default:0" json:"fail_count"`            // 累计失败次数
+	FailedRunId  int64      `gorm:"column:failed_run_id;not null;default:0" json:"failed_run_id"`               // 最近一次失败的运行记录ID
+	PassedRunId  int64      `gorm:"column:passed_run_id;not null;default:0" json:"passed_run_id"`               // 订正通过的运行记录ID
+	Status       int32      `gorm:"column:status;type:tinyint;not null;default:0" json:"status"`                // 0-未订正，1-已订正，2-已掌握
+	Note         string     `gorm:"column:note;type:text" json:"note"`                                          // 学生笔记
+	FixedTime    *time.Time `gorm:"column:fixed_time;type:datetime" json:"fixed_time"`                          // 订正时间
+	MasteredTime *time.Time `gorm:"column:mastered_time;type:datetime" json:"mastered_time"`                    // 标记掌握时间
+	LastFailTime time.Time  `gorm:"column:last_fail_time;type:datetime" json:"last_fail_time"`                  // 最近失败时间
+	CreateTime   time.Time  `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
+	UpdateTime   time.Time  `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
+}
+
+// TableName 指定表名
+func (StudentMistake) TableName() string {
+	return "student_mistake"
+}
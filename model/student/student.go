@@ -20,7 +20,7 @@ type Student struct {
 	ProgrammingLevel string    `gorm:"column:programming_level;type:varchar(32)" json:"programming_level"`
 	Interests        string    `gorm:"column:interests;type:text" json:"interests"`
 	LearningTags     string    `gorm:"column:learning_tags;type:text" json:"learning_tags"`
-	LearningProgress string    `gorm:"column:learning_progress;type:text" json:"learning_progress"`
+	LearningProgress string    `gorm:"column:learning_progress;type:text" json:"learning_progress"` // 已废弃：客户端自报进度，班级学习进度由 ProgressService 计算
 	Status           int32     `gorm:"column:status;type:tinyint;not null;default:1" json:"status"`
 	CreateTime       time.Time `gorm:"column:create_time;type:datetime;autoCreateTime" json:"create_time"`
 	UpdateTime       time.Time `gorm:"column:update_time;type:datetime;autoUpdateTime" json:"update_time"`
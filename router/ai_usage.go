@@ -0,0 +1,157 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+var aiUsageService *service.AIUsageService
+
+func RegisterAIUsageRoutes(protectedRouter *mux.Router) {
+	adminRouter := protectedRouter.PathPrefix("/admin/ai").Subrouter()
+	adminRouter.Use(authen.AdminAuthMiddleware)
+
+	adminRouter.HandleFunc("/quota", getAIGlobalQuotaHandler).Methods("GET")
+	adminRouter.HandleFunc("/quota", setAIGlobalQuotaHandler).Methods("POST")
+	adminRouter.HandleFunc("/quota/classes", listAIClassQuotasHandler).Methods("GET")
+	adminRouter.HandleFunc("/quota/classes", setAIClassQuotaHandler).Methods("POST")
+	adminRouter.HandleFunc("/quota/classes/{class_id}", deleteAIClassQuotaHandler).Methods("DELETE")
+
+	adminRouter.HandleFunc("/usage", getAIUsageReportHandler).Methods("GET")
+	adminRouter.HandleFunc("/usage/export", exportAIUsageReportHandler).Methods("GET")
+}
+
+func getAIGlobalQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	quota, err := aiUsageService.GetGlobalQuota()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"code":    consts.SuccessCode,
+		"message": consts.MessageQuerySuccess,
+		"quota":   quota,
+	})
+}
+
+func setAIGlobalQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	adminId, ok := authen.GetAdminIDFromContext(r.Context())
+	if !ok || adminId == "" {
+		writeError(w, http.StatusUnauthorized, "未授权：需要管理员权限")
+		return
+	}
+	var input service.AIQuotaInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "请求参数有误")
+		return
+	}
+	quota, err := aiUsageService.SetGlobalQuota(adminId, input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"code":    consts.SuccessCode,
+		"message": consts.MessageUpdateSuccess,
+		"quota":   quota,
+	})
+}
+
+func listAIClassQuotasHandler(w http.ResponseWriter, r *http.Request) {
+	page := parseIntWithDefault(r.URL.Query().Get("page"), 1)
+	pageSize := parseIntWithDefault(r.URL.Query().Get("page_size"), 10)
+	quotas, total, err := aiUsageService.ListClassQuotas(page, pageSize)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"code":      consts.SuccessCode,
+		"message":   consts.MessageQuerySuccess,
+		"quotas":    quotas,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+func setAIClassQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	adminId, ok := authen.GetAdminIDFromContext(r.Context())
+	if !ok || adminId == "" {
+		writeError(w, http.StatusUnauthorized, "未授权：需要管理员权限")
+		return
+	}
+	var input service.AIQuotaInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "请求参数有误")
+		return
+	}
+	quota, err := aiUsageService.SetClassQuota(adminId, input)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"code":    consts.SuccessCode,
+		"message": consts.MessageUpdateSuccess,
+		"quota":   quota,
+	})
+}
+
+func deleteAIClassQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	if err := aiUsageService.DeleteClassQuota(mux.Vars(r)["class_id"]); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"code":    consts.SuccessCode,
+		"message": "删除成功",
+	})
+}
+
+func getAIUsageReportHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := aiUsageService.GetUsageReport(buildAIUsageReportInput(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"code":    consts.SuccessCode,
+		"message": consts.MessageQuerySuccess,
+		"report":  report,
+	})
+}
+
+func exportAIUsageReportHandler(w http.ResponseWriter, r *http.Request) {
+	content, fileName, err := aiUsageService.ExportUsageReport(buildAIUsageReportInput(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	setFileHeaders(w)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(fileName))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+func buildAIUsageReportInput(r *http.Request) service.AIUsageReportInput {
+	query := r.URL.Query()
+	return service.AIUsageReportInput{
+		StartDate: strings.TrimSpace(query.Get("start_date")),
+		EndDate:   strings.TrimSpace(query.Get("end_date")),
+		GroupBy:   strings.TrimSpace(query.Get("group_by")),
+		StudentID: strings.TrimSpace(query.Get("student_id")),
+		ClassID:   strings.TrimSpace(query.Get("class_id")),
+		ModelID:   strings.TrimSpace(query.Get("model_id")),
+		Page:      parseIntWithDefault(query.Get("page"), 1),
+		PageSize:  parseIntWithDefault(query.Get("page_size"), 10),
+	}
+}
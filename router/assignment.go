@@ -0,0 +1,70 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var assignmentService *service_impl.AssignmentServiceImpl
+
+// registerAssignment 注册作业相关路由
+func registerAssignment(protectedRouter *mux.Router) {
+	// 教师设置小节作业参数（开放/截止时间、迟交策略、提交次数）
+	protectedRouter.HandleFunc("/teacher/section/assignment", setAssignmentHandler).Methods("POST")
+	// 学生查询自己在作业小节的提交情况
+	protectedRouter.HandleFunc("/student/section/assignment", getStudentAssignmentHandler).Methods("GET")
+}
+
+// setAssignmentHandler 设置小节作业参数
+func setAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.SetAssignmentRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := assignmentService.SetAssignment(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getStudentAssignmentHandler 查询学生作业情况
+// GET /student/section/assignment?section_id=xxx
+func getStudentAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	sectionId := r.URL.Query().Get("section_id")
+	if sectionId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "section_id 不能为空")
+		return
+	}
+	resp, err := assignmentService.GetStudentAssignment(ctx, studentId, sectionId)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
@@ -0,0 +1,253 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var attendanceService *service_impl.AttendanceServiceImpl
+
+// registerAttendance 注册课堂签到相关路由
+func registerAttendance(protectedRouter *mux.Router) {
+	// 学生：输入签到码签到、查询本人考勤
+	protectedRouter.HandleFunc("/student/class/attendance", checkInHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/attendance/mine", getMyAttendanceHandler).Methods("POST")
+	// 教师：发起/结束签到、获取轮换签到码、手动标记考勤
+	protectedRouter.HandleFunc("/teacher/class/attendance/open", openAttendanceHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/attendance/code", getAttendanceCodeHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/attendance/close", closeAttendanceHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/attendance/sessions", listAttendanceSessionsHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/attendance/records", getAttendanceRecordsHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/attendance/mark", markAttendanceHandler).Methods("POST")
+	// 教师：考勤报表与导出（csv / xlsx）
+	protectedRouter.HandleFunc("/teacher/class/attendance/report", getAttendanceReportHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/attendance/export", exportAttendanceReportHandler).Methods("GET")
+}
+
+// checkInHandler 学生输入签到码签到
+func checkInHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.CheckInRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.CheckIn(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getMyAttendanceHandler 学生查询本人考勤
+func getMyAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.GetMyAttendanceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.GetMyAttendance(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// openAttendanceHandler 教师发起课堂签到
+func openAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.OpenAttendanceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.OpenSession(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getAttendanceCodeHandler 查询当前签到码（投屏轮询）
+func getAttendanceCodeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.AttendanceSessionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.GetSessionCode(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// closeAttendanceHandler 教师结束签到
+func closeAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.AttendanceSessionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.CloseSession(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listAttendanceSessionsHandler 查询班级签到场次
+func listAttendanceSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ListAttendanceSessionsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.ListSessions(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getAttendanceRecordsHandler 查询签到场次内学生考勤
+func getAttendanceRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.AttendanceSessionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.GetSessionRecords(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// markAttendanceHandler 教师手动标记学生考勤
+func markAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.MarkAttendanceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.MarkAttendance(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getAttendanceReportHandler 查询班级考勤报表
+func getAttendanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.GetAttendanceReportRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := attendanceService.GetAttendanceReport(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// exportAttendanceReportHandler 导出班级考勤报表
+// GET /teacher/class/attendance/export?teacher_id=xxx&class_id=xxx&format=csv|xlsx
+func exportAttendanceReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	content, fileName, err := attendanceService.ExportAttendanceReport(r.Context(),
+		strings.TrimSpace(query.Get("teacher_id")), strings.TrimSpace(query.Get("class_id")), format)
+	if err != nil {
+		setResponseHeaders(w)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	setFileHeaders(w)
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(fileName))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
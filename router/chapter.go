@@ -2,9 +2,13 @@ package router
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
 	"github.com/yzf120/elysia-backend/errs"
 	"github.com/yzf120/elysia-backend/service_impl"
 )
@@ -25,6 +29,14 @@ func registerChapter(publicRouter *mux.Router, protectedRouter *mux.Router) {
 	protectedRouter.HandleFunc("/teacher/section/delete", deleteSectionHandler).Methods("POST")
 	protectedRouter.HandleFunc("/teacher/section/reorder", reorderSectionsHandler).Methods("POST")
 
+	// 发布与解锁设置（仅教师）
+	protectedRouter.HandleFunc("/teacher/chapter/release", setChapterReleaseHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/section/release", setSectionReleaseHandler).Methods("POST")
+
+	// 课程模板导出/导入（仅教师）
+	protectedRouter.HandleFunc("/teacher/class/template/export", exportCourseTemplateHandler).Methods("GET")
+	protectedRouter.HandleFunc("/teacher/class/template/import", importCourseTemplateHandler).Methods("POST")
+
 	// 查询：师生共用
 	protectedRouter.HandleFunc("/class/chapters", getClassChaptersHandler).Methods("POST")
 }
@@ -221,6 +233,54 @@ func reorderSectionsHandler(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, resp)
 }
 
+// setChapterReleaseHandler 设置章节发布时间
+func setChapterReleaseHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.SetChapterReleaseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := chapterService.SetChapterRelease(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		errResp := &errs.BaseResponse{Data: nil, Error: errs.NewError(int(resp.Code), resp.Message)}
+		respBytes, _ := json.Marshal(errResp)
+		w.WriteHeader(http.StatusOK)
+		w.Write(respBytes)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// setSectionReleaseHandler 设置小节发布时间与前置小节
+func setSectionReleaseHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.SetSectionReleaseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := chapterService.SetSectionRelease(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		errResp := &errs.BaseResponse{Data: nil, Error: errs.NewError(int(resp.Code), resp.Message)}
+		respBytes, _ := json.Marshal(errResp)
+		w.WriteHeader(http.StatusOK)
+		w.Write(respBytes)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
 // getClassChaptersHandler 查询班级章节列表（师生共用）
 func getClassChaptersHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -230,7 +290,9 @@ func getClassChaptersHandler(w http.ResponseWriter, r *http.Request) {
 		writeErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	resp, err := chapterService.GetClassChapters(ctx, req)
+	userType, _ := authen.GetUserTypeFromContext(ctx)
+	roleId, _ := authen.GetRoleIDFromContext(ctx)
+	resp, err := chapterService.GetClassChapters(ctx, userType, roleId, req)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -244,3 +306,75 @@ func getClassChaptersHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	writeSuccessResponse(w, resp)
 }
+
+// courseTemplateMaxFileSize 课程模板文件大小上限
+const courseTemplateMaxFileSize = 20 << 20
+
+// exportCourseTemplateHandler 导出课程模板
+// GET /teacher/class/template/export?teacher_id=xxx&class_id=xxx&format=zip|json
+func exportCourseTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	content, fileName, err := chapterService.ExportCourseTemplate(r.Context(),
+		strings.TrimSpace(query.Get("teacher_id")), strings.TrimSpace(query.Get("class_id")), format)
+	if err != nil {
+		setResponseHeaders(w)
+		_, msg := errs.ParseCommonError(err.Error())
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	setFileHeaders(w)
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/zip")
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(fileName))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+// importCourseTemplateHandler 导入课程模板
+// POST multipart/form-data：teacher_id、class_id、file（导出的 .zip 或 .json）
+func importCourseTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	r.Body = http.MaxBytesReader(w, r.Body, courseTemplateMaxFileSize+(1<<20))
+	if err := r.ParseMultipartForm(courseTemplateMaxFileSize); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "表单解析失败或文件过大")
+		return
+	}
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "请上传课程模板文件")
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, courseTemplateMaxFileSize+1))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "读取课程模板文件失败")
+		return
+	}
+	if len(content) > courseTemplateMaxFileSize {
+		writeErrorResponse(w, http.StatusBadRequest, "课程模板文件不能超过 20MB")
+		return
+	}
+
+	req := &service_impl.ImportCourseTemplateRequest{
+		TeacherId: strings.TrimSpace(r.FormValue("teacher_id")),
+		ClassId:   strings.TrimSpace(r.FormValue("class_id")),
+		FileName:  fileHeader.Filename,
+		Content:   content,
+	}
+	resp, err := chapterService.ImportCourseTemplate(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
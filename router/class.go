@@ -2,9 +2,12 @@ package router
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
 	"github.com/yzf120/elysia-backend/errs"
 	"github.com/yzf120/elysia-backend/service_impl"
 )
@@ -19,10 +22,12 @@ func registerClass(publicRouter *mux.Router, protectedRouter *mux.Router) {
 	protectedRouter.HandleFunc("/teacher/class/create", createClassHandler).Methods("POST")
 	protectedRouter.HandleFunc("/teacher/class/update", updateClassHandler).Methods("POST")
 	protectedRouter.HandleFunc("/teacher/class/remove-student", removeStudentHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/clone", cloneClassHandler).Methods("POST")
 
 	// 查询：学生和教师均可调用（受保护路由，通用路由前缀）
 	protectedRouter.HandleFunc("/class/subjects", listSubjectsHandler).Methods("GET")
 	protectedRouter.HandleFunc("/class/semesters", listSemestersHandler).Methods("GET")
+	protectedRouter.HandleFunc("/class/semesters/current", getCurrentSemesterHandler).Methods("GET")
 	protectedRouter.HandleFunc("/class/get-by-code", getClassByCodeHandler).Methods("GET")
 	protectedRouter.HandleFunc("/class/members", getClassMembersHandler).Methods("POST")
 	protectedRouter.HandleFunc("/class/teacher-classes", getTeacherClassesHandler).Methods("POST")
@@ -36,6 +41,36 @@ func registerClass(publicRouter *mux.Router, protectedRouter *mux.Router) {
 	protectedRouter.HandleFunc("/teacher/class/announcement/publish", publishAnnouncementHandler).Methods("POST")
 	protectedRouter.HandleFunc("/teacher/class/announcement/delete", deleteAnnouncementHandler).Methods("POST")
 	protectedRouter.HandleFunc("/class/announcements", getAnnouncementsHandler).Methods("POST")
+	// 公告置顶与阅读情况（仅教师），学生标记已读与查询未读数
+	protectedRouter.HandleFunc("/teacher/class/announcement/pin", pinAnnouncementHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/announcement/readers", getAnnouncementReadersHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/announcement/read", markAnnouncementReadHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/announcement/unread", getUnreadAnnouncementsHandler).Methods("GET")
+
+	// 邀请：教师轮换验证码、管理邀请链接；加班二维码为公开接口（扫码前未登录）
+	protectedRouter.HandleFunc("/teacher/class/code/rotate", rotateClassCodeHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/invite/create", createInviteHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/invite/list", listInvitesHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/invite/revoke", revokeInviteHandler).Methods("POST")
+	publicRouter.HandleFunc("/class/qrcode", getClassQRCodeHandler).Methods("GET")
+
+	// 加入审批与候补：教师设置加入策略、审批申请，学生查询/撤回自己的申请
+	protectedRouter.HandleFunc("/teacher/class/join-policy", setJoinPolicyHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/join-request/list", listJoinRequestsHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/join-request/approve", approveJoinRequestHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/join-request/reject", rejectJoinRequestHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/join-requests", getStudentJoinRequestsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/student/class/join-request/cancel", cancelJoinRequestHandler).Methods("POST")
+
+	// 名单导入：教师上传 csv/xlsx 批量加入学生
+	protectedRouter.HandleFunc("/teacher/class/roster/import", importRosterHandler).Methods("POST")
+
+	// 教学团队（协同教师、助教）
+	protectedRouter.HandleFunc("/teacher/class/staff/add", addClassStaffHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/staff/update", updateClassStaffHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/staff/remove", removeClassStaffHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/staff/list", listClassStaffHandler).Methods("POST")
+	protectedRouter.HandleFunc("/class/staff/permissions", getMyClassPermissionsHandler).Methods("POST")
 }
 
 // listSubjectsHandler 查询全量启用科目列表
@@ -84,6 +119,22 @@ func listSemestersHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(respBytes)
 }
 
+// getCurrentSemesterHandler 按日期查询当前学期
+func getCurrentSemesterHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	resp, err := classService.GetCurrentSemester(ctx)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
 // createClassHandler 创建班级处理器（仅教师）
 func createClassHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -121,6 +172,27 @@ func createClassHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(respBytes)
 }
 
+// cloneClassHandler 教师克隆班级到新学期
+func cloneClassHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.CloneClassRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.CloneClass(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
 // updateClassHandler 更新班级信息处理器（仅教师）
 func updateClassHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -475,7 +547,9 @@ func getAnnouncementsHandler(w http.ResponseWriter, r *http.Request) {
 		writeErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	resp, err := classService.GetAnnouncements(ctx, req)
+	userType, _ := authen.GetUserTypeFromContext(ctx)
+	roleId, _ := authen.GetRoleIDFromContext(ctx)
+	resp, err := classService.GetAnnouncements(ctx, userType, roleId, req)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -489,3 +563,493 @@ func getAnnouncementsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	writeSuccessResponse(w, resp)
 }
+
+// pinAnnouncementHandler 教师置顶/取消置顶公告
+func pinAnnouncementHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.PinAnnouncementRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.PinAnnouncement(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getAnnouncementReadersHandler 教师查询公告阅读情况
+func getAnnouncementReadersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.AnnouncementReadersRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.GetAnnouncementReaders(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// markAnnouncementReadHandler 学生标记公告已读
+func markAnnouncementReadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	req := &service_impl.MarkAnnouncementReadRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.MarkAnnouncementRead(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getUnreadAnnouncementsHandler 学生查询各班级未读公告数
+func getUnreadAnnouncementsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	resp, err := classService.GetUnreadAnnouncements(ctx, studentId)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// rotateClassCodeHandler 教师轮换班级验证码
+func rotateClassCodeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.RotateClassCodeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.RotateClassCode(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// createInviteHandler 教师创建邀请链接
+func createInviteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.CreateInviteRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.CreateInvite(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listInvitesHandler 教师查询邀请链接列表
+func listInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ListInvitesRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.ListInvites(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// revokeInviteHandler 教师撤销邀请链接
+func revokeInviteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.RevokeInviteRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.RevokeInvite(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getClassQRCodeHandler 获取加班二维码图片
+// GET /class/qrcode?code=xxx（code 为班级验证码或邀请链接码）
+func getClassQRCodeHandler(w http.ResponseWriter, r *http.Request) {
+	code := strings.TrimSpace(r.URL.Query().Get("code"))
+	content, err := classService.GetJoinQRCode(r.Context(), code)
+	if err != nil {
+		setResponseHeaders(w)
+		_, msg := errs.ParseCommonError(err.Error())
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	setFileHeaders(w)
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+// setJoinPolicyHandler 教师设置班级加入策略
+func setJoinPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.SetJoinPolicyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.SetJoinPolicy(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listJoinRequestsHandler 教师查询班级加入申请
+func listJoinRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ListJoinRequestsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.ListJoinRequests(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// approveJoinRequestHandler 教师通过加入申请
+func approveJoinRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ApproveJoinRequestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.ApproveJoinRequest(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// rejectJoinRequestHandler 教师拒绝加入申请
+func rejectJoinRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.RejectJoinRequestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.RejectJoinRequest(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getStudentJoinRequestsHandler 学生查询自己的加入申请
+func getStudentJoinRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	resp, err := classService.GetStudentJoinRequests(ctx, studentId)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// cancelJoinRequestHandler 学生撤回加入申请
+func cancelJoinRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	req := &service_impl.CancelJoinRequestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.CancelJoinRequest(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// rosterMaxFileSize 名单文件大小上限
+const rosterMaxFileSize = 5 << 20
+
+// importRosterHandler 教师上传名单批量加入学生
+// POST multipart/form-data：teacher_id、class_id、create_missing（true/false）、file（csv 或 xlsx）
+func importRosterHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	r.Body = http.MaxBytesReader(w, r.Body, rosterMaxFileSize+(1<<20))
+	if err := r.ParseMultipartForm(rosterMaxFileSize); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "表单解析失败或文件过大")
+		return
+	}
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "请上传名单文件")
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, rosterMaxFileSize+1))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "读取名单文件失败")
+		return
+	}
+	if len(content) > rosterMaxFileSize {
+		writeErrorResponse(w, http.StatusBadRequest, "名单文件不能超过 5MB")
+		return
+	}
+
+	req := &service_impl.ImportRosterRequest{
+		TeacherId:     strings.TrimSpace(r.FormValue("teacher_id")),
+		ClassId:       strings.TrimSpace(r.FormValue("class_id")),
+		CreateMissing: parseBoolWithDefault(r.FormValue("create_missing")),
+		FileName:      fileHeader.Filename,
+		Content:       content,
+	}
+	resp, err := classService.ImportRoster(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// addClassStaffHandler 班级创建者添加协同教师或助教
+func addClassStaffHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.AddClassStaffRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.AddClassStaff(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// updateClassStaffHandler 班级创建者修改教学团队成员权限
+func updateClassStaffHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.UpdateClassStaffRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.UpdateClassStaff(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// removeClassStaffHandler 班级创建者移除教学团队成员
+func removeClassStaffHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.RemoveClassStaffRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.RemoveClassStaff(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listClassStaffHandler 查询班级教学团队
+func listClassStaffHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ListClassStaffRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.ListClassStaff(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getMyClassPermissionsHandler 查询当前用户在班级中的角色与权限（教师或学生助教）
+func getMyClassPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	userId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || userId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	req := &service_impl.ClassStaffPermissionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classService.GetMyClassPermissions(ctx, userId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
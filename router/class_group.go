@@ -0,0 +1,379 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var classGroupService *service_impl.ClassGroupServiceImpl
+
+// registerClassGroup 注册班级小组相关路由
+func registerClassGroup(protectedRouter *mux.Router) {
+	// 学生：查看分组、自建/加入/退出小组（需班级开启自选小组）、组长修改小组信息与转让组长
+	protectedRouter.HandleFunc("/student/class/groups", studentGetGroupOverviewHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/group/create", studentCreateGroupHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/group/update", studentUpdateGroupHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/group/join", studentJoinGroupHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/group/leave", studentLeaveGroupHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/group/leader", studentTransferGroupLeaderHandler).Methods("POST")
+	// 教师：分组管理
+	protectedRouter.HandleFunc("/teacher/class/groups", getGroupOverviewHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/groups/setting", setGroupSettingsHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/group/create", teacherCreateGroupHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/group/update", teacherUpdateGroupHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/group/delete", teacherDeleteGroupHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/group/members/add", teacherAddGroupMembersHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/group/members/remove", teacherRemoveGroupMemberHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/group/leader", teacherSetGroupLeaderHandler).Methods("POST")
+	// 教师：设置算法题/讨论小节的小组模式
+	protectedRouter.HandleFunc("/teacher/section/group-mode", setSectionGroupModeHandler).Methods("POST")
+}
+
+// studentGetGroupOverviewHandler 学生查询班级分组情况
+func studentGetGroupOverviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.StudentClassGroupRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.StudentGetGroupOverview(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// studentCreateGroupHandler 学生自建小组
+func studentCreateGroupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.StudentGroupInfoRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.StudentCreateGroup(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// studentUpdateGroupHandler 组长修改小组信息
+func studentUpdateGroupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.StudentGroupInfoRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.StudentUpdateGroup(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// studentJoinGroupHandler 学生加入小组
+func studentJoinGroupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.StudentJoinGroupRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.StudentJoinGroup(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// studentLeaveGroupHandler 学生退出所在小组
+func studentLeaveGroupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.StudentClassGroupRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.StudentLeaveGroup(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// studentTransferGroupLeaderHandler 组长转让组长身份
+func studentTransferGroupLeaderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.StudentTransferLeaderRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.StudentTransferLeader(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getGroupOverviewHandler 教学团队查询班级分组情况
+func getGroupOverviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.GetGroupOverviewRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.GetGroupOverview(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// setGroupSettingsHandler 教师设置分组方式
+func setGroupSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.SetGroupSettingsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.SetGroupSettings(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherCreateGroupHandler 教师创建小组
+func teacherCreateGroupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherCreateGroupRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.TeacherCreateGroup(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherUpdateGroupHandler 教师修改小组信息
+func teacherUpdateGroupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherUpdateGroupRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.TeacherUpdateGroup(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherDeleteGroupHandler 教师解散小组
+func teacherDeleteGroupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherDeleteGroupRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.TeacherDeleteGroup(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherAddGroupMembersHandler 教师将学生分入小组
+func teacherAddGroupMembersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherAddGroupMembersRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.TeacherAddMembers(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherRemoveGroupMemberHandler 教师将学生移出小组
+func teacherRemoveGroupMemberHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherGroupMemberRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.TeacherRemoveMember(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherSetGroupLeaderHandler 教师指定组长
+func teacherSetGroupLeaderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherGroupMemberRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.TeacherSetLeader(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// setSectionGroupModeHandler 教师设置小节的小组模式
+func setSectionGroupModeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.SetSectionGroupModeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classGroupService.SetSectionGroupMode(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
@@ -0,0 +1,106 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var classInsightService *service_impl.ClassInsightServiceImpl
+
+// registerClassInsight 注册班级教学洞察相关路由
+func registerClassInsight(protectedRouter *mux.Router) {
+	// 教师：班级教学洞察（卡题学生、常见错误、高错误率题目）
+	protectedRouter.HandleFunc("/teacher/class/insights", getClassInsightsHandler).Methods("POST")
+	// 教师：订阅/取消订阅班级每日摘要
+	protectedRouter.HandleFunc("/teacher/class/insights/digest/subscribe", setDigestSubscriptionHandler).Methods("POST")
+	// 教师：每日摘要列表与已读
+	protectedRouter.HandleFunc("/teacher/insights/digests", listDigestsHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/insights/digests/read", markDigestsReadHandler).Methods("POST")
+}
+
+// getClassInsightsHandler 查询班级教学洞察
+func getClassInsightsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.GetClassInsightsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classInsightService.GetClassInsights(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// setDigestSubscriptionHandler 订阅或取消订阅每日摘要
+func setDigestSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.SetDigestSubscriptionRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classInsightService.SetDigestSubscription(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listDigestsHandler 查询每日摘要
+func listDigestsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ListDigestsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classInsightService.ListDigests(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// markDigestsReadHandler 标记每日摘要已读
+func markDigestsReadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.MarkDigestsReadRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := classInsightService.MarkDigestsRead(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
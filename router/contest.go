@@ -0,0 +1,224 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var contestService *service_impl.ContestServiceImpl
+
+// registerContest 注册比赛相关路由
+func registerContest(protectedRouter *mux.Router) {
+	// 教师操作
+	protectedRouter.HandleFunc("/teacher/contest/create", createContestHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/contest/update", updateContestHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/contest/delete", deleteContestHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/contest/scoreboard", teacherScoreboardHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/contest/submissions", teacherContestSubmissionsHandler).Methods("POST")
+
+	// 查询：师生共用
+	protectedRouter.HandleFunc("/class/contests", listClassContestsHandler).Methods("POST")
+
+	// 学生操作（比赛内提交使用 /student/code/run 并传入 contest_id）
+	protectedRouter.HandleFunc("/student/contest/detail", studentContestDetailHandler).Methods("GET")
+	protectedRouter.HandleFunc("/student/contest/scoreboard", studentScoreboardHandler).Methods("GET")
+	protectedRouter.HandleFunc("/student/contest/submissions", studentContestSubmissionsHandler).Methods("GET")
+}
+
+// createContestHandler 创建比赛
+func createContestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.CreateContestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := contestService.CreateContest(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// updateContestHandler 更新比赛
+func updateContestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.UpdateContestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := contestService.UpdateContest(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// deleteContestHandler 删除比赛
+func deleteContestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherContestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := contestService.DeleteContest(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherScoreboardHandler 查询实时榜单（教师，不受封榜影响）
+func teacherScoreboardHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherContestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := contestService.GetTeacherScoreboard(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherContestSubmissionsHandler 查询比赛提交记录（教师）
+func teacherContestSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherContestRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := contestService.ListContestSubmissions(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listClassContestsHandler 查询班级比赛列表
+func listClassContestsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ListClassContestsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	userType, _ := authen.GetUserTypeFromContext(ctx)
+	roleId, _ := authen.GetRoleIDFromContext(ctx)
+	resp, err := contestService.ListClassContests(ctx, userType, roleId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// studentContestDetailHandler 查询比赛详情（学生）
+// GET /student/contest/detail?contest_id=xxx
+func studentContestDetailHandler(w http.ResponseWriter, r *http.Request) {
+	handleStudentContestQuery(w, r, func(ctx context.Context, studentId, contestId string) (int32, string, interface{}, error) {
+		resp, err := contestService.GetStudentContestDetail(ctx, studentId, contestId)
+		if err != nil {
+			return 0, "", nil, err
+		}
+		return resp.Code, resp.Message, resp, nil
+	})
+}
+
+// studentScoreboardHandler 查询榜单（学生）
+// GET /student/contest/scoreboard?contest_id=xxx
+func studentScoreboardHandler(w http.ResponseWriter, r *http.Request) {
+	handleStudentContestQuery(w, r, func(ctx context.Context, studentId, contestId string) (int32, string, interface{}, error) {
+		resp, err := contestService.GetStudentScoreboard(ctx, studentId, contestId)
+		if err != nil {
+			return 0, "", nil, err
+		}
+		return resp.Code, resp.Message, resp, nil
+	})
+}
+
+// studentContestSubmissionsHandler 查询自己的比赛提交（学生）
+// GET /student/contest/submissions?contest_id=xxx
+func studentContestSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	handleStudentContestQuery(w, r, func(ctx context.Context, studentId, contestId string) (int32, string, interface{}, error) {
+		resp, err := contestService.ListStudentSubmissions(ctx, studentId, contestId)
+		if err != nil {
+			return 0, "", nil, err
+		}
+		return resp.Code, resp.Message, resp, nil
+	})
+}
+
+// handleStudentContestQuery 学生比赛查询接口的公共处理：校验登录、解析 contest_id、写入响应
+func handleStudentContestQuery(w http.ResponseWriter, r *http.Request,
+	query func(ctx context.Context, studentId, contestId string) (int32, string, interface{}, error)) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	contestId := r.URL.Query().Get("contest_id")
+	if contestId == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "contest_id 不能为空")
+		return
+	}
+
+	code, message, resp, err := query(ctx, studentId, contestId)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if code != 0 {
+		writeBizErrorResponse(w, code, message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
@@ -12,24 +12,32 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/errs"
 	agentpb "github.com/yzf120/elysia-backend/proto/agent"
 	"github.com/yzf120/elysia-backend/rpc"
+	"github.com/yzf120/elysia-backend/service"
 	agent_session "github.com/yzf120/elysia-session/proto/agent_session"
 	conversationpb "github.com/yzf120/elysia-session/proto/conversation"
 )
 
+var aiChatService *service.AIChatService
+
 // AIChatRequest AI对话请求（来自前端）
 type AIChatRequest struct {
 	// 会话ID（可选，首轮对话为空，后续对话传入）
 	SessionID string `json:"session_id,omitempty"`
+	// 班级ID（在班级内发起的对话传入，用量计入该班级并受班级额度限制；加入多个班级且有班级额度时必填）
+	ClassID string `json:"class_id,omitempty"`
 	// 题目ID（编程界面开启的对话时传入，普通对话不传或传0）
 	ProblemID int64 `json:"problem_id,omitempty"`
 	// 问题类型标识，如 "algorithm_problem" 表示算法题
 	QuestionType string `json:"question_type"`
 	// 题目信息（作为上下文传给AI）
 	ProblemInfo *ProblemContext `json:"problem_info,omitempty"`
-	// 对话历史（包含本次用户消息）
-	Messages []ChatMessage `json:"messages"`
+	// 本次用户消息（历史消息由服务端按 session_id 加载）
+	Message string `json:"message"`
+	// 对话历史（已废弃：兼容旧版前端，仅取最后一条用户消息作为本次提问，其余历史忽略）
+	Messages []ChatMessage `json:"messages,omitempty"`
 	// 模型ID（可选，默认使用豆包）
 	ModelID string `json:"model_id,omitempty"`
 	// 是否开启深度思考模式
@@ -60,14 +68,16 @@ type ProblemContext struct {
 }
 
 func registerConversation(router *mux.Router) {
-	// 学生AI答疑接口（SSE流式输出）
-	router.HandleFunc("/student/ai/chat", studentAIChatHandler).Methods("POST", "OPTIONS")
+	// 学生AI答疑接口（SSE流式输出，考试期间禁用）
+	router.HandleFunc("/student/ai/chat", examGuard(studentAIChatHandler)).Methods("POST", "OPTIONS")
 	// 查询支持的模型列表
 	router.HandleFunc("/student/ai/models", studentAIModelsHandler).Methods("GET", "OPTIONS")
 	// 查询用户会话列表
 	router.HandleFunc("/student/ai/sessions", studentAISessionsHandler).Methods("GET", "OPTIONS")
 	// 查询某会话的消息列表
 	router.HandleFunc("/student/ai/sessions/{sessionId}/messages", studentAISessionMessagesHandler).Methods("GET", "OPTIONS")
+	// 查询本人 AI 用量与剩余额度
+	router.HandleFunc("/student/ai/usage", studentAIUsageHandler).Methods("GET", "OPTIONS")
 }
 
 // studentAIModelsHandler 查询支持的模型列表
@@ -106,6 +116,9 @@ func studentAIModelsHandler(w http.ResponseWriter, r *http.Request) {
 
 // studentAIChatHandler 学生AI答疑处理器（SSE流式输出）
 // POST /student/ai/chat
+// 首个 SSE 事件为 event: session，携带本轮对话所属的会话ID（首轮对话时为新建会话）；
+// 前端只需传入本次提问，历史消息由服务端按会话加载并按模型 token 预算组装；
+// 学生消息在调用模型前写入，AI 回复在流结束后写入（写入失败时由后台任务重试）
 func studentAIChatHandler(w http.ResponseWriter, r *http.Request) {
 	// 处理 OPTIONS 预检请求
 	if r.Method == "OPTIONS" {
@@ -132,10 +145,28 @@ func studentAIChatHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(request.Messages) == 0 {
-		http.Error(w, "messages 不能为空", http.StatusBadRequest)
+	// 本次用户消息（兼容旧版前端：取 messages 最后一条 role=user 的消息）
+	userMsg := request.Message
+	if userMsg == "" {
+		for i := len(request.Messages) - 1; i >= 0; i-- {
+			if request.Messages[i].Role == "user" {
+				userMsg = request.Messages[i].Content
+				break
+			}
+		}
+	}
+	if strings.TrimSpace(userMsg) == "" {
+		http.Error(w, "message 不能为空", http.StatusBadRequest)
+		return
+	}
+
+	// 校验并预占 AI 用量额度（额度用完时直接返回提示，不建立流式连接），本轮用量记账后释放
+	reservation, err := aiUsageService.ReserveQuota(studentId, request.ClassID, userMsg)
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
+	defer reservation.Release()
 
 	// 设置 SSE 响应头
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -156,12 +187,38 @@ func studentAIChatHandler(w http.ResponseWriter, r *http.Request) {
 		modelID = "doubao-seed-1-6-lite-251015" // 默认豆包模型
 	}
 
+	// 首轮对话先创建会话，并将会话ID作为首个事件下发
+	session, isNew, err := aiChatService.EnsureSession(reqCtx, studentId, request.SessionID, userMsg, request.ProblemID)
+	if err != nil {
+		log.Printf("[conversation] 获取会话失败，学生: %s, err: %v", studentId, err)
+		writeSSEError(w, flusher, aiChatErrorMessage(err, "创建会话失败，请稍后再试"))
+		return
+	}
+	sessionID := session.SessionId
+	sessionData, _ := json.Marshal(map[string]interface{}{
+		"session_id": sessionID,
+		"is_new":     isNew,
+	})
+	fmt.Fprintf(w, "event: session\ndata: %s\n\n", string(sessionData))
+	flusher.Flush()
+
 	// 构建系统提示词
 	systemPrompt := buildSystemPrompt(request.QuestionType, request.ProblemInfo, request.UserCode, request.UserCodeLang)
 
+	// 写入学生消息，并由服务端加载历史、按模型 token 预算组装上下文
+	turn, err := aiChatService.PrepareTurn(reqCtx, session, modelID, systemPrompt, userMsg)
+	if err != nil {
+		log.Printf("[conversation] 组装对话上下文失败，sessionID: %s, err: %v", sessionID, err)
+		writeSSEError(w, flusher, "保存对话记录失败，请稍后再试")
+		return
+	}
+	if turn.Truncated {
+		log.Printf("[conversation] 会话历史超出模型预算，已截断早期消息，sessionID: %s, 模型: %s", sessionID, modelID)
+	}
+
 	// 构建发送给 chat-agent 的消息列表
-	agentMessages := make([]agentpb.AgentChatMessage, 0, len(request.Messages))
-	for _, msg := range request.Messages {
+	agentMessages := make([]agentpb.AgentChatMessage, 0, len(turn.Messages))
+	for _, msg := range turn.Messages {
 		agentMessages = append(agentMessages, agentpb.AgentChatMessage{
 			Role:    msg.Role,
 			Content: msg.Content,
@@ -187,7 +244,7 @@ func studentAIChatHandler(w http.ResponseWriter, r *http.Request) {
 	agentReq := &agentpb.AgentStreamChatRequest{
 		ModelID:      modelID,
 		Messages:     agentMessages,
-		SystemPrompt: systemPrompt,
+		SystemPrompt: turn.SystemPrompt,
 	}
 
 	// 透传深度思考参数
@@ -198,22 +255,37 @@ func studentAIChatHandler(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[conversation] 深度思考模式已开启，模型: %s，学生: %s", modelID, studentId)
 	}
 
+	aiReply := ""
+	usage := &agentpb.AgentStreamChatResponse{}
 	agentStream, err := rpc.GetAgentClient().GetProxy().StreamChat(rpcCtx, agentReq)
 	if err != nil {
 		log.Printf("[conversation] 调用 chat-agent StreamChat 失败: %v", err)
-		// 通过 SSE 发送错误事件
-		fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
-		flusher.Flush()
-		return
+		writeSSEError(w, flusher, err.Error())
+	} else {
+		log.Printf("[conversation] 开始接收 chat-agent 流式响应，学生: %s", studentId)
+		aiReply = relayAgentStream(w, flusher, agentStream, usage)
+		log.Printf("[conversation] SSE 流式响应完成，学生: %s", studentId)
 	}
 
-	log.Printf("[conversation] 开始接收 chat-agent 流式响应，学生: %s", studentId)
+	// 记录模型用量
+	aiChatService.RecordTurnUsage(session, turn, reservation.ClassId, modelID, aiReply, usage)
 
-	// 用于拼接完整的 AI 回复内容
-	var aiReplyBuilder strings.Builder
-	streamErr := false
+	// 写入 AI 回复（若流异常则使用兜底回复，保证每轮 Q/A 成对存储）
+	if strings.TrimSpace(aiReply) == "" {
+		aiReply = "抱歉，AI 助教暂时无法回答，请稍后再试。"
+		log.Printf("[conversation] AI 回复异常，使用兜底回复，学生: %s", studentId)
+	}
+	if err := aiChatService.SaveAssistantReply(sessionID, studentId, modelID, aiReply, turn.AISeq); err != nil {
+		log.Printf("[conversation] 写入 AI 回复失败，sessionID: %s, err: %v", sessionID, err)
+		return
+	}
+	log.Printf("[conversation] 会话记录存储完成，sessionID: %s, userSeq: %d, aiSeq: %d", sessionID, turn.UserSeq, turn.AISeq)
+}
 
-	// 逐个接收 chat-agent 的流式响应，通过 SSE 发送给前端
+// relayAgentStream 逐个接收 chat-agent 的流式响应并通过 SSE 转发给前端，返回拼接后的完整回复（流异常时返回空），
+// 模型返回的 token 用量合并到 usage 中
+func relayAgentStream(w http.ResponseWriter, flusher http.Flusher, agentStream agentpb.AgentService_StreamChatClient, usage *agentpb.AgentStreamChatResponse) string {
+	var aiReplyBuilder strings.Builder
 	for {
 		chunk, err := agentStream.Recv()
 		if err == io.EOF {
@@ -224,16 +296,15 @@ func studentAIChatHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		if err != nil {
 			log.Printf("[conversation] 接收 chat-agent 响应失败: %v", err)
-			fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
-			flusher.Flush()
-			streamErr = true
-			break
+			writeSSEError(w, flusher, err.Error())
+			return ""
 		}
 
-		// 累积 AI 回复内容
+		// 累积 AI 回复内容与用量
 		if chunk.Content != "" {
 			aiReplyBuilder.WriteString(chunk.Content)
 		}
+		service.MergeChunkUsage(usage, chunk)
 
 		// 将 chunk 序列化为 JSON 并通过 SSE 发送
 		chunkData, jsonErr := json.Marshal(map[string]interface{}{
@@ -259,95 +330,21 @@ func studentAIChatHandler(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 	}
+	return aiReplyBuilder.String()
+}
 
-	log.Printf("[conversation] SSE 流式响应完成，学生: %s", studentId)
-
-	// ===== 异步存储会话和对话记录到 session 服务 =====
-	go func() {
-		// 确定 AI 最终回复内容（若流异常则使用兜底回复）
-		aiReply := aiReplyBuilder.String()
-		if streamErr || strings.TrimSpace(aiReply) == "" {
-			aiReply = "抱歉，AI 助教暂时无法回答，请稍后再试。"
-			log.Printf("[conversation] AI 回复异常，使用兜底回复，学生: %s", studentId)
-		}
-
-		// 取本次用户消息（messages 最后一条 role=user 的消息）
-		userMsg := ""
-		for i := len(request.Messages) - 1; i >= 0; i-- {
-			if request.Messages[i].Role == "user" {
-				userMsg = request.Messages[i].Content
-				break
-			}
-		}
-
-		sessionSvcCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		sessionID := request.SessionID
-		// 计算本轮 Q/A 的消息序号
-		// 已有消息数 = (len(request.Messages) - 1) 条历史 + 本次用户消息
-		// 本次用户消息 seq = 已有历史消息数 + 1（奇数）
-		// 本次 AI 回复 seq = 已有历史消息数 + 2（偶数）
-		historyCount := int32(len(request.Messages) - 1) // 不含本次用户消息
-		userSeq := historyCount + 1
-		aiSeq := historyCount + 2
-
-		if sessionID == "" {
-			// 首轮对话：创建新会话
-			sessionTitle := userMsg
-			if len([]rune(sessionTitle)) > 30 {
-				runes := []rune(sessionTitle)
-				sessionTitle = string(runes[:30]) + "..."
-			}
-			createRsp, err := rpc.GetSessionClient().CreateSession(sessionSvcCtx, &agent_session.CreateSessionRequest{
-				UserId:       studentId,
-				SessionTitle: sessionTitle,
-				ProblemId:    request.ProblemID,
-			})
-			if err != nil {
-				log.Printf("[conversation] 创建会话失败，学生: %s, err: %v", studentId, err)
-				return
-			}
-			if createRsp.Code != 200 {
-				log.Printf("[conversation] 创建会话返回错误，学生: %s, code: %d, msg: %s", studentId, createRsp.Code, createRsp.Message)
-				return
-			}
-			sessionID = createRsp.SessionId
-			log.Printf("[conversation] 创建会话成功，学生: %s, sessionID: %s", studentId, sessionID)
-		}
-
-		// 写入用户消息（奇数 seq）
-		_, err := rpc.GetSessionClient().CreateConversation(sessionSvcCtx, &conversationpb.CreateConversationRequest{
-			SessionId:   sessionID,
-			UserId:      studentId,
-			ModelId:     modelID,
-			MessageType: conversationpb.MessageType_MESSAGE_TYPE_TEXT,
-			SenderType:  conversationpb.SenderType_SENDER_TYPE_USER,
-			Content:     userMsg,
-			MessageSeq:  userSeq,
-		})
-		if err != nil {
-			log.Printf("[conversation] 写入用户消息失败，sessionID: %s, err: %v", sessionID, err)
-			return
-		}
-
-		// 写入 AI 回复（偶数 seq）
-		_, err = rpc.GetSessionClient().CreateConversation(sessionSvcCtx, &conversationpb.CreateConversationRequest{
-			SessionId:   sessionID,
-			UserId:      studentId,
-			ModelId:     modelID,
-			MessageType: conversationpb.MessageType_MESSAGE_TYPE_TEXT,
-			SenderType:  conversationpb.SenderType_SENDER_TYPE_AGENT,
-			Content:     aiReply,
-			MessageSeq:  aiSeq,
-		})
-		if err != nil {
-			log.Printf("[conversation] 写入 AI 回复失败，sessionID: %s, err: %v", sessionID, err)
-			return
-		}
+// aiChatErrorMessage 参数类错误（如会话不属于当前学生）直接返回给前端，其余错误使用兜底提示
+func aiChatErrorMessage(err error, fallback string) string {
+	if code, msg := errs.ParseCommonError(err.Error()); code == errs.ErrBadRequest {
+		return msg
+	}
+	return fallback
+}
 
-		log.Printf("[conversation] 会话记录存储完成，sessionID: %s, userSeq: %d, aiSeq: %d", sessionID, userSeq, aiSeq)
-	}()
+// writeSSEError 通过 SSE 发送错误事件
+func writeSSEError(w http.ResponseWriter, flusher http.Flusher, message string) {
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", message)
+	flusher.Flush()
 }
 
 // studentAISessionsHandler 获取用户AI会话列表
@@ -482,6 +479,43 @@ func studentAISessionMessagesHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// studentAIUsageHandler 查询本人 AI 用量与剩余额度
+// GET /student/ai/usage?class_id=
+func studentAIUsageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	studentId, ok := authen.GetRoleIDFromContext(r.Context())
+	if !ok || studentId == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]interface{}{"code": 401, "message": "未授权"},
+			"data":  nil,
+		})
+		return
+	}
+
+	usage, err := aiUsageService.GetMyUsage(studentId, strings.TrimSpace(r.URL.Query().Get("class_id")))
+	if err != nil {
+		log.Printf("[conversation] 查询 AI 用量失败，学生: %s, err: %v", studentId, err)
+		writeServiceError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"code": 0},
+		"data":  usage,
+	})
+}
+
 // buildSystemPrompt 根据问题类型和题目信息构建系统提示词
 func buildSystemPrompt(questionType string, problemInfo *ProblemContext, userCode string, userCodeLang string) string {
 	basePrompt := "你是一位专业的编程助教，擅长帮助学生理解算法和编程问题。请用清晰、易懂的方式回答学生的问题，可以给出思路提示，但不要直接给出完整答案，鼓励学生自己思考。"
@@ -0,0 +1,330 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/model/discussion"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var discussionService *service_impl.DiscussionServiceImpl
+
+// registerDiscussion 注册讨论区相关路由（需要认证）
+func registerDiscussion(protectedRouter *mux.Router) {
+	// 学生：主题帖列表、主题帖详情、发帖/回复、点赞、删除自己的帖子
+	protectedRouter.HandleFunc("/student/discussion/threads", studentListThreadsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/student/discussion/thread", studentGetThreadHandler).Methods("GET")
+	protectedRouter.HandleFunc("/student/discussion/post", studentCreatePostHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/discussion/like", studentLikePostHandler).Methods("POST")
+	protectedRouter.HandleFunc("/student/discussion/delete", studentDeletePostHandler).Methods("POST")
+	// 学生：@提及与回复通知
+	protectedRouter.HandleFunc("/student/discussion/notifications", listDiscussionNotificationsHandler).Methods("GET")
+	protectedRouter.HandleFunc("/student/discussion/notifications/read", markDiscussionNotificationsReadHandler).Methods("POST")
+
+	// 教师：主题帖列表（含已隐藏）、主题帖详情、发帖/回复、点赞
+	protectedRouter.HandleFunc("/teacher/discussion/threads", teacherListThreadsHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/discussion/thread", teacherGetThreadHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/discussion/post", teacherCreatePostHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/discussion/like", teacherLikePostHandler).Methods("POST")
+	// 教师：置顶、标记答案、审核（隐藏/取消隐藏/删除）
+	protectedRouter.HandleFunc("/teacher/discussion/pin", pinThreadHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/discussion/answer", markAnswerHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/discussion/moderate", moderatePostHandler).Methods("POST")
+}
+
+// ==================== 学生接口 ====================
+
+// studentListThreadsHandler 查询主题帖列表（学生）
+// GET /student/discussion/threads?section_id=xxx&page=1&page_size=20
+func studentListThreadsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	query := r.URL.Query()
+	req := &service_impl.ListThreadsRequest{
+		SectionId: query.Get("section_id"),
+		Page:      int32(parseIntWithDefault(query.Get("page"), 1)),
+		PageSize:  int32(parseIntWithDefault(query.Get("page_size"), 20)),
+	}
+	resp, err := discussionService.ListThreads(ctx, discussion.AuthorTypeStudent, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// studentGetThreadHandler 查询主题帖详情（学生）
+// GET /student/discussion/thread?post_id=xxx&page=1&page_size=50
+func studentGetThreadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	query := r.URL.Query()
+	req := &service_impl.GetThreadRequest{
+		PostId:   query.Get("post_id"),
+		Page:     int32(parseIntWithDefault(query.Get("page"), 1)),
+		PageSize: int32(parseIntWithDefault(query.Get("page_size"), 50)),
+	}
+	resp, err := discussionService.GetThread(ctx, discussion.AuthorTypeStudent, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// studentCreatePostHandler 发帖/回复（学生）
+func studentCreatePostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.CreatePostRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.CreatePost(ctx, discussion.AuthorTypeStudent, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// studentLikePostHandler 点赞/取消点赞（学生）
+func studentLikePostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.LikePostRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.LikePost(ctx, discussion.AuthorTypeStudent, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// studentDeletePostHandler 删除自己的帖子（学生）
+func studentDeletePostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.DiscussionPostRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.DeleteOwnPost(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// listDiscussionNotificationsHandler 查询讨论通知（学生）
+// GET /student/discussion/notifications?unread_only=true&page=1&page_size=20
+func listDiscussionNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	query := r.URL.Query()
+	unreadOnly, _ := strconv.ParseBool(query.Get("unread_only"))
+	req := &service_impl.ListNotificationsRequest{
+		UnreadOnly: unreadOnly,
+		Page:       int32(parseIntWithDefault(query.Get("page"), 1)),
+		PageSize:   int32(parseIntWithDefault(query.Get("page_size"), 20)),
+	}
+	resp, err := discussionService.ListNotifications(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// markDiscussionNotificationsReadHandler 标记讨论通知已读（学生）
+func markDiscussionNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.MarkNotificationsReadRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.MarkNotificationsRead(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// ==================== 教师接口 ====================
+
+// teacherListThreadsHandler 查询主题帖列表（教师，含已隐藏的帖子）
+func teacherListThreadsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ListThreadsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.ListThreads(ctx, discussion.AuthorTypeTeacher, req.TeacherId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// teacherGetThreadHandler 查询主题帖详情（教师）
+func teacherGetThreadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.GetThreadRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.GetThread(ctx, discussion.AuthorTypeTeacher, req.TeacherId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// teacherCreatePostHandler 发帖/回复（教师）
+func teacherCreatePostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.CreatePostRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.CreatePost(ctx, discussion.AuthorTypeTeacher, req.TeacherId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// teacherLikePostHandler 点赞/取消点赞（教师）
+func teacherLikePostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.LikePostRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.LikePost(ctx, discussion.AuthorTypeTeacher, req.TeacherId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// pinThreadHandler 置顶/取消置顶主题帖（教师）
+func pinThreadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.DiscussionPostRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.PinThread(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// markAnswerHandler 标记/取消标记答案（教师）
+func markAnswerHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.DiscussionPostRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.MarkAnswer(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// moderatePostHandler 审核帖子（教师）
+func moderatePostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.DiscussionPostRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := discussionService.ModeratePost(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeDiscussionResponse(w, resp.Code, resp.Message, resp)
+}
+
+// writeDiscussionResponse 根据业务码写入讨论区接口响应
+func writeDiscussionResponse(w http.ResponseWriter, code int32, message string, resp interface{}) {
+	if code != 0 {
+		writeBizErrorResponse(w, code, message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
@@ -0,0 +1,165 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var examService *service_impl.ExamServiceImpl
+
+// registerExam 注册考试防作弊相关路由（需要认证）
+func registerExam(protectedRouter *mux.Router) {
+	// 进入考试（绑定IP与登录会话）
+	protectedRouter.HandleFunc("/student/exam/enter", enterExamHandler).Methods("POST")
+	// 上报考试事件（切屏、粘贴等）
+	protectedRouter.HandleFunc("/student/exam/events", reportExamEventsHandler).Methods("POST")
+	// 查询考试会话列表（教师）
+	protectedRouter.HandleFunc("/teacher/exam/sessions", listExamSessionsHandler).Methods("POST")
+	// 查询考试事件日志（教师）
+	protectedRouter.HandleFunc("/teacher/exam/events", listExamEventsHandler).Methods("POST")
+	// 重置学生考试会话（教师）
+	protectedRouter.HandleFunc("/teacher/exam/reset", resetExamSessionHandler).Methods("POST")
+}
+
+// examGuard 考试期间禁用的接口：学生处于进行中的考试时拒绝访问
+func examGuard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next(w, r)
+			return
+		}
+		ctx := r.Context()
+		userType, _ := authen.GetUserTypeFromContext(ctx)
+		studentId, _ := authen.GetRoleIDFromContext(ctx)
+		if userType == consts.RoleStudent && studentId != "" && examService.GetActiveExamId(ctx, studentId) != "" {
+			setResponseHeaders(w)
+			writeErrorResponse(w, http.StatusForbidden, "考试期间不可使用该功能")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// enterExamHandler 进入考试
+func enterExamHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	req := &service_impl.EnterExamRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := examService.EnterExam(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// reportExamEventsHandler 上报考试事件
+func reportExamEventsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	req := &service_impl.ReportExamEventsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := examService.ReportExamEvents(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listExamSessionsHandler 查询考试会话列表（教师）
+func listExamSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherExamRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := examService.ListExamSessions(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listExamEventsHandler 查询考试事件日志（教师）
+func listExamEventsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherExamRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := examService.ListExamEvents(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// resetExamSessionHandler 重置学生考试会话（教师）
+func resetExamSessionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.TeacherExamRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := examService.ResetExamSession(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
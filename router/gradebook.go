@@ -0,0 +1,88 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var gradebookService *service_impl.GradebookServiceImpl
+
+// registerGradebook 注册成绩册相关路由（仅教师）
+func registerGradebook(protectedRouter *mux.Router) {
+	// 查询班级成绩册（学生 × 算法题小节）
+	protectedRouter.HandleFunc("/teacher/gradebook", getGradebookHandler).Methods("POST")
+	// 导出班级成绩册（csv / xlsx）
+	protectedRouter.HandleFunc("/teacher/gradebook/export", exportGradebookHandler).Methods("GET")
+	// 设置章节成绩权重
+	protectedRouter.HandleFunc("/teacher/gradebook/weights", updateChapterWeightsHandler).Methods("POST")
+}
+
+// getGradebookHandler 查询班级成绩册
+func getGradebookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.GetGradebookRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := gradebookService.GetGradebook(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// exportGradebookHandler 导出班级成绩册
+// GET /teacher/gradebook/export?teacher_id=xxx&class_id=xxx&format=csv|xlsx
+func exportGradebookHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(query.Get("format")))
+	content, fileName, err := gradebookService.ExportGradebook(r.Context(),
+		strings.TrimSpace(query.Get("teacher_id")), strings.TrimSpace(query.Get("class_id")), format)
+	if err != nil {
+		setResponseHeaders(w)
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	setFileHeaders(w)
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(fileName))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+// updateChapterWeightsHandler 设置章节成绩权重
+func updateChapterWeightsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.UpdateChapterWeightsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := gradebookService.UpdateChapterWeights(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var leaderboardService *service_impl.LeaderboardServiceImpl
+
+// registerLeaderboard 注册班级排行榜相关路由
+func registerLeaderboard(protectedRouter *mux.Router) {
+	// 学生：查看班级排行榜、设置自己的展示方式（实名/匿名/不参与）
+	protectedRouter.HandleFunc("/student/class/leaderboard", examGuard(studentGetLeaderboardHandler)).Methods("POST")
+	protectedRouter.HandleFunc("/student/class/leaderboard/visibility", setLeaderboardVisibilityHandler).Methods("POST")
+	// 教师：查看班级排行榜、开启/关闭排行榜
+	protectedRouter.HandleFunc("/teacher/class/leaderboard", teacherGetLeaderboardHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/class/leaderboard/setting", setLeaderboardEnabledHandler).Methods("POST")
+}
+
+// studentGetLeaderboardHandler 学生查看班级排行榜
+func studentGetLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.GetLeaderboardRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := leaderboardService.GetStudentLeaderboard(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// setLeaderboardVisibilityHandler 学生设置排行榜展示方式
+func setLeaderboardVisibilityHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.SetLeaderboardVisibilityRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := leaderboardService.SetLeaderboardVisibility(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// teacherGetLeaderboardHandler 教师查看班级排行榜
+func teacherGetLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.GetLeaderboardRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := leaderboardService.GetTeacherLeaderboard(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// setLeaderboardEnabledHandler 教师开启或关闭班级排行榜
+func setLeaderboardEnabledHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.SetLeaderboardEnabledRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := leaderboardService.SetLeaderboardEnabled(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
@@ -0,0 +1,135 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var mistakeService *service_impl.MistakeServiceImpl
+
+// registerMistake 注册错题本相关路由（学生端，需要认证）
+func registerMistake(protectedRouter *mux.Router) {
+	// 查询错题列表
+	protectedRouter.HandleFunc("/student/mistake/list", listMistakesHandler).Methods("GET")
+	// 查询错题详情（失败代码与通过代码对比）
+	protectedRouter.HandleFunc("/student/mistake/detail", getMistakeDetailHandler).Methods("GET")
+	// 更新错题笔记
+	protectedRouter.HandleFunc("/student/mistake/note", updateMistakeNoteHandler).Methods("POST")
+	// 标记/取消标记已掌握
+	protectedRouter.HandleFunc("/student/mistake/master", markMistakeMasteredHandler).Methods("POST")
+}
+
+// listMistakesHandler 查询错题列表
+// GET /student/mistake/list?status=-1&reason=WA&page=1&page_size=20
+func listMistakesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	query := r.URL.Query()
+	req := &service_impl.ListMistakesRequest{
+		Status:   int32(parseIntWithDefault(query.Get("status"), -1)),
+		Reason:   query.Get("reason"),
+		Page:     int32(parseIntWithDefault(query.Get("page"), 1)),
+		PageSize: int32(parseIntWithDefault(query.Get("page_size"), 20)),
+	}
+	resp, err := mistakeService.ListMistakes(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getMistakeDetailHandler 查询错题详情
+// GET /student/mistake/detail?mistake_id=xxx
+func getMistakeDetailHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	resp, err := mistakeService.GetMistakeDetail(ctx, studentId, r.URL.Query().Get("mistake_id"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// updateMistakeNoteHandler 更新错题笔记
+func updateMistakeNoteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	req := &service_impl.UpdateMistakeNoteRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := mistakeService.UpdateMistakeNote(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// markMistakeMasteredHandler 标记/取消标记错题已掌握
+func markMistakeMasteredHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+
+	req := &service_impl.MarkMistakeMasteredRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := mistakeService.MarkMistakeMastered(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
@@ -33,9 +33,9 @@ func RegisterPlatformContentRoutes(protectedRouter *mux.Router) {
 	adminRouter.HandleFunc("/platform-bookshelf/{item_id}", deleteBookshelfItemHandler).Methods("DELETE")
 
 	protectedRouter.HandleFunc("/system-announcements", listUserSystemAnnouncementsHandler).Methods("GET")
-	protectedRouter.HandleFunc("/platform-bookshelf", listUserBookshelfItemsHandler).Methods("GET")
-	protectedRouter.HandleFunc("/platform-bookshelf/files/{item_id}/view", viewBookshelfAttachmentHandler).Methods("GET")
-	protectedRouter.HandleFunc("/platform-bookshelf/files/{item_id}/download", downloadBookshelfAttachmentHandler).Methods("GET")
+	protectedRouter.HandleFunc("/platform-bookshelf", examGuard(listUserBookshelfItemsHandler)).Methods("GET")
+	protectedRouter.HandleFunc("/platform-bookshelf/files/{item_id}/view", examGuard(viewBookshelfAttachmentHandler)).Methods("GET")
+	protectedRouter.HandleFunc("/platform-bookshelf/files/{item_id}/download", examGuard(downloadBookshelfAttachmentHandler)).Methods("GET")
 }
 
 func createSystemAnnouncementHandler(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,62 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var progressService *service_impl.ProgressServiceImpl
+
+// registerProgress 注册学习进度相关路由
+func registerProgress(protectedRouter *mux.Router) {
+	// 学生：我的各班级学习进度
+	protectedRouter.HandleFunc("/student/progress", getMyProgressHandler).Methods("GET")
+	// 教师：班级全部成员的学习进度
+	protectedRouter.HandleFunc("/teacher/class/progress", getClassProgressHandler).Methods("POST")
+}
+
+// getMyProgressHandler 查询我的学习进度
+func getMyProgressHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	resp, err := progressService.GetMyProgress(ctx, studentId)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// getClassProgressHandler 查询班级学习进度
+func getClassProgressHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.GetClassProgressRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := progressService.GetClassProgress(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
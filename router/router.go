@@ -27,8 +27,23 @@ func Init() {
 	classService = service_impl.NewClassServiceImpl()
 	chapterService = service_impl.NewChapterServiceImpl()
 	codeRunService = service_impl.NewCodeRunServiceImpl()
+	mistakeService = service_impl.NewMistakeServiceImpl()
+	assignmentService = service_impl.NewAssignmentServiceImpl()
+	quizService = service_impl.NewQuizServiceImpl()
+	sectionResourceService = service_impl.NewSectionResourceServiceImpl()
+	progressService = service_impl.NewProgressServiceImpl()
+	classInsightService = service_impl.NewClassInsightServiceImpl()
+	leaderboardService = service_impl.NewLeaderboardServiceImpl()
+	classGroupService = service_impl.NewClassGroupServiceImpl()
+	attendanceService = service_impl.NewAttendanceServiceImpl()
+	gradebookService = service_impl.NewGradebookServiceImpl()
+	contestService = service_impl.NewContestServiceImpl()
+	examService = service_impl.NewExamServiceImpl()
+	discussionService = service_impl.NewDiscussionServiceImpl()
 	platformContentService = service.NewPlatformContentService()
 	adminUserManagementService = service.NewAdminUserManagementService()
+	aiChatService = service.NewAIChatService()
+	aiUsageService = service.NewAIUsageService()
 	teacherApprovalService = service_impl.NewTeacherApprovalServiceImpl()
 }
 
@@ -77,15 +92,42 @@ func registerApiRouters(publicRouter *mux.Router, protectedRouter *mux.Router) {
 	// 章节相关接口（增删改仅教师，查询学生和教师均可）
 	registerChapter(publicRouter, protectedRouter)
 
+	// 作业相关接口（教师设置作业，学生查询提交情况）
+	registerAssignment(protectedRouter)
+	// 测验相关接口（教师管理题目与查看成绩，学生作答）
+	registerQuiz(protectedRouter)
+	registerSectionResource(protectedRouter)
+	registerProgress(protectedRouter)
+	registerClassInsight(protectedRouter)
+	registerLeaderboard(protectedRouter)
+	registerClassGroup(protectedRouter)
+	registerAttendance(protectedRouter)
+
+	// 成绩册相关接口（仅教师）
+	registerGradebook(protectedRouter)
+
+	// 比赛/考试相关接口（教师管理，学生参赛）
+	registerContest(protectedRouter)
+	// 考试防作弊相关接口（会话绑定、事件日志）
+	registerExam(protectedRouter)
+	// 讨论区相关接口（讨论小节的帖子、回复与通知）
+	registerDiscussion(protectedRouter)
+
 	// 代码运行相关接口（学生端）
 	registerCodeRun(protectedRouter)
 
+	// 错题本相关接口（学生端）
+	registerMistake(protectedRouter)
+
 	// 平台系统公告与平台书架接口
 	RegisterPlatformContentRoutes(protectedRouter)
 
 	// 管理员用户管理接口
 	RegisterAdminUserManagementRoutes(protectedRouter)
 
+	// 管理员 AI 用量额度与报表接口
+	RegisterAIUsageRoutes(protectedRouter)
+
 }
 
 // registerLogout 注册登出接口（需要认证）
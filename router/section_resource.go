@@ -0,0 +1,154 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/service_impl"
+)
+
+var sectionResourceService *service_impl.SectionResourceServiceImpl
+
+// registerSectionResource 注册学习资源小节（阅读资料、视频）相关路由
+func registerSectionResource(protectedRouter *mux.Router) {
+	// 教师：设置资源、查看学生查看情况
+	protectedRouter.HandleFunc("/teacher/section/resource", setSectionResourceHandler).Methods("POST")
+	protectedRouter.HandleFunc("/teacher/section/resource/views", listSectionViewsHandler).Methods("POST")
+
+	// 学生：打开资源（记录查看）
+	protectedRouter.HandleFunc("/student/section/resource/open", examGuard(openSectionResourceHandler)).Methods("POST")
+
+	// 师生共用：查看、下载班级上传的阅读资料
+	protectedRouter.HandleFunc("/class/section/{section_id}/file/view", examGuard(viewSectionResourceFileHandler)).Methods("GET")
+	protectedRouter.HandleFunc("/class/section/{section_id}/file/download", examGuard(downloadSectionResourceFileHandler)).Methods("GET")
+}
+
+// ==================== 教师接口 ====================
+
+// setSectionResourceHandler 设置学习资源（multipart 表单，上传文件字段为 file）
+func setSectionResourceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	if err := r.ParseMultipartForm(60 << 20); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "表单解析失败或文件过大")
+		return
+	}
+	req := &service_impl.SetSectionResourceRequest{
+		TeacherId:       strings.TrimSpace(r.FormValue("teacher_id")),
+		SectionId:       strings.TrimSpace(r.FormValue("section_id")),
+		BookshelfItemId: strings.TrimSpace(r.FormValue("bookshelf_item_id")),
+		VideoUrl:        strings.TrimSpace(r.FormValue("video_url")),
+	}
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil && !errors.Is(err, http.ErrMissingFile) {
+		writeErrorResponse(w, http.StatusBadRequest, "读取上传文件失败")
+		return
+	}
+	if err == nil {
+		_ = file.Close()
+		req.File = fileHeader
+	}
+	resp, err := sectionResourceService.SetSectionResource(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// listSectionViewsHandler 查询学生查看情况
+func listSectionViewsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	req := &service_impl.ListSectionViewsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := sectionResourceService.ListSectionViews(ctx, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// ==================== 学生接口 ====================
+
+// openSectionResourceHandler 打开学习资源
+func openSectionResourceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	setResponseHeaders(w)
+	studentId, ok := authen.GetRoleIDFromContext(ctx)
+	if !ok || studentId == "" {
+		writeErrorResponse(w, http.StatusUnauthorized, "未授权，请先登录")
+		return
+	}
+	req := &service_impl.OpenSectionResourceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := sectionResourceService.OpenSectionResource(ctx, studentId, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	writeSuccessResponse(w, resp)
+}
+
+// ==================== 文件接口 ====================
+
+// viewSectionResourceFileHandler 在线查看阅读资料
+func viewSectionResourceFileHandler(w http.ResponseWriter, r *http.Request) {
+	serveSectionResourceFile(w, r, false)
+}
+
+// downloadSectionResourceFileHandler 下载阅读资料
+func downloadSectionResourceFileHandler(w http.ResponseWriter, r *http.Request) {
+	serveSectionResourceFile(w, r, true)
+}
+
+func serveSectionResourceFile(w http.ResponseWriter, r *http.Request, download bool) {
+	ctx := r.Context()
+	userType, _ := authen.GetUserTypeFromContext(ctx)
+	roleId, _ := authen.GetRoleIDFromContext(ctx)
+	resp, err := sectionResourceService.GetSectionResourceFile(ctx, userType, roleId, mux.Vars(r)["section_id"])
+	if err != nil {
+		setResponseHeaders(w)
+		writeErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Code != 0 {
+		setResponseHeaders(w)
+		writeBizErrorResponse(w, resp.Code, resp.Message)
+		return
+	}
+	setFileHeaders(w)
+	disposition := "inline"
+	if download {
+		disposition = "attachment"
+		w.Header().Set("Content-Type", "application/octet-stream")
+	} else if resp.Section.AttachmentMimeType != "" {
+		w.Header().Set("Content-Type", resp.Section.AttachmentMimeType)
+	}
+	w.Header().Set("Content-Disposition", disposition+"; filename*=UTF-8''"+url.PathEscape(resp.Section.AttachmentName))
+	http.ServeFile(w, r, resp.FilePath)
+}
@@ -33,6 +33,7 @@ func registerStudent(publicRouter *mux.Router, protectedRouter *mux.Router) {
 	protectedRouter.HandleFunc("/student/get", getStudentHandler).Methods("GET")
 	protectedRouter.HandleFunc("/student/update", updateStudentHandler).Methods("POST")
 	protectedRouter.HandleFunc("/student/list", listStudentsHandler).Methods("POST")
+	// 已废弃：客户端自行写入的进度不可信，请使用 GET /student/progress 查询服务端按班级计算的学习进度
 	protectedRouter.HandleFunc("/student/update-progress", updateLearningProgressHandler).Methods("POST")
 }
 
@@ -200,7 +201,7 @@ func listStudentsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(respBytes)
 }
 
-// updateLearningProgressHandler 更新学习进度处理器
+// updateLearningProgressHandler 更新学习进度处理器（已废弃，保留兼容旧客户端）
 func updateLearningProgressHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
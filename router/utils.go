@@ -37,3 +37,14 @@ func writeSuccessResponse(w http.ResponseWriter, data interface{}) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(respBytes)
 }
+
+// writeBizErrorResponse 写入业务错误响应（HTTP 状态码为 200，错误码放在 error 中）
+func writeBizErrorResponse(w http.ResponseWriter, code int32, message string) {
+	errResp := &errs.BaseResponse{
+		Data:  nil,
+		Error: errs.NewError(int(code), message),
+	}
+	respBytes, _ := json.Marshal(errResp)
+	w.WriteHeader(http.StatusOK)
+	w.Write(respBytes)
+}
@@ -0,0 +1,609 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/model/conversation"
+	agentpb "github.com/yzf120/elysia-backend/proto/agent"
+	"github.com/yzf120/elysia-backend/rpc"
+	agent_session "github.com/yzf120/elysia-session/proto/agent_session"
+	conversationpb "github.com/yzf120/elysia-session/proto/conversation"
+	"gorm.io/gorm"
+)
+
+const (
+	aiChatSessionTitleLen   = 30               // 会话标题截取的用户消息长度
+	aiChatRPCTimeout        = 10 * time.Second // 调用 session 服务的超时时间
+	aiChatOutboxInterval    = 30 * time.Second // 待投递消息重试任务执行间隔
+	aiChatOutboxBatchSize   = 100              // 每次重试的最大消息数
+	aiChatOutboxMaxRetry    = 10               // 最大重试次数，超过后标记为投递失败
+	aiChatOutboxMaxBackoff  = 30 * time.Minute // 重试退避上限
+	aiChatOutboxLockKey     = "ai:chat:outbox:lock"
+	aiChatOutboxErrorMaxLen = 500
+
+	aiChatHistoryPageSize      = 100  // 加载会话历史的分页大小
+	aiChatHistoryMaxPages      = 20   // 加载会话历史的最大页数
+	defaultAIChatContextBudget = 8000 // 未配置模型的上下文 token 预算
+	aiChatSummaryModel         = "doubao-seed-1-6-lite-251015"
+	aiChatSummaryTimeout       = 2 * time.Minute
+	aiChatSummaryMsgMaxLen     = 2000 // 生成摘要时单条消息截取的最大长度
+	aiChatSummaryLockKey       = "ai:chat:summary:lock:%s"
+	aiChatSummaryPrompt        = "你是编程答疑对话的摘要助手。请把“已有摘要”和“新增对话”合并为一份新的摘要，保留学生正在解决的问题、已确认的思路与结论、仍未解决的疑问以及学生代码中的关键错误，使用第三人称简洁陈述，不超过500字，只输出摘要正文。"
+)
+
+// aiChatContextBudgets 各模型的上下文 token 预算（含系统提示词与本次提问，按模型ID前缀匹配，靠前的优先）
+var aiChatContextBudgets = []struct {
+	prefix string
+	budget int
+}{
+	{"doubao-seed-1-6-lite", 16000},
+	{"doubao", 24000},
+	{"qwen-turbo", 8000},
+	{"qwen", 16000},
+}
+
+// AIChatService AI 答疑会话服务（会话创建、服务端上下文组装与对话记录持久化）
+type AIChatService struct {
+	aiChatDAO    dao.AIChatDAO
+	usageService *AIUsageService
+}
+
+// NewAIChatService 创建 AI 答疑会话服务
+func NewAIChatService() *AIChatService {
+	return &AIChatService{
+		aiChatDAO:    dao.NewAIChatDAO(),
+		usageService: NewAIUsageService(),
+	}
+}
+
+// AIChatMessage 发送给模型的一条对话消息
+type AIChatMessage struct {
+	Role    string // user 或 assistant
+	Content string
+}
+
+// AIChatTurn 服务端组装的一轮对话上下文
+type AIChatTurn struct {
+	UserSeq      int32           // 本次学生消息序号
+	AISeq        int32           // 本次 AI 回复序号
+	SystemPrompt string          // 系统提示词（含早期对话摘要）
+	Messages     []AIChatMessage // 预算内的历史消息（含本次提问）
+	Truncated    bool            // 是否有历史消息因超出预算未放入上下文
+	PromptTokens int             // 估算的输入 token 数（模型未返回用量时用于记账）
+}
+
+// aiChatHistoryMessage 会话中的一条历史消息
+type aiChatHistoryMessage struct {
+	Seq     int32
+	Role    string
+	Content string
+}
+
+// EnsureSession 确保会话存在并校验归属：sessionId 为空时以本次用户消息为标题创建新会话，返回会话元数据及是否为新建会话
+func (s *AIChatService) EnsureSession(ctx context.Context, studentId, sessionId, userMessage string, problemId int64) (*conversation.AIChatSession, bool, error) {
+	if sessionId != "" {
+		session, err := s.getSession(ctx, studentId, sessionId)
+		return session, false, err
+	}
+
+	title := userMessage
+	if runes := []rune(title); len(runes) > aiChatSessionTitleLen {
+		title = string(runes[:aiChatSessionTitleLen]) + "..."
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, aiChatRPCTimeout)
+	defer cancel()
+	rsp, err := rpc.GetSessionClient().CreateSession(rpcCtx, &agent_session.CreateSessionRequest{
+		UserId:       studentId,
+		SessionTitle: title,
+		ProblemId:    problemId,
+	})
+	if err != nil {
+		return nil, false, errs.NewCommonError(errs.ErrInternal, "创建会话失败: "+err.Error())
+	}
+	if rsp.Code != 200 || rsp.SessionId == "" {
+		return nil, false, errs.NewCommonError(errs.ErrInternal, fmt.Sprintf("创建会话失败: code=%d, %s", rsp.Code, rsp.Message))
+	}
+
+	session := &conversation.AIChatSession{
+		SessionId: rsp.SessionId,
+		UserId:    studentId,
+		ProblemId: problemId,
+	}
+	if err := s.aiChatDAO.CreateSession(session); err != nil {
+		return nil, false, errs.NewCommonError(errs.ErrInternal, "保存会话失败: "+err.Error())
+	}
+	return session, true, nil
+}
+
+// getSession 查询会话元数据并校验归属；本地无记录的历史会话在 session 服务确认归属后，按已有消息补建元数据
+func (s *AIChatService) getSession(ctx context.Context, studentId, sessionId string) (*conversation.AIChatSession, error) {
+	session, err := s.aiChatDAO.GetSessionById(sessionId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询会话失败: "+err.Error())
+	}
+	if session == nil {
+		remote, err := s.findUserSession(ctx, studentId, sessionId)
+		if err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "查询会话失败: "+err.Error())
+		}
+		if remote == nil {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "会话不存在")
+		}
+		history, err := s.loadHistory(ctx, sessionId)
+		if err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "加载会话历史失败: "+err.Error())
+		}
+		adopted := &conversation.AIChatSession{
+			SessionId: sessionId,
+			UserId:    studentId,
+			ProblemId: remote.GetProblemId(),
+		}
+		if len(history) > 0 {
+			adopted.LastSeq = history[len(history)-1].Seq
+		}
+		if err := s.aiChatDAO.CreateSession(adopted); err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "保存会话失败: "+err.Error())
+		}
+		if session, err = s.aiChatDAO.GetSessionById(sessionId); err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "查询会话失败: "+err.Error())
+		}
+	}
+	if session.UserId != studentId {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "会话不存在")
+	}
+	return session, nil
+}
+
+// findUserSession 在 session 服务中查找学生名下的会话，不属于该学生时返回 nil
+func (s *AIChatService) findUserSession(ctx context.Context, studentId, sessionId string) (*agent_session.Session, error) {
+	for page := int32(1); page <= aiChatHistoryMaxPages; page++ {
+		rpcCtx, cancel := context.WithTimeout(ctx, aiChatRPCTimeout)
+		rsp, err := rpc.GetSessionClient().ListSessionsByUser(rpcCtx, &agent_session.ListSessionsByUserRequest{
+			UserId:   studentId,
+			Page:     page,
+			PageSize: aiChatHistoryPageSize,
+		})
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range rsp.GetSessions() {
+			if item.GetSessionId() == sessionId {
+				return item, nil
+			}
+		}
+		if len(rsp.GetSessions()) < aiChatHistoryPageSize {
+			break
+		}
+	}
+	return nil, nil
+}
+
+// PrepareTurn 开始一轮对话：从 session 服务加载历史消息，分配消息序号并写入本次学生消息，
+// 再按模型的 token 预算组装上下文（早期对话以滚动摘要代替，超出预算的旧消息被截断并在后台合并进摘要）
+func (s *AIChatService) PrepareTurn(ctx context.Context, session *conversation.AIChatSession, modelId, systemPrompt, userMessage string) (*AIChatTurn, error) {
+	history, err := s.loadHistory(ctx, session.SessionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "加载会话历史失败: "+err.Error())
+	}
+
+	lastSeq, err := s.aiChatDAO.ReserveSeq(session.SessionId, 2)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "分配消息序号失败: "+err.Error())
+	}
+	turn := &AIChatTurn{
+		UserSeq:      lastSeq - 1,
+		AISeq:        lastSeq,
+		SystemPrompt: systemPrompt,
+	}
+	if err := s.SaveUserMessage(session.SessionId, session.UserId, modelId, userMessage, turn.UserSeq); err != nil {
+		return nil, err
+	}
+
+	// 只取摘要之后、本轮之前的消息
+	recent := make([]aiChatHistoryMessage, 0, len(history))
+	for _, msg := range history {
+		if msg.Seq > session.SummarySeq && msg.Seq < turn.UserSeq {
+			recent = append(recent, msg)
+		}
+	}
+
+	if session.Summary != "" {
+		turn.SystemPrompt += "\n\n【此前对话摘要】\n" + session.Summary
+	}
+	budget := aiChatContextBudget(modelId)
+	remaining := budget - estimateTokens(turn.SystemPrompt) - estimateTokens(userMessage)
+	kept := fitHistory(recent, remaining)
+	turn.Truncated = len(kept) < len(recent)
+	for _, msg := range kept {
+		turn.Messages = append(turn.Messages, AIChatMessage{Role: msg.Role, Content: msg.Content})
+	}
+	turn.Messages = append(turn.Messages, AIChatMessage{Role: "user", Content: userMessage})
+	turn.PromptTokens = estimateTokens(turn.SystemPrompt)
+	for _, msg := range turn.Messages {
+		turn.PromptTokens += estimateTokens(msg.Content)
+	}
+
+	// 历史超出预算时，把放不进半个预算的旧消息合并进摘要，避免每轮都重新生成摘要
+	if turn.Truncated {
+		keepForNext := fitHistory(recent, remaining/2)
+		older := recent[:len(recent)-len(keepForNext)]
+		if len(older) > 0 {
+			go s.refreshSummary(session, older)
+		}
+	}
+	return turn, nil
+}
+
+// fitHistory 从最新的消息开始向前选取，直到超出预算；保证第一条为学生消息以保持一问一答
+func fitHistory(history []aiChatHistoryMessage, budget int) []aiChatHistoryMessage {
+	start := len(history)
+	used := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		used += estimateTokens(history[i].Content)
+		if used > budget {
+			break
+		}
+		start = i
+	}
+	for start < len(history) && history[start].Role != "user" {
+		start++
+	}
+	return history[start:]
+}
+
+// aiChatContextBudget 返回模型的上下文 token 预算（按模型ID前缀匹配）
+func aiChatContextBudget(modelId string) int {
+	for _, item := range aiChatContextBudgets {
+		if strings.HasPrefix(modelId, item.prefix) {
+			return item.budget
+		}
+	}
+	return defaultAIChatContextBudget
+}
+
+// estimateTokens 粗略估算文本的 token 数：中日韩字符按 1 个 token，其余字符每 4 个计 1 个 token，另加每条消息的格式开销
+func estimateTokens(text string) int {
+	cjk, other := 0, 0
+	for _, r := range text {
+		if r >= 0x2E80 {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4 + 4
+}
+
+// loadHistory 从 session 服务分页加载会话的全部消息，并合并尚未投递的消息，按序号升序返回
+func (s *AIChatService) loadHistory(ctx context.Context, sessionId string) ([]aiChatHistoryMessage, error) {
+	bySeq := make(map[int32]aiChatHistoryMessage)
+	for page := int32(1); page <= aiChatHistoryMaxPages; page++ {
+		rpcCtx, cancel := context.WithTimeout(ctx, aiChatRPCTimeout)
+		rsp, err := rpc.GetSessionClient().ListConversations(rpcCtx, &conversationpb.ListConversationsRequest{
+			SessionId: sessionId,
+			Page:      page,
+			PageSize:  aiChatHistoryPageSize,
+		})
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range rsp.GetConversations() {
+			role := "user"
+			if item.GetSenderType() == conversationpb.SenderType_SENDER_TYPE_AGENT {
+				role = "assistant"
+			}
+			bySeq[item.GetMessageSeq()] = aiChatHistoryMessage{Seq: item.GetMessageSeq(), Role: role, Content: item.GetContent()}
+		}
+		if len(rsp.GetConversations()) < aiChatHistoryPageSize || int32(len(bySeq)) >= rsp.Total {
+			break
+		}
+	}
+
+	pending, err := s.aiChatDAO.ListOutboxBySessionId(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range pending {
+		if _, ok := bySeq[item.MessageSeq]; ok {
+			continue
+		}
+		role := "user"
+		if item.SenderType == conversation.ChatSenderAgent {
+			role = "assistant"
+		}
+		bySeq[item.MessageSeq] = aiChatHistoryMessage{Seq: item.MessageSeq, Role: role, Content: item.Content}
+	}
+
+	history := make([]aiChatHistoryMessage, 0, len(bySeq))
+	for _, msg := range bySeq {
+		history = append(history, msg)
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Seq < history[j].Seq })
+	return history, nil
+}
+
+// refreshSummary 将较早的对话合并进会话的滚动摘要（后台执行，同一会话同时只生成一份摘要）
+func (s *AIChatService) refreshSummary(session *conversation.AIChatSession, older []aiChatHistoryMessage) {
+	sessionId, prevSummary := session.SessionId, session.Summary
+	if redisClient := client.GetRedisClient(); redisClient != nil {
+		lockKey := fmt.Sprintf(aiChatSummaryLockKey, sessionId)
+		ok, err := redisClient.Client.SetNX(context.Background(), lockKey, time.Now().Unix(), aiChatSummaryTimeout).Result()
+		if err != nil || !ok {
+			return
+		}
+		defer redisClient.Del(lockKey)
+	}
+
+	var dialog strings.Builder
+	for _, msg := range older {
+		content := msg.Content
+		if runes := []rune(content); len(runes) > aiChatSummaryMsgMaxLen {
+			content = string(runes[:aiChatSummaryMsgMaxLen]) + "..."
+		}
+		if msg.Role == "assistant" {
+			dialog.WriteString("AI助教：")
+		} else {
+			dialog.WriteString("学生：")
+		}
+		dialog.WriteString(content)
+		dialog.WriteString("\n")
+	}
+	prompt := "已有摘要：\n"
+	if prevSummary == "" {
+		prompt += "（无）"
+	} else {
+		prompt += prevSummary
+	}
+	prompt += "\n\n新增对话：\n" + dialog.String() + "\n请输出合并后的新摘要。"
+
+	ctx, cancel := context.WithTimeout(context.Background(), aiChatSummaryTimeout)
+	defer cancel()
+	stream, err := rpc.GetAgentClient().GetProxy().StreamChat(ctx, &agentpb.AgentStreamChatRequest{
+		ModelID:      aiChatSummaryModel,
+		Messages:     []agentpb.AgentChatMessage{{Role: "user", Content: prompt}},
+		SystemPrompt: aiChatSummaryPrompt,
+	})
+	if err != nil {
+		log.Printf("[AIChat] 生成对话摘要失败，sessionID: %s, err: %v", sessionId, err)
+		return
+	}
+	var summary strings.Builder
+	usage := &agentpb.AgentStreamChatResponse{}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("[AIChat] 生成对话摘要失败，sessionID: %s, err: %v", sessionId, err)
+			return
+		}
+		summary.WriteString(chunk.Content)
+		MergeChunkUsage(usage, chunk)
+		if chunk.IsEnd {
+			break
+		}
+	}
+	// 摘要的模型用量同样计入学生额度
+	s.recordUsage(AIUsageRecord{
+		StudentId: session.UserId,
+		SessionId: sessionId,
+		ModelId:   aiChatSummaryModel,
+		Purpose:   aiUsagePurposeSummary,
+	}, estimateTokens(aiChatSummaryPrompt)+estimateTokens(prompt), summary.String(), usage)
+	if strings.TrimSpace(summary.String()) == "" {
+		return
+	}
+	if err := s.aiChatDAO.UpdateSummary(sessionId, strings.TrimSpace(summary.String()), older[len(older)-1].Seq); err != nil {
+		log.Printf("[AIChat] 保存对话摘要失败，sessionID: %s, err: %v", sessionId, err)
+	}
+}
+
+// RecordTurnUsage 记录本轮对话的模型用量（classId 为班级内发起的对话所属班级）
+func (s *AIChatService) RecordTurnUsage(session *conversation.AIChatSession, turn *AIChatTurn, classId, modelId, reply string, usage *agentpb.AgentStreamChatResponse) {
+	s.recordUsage(AIUsageRecord{
+		StudentId: session.UserId,
+		ClassId:   classId,
+		SessionId: session.SessionId,
+		ModelId:   modelId,
+		Purpose:   aiUsagePurposeChat,
+	}, turn.PromptTokens, reply, usage)
+}
+
+// recordUsage 写入用量流水：优先使用模型返回的用量，未返回时按文本长度估算（无回复且无用量时不记账）
+func (s *AIChatService) recordUsage(record AIUsageRecord, promptEstimate int, reply string, usage *agentpb.AgentStreamChatResponse) {
+	if usage != nil {
+		record.PromptTokens = int64(usage.PromptTokens)
+		record.CompletionTokens = int64(usage.CompletionTokens)
+		record.TotalTokens = int64(usage.TotalTokens)
+	}
+	if record.PromptTokens == 0 && record.CompletionTokens == 0 && record.TotalTokens == 0 {
+		if strings.TrimSpace(reply) == "" {
+			return
+		}
+		record.PromptTokens = int64(promptEstimate)
+		record.CompletionTokens = int64(estimateTokens(reply))
+		record.Estimated = true
+	}
+	if err := s.usageService.RecordUsage(record); err != nil {
+		log.Printf("[AIChat] 记录模型用量失败，学生: %s, sessionID: %s, err: %v", record.StudentId, record.SessionId, err)
+	}
+}
+
+// MergeChunkUsage 合并流式 chunk 中的用量（各项取最大值，兼容仅最后一个 chunk 返回用量与逐块累计返回两种方式）
+func MergeChunkUsage(usage, chunk *agentpb.AgentStreamChatResponse) {
+	if chunk.PromptTokens > usage.PromptTokens {
+		usage.PromptTokens = chunk.PromptTokens
+	}
+	if chunk.CompletionTokens > usage.CompletionTokens {
+		usage.CompletionTokens = chunk.CompletionTokens
+	}
+	if chunk.TotalTokens > usage.TotalTokens {
+		usage.TotalTokens = chunk.TotalTokens
+	}
+}
+
+// SaveUserMessage 写入本轮学生消息（在调用模型前写入，写入失败时不再继续对话）
+func (s *AIChatService) SaveUserMessage(sessionId, studentId, modelId, content string, seq int32) error {
+	return s.saveMessage(&conversation.AIChatOutbox{
+		SessionId:  sessionId,
+		UserId:     studentId,
+		ModelId:    modelId,
+		SenderType: conversation.ChatSenderUser,
+		Content:    content,
+		MessageSeq: seq,
+	})
+}
+
+// SaveAssistantReply 写入本轮 AI 回复（不受客户端断开影响）
+func (s *AIChatService) SaveAssistantReply(sessionId, studentId, modelId, content string, seq int32) error {
+	return s.saveMessage(&conversation.AIChatOutbox{
+		SessionId:  sessionId,
+		UserId:     studentId,
+		ModelId:    modelId,
+		SenderType: conversation.ChatSenderAgent,
+		Content:    content,
+		MessageSeq: seq,
+	})
+}
+
+// saveMessage 先写入本地待投递表再投递到 session 服务，投递成功后删除；投递失败时保留记录由后台任务重试。
+// 本地落库失败时直接投递，两者均失败才返回错误。
+func (s *AIChatService) saveMessage(item *conversation.AIChatOutbox) error {
+	now := time.Now()
+	item.Status = conversation.ChatOutboxPending
+	item.NextRetryTime = now.Add(aiChatOutboxInterval)
+	if err := s.aiChatDAO.CreateOutbox(item); err != nil {
+		log.Printf("[AIChat] 写入待投递消息失败，直接投递，sessionID: %s, seq: %d, err: %v", item.SessionId, item.MessageSeq, err)
+		if deliverErr := s.deliver(item); deliverErr != nil {
+			return errs.NewCommonError(errs.ErrInternal, "保存对话记录失败: "+deliverErr.Error())
+		}
+		return nil
+	}
+
+	if err := s.deliver(item); err != nil {
+		log.Printf("[AIChat] 投递对话记录失败，稍后重试，sessionID: %s, seq: %d, err: %v", item.SessionId, item.MessageSeq, err)
+		s.markRetry(item, err, now)
+		return nil
+	}
+	if err := s.aiChatDAO.DeleteOutbox(item.Id); err != nil {
+		log.Printf("[AIChat] 删除已投递消息失败，id: %d, err: %v", item.Id, err)
+	}
+	return nil
+}
+
+// deliver 将一条消息写入 session 服务
+func (s *AIChatService) deliver(item *conversation.AIChatOutbox) error {
+	senderType := conversationpb.SenderType_SENDER_TYPE_USER
+	if item.SenderType == conversation.ChatSenderAgent {
+		senderType = conversationpb.SenderType_SENDER_TYPE_AGENT
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), aiChatRPCTimeout)
+	defer cancel()
+	_, err := rpc.GetSessionClient().CreateConversation(ctx, &conversationpb.CreateConversationRequest{
+		SessionId:   item.SessionId,
+		UserId:      item.UserId,
+		ModelId:     item.ModelId,
+		MessageType: conversationpb.MessageType_MESSAGE_TYPE_TEXT,
+		SenderType:  senderType,
+		Content:     item.Content,
+		MessageSeq:  item.MessageSeq,
+	})
+	return err
+}
+
+// markRetry 记录一次投递失败，按指数退避安排下次重试，超过最大重试次数后标记为投递失败
+func (s *AIChatService) markRetry(item *conversation.AIChatOutbox, cause error, now time.Time) {
+	lastErr := cause.Error()
+	if len(lastErr) > aiChatOutboxErrorMaxLen {
+		lastErr = lastErr[:aiChatOutboxErrorMaxLen]
+	}
+	retryCount := item.RetryCount + 1
+	updates := map[string]interface{}{
+		"retry_count": retryCount,
+		"last_error":  lastErr,
+	}
+	if retryCount >= aiChatOutboxMaxRetry {
+		updates["status"] = conversation.ChatOutboxFailed
+		log.Printf("[AIChat] 对话记录超过最大重试次数，已标记为投递失败，id: %d, sessionID: %s, seq: %d", item.Id, item.SessionId, item.MessageSeq)
+	} else {
+		backoff := aiChatOutboxInterval << uint(retryCount-1)
+		if backoff > aiChatOutboxMaxBackoff {
+			backoff = aiChatOutboxMaxBackoff
+		}
+		updates["next_retry_time"] = now.Add(backoff)
+	}
+	if err := s.aiChatDAO.UpdateOutbox(item.Id, updates); err != nil {
+		log.Printf("[AIChat] 更新待投递消息失败，id: %d, err: %v", item.Id, err)
+	}
+}
+
+// ==================== 待投递消息重试任务 ====================
+
+// StartAIChatOutboxScheduler 启动对话记录重试后台任务（多实例部署时通过 Redis 锁保证同一周期只有一个实例执行）
+func StartAIChatOutboxScheduler() {
+	s := NewAIChatService()
+	go func() {
+		ticker := time.NewTicker(aiChatOutboxInterval)
+		defer ticker.Stop()
+		for {
+			s.runScheduled(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// runScheduled 获取执行锁后重试一批到期的待投递消息
+func (s *AIChatService) runScheduled(now time.Time) {
+	if redisClient := client.GetRedisClient(); redisClient != nil {
+		ok, err := redisClient.Client.SetNX(context.Background(), aiChatOutboxLockKey, now.Unix(), aiChatOutboxInterval-time.Second).Result()
+		if err != nil {
+			log.Printf("[AIChat] 获取执行锁失败: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	delivered, err := s.RetryOutboxOnce(now)
+	if err != nil {
+		log.Printf("[AIChat] 重试对话记录失败: %v", err)
+		return
+	}
+	if delivered > 0 {
+		log.Printf("[AIChat] 已补写对话记录 %d 条", delivered)
+	}
+}
+
+// RetryOutboxOnce 重试一批到期的待投递消息，返回投递成功的条数
+func (s *AIChatService) RetryOutboxOnce(now time.Time) (int, error) {
+	items, err := s.aiChatDAO.ListDueOutbox(now, aiChatOutboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, item := range items {
+		if err := s.deliver(item); err != nil {
+			s.markRetry(item, err, now)
+			continue
+		}
+		if err := s.aiChatDAO.DeleteOutbox(item.Id); err != nil {
+			log.Printf("[AIChat] 删除已投递消息失败，id: %d, err: %v", item.Id, err)
+		}
+		delivered++
+	}
+	return delivered, nil
+}
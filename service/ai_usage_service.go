@@ -0,0 +1,712 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/model/conversation"
+	"gorm.io/gorm"
+)
+
+const (
+	aiUsagePurposeChat    = "chat"    // 答疑对话
+	aiUsagePurposeSummary = "summary" // 会话摘要
+
+	aiUsageDateLayout    = "2006-01-02"
+	aiUsageMaxReportDays = 366   // 用量报表最大统计天数
+	aiUsageExportLimit   = 10000 // 导出的最大行数
+
+	aiUsageReserveKey      = "ai:usage:reserve:%s:%s"    // 学生进行中对话的预占用量（学生ID、日期或月份）
+	aiUsageClassReserveKey = "ai:usage:reserve:%s:%s:%s" // 学生在班级内进行中对话的预占用量（学生ID、班级ID、日期或月份）
+	aiUsageReserveTTL      = 10 * time.Minute            // 预占用量的过期时间，防止进程异常退出后无法释放
+
+	aiUsageReserveReplyTokens = 2000 // 预占时按此估算单轮回复与上下文的用量
+)
+
+// aiUsageReleaseScript 释放预占用量（键已过期时不再扣减，避免出现负数）
+var aiUsageReleaseScript = redis.NewScript(`for i, key in ipairs(KEYS) do if redis.call("exists", key) == 1 then redis.call("decrby", key, ARGV[1]) end end return 0`)
+
+// aiUsageGroupColumns 用量报表支持的分组维度
+var aiUsageGroupColumns = map[string][]string{
+	"student":       {"student_id"},
+	"class":         {"class_id"},
+	"model":         {"model_id"},
+	"date":          {"usage_date"},
+	"student_model": {"student_id", "model_id"},
+	"class_model":   {"class_id", "model_id"},
+}
+
+// AIUsageService AI 用量记账与额度服务
+type AIUsageService struct {
+	usageDAO       dao.AIUsageDAO
+	classDAO       dao.ClassDAO
+	classMemberDAO dao.ClassMemberDAO
+	studentDAO     dao.StudentDAO
+}
+
+// NewAIUsageService 创建 AI 用量服务
+func NewAIUsageService() *AIUsageService {
+	return &AIUsageService{
+		usageDAO:       dao.NewAIUsageDAO(),
+		classDAO:       dao.NewClassDAO(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+	}
+}
+
+// AIUsageRecord 一次模型调用的用量
+type AIUsageRecord struct {
+	StudentId        string
+	ClassId          string
+	SessionId        string
+	ModelId          string
+	Purpose          string
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+	Estimated        bool
+}
+
+type AIQuotaInput struct {
+	ClassID       string `json:"class_id"`
+	DailyTokens   int64  `json:"daily_tokens"`
+	MonthlyTokens int64  `json:"monthly_tokens"`
+}
+
+type AIQuotaDTO struct {
+	Scope         string `json:"scope"`
+	ClassID       string `json:"class_id,omitempty"`
+	ClassName     string `json:"class_name,omitempty"`
+	DailyTokens   int64  `json:"daily_tokens"`
+	MonthlyTokens int64  `json:"monthly_tokens"`
+	UpdatedBy     string `json:"updated_by"`
+	UpdateTime    string `json:"update_time"`
+}
+
+// AIQuotaStatusDTO 学生某一额度的使用情况（limit 为 0 表示不限，remaining 为 -1 表示不限）
+type AIQuotaStatusDTO struct {
+	DailyUsed        int64 `json:"daily_used"`
+	DailyLimit       int64 `json:"daily_limit"`
+	DailyRemaining   int64 `json:"daily_remaining"`
+	MonthlyUsed      int64 `json:"monthly_used"`
+	MonthlyLimit     int64 `json:"monthly_limit"`
+	MonthlyRemaining int64 `json:"monthly_remaining"`
+}
+
+type AIMyUsageDTO struct {
+	Global  *AIQuotaStatusDTO `json:"global"`
+	ClassID string            `json:"class_id,omitempty"`
+	Class   *AIQuotaStatusDTO `json:"class,omitempty"`
+}
+
+type AIUsageReportInput struct {
+	StartDate string
+	EndDate   string
+	GroupBy   string
+	StudentID string
+	ClassID   string
+	ModelID   string
+	Page      int
+	PageSize  int
+}
+
+type AIUsageReportItem struct {
+	StudentID        string `json:"student_id,omitempty"`
+	StudentName      string `json:"student_name,omitempty"`
+	StudentNumber    string `json:"student_number,omitempty"`
+	ClassID          string `json:"class_id,omitempty"`
+	ClassName        string `json:"class_name,omitempty"`
+	ModelID          string `json:"model_id,omitempty"`
+	UsageDate        string `json:"usage_date,omitempty"`
+	Requests         int64  `json:"requests"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	TotalTokens      int64  `json:"total_tokens"`
+}
+
+type AIUsageReportDTO struct {
+	StartDate string               `json:"start_date"`
+	EndDate   string               `json:"end_date"`
+	GroupBy   string               `json:"group_by"`
+	Summary   *AIUsageReportItem   `json:"summary"`
+	Items     []*AIUsageReportItem `json:"items"`
+	Total     int64                `json:"total"`
+	Page      int                  `json:"page"`
+	PageSize  int                  `json:"page_size"`
+}
+
+// ==================== 额度校验与记账 ====================
+
+// AIQuotaReservation 对话前预占的额度，对话记账后需调用 Release 释放
+type AIQuotaReservation struct {
+	ClassId string // 本次对话计入的班级（未指定且只加入一个班级时取该班级）
+	tokens  int64
+	keys    []string
+}
+
+// Release 释放预占的额度（本轮用量已写入流水后调用）
+func (r *AIQuotaReservation) Release() {
+	if r == nil || len(r.keys) == 0 {
+		return
+	}
+	redisClient := client.GetRedisClient()
+	if redisClient == nil {
+		return
+	}
+	if err := aiUsageReleaseScript.Run(context.Background(), redisClient.Client, r.keys, r.tokens).Err(); err != nil {
+		log.Printf("[AIUsage] 释放预占额度失败, keys: %v, err: %v", r.keys, err)
+	}
+	r.keys = nil
+}
+
+// ReserveQuota 对话前校验学生额度并预占本轮的预估用量：全局额度按学生的全部用量计算，班级额度按学生在该班级内的用量计算。
+// 未指定班级时，只加入一个班级的学生计入该班级；加入多个班级且其中有班级配置了额度时必须指定班级，避免绕过班级额度。
+// 预占通过 Redis INCRBY 原子累加，同一学生并发发起的对话会互相计入，避免同时通过校验后超出额度
+func (s *AIUsageService) ReserveQuota(studentId, classId, message string) (*AIQuotaReservation, error) {
+	members, err := s.classMemberDAO.ListAllClassesByStudentId(studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询班级信息失败: "+err.Error())
+	}
+	switch {
+	case classId != "":
+		joined := false
+		for _, member := range members {
+			if member.ClassId == classId {
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			return nil, errs.NewCommonError(http.StatusBadRequest, "您不是该班级成员")
+		}
+	case len(members) == 1:
+		classId = members[0].ClassId
+	case len(members) > 1:
+		for _, member := range members {
+			quota, err := s.getQuota(conversation.AIQuotaScopeClass, member.ClassId)
+			if err != nil {
+				return nil, errs.NewCommonError(http.StatusInternalServerError, "查询额度配置失败: "+err.Error())
+			}
+			if quota != nil && (quota.DailyTokens > 0 || quota.MonthlyTokens > 0) {
+				return nil, errs.NewCommonError(http.StatusBadRequest, "请先选择所在班级再提问")
+			}
+		}
+	}
+
+	reservation := &AIQuotaReservation{ClassId: classId}
+	now := time.Now()
+	estimate := int64(estimateTokens(message)) + aiUsageReserveReplyTokens
+	globalReserved, classReserved, err := reservation.reserve(studentId, classId, estimate, now)
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "预占 AI 额度失败: "+err.Error())
+	}
+	if err := s.checkScopeQuota(studentId, conversation.AIQuotaScopeGlobal, "", "", globalReserved, now); err != nil {
+		reservation.Release()
+		return nil, err
+	}
+	if classId != "" {
+		if err := s.checkScopeQuota(studentId, conversation.AIQuotaScopeClass, classId, "本班级", classReserved, now); err != nil {
+			reservation.Release()
+			return nil, err
+		}
+	}
+	return reservation, nil
+}
+
+// reservedTokens 同一学生其他进行中对话预占的当日、当月用量
+type reservedTokens struct {
+	daily   int64
+	monthly int64
+}
+
+// reserve 在 Redis 中累加学生当日、当月的预占用量（classId 不为空时同时累加班级内的预占用量），
+// 返回其他进行中对话已预占的全部用量与班级内用量（未配置 Redis 时不预占）
+func (r *AIQuotaReservation) reserve(studentId, classId string, tokens int64, now time.Time) (reservedTokens, reservedTokens, error) {
+	redisClient := client.GetRedisClient()
+	if redisClient == nil {
+		return reservedTokens{}, reservedTokens{}, nil
+	}
+	ctx := context.Background()
+	day, month := now.Format(aiUsageDateLayout), now.Format("2006-01")
+	keys := []string{
+		fmt.Sprintf(aiUsageReserveKey, studentId, day),
+		fmt.Sprintf(aiUsageReserveKey, studentId, month),
+	}
+	if classId != "" {
+		keys = append(keys,
+			fmt.Sprintf(aiUsageClassReserveKey, studentId, classId, day),
+			fmt.Sprintf(aiUsageClassReserveKey, studentId, classId, month))
+	}
+	pipe := redisClient.Client.TxPipeline()
+	counts := make([]*redis.IntCmd, 0, len(keys))
+	for _, key := range keys {
+		counts = append(counts, pipe.IncrBy(ctx, key, tokens))
+		pipe.Expire(ctx, key, aiUsageReserveTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return reservedTokens{}, reservedTokens{}, err
+	}
+	r.tokens, r.keys = tokens, keys
+	global := reservedTokens{daily: counts[0].Val() - tokens, monthly: counts[1].Val() - tokens}
+	var class reservedTokens
+	if classId != "" {
+		class = reservedTokens{daily: counts[2].Val() - tokens, monthly: counts[3].Val() - tokens}
+	}
+	return global, class, nil
+}
+
+// checkScopeQuota 校验某一范围的额度（已用量 + 其他进行中对话的预占量），未配置额度时不限制
+func (s *AIUsageService) checkScopeQuota(studentId, scope, scopeId, scopeLabel string, reserved reservedTokens, now time.Time) error {
+	quota, err := s.getQuota(scope, scopeId)
+	if err != nil {
+		return errs.NewCommonError(http.StatusInternalServerError, "查询额度配置失败: "+err.Error())
+	}
+	if quota == nil || (quota.DailyTokens <= 0 && quota.MonthlyTokens <= 0) {
+		return nil
+	}
+	status, err := s.usageStatus(studentId, scopeId, quota, now)
+	if err != nil {
+		return errs.NewCommonError(http.StatusInternalServerError, "查询 AI 用量失败: "+err.Error())
+	}
+	if status.DailyLimit > 0 && status.DailyUsed+reserved.daily >= status.DailyLimit {
+		return errs.NewCommonError(http.StatusTooManyRequests, scopeLabel+"今日 AI 答疑额度已用完，明天再来试试吧～")
+	}
+	if status.MonthlyLimit > 0 && status.MonthlyUsed+reserved.monthly >= status.MonthlyLimit {
+		return errs.NewCommonError(http.StatusTooManyRequests, scopeLabel+"本月 AI 答疑额度已用完，下个月再来试试吧～")
+	}
+	return nil
+}
+
+// usageStatus 统计学生当日、当月用量（classId 为空表示全部用量），quota 为 nil 表示不限
+func (s *AIUsageService) usageStatus(studentId, classId string, quota *conversation.AIUsageQuota, now time.Time) (*AIQuotaStatusDTO, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	dailyUsed, err := s.usageDAO.SumStudentTokens(studentId, classId, today, today)
+	if err != nil {
+		return nil, err
+	}
+	monthlyUsed, err := s.usageDAO.SumStudentTokens(studentId, classId, monthStart, today)
+	if err != nil {
+		return nil, err
+	}
+	status := &AIQuotaStatusDTO{DailyUsed: dailyUsed, MonthlyUsed: monthlyUsed}
+	if quota != nil {
+		status.DailyLimit = quota.DailyTokens
+		status.MonthlyLimit = quota.MonthlyTokens
+	}
+	status.DailyRemaining = remainingTokens(status.DailyLimit, dailyUsed)
+	status.MonthlyRemaining = remainingTokens(status.MonthlyLimit, monthlyUsed)
+	return status, nil
+}
+
+// remainingTokens 计算剩余额度（不限时返回 -1）
+func remainingTokens(limit, used int64) int64 {
+	if limit <= 0 {
+		return -1
+	}
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// getQuota 查询额度配置，未配置时返回 nil
+func (s *AIUsageService) getQuota(scope, scopeId string) (*conversation.AIUsageQuota, error) {
+	quota, err := s.usageDAO.GetQuota(scope, scopeId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return quota, err
+}
+
+// RecordUsage 写入一次模型调用的用量流水
+func (s *AIUsageService) RecordUsage(record AIUsageRecord) error {
+	if record.TotalTokens == 0 {
+		record.TotalTokens = record.PromptTokens + record.CompletionTokens
+	}
+	if record.TotalTokens <= 0 {
+		return nil
+	}
+	if record.Purpose == "" {
+		record.Purpose = aiUsagePurposeChat
+	}
+	now := time.Now()
+	return s.usageDAO.CreateLedger(&conversation.AIUsageLedger{
+		StudentId:        record.StudentId,
+		ClassId:          record.ClassId,
+		ModelId:          record.ModelId,
+		SessionId:        record.SessionId,
+		Purpose:          record.Purpose,
+		PromptTokens:     record.PromptTokens,
+		CompletionTokens: record.CompletionTokens,
+		TotalTokens:      record.TotalTokens,
+		Estimated:        record.Estimated,
+		UsageDate:        time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+	})
+}
+
+// GetMyUsage 查询学生本人的用量与剩余额度（classId 不为空时同时返回班级额度）
+func (s *AIUsageService) GetMyUsage(studentId, classId string) (*AIMyUsageDTO, error) {
+	now := time.Now()
+	result := &AIMyUsageDTO{}
+	quota, err := s.getQuota(conversation.AIQuotaScopeGlobal, "")
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询额度配置失败: "+err.Error())
+	}
+	if result.Global, err = s.usageStatus(studentId, "", quota, now); err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询 AI 用量失败: "+err.Error())
+	}
+	if classId == "" {
+		return result, nil
+	}
+
+	member, err := s.classMemberDAO.GetMember(classId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(http.StatusBadRequest, "您不是该班级成员")
+	}
+	quota, err = s.getQuota(conversation.AIQuotaScopeClass, classId)
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询额度配置失败: "+err.Error())
+	}
+	result.ClassID = classId
+	if result.Class, err = s.usageStatus(studentId, classId, quota, now); err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询 AI 用量失败: "+err.Error())
+	}
+	return result, nil
+}
+
+// ==================== 额度配置（管理员） ====================
+
+// GetGlobalQuota 查询全局默认额度（未配置时各项为 0，即不限）
+func (s *AIUsageService) GetGlobalQuota() (*AIQuotaDTO, error) {
+	quota, err := s.getQuota(conversation.AIQuotaScopeGlobal, "")
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询额度配置失败: "+err.Error())
+	}
+	if quota == nil {
+		return &AIQuotaDTO{Scope: conversation.AIQuotaScopeGlobal}, nil
+	}
+	return mapAIQuota(quota, ""), nil
+}
+
+// SetGlobalQuota 设置全局默认额度
+func (s *AIUsageService) SetGlobalQuota(adminId string, input AIQuotaInput) (*AIQuotaDTO, error) {
+	if err := validateAIQuotaInput(input); err != nil {
+		return nil, err
+	}
+	quota := &conversation.AIUsageQuota{
+		Scope:         conversation.AIQuotaScopeGlobal,
+		DailyTokens:   input.DailyTokens,
+		MonthlyTokens: input.MonthlyTokens,
+		UpdatedBy:     adminId,
+	}
+	if err := s.usageDAO.SaveQuota(quota); err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "保存额度配置失败: "+err.Error())
+	}
+	return s.GetGlobalQuota()
+}
+
+// ListClassQuotas 分页查询班级额度配置
+func (s *AIUsageService) ListClassQuotas(page, pageSize int) ([]*AIQuotaDTO, int64, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	quotas, err := s.usageDAO.ListQuotasByScope(conversation.AIQuotaScopeClass, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(http.StatusInternalServerError, "查询额度配置失败: "+err.Error())
+	}
+	total, err := s.usageDAO.CountQuotasByScope(conversation.AIQuotaScopeClass)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(http.StatusInternalServerError, "查询额度配置失败: "+err.Error())
+	}
+	classIds := make([]string, 0, len(quotas))
+	for _, quota := range quotas {
+		classIds = append(classIds, quota.ScopeId)
+	}
+	classNames := s.classNames(classIds)
+	items := make([]*AIQuotaDTO, 0, len(quotas))
+	for _, quota := range quotas {
+		items = append(items, mapAIQuota(quota, classNames[quota.ScopeId]))
+	}
+	return items, total, nil
+}
+
+// SetClassQuota 设置班级额度（对每个学生在该班级内的用量生效，与全局额度同时生效）
+func (s *AIUsageService) SetClassQuota(adminId string, input AIQuotaInput) (*AIQuotaDTO, error) {
+	input.ClassID = strings.TrimSpace(input.ClassID)
+	if input.ClassID == "" {
+		return nil, errs.NewCommonError(http.StatusBadRequest, "班级ID不能为空")
+	}
+	if err := validateAIQuotaInput(input); err != nil {
+		return nil, err
+	}
+	class, err := s.classDAO.GetClassById(input.ClassID)
+	if err != nil || class == nil {
+		return nil, errs.NewCommonError(http.StatusNotFound, "班级不存在")
+	}
+	quota := &conversation.AIUsageQuota{
+		Scope:         conversation.AIQuotaScopeClass,
+		ScopeId:       input.ClassID,
+		DailyTokens:   input.DailyTokens,
+		MonthlyTokens: input.MonthlyTokens,
+		UpdatedBy:     adminId,
+	}
+	if err := s.usageDAO.SaveQuota(quota); err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "保存额度配置失败: "+err.Error())
+	}
+	saved, err := s.usageDAO.GetQuota(conversation.AIQuotaScopeClass, input.ClassID)
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询额度配置失败: "+err.Error())
+	}
+	return mapAIQuota(saved, class.ClassName), nil
+}
+
+// DeleteClassQuota 删除班级额度（删除后该班级只受全局额度限制）
+func (s *AIUsageService) DeleteClassQuota(classId string) error {
+	if strings.TrimSpace(classId) == "" {
+		return errs.NewCommonError(http.StatusBadRequest, "班级ID不能为空")
+	}
+	if err := s.usageDAO.DeleteQuota(conversation.AIQuotaScopeClass, classId); err != nil {
+		return errs.NewCommonError(http.StatusInternalServerError, "删除额度配置失败: "+err.Error())
+	}
+	return nil
+}
+
+func validateAIQuotaInput(input AIQuotaInput) error {
+	if input.DailyTokens < 0 || input.MonthlyTokens < 0 {
+		return errs.NewCommonError(http.StatusBadRequest, "额度不能为负数")
+	}
+	if input.DailyTokens > 0 && input.MonthlyTokens > 0 && input.DailyTokens > input.MonthlyTokens {
+		return errs.NewCommonError(http.StatusBadRequest, "每日额度不能大于每月额度")
+	}
+	return nil
+}
+
+func mapAIQuota(quota *conversation.AIUsageQuota, className string) *AIQuotaDTO {
+	return &AIQuotaDTO{
+		Scope:         quota.Scope,
+		ClassID:       quota.ScopeId,
+		ClassName:     className,
+		DailyTokens:   quota.DailyTokens,
+		MonthlyTokens: quota.MonthlyTokens,
+		UpdatedBy:     quota.UpdatedBy,
+		UpdateTime:    formatTime(quota.UpdateTime),
+	}
+}
+
+// ==================== 用量报表（管理员） ====================
+
+// GetUsageReport 按学生、班级、模型或日期分组统计用量（默认统计本月）
+func (s *AIUsageService) GetUsageReport(input AIUsageReportInput) (*AIUsageReportDTO, error) {
+	filter, groupBy, err := buildAIUsageFilter(&input)
+	if err != nil {
+		return nil, err
+	}
+	input.Page, input.PageSize = normalizePage(input.Page, input.PageSize)
+	stats, err := s.usageDAO.ListUsageStats(filter, groupBy, input.PageSize, (input.Page-1)*input.PageSize)
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询用量报表失败: "+err.Error())
+	}
+	total, err := s.usageDAO.CountUsageGroups(filter, groupBy)
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询用量报表失败: "+err.Error())
+	}
+	sum, err := s.usageDAO.SumUsage(filter)
+	if err != nil {
+		return nil, errs.NewCommonError(http.StatusInternalServerError, "查询用量报表失败: "+err.Error())
+	}
+	return &AIUsageReportDTO{
+		StartDate: filter.StartDate.Format(aiUsageDateLayout),
+		EndDate:   filter.EndDate.Format(aiUsageDateLayout),
+		GroupBy:   input.GroupBy,
+		Summary: &AIUsageReportItem{
+			Requests:         sum.Requests,
+			PromptTokens:     sum.PromptTokens,
+			CompletionTokens: sum.CompletionTokens,
+			TotalTokens:      sum.TotalTokens,
+		},
+		Items:    s.mapUsageStats(stats),
+		Total:    total,
+		Page:     input.Page,
+		PageSize: input.PageSize,
+	}, nil
+}
+
+// ExportUsageReport 导出用量报表（CSV）
+func (s *AIUsageService) ExportUsageReport(input AIUsageReportInput) ([]byte, string, error) {
+	filter, groupBy, err := buildAIUsageFilter(&input)
+	if err != nil {
+		return nil, "", err
+	}
+	stats, err := s.usageDAO.ListUsageStats(filter, groupBy, aiUsageExportLimit, 0)
+	if err != nil {
+		return nil, "", errs.NewCommonError(http.StatusInternalServerError, "查询用量报表失败: "+err.Error())
+	}
+
+	header := make([]string, 0, 10)
+	for _, column := range groupBy {
+		switch column {
+		case "student_id":
+			header = append(header, "学生姓名", "学号")
+		case "class_id":
+			header = append(header, "班级")
+		case "model_id":
+			header = append(header, "模型")
+		case "usage_date":
+			header = append(header, "日期")
+		}
+	}
+	header = append(header, "调用次数", "输入token", "输出token", "总token")
+
+	rows := make([][]string, 0, len(stats))
+	for _, item := range s.mapUsageStats(stats) {
+		row := make([]string, 0, len(header))
+		for _, column := range groupBy {
+			switch column {
+			case "student_id":
+				name := item.StudentName
+				if name == "" {
+					name = item.StudentID
+				}
+				row = append(row, name, item.StudentNumber)
+			case "class_id":
+				name := item.ClassName
+				if item.ClassID == "" {
+					name = "未关联班级"
+				} else if name == "" {
+					name = item.ClassID
+				}
+				row = append(row, name)
+			case "model_id":
+				row = append(row, item.ModelID)
+			case "usage_date":
+				row = append(row, item.UsageDate)
+			}
+		}
+		row = append(row,
+			strconv.FormatInt(item.Requests, 10),
+			strconv.FormatInt(item.PromptTokens, 10),
+			strconv.FormatInt(item.CompletionTokens, 10),
+			strconv.FormatInt(item.TotalTokens, 10),
+		)
+		rows = append(rows, row)
+	}
+	content, err := buildCSV(header, rows)
+	if err != nil {
+		return nil, "", errs.NewCommonError(http.StatusInternalServerError, "导出用量报表失败: "+err.Error())
+	}
+	fileName := fmt.Sprintf("AI用量报表_%s_%s.csv", filter.StartDate.Format("20060102"), filter.EndDate.Format("20060102"))
+	return content, fileName, nil
+}
+
+// buildAIUsageFilter 解析报表筛选条件与分组维度
+func buildAIUsageFilter(input *AIUsageReportInput) (dao.AIUsageFilter, []string, error) {
+	now := time.Now()
+	filter := dao.AIUsageFilter{
+		StartDate: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()),
+		EndDate:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		StudentId: strings.TrimSpace(input.StudentID),
+		ClassId:   strings.TrimSpace(input.ClassID),
+		ModelId:   strings.TrimSpace(input.ModelID),
+	}
+	if input.StartDate != "" {
+		date, err := time.ParseInLocation(aiUsageDateLayout, input.StartDate, now.Location())
+		if err != nil {
+			return filter, nil, errs.NewCommonError(http.StatusBadRequest, "开始日期格式有误，应为 YYYY-MM-DD")
+		}
+		filter.StartDate = date
+	}
+	if input.EndDate != "" {
+		date, err := time.ParseInLocation(aiUsageDateLayout, input.EndDate, now.Location())
+		if err != nil {
+			return filter, nil, errs.NewCommonError(http.StatusBadRequest, "结束日期格式有误，应为 YYYY-MM-DD")
+		}
+		filter.EndDate = date
+	}
+	if filter.EndDate.Before(filter.StartDate) {
+		return filter, nil, errs.NewCommonError(http.StatusBadRequest, "结束日期不能早于开始日期")
+	}
+	if filter.EndDate.Sub(filter.StartDate) > aiUsageMaxReportDays*24*time.Hour {
+		return filter, nil, errs.NewCommonError(http.StatusBadRequest, fmt.Sprintf("统计范围不能超过 %d 天", aiUsageMaxReportDays))
+	}
+
+	if input.GroupBy == "" {
+		input.GroupBy = "student"
+	}
+	groupBy, ok := aiUsageGroupColumns[input.GroupBy]
+	if !ok {
+		return filter, nil, errs.NewCommonError(http.StatusBadRequest, "不支持的分组维度")
+	}
+	return filter, groupBy, nil
+}
+
+// mapUsageStats 转换聚合结果并补充学生、班级名称
+func (s *AIUsageService) mapUsageStats(stats []*dao.AIUsageStat) []*AIUsageReportItem {
+	studentIds := make([]string, 0, len(stats))
+	classIds := make([]string, 0, len(stats))
+	for _, stat := range stats {
+		if stat.StudentId != "" {
+			studentIds = append(studentIds, stat.StudentId)
+		}
+		if stat.ClassId != "" {
+			classIds = append(classIds, stat.ClassId)
+		}
+	}
+	type studentInfo struct{ name, number string }
+	students := make(map[string]studentInfo)
+	if len(studentIds) > 0 {
+		if list, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{uniqueStrings(studentIds)}); err == nil {
+			for _, stu := range list {
+				students[stu.StudentId] = studentInfo{name: stu.StudentName, number: stu.StudentNumber}
+			}
+		}
+	}
+	classNames := s.classNames(classIds)
+
+	items := make([]*AIUsageReportItem, 0, len(stats))
+	for _, stat := range stats {
+		item := &AIUsageReportItem{
+			StudentID:        stat.StudentId,
+			StudentName:      students[stat.StudentId].name,
+			StudentNumber:    students[stat.StudentId].number,
+			ClassID:          stat.ClassId,
+			ClassName:        classNames[stat.ClassId],
+			ModelID:          stat.ModelId,
+			Requests:         stat.Requests,
+			PromptTokens:     stat.PromptTokens,
+			CompletionTokens: stat.CompletionTokens,
+			TotalTokens:      stat.TotalTokens,
+		}
+		if !stat.UsageDate.IsZero() {
+			item.UsageDate = stat.UsageDate.Format(aiUsageDateLayout)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// classNames 批量查询班级名称
+func (s *AIUsageService) classNames(classIds []string) map[string]string {
+	names := make(map[string]string)
+	if len(classIds) == 0 {
+		return names
+	}
+	ids := uniqueStrings(classIds)
+	classes, err := s.classDAO.ListClasses("class_id IN ?", []interface{}{ids}, int32(len(ids)), 0)
+	if err != nil {
+		return names
+	}
+	for _, c := range classes {
+		names[c.ClassId] = c.ClassName
+	}
+	return names
+}
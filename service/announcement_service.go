@@ -0,0 +1,320 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+)
+
+// AnnouncementService 班级公告服务
+type AnnouncementService struct {
+	announcementDAO dao.AnnouncementDAO
+	classDAO        dao.ClassDAO
+	staffService    *ClassStaffService
+	classMemberDAO  dao.ClassMemberDAO
+	studentDAO      dao.StudentDAO
+}
+
+// NewAnnouncementService 创建班级公告服务
+func NewAnnouncementService() *AnnouncementService {
+	return &AnnouncementService{
+		announcementDAO: dao.NewAnnouncementDAO(),
+		classDAO:        dao.NewClassDAO(),
+		staffService:    NewClassStaffService(),
+		classMemberDAO:  dao.NewClassMemberDAO(),
+		studentDAO:      dao.NewStudentDAO(),
+	}
+}
+
+// AnnouncementView 公告视图（教师视图带已读统计，学生视图带已读标记）
+type AnnouncementView struct {
+	*classModel.ClassAnnouncement
+	IsExpired   bool  `json:"is_expired"`
+	IsRead      bool  `json:"is_read"`      // 学生视图：当前学生是否已读
+	ReadCount   int32 `json:"read_count"`   // 教师视图：已读人数
+	MemberCount int32 `json:"member_count"` // 教师视图：班级人数
+}
+
+// AnnouncementReader 公告阅读情况
+type AnnouncementReader struct {
+	StudentId     string     `json:"student_id"`
+	StudentName   string     `json:"student_name"`
+	StudentNumber string     `json:"student_number"`
+	IsRead        bool       `json:"is_read"`
+	ReadTime      *time.Time `json:"read_time"`
+}
+
+// ClassUnreadCount 班级未读公告数
+type ClassUnreadCount struct {
+	ClassId   string `json:"class_id"`
+	ClassName string `json:"class_name"`
+	Unread    int32  `json:"unread"`
+}
+
+// ==================== 教师操作 ====================
+
+// PublishAnnouncement 发布公告（教师操作）
+func (s *AnnouncementService) PublishAnnouncement(teacherId, classId, title, content string, isPinned bool, expireTime string) (*classModel.ClassAnnouncement, error) {
+	if teacherId == "" || classId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "teacher_id 和 class_id 不能为空")
+	}
+	if title == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "公告标题不能为空")
+	}
+	if content == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "公告内容不能为空")
+	}
+	if _, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermAnnouncement); err != nil {
+		return nil, err
+	}
+	expireAt, err := parseOptionalTime(expireTime)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "过期时间格式错误")
+	}
+	now := time.Now()
+	if expireAt != nil && !expireAt.After(now) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "过期时间必须晚于当前时间")
+	}
+
+	a := &classModel.ClassAnnouncement{
+		AnnouncementId: fmt.Sprintf("ann_%d", now.UnixNano()),
+		ClassId:        classId,
+		TeacherId:      teacherId,
+		Title:          title,
+		Content:        content,
+		IsPinned:       isPinned,
+		ExpireTime:     expireAt,
+		PublishTime:    now,
+	}
+	if err := s.announcementDAO.CreateAnnouncement(a); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "保存公告失败: "+err.Error())
+	}
+	return a, nil
+}
+
+// SetAnnouncementPinned 置顶/取消置顶公告（教师操作）
+func (s *AnnouncementService) SetAnnouncementPinned(teacherId, announcementId string, pinned bool) error {
+	a, err := s.getOwnedAnnouncement(teacherId, announcementId)
+	if err != nil {
+		return err
+	}
+	if err := s.staffService.CheckClassWritable(a.ClassId); err != nil {
+		return err
+	}
+	if err := s.announcementDAO.UpdateAnnouncement(a.AnnouncementId, map[string]interface{}{"is_pinned": pinned}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新公告失败: "+err.Error())
+	}
+	return nil
+}
+
+// DeleteAnnouncement 删除公告（教师操作）
+func (s *AnnouncementService) DeleteAnnouncement(teacherId, classId, announcementId string) error {
+	if teacherId == "" || classId == "" || announcementId == "" {
+		return errs.NewCommonError(errs.ErrBadRequest, "参数不能为空")
+	}
+	a, err := s.getOwnedAnnouncement(teacherId, announcementId)
+	if err != nil {
+		return err
+	}
+	if a.ClassId != classId {
+		return errs.NewCommonError(errs.ErrBadRequest, "公告不属于该班级")
+	}
+	if err := s.staffService.CheckClassWritable(a.ClassId); err != nil {
+		return err
+	}
+	if err := s.announcementDAO.DeleteAnnouncement(announcementId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "删除公告失败: "+err.Error())
+	}
+	return nil
+}
+
+// ListTeacherAnnouncements 查询班级公告（教师视图，含已过期公告与已读统计）
+func (s *AnnouncementService) ListTeacherAnnouncements(teacherId, classId string) ([]*AnnouncementView, error) {
+	if err := s.checkAnnouncementPermission(teacherId, classId); err != nil {
+		return nil, err
+	}
+	list, err := s.announcementDAO.ListAnnouncementsByClassId(classId, nil)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询公告失败: "+err.Error())
+	}
+	memberCount, err := s.classMemberDAO.CountMembersByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "统计班级人数失败: "+err.Error())
+	}
+	ids := make([]string, 0, len(list))
+	for _, a := range list {
+		ids = append(ids, a.AnnouncementId)
+	}
+	readCounts, err := s.announcementDAO.CountReadsByAnnouncementIds(ids)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "统计已读人数失败: "+err.Error())
+	}
+
+	now := time.Now()
+	views := make([]*AnnouncementView, 0, len(list))
+	for _, a := range list {
+		views = append(views, &AnnouncementView{
+			ClassAnnouncement: a,
+			IsExpired:         isAnnouncementExpired(a, now),
+			ReadCount:         readCounts[a.AnnouncementId],
+			MemberCount:       memberCount,
+		})
+	}
+	return views, nil
+}
+
+// ListAnnouncementReaders 查询公告的阅读情况（教师操作，列出班级全部成员的已读/未读）
+func (s *AnnouncementService) ListAnnouncementReaders(teacherId, announcementId string) ([]*AnnouncementReader, error) {
+	a, err := s.getOwnedAnnouncement(teacherId, announcementId)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.classMemberDAO.ListAllMembersByClassId(a.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	reads, err := s.announcementDAO.ListReadsByAnnouncementId(announcementId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询已读回执失败: "+err.Error())
+	}
+	readTimes := make(map[string]time.Time, len(reads))
+	for _, r := range reads {
+		readTimes[r.StudentId] = r.ReadTime
+	}
+	studentIds := make([]string, 0, len(members))
+	for _, m := range members {
+		studentIds = append(studentIds, m.StudentId)
+	}
+	studentInfo := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		if students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds}); err == nil {
+			for _, st := range students {
+				studentInfo[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+			}
+		}
+	}
+
+	readers := make([]*AnnouncementReader, 0, len(members))
+	for _, m := range members {
+		item := &AnnouncementReader{
+			StudentId:     m.StudentId,
+			StudentName:   studentInfo[m.StudentId][0],
+			StudentNumber: studentInfo[m.StudentId][1],
+		}
+		if t, ok := readTimes[m.StudentId]; ok {
+			readTime := t
+			item.IsRead = true
+			item.ReadTime = &readTime
+		}
+		readers = append(readers, item)
+	}
+	return readers, nil
+}
+
+// ==================== 学生操作 ====================
+
+// ListStudentAnnouncements 查询班级公告（学生视图，不含已过期公告，带已读标记）
+func (s *AnnouncementService) ListStudentAnnouncements(studentId, classId string) ([]*AnnouncementView, error) {
+	if err := s.checkClassMember(studentId, classId); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	list, err := s.announcementDAO.ListAnnouncementsByClassId(classId, &now)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询公告失败: "+err.Error())
+	}
+	ids := make([]string, 0, len(list))
+	for _, a := range list {
+		ids = append(ids, a.AnnouncementId)
+	}
+	readSet, err := s.announcementDAO.ListReadAnnouncementIds(studentId, ids)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询已读状态失败: "+err.Error())
+	}
+	views := make([]*AnnouncementView, 0, len(list))
+	for _, a := range list {
+		views = append(views, &AnnouncementView{ClassAnnouncement: a, IsRead: readSet[a.AnnouncementId]})
+	}
+	return views, nil
+}
+
+// MarkAnnouncementRead 标记公告已读（学生操作，重复标记忽略）
+func (s *AnnouncementService) MarkAnnouncementRead(studentId, announcementId string) error {
+	if announcementId == "" {
+		return errs.NewCommonError(errs.ErrBadRequest, "公告ID不能为空")
+	}
+	a, err := s.announcementDAO.GetAnnouncementById(announcementId)
+	if err != nil || a == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "公告不存在")
+	}
+	if err := s.checkClassMember(studentId, a.ClassId); err != nil {
+		return err
+	}
+	err = s.announcementDAO.MarkRead(&classModel.ClassAnnouncementRead{
+		AnnouncementId: announcementId,
+		ClassId:        a.ClassId,
+		StudentId:      studentId,
+		ReadTime:       time.Now(),
+	})
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "记录已读失败: "+err.Error())
+	}
+	return nil
+}
+
+// GetUnreadCounts 查询学生各班级的未读公告数（学生操作）
+func (s *AnnouncementService) GetUnreadCounts(studentId string) ([]*ClassUnreadCount, int32, error) {
+	counts, err := s.announcementDAO.CountUnreadByStudentId(studentId, time.Now())
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "统计未读公告失败: "+err.Error())
+	}
+	var total int32
+	list := make([]*ClassUnreadCount, 0, len(counts))
+	for classId, n := range counts {
+		item := &ClassUnreadCount{ClassId: classId, Unread: n}
+		if c, err := s.classDAO.GetClassById(classId); err == nil && c != nil {
+			item.ClassName = c.ClassName
+		}
+		list = append(list, item)
+		total += n
+	}
+	return list, total, nil
+}
+
+// ==================== 内部工具 ====================
+
+// checkAnnouncementPermission 校验教师拥有班级公告权限
+func (s *AnnouncementService) checkAnnouncementPermission(teacherId, classId string) error {
+	_, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermAnnouncement)
+	return err
+}
+
+// checkClassMember 校验学生为班级成员
+func (s *AnnouncementService) checkClassMember(studentId, classId string) error {
+	member, err := s.classMemberDAO.GetMember(classId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	return nil
+}
+
+// getOwnedAnnouncement 查询公告并校验教师为班级创建者
+func (s *AnnouncementService) getOwnedAnnouncement(teacherId, announcementId string) (*classModel.ClassAnnouncement, error) {
+	a, err := s.announcementDAO.GetAnnouncementById(announcementId)
+	if err != nil || a == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "公告不存在")
+	}
+	if err := s.checkAnnouncementPermission(teacherId, a.ClassId); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// isAnnouncementExpired 公告是否已过期
+func isAnnouncementExpired(a *classModel.ClassAnnouncement, now time.Time) bool {
+	return a.ExpireTime != nil && !a.ExpireTime.After(now)
+}
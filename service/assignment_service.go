@@ -0,0 +1,306 @@
+package service
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	codeModel "github.com/yzf120/elysia-backend/model/code"
+)
+
+// AssignmentService 作业服务（小节的开放/截止/迟交/提交次数设置与成绩记录）
+type AssignmentService struct {
+	chapterDAO       dao.ChapterDAO
+	classDAO         dao.ClassDAO
+	staffService     *ClassStaffService
+	classMemberDAO   dao.ClassMemberDAO
+	codeRunDAO       dao.CodeRunDAO
+	sectionResultDAO dao.SectionResultDAO
+	accessService    *SectionAccessService
+	progressService  *ProgressService
+	groupService     *ClassGroupService
+}
+
+// NewAssignmentService 创建作业服务
+func NewAssignmentService() *AssignmentService {
+	return &AssignmentService{
+		chapterDAO:       dao.NewChapterDAO(),
+		classDAO:         dao.NewClassDAO(),
+		staffService:     NewClassStaffService(),
+		classMemberDAO:   dao.NewClassMemberDAO(),
+		codeRunDAO:       dao.NewCodeRunDAO(),
+		sectionResultDAO: dao.NewSectionResultDAO(),
+		accessService:    NewSectionAccessService(),
+		progressService:  NewProgressService(),
+		groupService:     NewClassGroupService(),
+	}
+}
+
+// SetAssignment 设置小节的作业参数（教师操作）
+// openTime/dueTime 格式为 "2006-01-02 15:04:05"，传空字符串表示不限制
+func (s *AssignmentService) SetAssignment(teacherId, sectionId, openTime, dueTime string, latePolicy, latePenalty, maxAttempts int32) error {
+	section, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil || section == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
+	}
+	if section.SectionType != classModel.SectionTypeProblem {
+		return errs.NewCommonError(errs.ErrBadRequest, "只有算法题小节可以设置作业")
+	}
+
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
+	}
+
+	if latePolicy < classModel.LatePolicyAllow || latePolicy > classModel.LatePolicyHardClose {
+		return errs.NewCommonError(errs.ErrBadRequest, "迟交策略不合法（0-允许迟交，1-按天扣分，2-截止后禁止提交）")
+	}
+	if latePenalty < 0 || latePenalty > 100 {
+		return errs.NewCommonError(errs.ErrBadRequest, "每日扣分比例需在 0-100 之间")
+	}
+	if maxAttempts < 0 {
+		return errs.NewCommonError(errs.ErrBadRequest, "最大提交次数不能为负数")
+	}
+
+	openAt, err := parseOptionalTime(openTime)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "开放时间格式错误: "+err.Error())
+	}
+	dueAt, err := parseOptionalTime(dueTime)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "截止时间格式错误: "+err.Error())
+	}
+	if openAt != nil && dueAt != nil && !dueAt.After(*openAt) {
+		return errs.NewCommonError(errs.ErrBadRequest, "截止时间必须晚于开放时间")
+	}
+
+	updates := map[string]interface{}{
+		"open_time":    openAt,
+		"due_time":     dueAt,
+		"late_policy":  latePolicy,
+		"late_penalty": latePenalty,
+		"max_attempts": maxAttempts,
+	}
+	if err := s.chapterDAO.UpdateSection(sectionId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新作业设置失败: "+err.Error())
+	}
+	return nil
+}
+
+// CheckSubmission 校验班级作业内的提交是否允许，返回对应小节
+// 只有 submit 类型受开放时间、截止时间和提交次数限制
+func (s *AssignmentService) CheckSubmission(studentId, sectionId string, problemId int64, runType string) (*classModel.ClassSection, error) {
+	section, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil || section == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
+	}
+	if section.SectionType != classModel.SectionTypeProblem || section.ProblemId != strconv.FormatInt(problemId, 10) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "题目与小节不匹配")
+	}
+
+	member, err := s.classMemberDAO.GetMember(section.ClassId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	if err := s.staffService.CheckClassWritable(section.ClassId); err != nil {
+		return nil, err
+	}
+	if err := s.accessService.CheckSectionAccess(section, studentId); err != nil {
+		return nil, err
+	}
+
+	if section.GroupMode && s.groupService.GetStudentGroup(section.ClassId, studentId) == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该小节为小组作业，请先加入小组")
+	}
+
+	if runType != "submit" {
+		return section, nil
+	}
+
+	now := time.Now()
+	if section.OpenTime != nil && now.Before(*section.OpenTime) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "作业尚未开放，开放时间: "+section.OpenTime.Format("2006-01-02 15:04:05"))
+	}
+	if section.DueTime != nil && now.After(*section.DueTime) && section.LatePolicy == classModel.LatePolicyHardClose {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "作业已截止，无法提交")
+	}
+	if section.MaxAttempts > 0 {
+		count, err := s.countSubmits(section, studentId)
+		if err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "查询提交次数失败: "+err.Error())
+		}
+		if count >= int64(section.MaxAttempts) {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "已达到最大提交次数")
+		}
+	}
+	return section, nil
+}
+
+// OnCodeRunJudged 评测完成后更新学生在作业小节上的成绩（只处理班级内的 submit 记录）
+// 小组模式的小节，成绩同时计入提交者所在小组的全部成员
+func (s *AssignmentService) OnCodeRunJudged(runId int64) {
+	run, err := s.codeRunDAO.GetCodeRunById(runId)
+	if err != nil || run == nil || run.RunType != "submit" || run.SectionId == "" {
+		return
+	}
+	section, err := s.chapterDAO.GetSectionById(run.SectionId)
+	if err != nil || section == nil {
+		return
+	}
+	studentIds := []string{run.StudentId}
+	if section.GroupMode {
+		studentIds = s.groupService.ListGroupmateIds(section.ClassId, run.StudentId)
+	}
+	for _, studentId := range studentIds {
+		s.applyRunResult(section, run, studentId)
+		// 成绩写入后重新计算学习进度
+		s.progressService.RefreshStudentProgress(section.ClassId, studentId)
+	}
+}
+
+// applyRunResult 将一次评测结果写入学生在作业小节上的成绩
+func (s *AssignmentService) applyRunResult(section *classModel.ClassSection, run *codeModel.CodeRun, studentId string) {
+	rawScore := calcRunScore(run)
+	score, isLate, lateDays := calcLateScore(section, run.CreatedAt, rawScore)
+	submitTime := run.CreatedAt
+
+	result := &classModel.ClassSectionResult{
+		SectionId:      run.SectionId,
+		StudentId:      studentId,
+		ClassId:        section.ClassId,
+		ChapterId:      section.ChapterId,
+		ProblemId:      run.ProblemId,
+		Attempts:       1,
+		BestRunId:      run.Id,
+		BestStatus:     run.Status,
+		BestScore:      score,
+		BestSubmitTime: &submitTime,
+		IsLate:         isLate,
+		LateDays:       lateDays,
+	}
+	if !isLate {
+		result.OnTimeRunId = run.Id
+		result.OnTimeStatus = run.Status
+		result.OnTimeScore = rawScore
+	}
+	err := s.sectionResultDAO.ApplyResult(result, func(existing *classModel.ClassSectionResult) map[string]interface{} {
+		updates := map[string]interface{}{
+			"attempts": existing.Attempts + 1,
+		}
+		// 得分更高才替换最佳提交（同分保留更早的提交）
+		if score > existing.BestScore || existing.BestRunId == 0 {
+			updates["best_run_id"] = run.Id
+			updates["best_status"] = run.Status
+			updates["best_score"] = score
+			updates["best_submit_time"] = submitTime
+			updates["is_late"] = isLate
+			updates["late_days"] = lateDays
+		}
+		if !isLate && (rawScore > existing.OnTimeScore || existing.OnTimeRunId == 0) {
+			updates["on_time_run_id"] = run.Id
+			updates["on_time_status"] = run.Status
+			updates["on_time_score"] = rawScore
+		}
+		return updates
+	})
+	if err != nil {
+		log.Printf("[AssignmentService] 写入作业成绩失败: run_id=%d, student_id=%s, err=%v", run.Id, studentId, err)
+	}
+}
+
+// CreateSubmitRun 创建班级作业内的运行记录，设置了最大提交次数的 submit 在行锁内统计并插入
+func (s *AssignmentService) CreateSubmitRun(section *classModel.ClassSection, record *codeModel.CodeRun) error {
+	if record.RunType != "submit" || section.MaxAttempts <= 0 {
+		if err := s.codeRunDAO.CreateCodeRun(record); err != nil {
+			return errs.NewCommonError(errs.ErrInternal, "创建运行记录失败: "+err.Error())
+		}
+		return nil
+	}
+	studentIds := []string{record.StudentId}
+	if section.GroupMode {
+		studentIds = s.groupService.ListGroupmateIds(section.ClassId, record.StudentId)
+	}
+	created, err := s.codeRunDAO.CreateLimitedSubmit(record, studentIds, section.MaxAttempts)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "创建运行记录失败: "+err.Error())
+	}
+	if !created {
+		return errs.NewCommonError(errs.ErrBadRequest, "已达到最大提交次数")
+	}
+	return nil
+}
+
+// GetStudentAssignment 查询学生在某作业小节的设置、成绩与已用提交次数
+func (s *AssignmentService) GetStudentAssignment(studentId, sectionId string) (*classModel.ClassSection, *classModel.ClassSectionResult, int64, error) {
+	section, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil || section == nil {
+		return nil, nil, 0, errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
+	}
+	member, err := s.classMemberDAO.GetMember(section.ClassId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, nil, 0, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	if err := s.accessService.CheckSectionAccess(section, studentId); err != nil {
+		return nil, nil, 0, err
+	}
+	result, _ := s.sectionResultDAO.GetResult(sectionId, studentId)
+	used, err := s.countSubmits(section, studentId)
+	if err != nil {
+		return nil, nil, 0, errs.NewCommonError(errs.ErrInternal, "查询提交次数失败: "+err.Error())
+	}
+	return section, result, used, nil
+}
+
+// countSubmits 统计学生在小节上已用的提交次数（小组模式下统计全组成员的提交）
+func (s *AssignmentService) countSubmits(section *classModel.ClassSection, studentId string) (int64, error) {
+	studentIds := []string{studentId}
+	if section.GroupMode {
+		studentIds = s.groupService.ListGroupmateIds(section.ClassId, studentId)
+	}
+	var total int64
+	for _, id := range studentIds {
+		count, err := s.codeRunDAO.CountSubmitsBySection(id, section.SectionId)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// calcLateScore 根据迟交策略计算扣分后的得分，返回 (得分, 是否迟交, 迟交天数)
+// 迟交不足一天按一天计算
+func calcLateScore(section *classModel.ClassSection, submitTime time.Time, rawScore int32) (int32, bool, int32) {
+	if section.DueTime == nil || !submitTime.After(*section.DueTime) {
+		return rawScore, false, 0
+	}
+	late := submitTime.Sub(*section.DueTime)
+	lateDays := int32(late / (24 * time.Hour))
+	if late%(24*time.Hour) > 0 {
+		lateDays++
+	}
+	if section.LatePolicy != classModel.LatePolicyPenalty {
+		return rawScore, true, lateDays
+	}
+	percent := 100 - section.LatePenalty*lateDays
+	if percent < 0 {
+		percent = 0
+	}
+	return rawScore * percent / 100, true, lateDays
+}
+
+// parseOptionalTime 解析可选时间字符串（空字符串返回 nil）
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
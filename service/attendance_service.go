@@ -0,0 +1,760 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/utils"
+)
+
+const (
+	defaultAttendanceDuration = 10 // 默认签到时长（分钟）
+	maxAttendanceDuration     = 240
+	defaultAttendanceLate     = 5  // 默认开始多少分钟后签到记为迟到
+	defaultCodeInterval       = 30 // 默认签到码轮换间隔（秒）
+	minCodeInterval           = 10
+	maxCodeInterval           = 300
+
+	// attendanceCodeGrace 签到码轮换后旧码的额外有效时间（避免学生刚输入完码就失效）
+	attendanceCodeGrace = 10 * time.Second
+	// attendanceMaxFailures 每分钟内允许输错签到码的次数
+	attendanceMaxFailures = 10
+)
+
+// AttendanceService 课堂签到服务（教师发起签到，签到码存放在 Redis 中按间隔轮换，学生输入签到码完成签到）
+type AttendanceService struct {
+	attendanceDAO  dao.AttendanceDAO
+	classMemberDAO dao.ClassMemberDAO
+	studentDAO     dao.StudentDAO
+	staffService   *ClassStaffService
+	redisClient    *client.RedisClient
+}
+
+// NewAttendanceService 创建课堂签到服务
+func NewAttendanceService() *AttendanceService {
+	return &AttendanceService{
+		attendanceDAO:  dao.NewAttendanceDAO(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+		staffService:   NewClassStaffService(),
+		redisClient:    client.GetRedisClient(),
+	}
+}
+
+// AttendanceSessionView 签到场次视图
+type AttendanceSessionView struct {
+	*classModel.ClassAttendanceSession
+	IsOpen        bool  `json:"is_open"`        // 是否仍可签到
+	CheckedIn     int64 `json:"checked_in"`     // 已签到人数（出勤+迟到）
+	TotalStudents int32 `json:"total_students"` // 班级学生人数
+}
+
+// AttendanceCode 当前签到码
+type AttendanceCode struct {
+	SessionId     string `json:"session_id"`
+	Code          string `json:"code"`
+	ExpiresIn     int    `json:"expires_in"` // 距离下次轮换的秒数
+	CheckedIn     int64  `json:"checked_in"`
+	TotalStudents int32  `json:"total_students"`
+}
+
+// AttendanceRecordRow 签到场次内的学生考勤
+type AttendanceRecordRow struct {
+	StudentId     string `json:"student_id"`
+	StudentName   string `json:"student_name"`
+	StudentNumber string `json:"student_number"`
+	Status        int32  `json:"status"` // 0-尚未签到，1-出勤，2-迟到，3-缺勤，4-请假
+	StatusLabel   string `json:"status_label"`
+	CheckinTime   string `json:"checkin_time"`
+	Source        string `json:"source"`
+}
+
+// AttendanceSummary 考勤统计
+type AttendanceSummary struct {
+	Present        int32   `json:"present"`
+	Late           int32   `json:"late"`
+	Absent         int32   `json:"absent"`
+	Excused        int32   `json:"excused"`
+	AttendanceRate float64 `json:"attendance_rate"` // 出勤率（%），(出勤+迟到)/(出勤+迟到+缺勤)，请假不计入
+}
+
+// AttendanceReportSession 考勤报表中的签到场次
+type AttendanceReportSession struct {
+	SessionId string `json:"session_id"`
+	Title     string `json:"title"`
+	StartTime string `json:"start_time"`
+}
+
+// AttendanceReportRow 考勤报表行（一个学生）
+type AttendanceReportRow struct {
+	StudentId     string  `json:"student_id"`
+	StudentName   string  `json:"student_name"`
+	StudentNumber string  `json:"student_number"`
+	Statuses      []int32 `json:"statuses"` // 与 sessions 一一对应，0 表示无记录（如签到结束后才加入班级）
+	AttendanceSummary
+}
+
+// AttendanceReport 班级考勤报表（只统计已结束的签到场次）
+type AttendanceReport struct {
+	ClassId   string                     `json:"class_id"`
+	ClassName string                     `json:"class_name"`
+	Sessions  []*AttendanceReportSession `json:"sessions"`
+	Rows      []*AttendanceReportRow     `json:"rows"`
+}
+
+// MyAttendanceRecord 学生本人的考勤记录
+type MyAttendanceRecord struct {
+	SessionId   string `json:"session_id"`
+	Title       string `json:"title"`
+	StartTime   string `json:"start_time"`
+	Status      int32  `json:"status"`
+	StatusLabel string `json:"status_label"`
+	CheckinTime string `json:"checkin_time"`
+}
+
+// MyAttendance 学生本人在班级内的考勤
+type MyAttendance struct {
+	OpenSessionId string                `json:"open_session_id"` // 进行中的签到场次（为空表示当前无签到）
+	CheckedIn     bool                  `json:"checked_in"`      // 是否已完成进行中场次的签到
+	Records       []*MyAttendanceRecord `json:"records"`
+	AttendanceSummary
+}
+
+// ==================== 教师操作 ====================
+
+// OpenSession 发起课堂签到（同一班级同时只能有一个进行中的签到）
+// lateAfter 为开始后多少分钟签到记为迟到（0 表示默认 5 分钟，不小于签到时长表示不记迟到）
+func (s *AttendanceService) OpenSession(teacherId, classId, title string, duration, lateAfter, codeInterval int32) (*AttendanceSessionView, error) {
+	class, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermAttendance)
+	if err != nil {
+		return nil, err
+	}
+	if s.redisClient == nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "签到服务暂不可用")
+	}
+	if duration == 0 {
+		duration = defaultAttendanceDuration
+	}
+	if lateAfter == 0 {
+		lateAfter = defaultAttendanceLate
+	}
+	if codeInterval == 0 {
+		codeInterval = defaultCodeInterval
+	}
+	if duration < 1 || duration > maxAttendanceDuration {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("签到时长需在 1-%d 分钟之间", maxAttendanceDuration))
+	}
+	if lateAfter < 0 {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "迟到时间不能为负数")
+	}
+	if lateAfter > duration {
+		lateAfter = duration
+	}
+	if codeInterval < minCodeInterval || codeInterval > maxCodeInterval {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("签到码轮换间隔需在 %d-%d 秒之间", minCodeInterval, maxCodeInterval))
+	}
+
+	s.finalizeExpired(classId)
+	if open, err := s.attendanceDAO.GetOpenSession(classId); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询签到失败: "+err.Error())
+	} else if open != nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "已有进行中的签到，请先结束")
+	}
+
+	now := time.Now()
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = "课堂签到 " + now.Format("2006-01-02 15:04")
+	}
+	if len([]rune(title)) > 64 {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "签到标题不能超过64字")
+	}
+	session := &classModel.ClassAttendanceSession{
+		SessionId:    fmt.Sprintf("att_%d", now.UnixNano()),
+		ClassId:      classId,
+		Title:        title,
+		OpenedBy:     teacherId,
+		StartTime:    now,
+		LateTime:     now.Add(time.Duration(lateAfter) * time.Minute),
+		EndTime:      now.Add(time.Duration(duration) * time.Minute),
+		CodeInterval: codeInterval,
+		Status:       classModel.AttendanceSessionOpen,
+	}
+	if err := s.attendanceDAO.CreateSession(session); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "发起签到失败: "+err.Error())
+	}
+	return &AttendanceSessionView{ClassAttendanceSession: session, IsOpen: true, TotalStudents: class.CurrentStudents}, nil
+}
+
+// GetSessionCode 查询当前签到码（供课堂投屏轮询，过期时生成新码）
+func (s *AttendanceService) GetSessionCode(teacherId, sessionId string) (*AttendanceCode, error) {
+	session, class, err := s.getSession(teacherId, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	if !isSessionOpen(session, time.Now()) {
+		s.finalizeExpired(session.ClassId)
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "签到已结束")
+	}
+	code, ttl, err := s.currentCode(session)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "生成签到码失败: "+err.Error())
+	}
+	counts, _ := s.attendanceDAO.CountRecordsBySessionIds([]string{sessionId})
+	return &AttendanceCode{
+		SessionId:     sessionId,
+		Code:          code,
+		ExpiresIn:     int(ttl.Seconds()),
+		CheckedIn:     counts[sessionId],
+		TotalStudents: class.CurrentStudents,
+	}, nil
+}
+
+// CloseSession 提前结束签到，未签到的学生记为缺勤
+func (s *AttendanceService) CloseSession(teacherId, sessionId string) error {
+	session, _, err := s.getSession(teacherId, sessionId, true)
+	if err != nil {
+		return err
+	}
+	if session.Status != classModel.AttendanceSessionOpen {
+		return errs.NewCommonError(errs.ErrBadRequest, "签到已结束")
+	}
+	now := time.Now()
+	if session.EndTime.Before(now) {
+		now = session.EndTime
+	}
+	return s.closeSession(session, now)
+}
+
+// ListSessions 分页查询班级签到场次
+func (s *AttendanceService) ListSessions(teacherId, classId string, page, pageSize int32) ([]*AttendanceSessionView, int64, error) {
+	class, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermAttendance)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.finalizeExpired(classId)
+	p, size := normalizePage(int(page), int(pageSize))
+	sessions, err := s.attendanceDAO.ListSessionsByClassId(classId, int32(size), int32((p-1)*size))
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "查询签到记录失败: "+err.Error())
+	}
+	total, err := s.attendanceDAO.CountSessionsByClassId(classId)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "统计签到数量失败: "+err.Error())
+	}
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.SessionId)
+	}
+	counts, _ := s.attendanceDAO.CountRecordsBySessionIds(ids)
+	now := time.Now()
+	views := make([]*AttendanceSessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, &AttendanceSessionView{
+			ClassAttendanceSession: session,
+			IsOpen:                 isSessionOpen(session, now),
+			CheckedIn:              counts[session.SessionId],
+			TotalStudents:          class.CurrentStudents,
+		})
+	}
+	return views, total, nil
+}
+
+// GetSessionRecords 查询签到场次内全部学生的考勤情况
+func (s *AttendanceService) GetSessionRecords(teacherId, sessionId string) ([]*AttendanceRecordRow, error) {
+	session, _, err := s.getSession(teacherId, sessionId, false)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.classMemberDAO.ListAllMembersByClassId(session.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	records, err := s.attendanceDAO.ListRecordsBySessionId(sessionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询考勤记录失败: "+err.Error())
+	}
+	recordMap := make(map[string]*classModel.ClassAttendanceRecord, len(records))
+	for _, r := range records {
+		recordMap[r.StudentId] = r
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.StudentId)
+	}
+	info := s.loadStudentInfo(ids)
+	rows := make([]*AttendanceRecordRow, 0, len(members))
+	for _, m := range members {
+		row := &AttendanceRecordRow{
+			StudentId:     m.StudentId,
+			StudentName:   info[m.StudentId][0],
+			StudentNumber: info[m.StudentId][1],
+		}
+		if r := recordMap[m.StudentId]; r != nil {
+			row.Status = r.Status
+			row.Source = r.Source
+			if r.CheckinTime != nil {
+				row.CheckinTime = formatTime(*r.CheckinTime)
+			}
+		}
+		row.StatusLabel = attendanceStatusLabel(row.Status)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// SetRecordStatus 教师手动标记学生考勤（补签、请假、改为缺勤等）
+func (s *AttendanceService) SetRecordStatus(teacherId, sessionId, studentId string, status int32) error {
+	session, _, err := s.getSession(teacherId, sessionId, true)
+	if err != nil {
+		return err
+	}
+	if status < classModel.AttendancePresent || status > classModel.AttendanceExcused {
+		return errs.NewCommonError(errs.ErrBadRequest, "考勤状态不合法（1-出勤，2-迟到，3-缺勤，4-请假）")
+	}
+	member, err := s.classMemberDAO.GetMember(session.ClassId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return errs.NewCommonError(errs.ErrBadRequest, "该学生不是班级成员")
+	}
+	record := &classModel.ClassAttendanceRecord{
+		SessionId: sessionId,
+		ClassId:   session.ClassId,
+		StudentId: studentId,
+		Status:    status,
+		Source:    classModel.AttendanceSourceTeacher,
+		MarkedBy:  teacherId,
+	}
+	// 出勤、迟到保留学生原签到时间
+	if status == classModel.AttendancePresent || status == classModel.AttendanceLate {
+		if existing, _ := s.attendanceDAO.GetRecord(sessionId, studentId); existing != nil {
+			record.CheckinTime = existing.CheckinTime
+		}
+	}
+	if err := s.attendanceDAO.SaveRecord(record); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新考勤失败: "+err.Error())
+	}
+	return nil
+}
+
+// GetAttendanceReport 查询班级考勤报表（每个学生每次签到的状态与统计）
+func (s *AttendanceService) GetAttendanceReport(teacherId, classId string) (*AttendanceReport, error) {
+	class, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermAttendance)
+	if err != nil {
+		return nil, err
+	}
+	s.finalizeExpired(classId)
+	sessions, err := s.attendanceDAO.ListClosedSessionsByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询签到记录失败: "+err.Error())
+	}
+	records, err := s.attendanceDAO.ListRecordsByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询考勤记录失败: "+err.Error())
+	}
+	members, err := s.classMemberDAO.ListAllMembersByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+
+	report := &AttendanceReport{ClassId: classId, ClassName: class.ClassName}
+	sessionIndex := make(map[string]int, len(sessions))
+	for i, session := range sessions {
+		sessionIndex[session.SessionId] = i
+		report.Sessions = append(report.Sessions, &AttendanceReportSession{
+			SessionId: session.SessionId,
+			Title:     session.Title,
+			StartTime: formatTime(session.StartTime),
+		})
+	}
+	statusMap := make(map[string][]int32, len(members))
+	for _, m := range members {
+		statusMap[m.StudentId] = make([]int32, len(sessions))
+	}
+	for _, r := range records {
+		idx, ok := sessionIndex[r.SessionId]
+		if statuses := statusMap[r.StudentId]; ok && statuses != nil {
+			statuses[idx] = r.Status
+		}
+	}
+
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.StudentId)
+	}
+	info := s.loadStudentInfo(ids)
+	for _, m := range members {
+		statuses := statusMap[m.StudentId]
+		report.Rows = append(report.Rows, &AttendanceReportRow{
+			StudentId:         m.StudentId,
+			StudentName:       info[m.StudentId][0],
+			StudentNumber:     info[m.StudentId][1],
+			Statuses:          statuses,
+			AttendanceSummary: summarizeAttendance(statuses),
+		})
+	}
+	return report, nil
+}
+
+// ExportAttendanceReport 导出班级考勤报表（format: csv / xlsx），返回 (文件内容, 文件名)
+func (s *AttendanceService) ExportAttendanceReport(teacherId, classId, format string) ([]byte, string, error) {
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		return nil, "", errs.NewCommonError(errs.ErrBadRequest, "导出格式仅支持 csv 或 xlsx")
+	}
+	report, err := s.GetAttendanceReport(teacherId, classId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	header := []string{"学号", "姓名"}
+	for _, session := range report.Sessions {
+		header = append(header, fmt.Sprintf("%s（%s）", session.Title, session.StartTime))
+	}
+	// 末尾的统计列按数值写入 xlsx，学号等其余列按文本写入
+	numericCols := make([]int, 0, 5)
+	for i := 0; i < 5; i++ {
+		numericCols = append(numericCols, len(header)+i)
+	}
+	header = append(header, "出勤", "迟到", "缺勤", "请假", "出勤率(%)")
+
+	rows := make([][]string, 0, len(report.Rows))
+	for _, r := range report.Rows {
+		row := []string{r.StudentNumber, r.StudentName}
+		for _, status := range r.Statuses {
+			// 无记录（签到结束后才加入班级）
+			if status == 0 {
+				row = append(row, "-")
+				continue
+			}
+			row = append(row, attendanceStatusLabel(status))
+		}
+		row = append(row,
+			strconv.Itoa(int(r.Present)),
+			strconv.Itoa(int(r.Late)),
+			strconv.Itoa(int(r.Absent)),
+			strconv.Itoa(int(r.Excused)),
+			strconv.FormatFloat(r.AttendanceRate, 'f', -1, 64),
+		)
+		rows = append(rows, row)
+	}
+
+	fileName := fmt.Sprintf("%s_考勤_%s.%s", report.ClassName, time.Now().Format("20060102150405"), format)
+	var content []byte
+	if format == "xlsx" {
+		content, err = buildXLSX("考勤", header, rows, numericCols...)
+	} else {
+		content, err = buildCSV(header, rows)
+	}
+	if err != nil {
+		return nil, "", errs.NewCommonError(errs.ErrInternal, "导出考勤报表失败: "+err.Error())
+	}
+	return content, fileName, nil
+}
+
+// ==================== 学生操作 ====================
+
+// CheckIn 学生输入签到码完成签到（迟到时间之后签到记为迟到）
+func (s *AttendanceService) CheckIn(studentId, classId, code string) (*classModel.ClassAttendanceRecord, error) {
+	code = strings.TrimSpace(code)
+	if classId == "" || code == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级ID和签到码不能为空")
+	}
+	member, err := s.classMemberDAO.GetMember(classId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	if err := s.staffService.CheckClassWritable(classId); err != nil {
+		return nil, err
+	}
+	if s.redisClient == nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "签到服务暂不可用")
+	}
+
+	s.finalizeExpired(classId)
+	session, err := s.attendanceDAO.GetOpenSession(classId)
+	if err != nil || session == nil || !isSessionOpen(session, time.Now()) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "当前没有进行中的签到")
+	}
+	if existing, _ := s.attendanceDAO.GetRecord(session.SessionId, studentId); existing != nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "本次签到已有记录（"+attendanceStatusLabel(existing.Status)+"）")
+	}
+
+	ctx := context.Background()
+	failKey := attendanceFailKey(session.SessionId, studentId)
+	if failures, _ := s.redisClient.Get(failKey); failures != "" {
+		if n, _ := strconv.Atoi(failures); n >= attendanceMaxFailures {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "签到码错误次数过多，请稍后再试")
+		}
+	}
+	valid, err := s.redisClient.Exists(attendanceCodeKey(session.SessionId, code))
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "校验签到码失败: "+err.Error())
+	}
+	if valid == 0 {
+		pipe := s.redisClient.Client.TxPipeline()
+		pipe.Incr(ctx, failKey)
+		pipe.Expire(ctx, failKey, time.Minute)
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("[AttendanceService] 记录签到失败次数失败: session_id=%s, err=%v", session.SessionId, err)
+		}
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "签到码错误或已过期")
+	}
+
+	now := time.Now()
+	status := int32(classModel.AttendancePresent)
+	if now.After(session.LateTime) {
+		status = classModel.AttendanceLate
+	}
+	record := &classModel.ClassAttendanceRecord{
+		SessionId:   session.SessionId,
+		ClassId:     classId,
+		StudentId:   studentId,
+		Status:      status,
+		CheckinTime: &now,
+		Source:      classModel.AttendanceSourceCode,
+	}
+	created, err := s.attendanceDAO.CreateRecord(record)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "签到失败: "+err.Error())
+	}
+	if !created {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "本次签到已有记录")
+	}
+	return record, nil
+}
+
+// GetMyAttendance 学生查询本人在班级内的考勤记录
+func (s *AttendanceService) GetMyAttendance(studentId, classId string) (*MyAttendance, error) {
+	member, err := s.classMemberDAO.GetMember(classId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	s.finalizeExpired(classId)
+	sessions, err := s.attendanceDAO.ListClosedSessionsByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询签到记录失败: "+err.Error())
+	}
+	records, err := s.attendanceDAO.ListRecordsByStudentId(classId, studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询考勤记录失败: "+err.Error())
+	}
+	recordMap := make(map[string]*classModel.ClassAttendanceRecord, len(records))
+	for _, r := range records {
+		recordMap[r.SessionId] = r
+	}
+
+	result := &MyAttendance{Records: make([]*MyAttendanceRecord, 0, len(sessions))}
+	if open, _ := s.attendanceDAO.GetOpenSession(classId); open != nil {
+		result.OpenSessionId = open.SessionId
+		result.CheckedIn = recordMap[open.SessionId] != nil
+	}
+	statuses := make([]int32, 0, len(sessions))
+	// 最新的签到在前
+	for i := len(sessions) - 1; i >= 0; i-- {
+		session := sessions[i]
+		r := recordMap[session.SessionId]
+		if r == nil {
+			continue
+		}
+		item := &MyAttendanceRecord{
+			SessionId:   session.SessionId,
+			Title:       session.Title,
+			StartTime:   formatTime(session.StartTime),
+			Status:      r.Status,
+			StatusLabel: attendanceStatusLabel(r.Status),
+		}
+		if r.CheckinTime != nil {
+			item.CheckinTime = formatTime(*r.CheckinTime)
+		}
+		result.Records = append(result.Records, item)
+		statuses = append(statuses, r.Status)
+	}
+	result.AttendanceSummary = summarizeAttendance(statuses)
+	return result, nil
+}
+
+// ==================== 内部工具 ====================
+
+// getSession 查询签到场次并校验教学团队的考勤权限（write 为 true 时同时校验班级未归档）
+func (s *AttendanceService) getSession(teacherId, sessionId string, write bool) (*classModel.ClassAttendanceSession, *classModel.Class, error) {
+	session, err := s.attendanceDAO.GetSessionById(sessionId)
+	if err != nil || session == nil {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "签到不存在")
+	}
+	var class *classModel.Class
+	if write {
+		class, err = s.staffService.CheckWritePermission(session.ClassId, teacherId, consts.ClassPermAttendance)
+	} else {
+		class, err = s.staffService.CheckPermission(session.ClassId, teacherId, consts.ClassPermAttendance)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, class, nil
+}
+
+// currentCode 获取签到场次的当前签到码，过期时生成新码，返回 (签到码, 剩余有效时间)
+// 每个签到码单独以 key 记录有效期（轮换间隔 + 宽限时间），轮换后短时间内旧码仍可使用
+func (s *AttendanceService) currentCode(session *classModel.ClassAttendanceSession) (string, time.Duration, error) {
+	if s.redisClient == nil {
+		return "", 0, fmt.Errorf("redis 未初始化")
+	}
+	ctx := context.Background()
+	interval := time.Duration(session.CodeInterval) * time.Second
+	currentKey := attendanceCurrentKey(session.SessionId)
+	for i := 0; i < 3; i++ {
+		if code, err := s.redisClient.Get(currentKey); err == nil && code != "" {
+			ttl, _ := s.redisClient.TTL(currentKey)
+			if ttl < 0 {
+				ttl = 0
+			}
+			return code, ttl, nil
+		}
+		code := utils.GenerateVerificationCode()
+		if err := s.redisClient.Set(attendanceCodeKey(session.SessionId, code), 1, interval+attendanceCodeGrace); err != nil {
+			return "", 0, err
+		}
+		ok, err := s.redisClient.Client.SetNX(ctx, currentKey, code, interval).Result()
+		if err != nil {
+			return "", 0, err
+		}
+		if ok {
+			return code, interval, nil
+		}
+		// 并发请求已生成新码，重新读取
+	}
+	return "", 0, fmt.Errorf("签到码生成冲突")
+}
+
+// finalizeExpired 结束班级内截止时间已过的签到场次
+func (s *AttendanceService) finalizeExpired(classId string) {
+	sessions, err := s.attendanceDAO.ListExpiredOpenSessions(classId, time.Now())
+	if err != nil {
+		log.Printf("[AttendanceService] 查询过期签到失败: class_id=%s, err=%v", classId, err)
+		return
+	}
+	for _, session := range sessions {
+		if err := s.closeSession(session, session.EndTime); err != nil {
+			log.Printf("[AttendanceService] 结束签到失败: session_id=%s, err=%v", session.SessionId, err)
+		}
+	}
+}
+
+// closeSession 结束签到场次：未签到的班级成员记为缺勤，并清除签到码
+func (s *AttendanceService) closeSession(session *classModel.ClassAttendanceSession, closedAt time.Time) error {
+	members, err := s.classMemberDAO.ListAllMembersByClassId(session.ClassId)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	records, err := s.attendanceDAO.ListRecordsBySessionId(session.SessionId)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "查询考勤记录失败: "+err.Error())
+	}
+	recorded := make(map[string]bool, len(records))
+	for _, r := range records {
+		recorded[r.StudentId] = true
+	}
+	var absent []*classModel.ClassAttendanceRecord
+	for _, m := range members {
+		if recorded[m.StudentId] {
+			continue
+		}
+		absent = append(absent, &classModel.ClassAttendanceRecord{
+			SessionId: session.SessionId,
+			ClassId:   session.ClassId,
+			StudentId: m.StudentId,
+			Status:    classModel.AttendanceAbsent,
+			Source:    classModel.AttendanceSourceSystem,
+		})
+	}
+	if err := s.attendanceDAO.CloseSession(session.SessionId, closedAt, absent); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "结束签到失败: "+err.Error())
+	}
+	if s.redisClient != nil {
+		_ = s.redisClient.Del(attendanceCurrentKey(session.SessionId))
+	}
+	return nil
+}
+
+// loadStudentInfo 批量查询学生姓名与学号（student_id -> [姓名, 学号]）
+func (s *AttendanceService) loadStudentInfo(studentIds []string) map[string][2]string {
+	info := make(map[string][2]string)
+	if len(studentIds) == 0 {
+		return info
+	}
+	students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds})
+	if err != nil {
+		return info
+	}
+	for _, st := range students {
+		info[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+	}
+	return info
+}
+
+// isSessionOpen 判断签到场次当前是否可签到
+func isSessionOpen(session *classModel.ClassAttendanceSession, now time.Time) bool {
+	return session.Status == classModel.AttendanceSessionOpen && now.Before(session.EndTime)
+}
+
+// summarizeAttendance 统计考勤状态
+func summarizeAttendance(statuses []int32) AttendanceSummary {
+	var summary AttendanceSummary
+	for _, status := range statuses {
+		switch status {
+		case classModel.AttendancePresent:
+			summary.Present++
+		case classModel.AttendanceLate:
+			summary.Late++
+		case classModel.AttendanceAbsent:
+			summary.Absent++
+		case classModel.AttendanceExcused:
+			summary.Excused++
+		}
+	}
+	if counted := summary.Present + summary.Late + summary.Absent; counted > 0 {
+		summary.AttendanceRate = roundScore(float64(summary.Present+summary.Late) * 100 / float64(counted))
+	}
+	return summary
+}
+
+// attendanceStatusLabel 考勤状态的中文名称
+func attendanceStatusLabel(status int32) string {
+	switch status {
+	case classModel.AttendancePresent:
+		return "出勤"
+	case classModel.AttendanceLate:
+		return "迟到"
+	case classModel.AttendanceAbsent:
+		return "缺勤"
+	case classModel.AttendanceExcused:
+		return "请假"
+	}
+	return "未签到"
+}
+
+// attendanceCurrentKey 签到场次当前签到码的缓存 key
+func attendanceCurrentKey(sessionId string) string {
+	return fmt.Sprintf("class:attendance:current:%s", sessionId)
+}
+
+// attendanceCodeKey 签到码有效期的缓存 key
+func attendanceCodeKey(sessionId, code string) string {
+	return fmt.Sprintf("class:attendance:code:%s:%s", sessionId, code)
+}
+
+// attendanceFailKey 学生输错签到码次数的缓存 key
+func attendanceFailKey(sessionId, studentId string) string {
+	return fmt.Sprintf("class:attendance:fail:%s:%s", sessionId, studentId)
+}
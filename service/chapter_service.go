@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/yzf120/elysia-backend/consts"
 	"github.com/yzf120/elysia-backend/dao"
 	"github.com/yzf120/elysia-backend/errs"
 	classModel "github.com/yzf120/elysia-backend/model/class"
@@ -12,15 +13,27 @@ import (
 
 // ChapterService 章节服务
 type ChapterService struct {
-	chapterDAO dao.ChapterDAO
-	classDAO   dao.ClassDAO
+	chapterDAO      dao.ChapterDAO
+	classDAO        dao.ClassDAO
+	classMemberDAO  dao.ClassMemberDAO
+	problemDAO      dao.ProblemDAO
+	quizDAO         dao.QuizDAO
+	staffService    *ClassStaffService
+	accessService   *SectionAccessService
+	progressService *ProgressService
 }
 
 // NewChapterService 创建章节服务
 func NewChapterService() *ChapterService {
 	return &ChapterService{
-		chapterDAO: dao.NewChapterDAO(),
-		classDAO:   dao.NewClassDAO(),
+		chapterDAO:      dao.NewChapterDAO(),
+		classDAO:        dao.NewClassDAO(),
+		classMemberDAO:  dao.NewClassMemberDAO(),
+		problemDAO:      dao.NewProblemDAO(),
+		quizDAO:         dao.NewQuizDAO(),
+		staffService:    NewClassStaffService(),
+		accessService:   NewSectionAccessService(),
+		progressService: NewProgressService(),
 	}
 }
 
@@ -32,13 +45,9 @@ func (s *ChapterService) CreateChapter(teacherId, classId, title, description st
 		return nil, errs.NewCommonError(errs.ErrBadRequest, "必填参数不能为空")
 	}
 
-	// 校验班级归属
-	class, err := s.classDAO.GetClassById(classId)
-	if err != nil || class == nil {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
-	}
-	if class.TeacherId != teacherId {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "无权限操作该班级")
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return nil, err
 	}
 
 	// 计算新章节的排序值（当前最大值 + 10，方便后续插入）
@@ -61,8 +70,9 @@ func (s *ChapterService) CreateChapter(teacherId, classId, title, description st
 		return nil, errs.NewCommonError(errs.ErrInternal, "创建章节失败: "+err.Error())
 	}
 
-	// 同步更新 class.chapter_ids
+	// 同步更新 class.chapter_ids，并清除班级进度缓存
 	s.syncChapterIds(classId)
+	s.progressService.InvalidateClassProgress(classId)
 
 	return chapter, nil
 }
@@ -74,10 +84,9 @@ func (s *ChapterService) UpdateChapter(teacherId, chapterId, title, description
 		return errs.NewCommonError(errs.ErrBadRequest, "章节不存在")
 	}
 
-	// 校验班级归属
-	class, err := s.classDAO.GetClassById(chapter.ClassId)
-	if err != nil || class == nil || class.TeacherId != teacherId {
-		return errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(chapter.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
 	}
 
 	updates := map[string]interface{}{}
@@ -94,6 +103,7 @@ func (s *ChapterService) UpdateChapter(teacherId, chapterId, title, description
 	if err := s.chapterDAO.UpdateChapter(chapterId, updates); err != nil {
 		return errs.NewCommonError(errs.ErrInternal, "更新章节失败: "+err.Error())
 	}
+	s.progressService.InvalidateClassProgress(chapter.ClassId)
 	return nil
 }
 
@@ -104,10 +114,9 @@ func (s *ChapterService) DeleteChapter(teacherId, chapterId string) error {
 		return errs.NewCommonError(errs.ErrBadRequest, "章节不存在")
 	}
 
-	// 校验班级归属
-	class, err := s.classDAO.GetClassById(chapter.ClassId)
-	if err != nil || class == nil || class.TeacherId != teacherId {
-		return errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(chapter.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
 	}
 
 	// 删除章节下所有小节
@@ -120,8 +129,9 @@ func (s *ChapterService) DeleteChapter(teacherId, chapterId string) error {
 		return errs.NewCommonError(errs.ErrInternal, "删除章节失败: "+err.Error())
 	}
 
-	// 同步更新 class.chapter_ids
+	// 同步更新 class.chapter_ids，并清除班级进度缓存
 	s.syncChapterIds(chapter.ClassId)
+	s.progressService.InvalidateClassProgress(chapter.ClassId)
 
 	return nil
 }
@@ -129,42 +139,180 @@ func (s *ChapterService) DeleteChapter(teacherId, chapterId string) error {
 // ReorderChapters 调整章节排序（教师操作）
 // orders: [{chapter_id, sort_order}, ...]
 func (s *ChapterService) ReorderChapters(teacherId, classId string, orders []dao.ChapterOrder) error {
-	// 校验班级归属
-	class, err := s.classDAO.GetClassById(classId)
-	if err != nil || class == nil || class.TeacherId != teacherId {
-		return errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
 	}
 
 	if err := s.chapterDAO.BatchUpdateChapterOrder(classId, orders); err != nil {
 		return errs.NewCommonError(errs.ErrInternal, "更新章节排序失败: "+err.Error())
 	}
 
-	// 同步更新 class.chapter_ids（按新排序）
+	// 同步更新 class.chapter_ids（按新排序），并清除班级进度缓存
 	s.syncChapterIds(classId)
+	s.progressService.InvalidateClassProgress(classId)
 
 	return nil
 }
 
 // GetChaptersByClassId 查询班级下所有章节（含小节，师生共用）
-func (s *ChapterService) GetChaptersByClassId(classId string) ([]*classModel.ClassChapter, map[string][]*classModel.ClassSection, error) {
+// 学生只能看到已发布的章节与小节，前置小节未完成的小节标记为锁定；
+// 教学团队默认查看全部内容，preview 为 true 时以学生视角预览（previewStudentId 为空时按尚无任何进度的学生展示，
+// 指定学生时需拥有成绩查看权限）
+func (s *ChapterService) GetChaptersByClassId(viewerType, viewerId, classId string, preview bool, previewStudentId string) ([]*classModel.ClassChapter, map[string][]*SectionView, error) {
+	if classId == "" {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "班级ID不能为空")
+	}
+
+	studentId := ""
+	switch viewerType {
+	case consts.RoleTeacher:
+		if _, err := s.staffService.GetMyPermissions(classId, viewerId); err != nil {
+			return nil, nil, err
+		}
+		if preview && previewStudentId != "" {
+			if _, err := s.staffService.CheckPermission(classId, viewerId, consts.ClassPermGradeView); err != nil {
+				return nil, nil, err
+			}
+			member, err := s.classMemberDAO.GetMember(classId, previewStudentId)
+			if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+				return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "预览的学生不是该班级成员")
+			}
+			studentId = previewStudentId
+		}
+	case consts.RoleStudent:
+		preview = true
+		studentId = viewerId
+	default:
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "无权限查看该班级章节")
+	}
+
 	chapters, err := s.chapterDAO.ListChaptersByClassId(classId)
 	if err != nil {
 		return nil, nil, errs.NewCommonError(errs.ErrInternal, "查询章节失败: "+err.Error())
 	}
+	sections, err := s.chapterDAO.ListSectionsByClassId(classId)
+	if err != nil {
+		return nil, nil, errs.NewCommonError(errs.ErrInternal, "查询小节失败: "+err.Error())
+	}
 
-	// 查询每个章节的小节
-	sectionMap := make(map[string][]*classModel.ClassSection)
-	for _, ch := range chapters {
-		sections, err := s.chapterDAO.ListSectionsByChapterId(ch.ChapterId)
+	if preview {
+		completed, err := s.accessService.LoadCompletedSections(classId, studentId, sections)
 		if err != nil {
-			continue
+			return nil, nil, err
 		}
-		sectionMap[ch.ChapterId] = sections
+		chapters, sectionMap := s.accessService.BuildStudentView(chapters, sections, completed, time.Now())
+		return chapters, sectionMap, nil
 	}
 
+	sectionMap := make(map[string][]*SectionView)
+	for _, sec := range sections {
+		sectionMap[sec.ChapterId] = append(sectionMap[sec.ChapterId], &SectionView{ClassSection: sec})
+	}
 	return chapters, sectionMap, nil
 }
 
+// SetChapterRelease 设置章节的定时发布时间（教师操作，releaseAt 为空表示立即发布）
+func (s *ChapterService) SetChapterRelease(teacherId, chapterId, releaseAt string) error {
+	chapter, err := s.chapterDAO.GetChapterById(chapterId)
+	if err != nil || chapter == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "章节不存在")
+	}
+
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(chapter.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
+	}
+
+	releaseTime, err := parseOptionalTime(releaseAt)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "发布时间格式错误: "+err.Error())
+	}
+	if err := s.chapterDAO.UpdateChapter(chapterId, map[string]interface{}{"release_at": releaseTime}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新发布设置失败: "+err.Error())
+	}
+	s.progressService.InvalidateClassProgress(chapter.ClassId)
+	return nil
+}
+
+// SetSectionRelease 设置小节的定时发布时间与前置小节（教师操作）
+// 前置小节必须属于同一班级，且不能形成循环依赖
+func (s *ChapterService) SetSectionRelease(teacherId, sectionId, releaseAt string, prerequisiteIds []string) error {
+	section, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil || section == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
+	}
+
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
+	}
+
+	releaseTime, err := parseOptionalTime(releaseAt)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "发布时间格式错误: "+err.Error())
+	}
+
+	sections, err := s.chapterDAO.ListSectionsByClassId(section.ClassId)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "查询小节失败: "+err.Error())
+	}
+	prereqMap := make(map[string][]string, len(sections))
+	for _, sec := range sections {
+		prereqMap[sec.SectionId] = parseStringList(sec.PrerequisiteIds)
+	}
+
+	ids := make([]string, 0, len(prerequisiteIds))
+	seen := make(map[string]bool)
+	for _, id := range prerequisiteIds {
+		if id == "" || seen[id] {
+			continue
+		}
+		if id == sectionId {
+			return errs.NewCommonError(errs.ErrBadRequest, "前置小节不能是小节本身")
+		}
+		if _, ok := prereqMap[id]; !ok {
+			return errs.NewCommonError(errs.ErrBadRequest, "前置小节不存在或不属于该班级: "+id)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	prereqMap[sectionId] = ids
+	if hasPrerequisiteCycle(sectionId, prereqMap) {
+		return errs.NewCommonError(errs.ErrBadRequest, "前置小节存在循环依赖")
+	}
+
+	idsJSON, _ := json.Marshal(ids)
+	updates := map[string]interface{}{
+		"release_at":       releaseTime,
+		"prerequisite_ids": string(idsJSON),
+	}
+	if err := s.chapterDAO.UpdateSection(sectionId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新发布设置失败: "+err.Error())
+	}
+	s.progressService.InvalidateClassProgress(section.ClassId)
+	return nil
+}
+
+// hasPrerequisiteCycle 判断从 start 出发沿前置关系能否回到 start
+func hasPrerequisiteCycle(start string, prereqMap map[string][]string) bool {
+	visited := make(map[string]bool)
+	stack := append([]string{}, prereqMap[start]...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == start {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, prereqMap[id]...)
+	}
+	return false
+}
+
 // ==================== 小节操作 ====================
 
 // CreateSection 创建小节（教师操作）
@@ -174,8 +322,8 @@ func (s *ChapterService) CreateSection(teacherId, chapterId, title, description
 	if teacherId == "" || chapterId == "" || title == "" {
 		return nil, errs.NewCommonError(errs.ErrBadRequest, "必填参数不能为空")
 	}
-	if sectionType != classModel.SectionTypeProblem && sectionType != classModel.SectionTypeDiscussion {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "小节类型不合法（1-算法题，2-讨论话题）")
+	if sectionType < classModel.SectionTypeProblem || sectionType > classModel.SectionTypeVideo {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "小节类型不合法（1-算法题，2-讨论话题，3-测验，4-阅读资料，5-视频）")
 	}
 
 	chapter, err := s.chapterDAO.GetChapterById(chapterId)
@@ -183,10 +331,9 @@ func (s *ChapterService) CreateSection(teacherId, chapterId, title, description
 		return nil, errs.NewCommonError(errs.ErrBadRequest, "章节不存在")
 	}
 
-	// 校验班级归属
-	class, err := s.classDAO.GetClassById(chapter.ClassId)
-	if err != nil || class == nil || class.TeacherId != teacherId {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(chapter.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return nil, err
 	}
 
 	// 计算排序值
@@ -206,6 +353,7 @@ func (s *ChapterService) CreateSection(teacherId, chapterId, title, description
 		ProblemId:         problemId,
 		DiscussionTitle:   discussionTitle,
 		DiscussionContent: discussionContent,
+		PrerequisiteIds:   "[]",
 		SortOrder:         sortOrder,
 		Status:            1,
 	}
@@ -213,6 +361,7 @@ func (s *ChapterService) CreateSection(teacherId, chapterId, title, description
 	if err := s.chapterDAO.CreateSection(section); err != nil {
 		return nil, errs.NewCommonError(errs.ErrInternal, "创建小节失败: "+err.Error())
 	}
+	s.progressService.InvalidateClassProgress(chapter.ClassId)
 
 	return section, nil
 }
@@ -226,10 +375,9 @@ func (s *ChapterService) UpdateSection(teacherId, sectionId, title, description
 		return errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
 	}
 
-	// 校验班级归属
-	class, err := s.classDAO.GetClassById(section.ClassId)
-	if err != nil || class == nil || class.TeacherId != teacherId {
-		return errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
 	}
 
 	updates := map[string]interface{}{}
@@ -265,15 +413,15 @@ func (s *ChapterService) DeleteSection(teacherId, sectionId string) error {
 		return errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
 	}
 
-	// 校验班级归属
-	class, err := s.classDAO.GetClassById(section.ClassId)
-	if err != nil || class == nil || class.TeacherId != teacherId {
-		return errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
 	}
 
 	if err := s.chapterDAO.DeleteSection(sectionId); err != nil {
 		return errs.NewCommonError(errs.ErrInternal, "删除小节失败: "+err.Error())
 	}
+	s.progressService.InvalidateClassProgress(section.ClassId)
 	return nil
 }
 
@@ -284,10 +432,9 @@ func (s *ChapterService) ReorderSections(teacherId, chapterId string, orders []d
 		return errs.NewCommonError(errs.ErrBadRequest, "章节不存在")
 	}
 
-	// 校验班级归属
-	class, err := s.classDAO.GetClassById(chapter.ClassId)
-	if err != nil || class == nil || class.TeacherId != teacherId {
-		return errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 校验章节编辑权限
+	if _, err := s.staffService.CheckWritePermission(chapter.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
 	}
 
 	if err := s.chapterDAO.BatchUpdateSectionOrder(chapterId, orders); err != nil {
@@ -0,0 +1,715 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+)
+
+const (
+	defaultGroupMaxSize = 5
+	groupMaxSizeLimit   = 20
+	groupNameMaxLen     = 64
+	groupDescMaxLen     = 256
+)
+
+// ClassGroupService 班级小组服务（教师分组或学生自选小组、组长管理，以及小组作业/讨论的成员查询）
+type ClassGroupService struct {
+	classDAO       dao.ClassDAO
+	classMemberDAO dao.ClassMemberDAO
+	groupDAO       dao.ClassGroupDAO
+	chapterDAO     dao.ChapterDAO
+	studentDAO     dao.StudentDAO
+	staffService   *ClassStaffService
+}
+
+// NewClassGroupService 创建班级小组服务
+func NewClassGroupService() *ClassGroupService {
+	return &ClassGroupService{
+		classDAO:       dao.NewClassDAO(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		groupDAO:       dao.NewClassGroupDAO(),
+		chapterDAO:     dao.NewChapterDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+		staffService:   NewClassStaffService(),
+	}
+}
+
+// GroupMemberView 小组成员视图
+type GroupMemberView struct {
+	StudentId     string `json:"student_id"`
+	StudentName   string `json:"student_name"`
+	StudentNumber string `json:"student_number"`
+	Role          string `json:"role,omitempty"` // leader / member（未分组学生为空）
+	JoinTime      string `json:"join_time,omitempty"`
+}
+
+// GroupView 小组视图（含成员）
+type GroupView struct {
+	*classModel.ClassGroup
+	Members []*GroupMemberView `json:"members"`
+}
+
+// GroupOverview 班级分组概览
+type GroupOverview struct {
+	ClassId      string             `json:"class_id"`
+	SelfEnroll   bool               `json:"self_enroll"`    // 是否允许学生自建/自选小组
+	GroupMaxSize int32              `json:"group_max_size"` // 学生自建小组的人数上限
+	Groups       []*GroupView       `json:"groups"`
+	Ungrouped    []*GroupMemberView `json:"ungrouped"` // 尚未分组的学生
+	MyGroupId    string             `json:"my_group_id,omitempty"`
+}
+
+// ==================== 教师操作 ====================
+
+// GetGroupOverview 教学团队查询班级分组情况
+func (s *ClassGroupService) GetGroupOverview(teacherId, classId string) (*GroupOverview, error) {
+	if _, err := s.staffService.GetMyPermissions(classId, teacherId); err != nil {
+		return nil, err
+	}
+	return s.buildOverview(classId, "")
+}
+
+// SetGroupSettings 设置是否允许学生自建/自选小组及自建小组的人数上限（需要班级管理权限）
+func (s *ClassGroupService) SetGroupSettings(teacherId, classId string, selfEnroll bool, maxSize int32) error {
+	if _, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermClassManage); err != nil {
+		return err
+	}
+	if maxSize == 0 {
+		maxSize = defaultGroupMaxSize
+	}
+	if maxSize < 1 || maxSize > groupMaxSizeLimit {
+		return errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("小组人数上限需在 1-%d 之间", groupMaxSizeLimit))
+	}
+	updates := map[string]interface{}{
+		"group_self_enroll": selfEnroll,
+		"group_max_size":    maxSize,
+	}
+	if err := s.classDAO.UpdateClass(classId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新分组设置失败: "+err.Error())
+	}
+	return nil
+}
+
+// TeacherCreateGroup 教师创建小组，可同时指定成员（第一个成员为组长）
+func (s *ClassGroupService) TeacherCreateGroup(teacherId, classId, name, description string, maxSize int32, studentIds []string) (*GroupView, error) {
+	class, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermClassManage)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize == 0 {
+		maxSize = class.GroupMaxSize
+	}
+	group, err := s.newGroup(classId, name, description, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	group.CreatorType = classModel.GroupCreatorTeacher
+	group.CreatedBy = teacherId
+	studentIds = uniqueStrings(studentIds)
+	if int32(len(studentIds)) > group.MaxSize {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("成员人数超过小组上限（%d人）", group.MaxSize))
+	}
+
+	if err := s.checkAssignable(classId, studentIds); err != nil {
+		return nil, err
+	}
+	if err := s.groupDAO.CreateGroup(group, newGroupMembers(classId, studentIds)); err != nil {
+		return nil, groupMemberWriteError("创建小组失败", err)
+	}
+	return s.getGroupView(group.GroupId)
+}
+
+// TeacherUpdateGroup 教师修改小组名称、简介与人数上限（人数上限不能小于当前人数）
+func (s *ClassGroupService) TeacherUpdateGroup(teacherId, groupId, name, description string, maxSize int32) error {
+	group, err := s.getManagedGroup(teacherId, groupId)
+	if err != nil {
+		return err
+	}
+	updates, err := s.groupInfoUpdates(group, name, description)
+	if err != nil {
+		return err
+	}
+	if maxSize != 0 {
+		if maxSize < 1 || maxSize > groupMaxSizeLimit {
+			return errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("小组人数上限需在 1-%d 之间", groupMaxSizeLimit))
+		}
+		if maxSize < group.MemberCount {
+			return errs.NewCommonError(errs.ErrBadRequest, "人数上限不能小于小组当前人数")
+		}
+		updates["max_size"] = maxSize
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	if err := s.groupDAO.UpdateGroup(groupId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新小组失败: "+err.Error())
+	}
+	return nil
+}
+
+// TeacherDeleteGroup 教师解散小组（成员变为未分组，已有成绩与帖子保留）
+func (s *ClassGroupService) TeacherDeleteGroup(teacherId, groupId string) error {
+	if _, err := s.getManagedGroup(teacherId, groupId); err != nil {
+		return err
+	}
+	if err := s.groupDAO.DeleteGroup(groupId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "解散小组失败: "+err.Error())
+	}
+	return nil
+}
+
+// TeacherAddMembers 教师将学生分入小组（学生需为班级成员且尚未分组）
+func (s *ClassGroupService) TeacherAddMembers(teacherId, groupId string, studentIds []string) error {
+	group, err := s.getManagedGroup(teacherId, groupId)
+	if err != nil {
+		return err
+	}
+	studentIds = uniqueStrings(studentIds)
+	if len(studentIds) == 0 {
+		return errs.NewCommonError(errs.ErrBadRequest, "学生ID列表不能为空")
+	}
+
+	if group.MemberCount+int32(len(studentIds)) > group.MaxSize {
+		return errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("超过小组人数上限（%d人）", group.MaxSize))
+	}
+	if err := s.checkAssignable(group.ClassId, studentIds); err != nil {
+		return err
+	}
+	// 人数上限与"每个学生只能属于一个小组"由条件更新与唯一键保证，上面的校验只用于给出明确提示
+	ok, err := s.groupDAO.AddMembers(groupId, newGroupMembers(group.ClassId, studentIds))
+	if err != nil {
+		return groupMemberWriteError("添加小组成员失败", err)
+	}
+	if !ok {
+		return errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("超过小组人数上限（%d人）", group.MaxSize))
+	}
+	return nil
+}
+
+// TeacherRemoveMember 教师将学生移出小组
+func (s *ClassGroupService) TeacherRemoveMember(teacherId, groupId, studentId string) error {
+	group, err := s.getManagedGroup(teacherId, groupId)
+	if err != nil {
+		return err
+	}
+	member, err := s.groupDAO.GetMemberByStudentId(group.ClassId, studentId)
+	if err != nil || member == nil || member.GroupId != groupId {
+		return errs.NewCommonError(errs.ErrBadRequest, "该学生不在此小组中")
+	}
+	return s.removeMember(group, studentId)
+}
+
+// TeacherSetLeader 教师指定组长
+func (s *ClassGroupService) TeacherSetLeader(teacherId, groupId, studentId string) error {
+	group, err := s.getManagedGroup(teacherId, groupId)
+	if err != nil {
+		return err
+	}
+	return s.setLeader(group, studentId)
+}
+
+// SetSectionGroupMode 设置小节的小组模式（需要章节编辑权限，仅算法题与讨论小节）
+// 算法题小节：组内任一成员的提交成绩计入全组成员；讨论小节：学生只能看到本组与教师发布的主题帖
+func (s *ClassGroupService) SetSectionGroupMode(teacherId, sectionId string, enabled bool) error {
+	section, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil || section == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
+	}
+	if section.SectionType != classModel.SectionTypeProblem && section.SectionType != classModel.SectionTypeDiscussion {
+		return errs.NewCommonError(errs.ErrBadRequest, "只有算法题和讨论小节可以设置小组模式")
+	}
+	if _, err := s.staffService.CheckWritePermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
+	}
+	if err := s.chapterDAO.UpdateSection(sectionId, map[string]interface{}{"group_mode": enabled}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新小组模式失败: "+err.Error())
+	}
+	return nil
+}
+
+// ==================== 学生操作 ====================
+
+// StudentGetGroupOverview 学生查询班级分组情况（含自己所在小组）
+func (s *ClassGroupService) StudentGetGroupOverview(studentId, classId string) (*GroupOverview, error) {
+	if err := s.checkClassMember(classId, studentId); err != nil {
+		return nil, err
+	}
+	return s.buildOverview(classId, studentId)
+}
+
+// StudentCreateGroup 学生自建小组并成为组长（班级需开启自选小组）
+func (s *ClassGroupService) StudentCreateGroup(studentId, classId, name, description string) (*GroupView, error) {
+	class, err := s.getSelfEnrollClass(classId, studentId)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.newGroup(classId, name, description, class.GroupMaxSize)
+	if err != nil {
+		return nil, err
+	}
+	group.CreatorType = classModel.GroupCreatorStudent
+	group.CreatedBy = studentId
+
+	if err := s.checkAssignable(classId, []string{studentId}); err != nil {
+		return nil, err
+	}
+	if err := s.groupDAO.CreateGroup(group, newGroupMembers(classId, []string{studentId})); err != nil {
+		return nil, groupMemberWriteError("创建小组失败", err)
+	}
+	return s.getGroupView(group.GroupId)
+}
+
+// StudentJoinGroup 学生加入小组（班级需开启自选小组，且小组未满）
+func (s *ClassGroupService) StudentJoinGroup(studentId, groupId string) error {
+	group, err := s.groupDAO.GetGroupById(groupId)
+	if err != nil || group == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "小组不存在")
+	}
+	if _, err := s.getSelfEnrollClass(group.ClassId, studentId); err != nil {
+		return err
+	}
+
+	if group.MemberCount >= group.MaxSize {
+		return errs.NewCommonError(errs.ErrBadRequest, "该小组已满")
+	}
+	if err := s.checkAssignable(group.ClassId, []string{studentId}); err != nil {
+		return err
+	}
+	ok, err := s.groupDAO.AddMembers(groupId, newGroupMembers(group.ClassId, []string{studentId}))
+	if err != nil {
+		return groupMemberWriteError("加入小组失败", err)
+	}
+	if !ok {
+		return errs.NewCommonError(errs.ErrBadRequest, "该小组已满")
+	}
+	return nil
+}
+
+// StudentLeaveGroup 学生退出所在小组（班级需开启自选小组；组长退出时由最早加入的组员接任）
+func (s *ClassGroupService) StudentLeaveGroup(studentId, classId string) error {
+	if _, err := s.getSelfEnrollClass(classId, studentId); err != nil {
+		return err
+	}
+	member, err := s.groupDAO.GetMemberByStudentId(classId, studentId)
+	if err != nil || member == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "您尚未加入小组")
+	}
+	group, err := s.groupDAO.GetGroupById(member.GroupId)
+	if err != nil || group == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "小组不存在")
+	}
+	return s.removeMember(group, studentId)
+}
+
+// StudentUpdateGroup 组长修改小组名称与简介
+func (s *ClassGroupService) StudentUpdateGroup(studentId, classId, name, description string) error {
+	group, err := s.getLedGroup(studentId, classId)
+	if err != nil {
+		return err
+	}
+	updates, err := s.groupInfoUpdates(group, name, description)
+	if err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	if err := s.groupDAO.UpdateGroup(group.GroupId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新小组失败: "+err.Error())
+	}
+	return nil
+}
+
+// StudentTransferLeader 组长将组长身份转让给组内其他成员
+func (s *ClassGroupService) StudentTransferLeader(studentId, classId, newLeaderId string) error {
+	group, err := s.getLedGroup(studentId, classId)
+	if err != nil {
+		return err
+	}
+	if newLeaderId == studentId {
+		return errs.NewCommonError(errs.ErrBadRequest, "您已是组长")
+	}
+	return s.setLeader(group, newLeaderId)
+}
+
+// ==================== 供其他服务调用 ====================
+
+// GetStudentGroup 查询学生在班级内所属的小组（未分组返回 nil）
+func (s *ClassGroupService) GetStudentGroup(classId, studentId string) *classModel.ClassGroupMember {
+	member, err := s.groupDAO.GetMemberByStudentId(classId, studentId)
+	if err != nil {
+		return nil
+	}
+	return member
+}
+
+// ListGroupmateIds 查询学生所在小组的全部成员ID（含本人；未分组时只返回本人）
+func (s *ClassGroupService) ListGroupmateIds(classId, studentId string) []string {
+	member := s.GetStudentGroup(classId, studentId)
+	if member == nil {
+		return []string{studentId}
+	}
+	members, err := s.groupDAO.ListMembersByGroupId(member.GroupId)
+	if err != nil || len(members) == 0 {
+		return []string{studentId}
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.StudentId)
+	}
+	return ids
+}
+
+// ListGroupNames 查询班级内学生所属小组名称（student_id -> 小组名称）
+func (s *ClassGroupService) ListGroupNames(classId string) map[string]string {
+	result := make(map[string]string)
+	groups, err := s.groupDAO.ListGroupsByClassId(classId)
+	if err != nil || len(groups) == 0 {
+		return result
+	}
+	names := make(map[string]string, len(groups))
+	for _, g := range groups {
+		names[g.GroupId] = g.Name
+	}
+	members, err := s.groupDAO.ListMembersByClassId(classId)
+	if err != nil {
+		return result
+	}
+	for _, m := range members {
+		result[m.StudentId] = names[m.GroupId]
+	}
+	return result
+}
+
+// OnMemberLeft 学生退出或被移出班级后，将其移出所在小组
+func (s *ClassGroupService) OnMemberLeft(classId, studentId string) {
+	member, err := s.groupDAO.GetMemberByStudentId(classId, studentId)
+	if err != nil || member == nil {
+		return
+	}
+	group, err := s.groupDAO.GetGroupById(member.GroupId)
+	if err != nil || group == nil {
+		return
+	}
+	if err := s.removeMember(group, studentId); err != nil {
+		log.Printf("[ClassGroupService] 退出班级后移出小组失败: class_id=%s, student_id=%s, err=%v", classId, studentId, err)
+	}
+}
+
+// ==================== 内部工具 ====================
+
+// newGroup 校验参数并构造小组
+func (s *ClassGroupService) newGroup(classId, name, description string, maxSize int32) (*classModel.ClassGroup, error) {
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	if name == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "小组名称不能为空")
+	}
+	if len([]rune(name)) > groupNameMaxLen {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("小组名称不能超过%d字", groupNameMaxLen))
+	}
+	if len([]rune(description)) > groupDescMaxLen {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("小组简介不能超过%d字", groupDescMaxLen))
+	}
+	if maxSize <= 0 {
+		maxSize = defaultGroupMaxSize
+	}
+	if maxSize > groupMaxSizeLimit {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("小组人数上限需在 1-%d 之间", groupMaxSizeLimit))
+	}
+	if err := s.checkGroupName(classId, "", name); err != nil {
+		return nil, err
+	}
+	return &classModel.ClassGroup{
+		GroupId:     fmt.Sprintf("grp_%d", time.Now().UnixNano()),
+		ClassId:     classId,
+		Name:        name,
+		Description: description,
+		MaxSize:     maxSize,
+	}, nil
+}
+
+// groupInfoUpdates 校验并构造小组名称、简介的更新字段（空值表示不修改）
+func (s *ClassGroupService) groupInfoUpdates(group *classModel.ClassGroup, name, description string) (map[string]interface{}, error) {
+	updates := make(map[string]interface{})
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	if name != "" && name != group.Name {
+		if len([]rune(name)) > groupNameMaxLen {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("小组名称不能超过%d字", groupNameMaxLen))
+		}
+		if err := s.checkGroupName(group.ClassId, group.GroupId, name); err != nil {
+			return nil, err
+		}
+		updates["name"] = name
+	}
+	if description != "" {
+		if len([]rune(description)) > groupDescMaxLen {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("小组简介不能超过%d字", groupDescMaxLen))
+		}
+		updates["description"] = description
+	}
+	return updates, nil
+}
+
+// checkGroupName 校验小组名称在班级内不重复
+func (s *ClassGroupService) checkGroupName(classId, excludeGroupId, name string) error {
+	groups, err := s.groupDAO.ListGroupsByClassId(classId)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "查询小组失败: "+err.Error())
+	}
+	for _, g := range groups {
+		if g.GroupId != excludeGroupId && g.Name == name {
+			return errs.NewCommonError(errs.ErrBadRequest, "小组名称已存在")
+		}
+	}
+	return nil
+}
+
+// checkAssignable 校验学生均为班级成员且尚未加入小组（并发下以 class_group_member 的唯一键为准）
+func (s *ClassGroupService) checkAssignable(classId string, studentIds []string) error {
+	for _, studentId := range studentIds {
+		if err := s.checkClassMember(classId, studentId); err != nil {
+			return errs.NewCommonError(errs.ErrBadRequest, "学生 "+studentId+" 不是班级成员")
+		}
+		if member, err := s.groupDAO.GetMemberByStudentId(classId, studentId); err == nil && member != nil {
+			return errs.NewCommonError(errs.ErrBadRequest, "学生 "+studentId+" 已加入其他小组")
+		}
+	}
+	return nil
+}
+
+// checkClassMember 校验学生为班级正常成员
+func (s *ClassGroupService) checkClassMember(classId, studentId string) error {
+	member, err := s.classMemberDAO.GetMember(classId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	return nil
+}
+
+// getSelfEnrollClass 校验学生为班级成员、班级未归档且开启了自选小组
+func (s *ClassGroupService) getSelfEnrollClass(classId, studentId string) (*classModel.Class, error) {
+	if err := s.checkClassMember(classId, studentId); err != nil {
+		return nil, err
+	}
+	if err := s.staffService.CheckClassWritable(classId); err != nil {
+		return nil, err
+	}
+	class, err := s.classDAO.GetClassById(classId)
+	if err != nil || class == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	if !class.GroupSelfEnroll {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该班级未开放自选小组，请联系教师分组")
+	}
+	return class, nil
+}
+
+// getManagedGroup 查询小组并校验教师拥有班级管理权限
+func (s *ClassGroupService) getManagedGroup(teacherId, groupId string) (*classModel.ClassGroup, error) {
+	group, err := s.groupDAO.GetGroupById(groupId)
+	if err != nil || group == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "小组不存在")
+	}
+	if _, err := s.staffService.CheckWritePermission(group.ClassId, teacherId, consts.ClassPermClassManage); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// getLedGroup 查询学生担任组长的小组
+func (s *ClassGroupService) getLedGroup(studentId, classId string) (*classModel.ClassGroup, error) {
+	if err := s.checkClassMember(classId, studentId); err != nil {
+		return nil, err
+	}
+	if err := s.staffService.CheckClassWritable(classId); err != nil {
+		return nil, err
+	}
+	member, err := s.groupDAO.GetMemberByStudentId(classId, studentId)
+	if err != nil || member == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您尚未加入小组")
+	}
+	if member.Role != classModel.GroupRoleLeader {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "只有组长可以执行该操作")
+	}
+	group, err := s.groupDAO.GetGroupById(member.GroupId)
+	if err != nil || group == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "小组不存在")
+	}
+	return group, nil
+}
+
+// setLeader 将组内成员设为组长
+func (s *ClassGroupService) setLeader(group *classModel.ClassGroup, studentId string) error {
+	member, err := s.groupDAO.GetMemberByStudentId(group.ClassId, studentId)
+	if err != nil || member == nil || member.GroupId != group.GroupId {
+		return errs.NewCommonError(errs.ErrBadRequest, "该学生不在此小组中")
+	}
+	if err := s.groupDAO.SetLeader(group.GroupId, studentId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "设置组长失败: "+err.Error())
+	}
+	return nil
+}
+
+// removeMember 移出小组成员：组长离开时由最早加入的组员接任，学生自建的小组无人后自动解散
+func (s *ClassGroupService) removeMember(group *classModel.ClassGroup, studentId string) error {
+	if err := s.groupDAO.RemoveMember(group.GroupId, studentId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "移出小组失败: "+err.Error())
+	}
+	if group.CreatorType == classModel.GroupCreatorStudent {
+		if err := s.groupDAO.DeleteEmptyGroup(group.GroupId); err != nil {
+			log.Printf("[ClassGroupService] 解散空小组失败: group_id=%s, err=%v", group.GroupId, err)
+		}
+	}
+	return nil
+}
+
+// newGroupMembers 构造待写入的小组成员（小组ID与角色由 DAO 填充）
+func newGroupMembers(classId string, studentIds []string) []*classModel.ClassGroupMember {
+	members := make([]*classModel.ClassGroupMember, 0, len(studentIds))
+	for _, studentId := range studentIds {
+		members = append(members, &classModel.ClassGroupMember{ClassId: classId, StudentId: studentId})
+	}
+	return members
+}
+
+// groupMemberWriteError 转换小组成员写入失败的错误（唯一键冲突表示学生已被并发分入其他小组）
+func groupMemberWriteError(action string, err error) error {
+	if dao.IsDuplicateKeyError(err) {
+		return errs.NewCommonError(errs.ErrBadRequest, "学生已加入其他小组")
+	}
+	return errs.NewCommonError(errs.ErrInternal, action+": "+err.Error())
+}
+
+// getGroupView 查询单个小组视图
+func (s *ClassGroupService) getGroupView(groupId string) (*GroupView, error) {
+	group, err := s.groupDAO.GetGroupById(groupId)
+	if err != nil || group == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "小组不存在")
+	}
+	members, err := s.groupDAO.ListMembersByGroupId(groupId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询小组成员失败: "+err.Error())
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.StudentId)
+	}
+	info := s.loadStudentInfo(ids)
+	view := &GroupView{ClassGroup: group, Members: make([]*GroupMemberView, 0, len(members))}
+	for _, m := range members {
+		view.Members = append(view.Members, buildGroupMemberView(m, info))
+	}
+	return view, nil
+}
+
+// buildOverview 组装班级分组概览（viewerId 为学生时返回其所在小组ID）
+func (s *ClassGroupService) buildOverview(classId, viewerId string) (*GroupOverview, error) {
+	class, err := s.classDAO.GetClassById(classId)
+	if err != nil || class == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	groups, err := s.groupDAO.ListGroupsByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询小组失败: "+err.Error())
+	}
+	groupMembers, err := s.groupDAO.ListMembersByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询小组成员失败: "+err.Error())
+	}
+	classMembers, err := s.classMemberDAO.ListAllMembersByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	ids := make([]string, 0, len(classMembers))
+	for _, m := range classMembers {
+		ids = append(ids, m.StudentId)
+	}
+	info := s.loadStudentInfo(ids)
+
+	overview := &GroupOverview{
+		ClassId:      classId,
+		SelfEnroll:   class.GroupSelfEnroll,
+		GroupMaxSize: class.GroupMaxSize,
+		Groups:       make([]*GroupView, 0, len(groups)),
+		Ungrouped:    make([]*GroupMemberView, 0),
+	}
+	views := make(map[string]*GroupView, len(groups))
+	for _, g := range groups {
+		view := &GroupView{ClassGroup: g, Members: make([]*GroupMemberView, 0)}
+		views[g.GroupId] = view
+		overview.Groups = append(overview.Groups, view)
+	}
+	grouped := make(map[string]bool, len(groupMembers))
+	for _, m := range groupMembers {
+		view := views[m.GroupId]
+		if view == nil {
+			continue
+		}
+		grouped[m.StudentId] = true
+		view.Members = append(view.Members, buildGroupMemberView(m, info))
+		if m.StudentId == viewerId {
+			overview.MyGroupId = m.GroupId
+		}
+	}
+	for _, m := range classMembers {
+		if !grouped[m.StudentId] {
+			overview.Ungrouped = append(overview.Ungrouped, &GroupMemberView{
+				StudentId:     m.StudentId,
+				StudentName:   info[m.StudentId][0],
+				StudentNumber: info[m.StudentId][1],
+			})
+		}
+	}
+	return overview, nil
+}
+
+// loadStudentInfo 批量查询学生姓名与学号（student_id -> [姓名, 学号]）
+func (s *ClassGroupService) loadStudentInfo(studentIds []string) map[string][2]string {
+	info := make(map[string][2]string)
+	if len(studentIds) == 0 {
+		return info
+	}
+	students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds})
+	if err != nil {
+		return info
+	}
+	for _, st := range students {
+		info[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+	}
+	return info
+}
+
+// buildGroupMemberView 构造小组成员视图
+func buildGroupMemberView(m *classModel.ClassGroupMember, info map[string][2]string) *GroupMemberView {
+	return &GroupMemberView{
+		StudentId:     m.StudentId,
+		StudentName:   info[m.StudentId][0],
+		StudentNumber: info[m.StudentId][1],
+		Role:          m.Role,
+		JoinTime:      formatTime(m.JoinTime),
+	}
+}
+
+// uniqueStrings 去除空值与重复值（保持原顺序）
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,694 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	codeModel "github.com/yzf120/elysia-backend/model/code"
+)
+
+const (
+	defaultInsightFailThreshold = 5  // 判定卡题的失败提交次数
+	defaultInsightWindowMinutes = 30 // 判定卡题的时间窗口（分钟）
+	defaultInsightInactiveDays  = 7  // 判定停滞的无进展天数
+	defaultInsightDays          = 30 // 统计最近多少天的提交
+	maxInsightDays              = 180
+
+	insightTopErrors     = 5  // 每道题展示的常见错误数
+	insightTopProblems   = 10 // 展示的高错误率题目数
+	insightMinSubmits    = 5  // 参与错误率排名的最少提交次数
+	insightErrorMaxRunes = 160
+
+	classInsightDigestHour     = 8         // 每日摘要生成时间（点）
+	classInsightDigestInterval = time.Hour // 每日摘要任务检查间隔
+	classInsightDigestLockKey  = "class:insight:digest:%s"
+)
+
+// 卡题原因
+const (
+	StuckReasonRepeatedFailures = "repeated_failures" // 短时间内多次提交失败且尚未通过
+	StuckReasonInactive         = "inactive"          // 长时间没有学习进展
+)
+
+var (
+	errorPathPattern   = regexp.MustCompile(`(?:[A-Za-z]:)?[\w.\-]*[/\\][\w.\-/\\]+`)
+	errorQuotedPattern = regexp.MustCompile("'[^']*'|\"[^\"]*\"|‘[^’]*’|`[^`']*'")
+	errorNumberPattern = regexp.MustCompile(`\d+`)
+	errorSpacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// ClassInsightService 班级教学洞察服务（卡题学生识别、常见错误聚类、高错误率题目、每日摘要）
+type ClassInsightService struct {
+	classMemberDAO  dao.ClassMemberDAO
+	chapterDAO      dao.ChapterDAO
+	codeRunDAO      dao.CodeRunDAO
+	problemDAO      dao.ProblemDAO
+	discussionDAO   dao.DiscussionDAO
+	quizDAO         dao.QuizDAO
+	sectionViewDAO  dao.SectionViewDAO
+	insightDAO      dao.ClassInsightDAO
+	staffService    *ClassStaffService
+	progressService *ProgressService
+}
+
+// NewClassInsightService 创建班级教学洞察服务
+func NewClassInsightService() *ClassInsightService {
+	return &ClassInsightService{
+		classMemberDAO:  dao.NewClassMemberDAO(),
+		chapterDAO:      dao.NewChapterDAO(),
+		codeRunDAO:      dao.NewCodeRunDAO(),
+		problemDAO:      dao.NewProblemDAO(),
+		discussionDAO:   dao.NewDiscussionDAO(),
+		quizDAO:         dao.NewQuizDAO(),
+		sectionViewDAO:  dao.NewSectionViewDAO(),
+		insightDAO:      dao.NewClassInsightDAO(),
+		staffService:    NewClassStaffService(),
+		progressService: NewProgressService(),
+	}
+}
+
+// InsightOptions 洞察统计参数（为 0 时使用默认值）
+type InsightOptions struct {
+	FailThreshold int32 `json:"fail_threshold"` // 时间窗口内失败提交达到该次数视为卡题（默认 5）
+	WindowMinutes int32 `json:"window_minutes"` // 卡题判定的时间窗口（分钟，默认 30）
+	InactiveDays  int32 `json:"inactive_days"`  // 超过该天数没有学习活动视为停滞（默认 7）
+	Days          int32 `json:"days"`           // 统计最近多少天的提交（默认 30，最多 180）
+}
+
+// StuckStudent 可能遇到困难的学生
+type StuckStudent struct {
+	StudentId       string  `json:"student_id"`
+	StudentName     string  `json:"student_name"`
+	StudentNumber   string  `json:"student_number"`
+	Reason          string  `json:"reason"`      // repeated_failures / inactive
+	ReasonText      string  `json:"reason_text"` // 原因说明
+	SectionId       string  `json:"section_id"`  // 卡住的小节（repeated_failures 时）
+	SectionTitle    string  `json:"section_title"`
+	ProblemId       int64   `json:"problem_id"`
+	FailedCount     int32   `json:"failed_count"`     // 时间窗口内的失败提交次数
+	LastActiveTime  string  `json:"last_active_time"` // 最近一次提交时间（为空表示统计期内没有提交）
+	ProgressPercent float64 `json:"progress_percent"` // 班级学习进度
+}
+
+// ErrorCluster 一类相似的编译/运行错误
+type ErrorCluster struct {
+	Status       string `json:"status"`        // compile_error / runtime_error
+	Message      string `json:"message"`       // 归一化后的错误信息（路径、数字、引号内容已替换）
+	Example      string `json:"example"`       // 原始错误信息示例
+	Count        int32  `json:"count"`         // 出现次数
+	StudentCount int32  `json:"student_count"` // 涉及学生数
+}
+
+// ProblemErrorClusters 题目的常见错误
+type ProblemErrorClusters struct {
+	ProblemId    int64           `json:"problem_id"`
+	ProblemTitle string          `json:"problem_title"`
+	SectionId    string          `json:"section_id"`
+	SectionTitle string          `json:"section_title"`
+	TotalErrors  int32           `json:"total_errors"`
+	Clusters     []*ErrorCluster `json:"clusters"`
+}
+
+// ProblemFailureRate 题目的答案错误/超时比例
+type ProblemFailureRate struct {
+	ProblemId       int64   `json:"problem_id"`
+	ProblemTitle    string  `json:"problem_title"`
+	SectionId       string  `json:"section_id"`
+	SectionTitle    string  `json:"section_title"`
+	Submits         int32   `json:"submits"`  // 已评测的提交次数
+	Students        int32   `json:"students"` // 提交过的学生数
+	Accepted        int32   `json:"accepted"`
+	WrongAnswer     int32   `json:"wrong_answer"`
+	TimeLimit       int32   `json:"time_limit"`
+	WrongAnswerRate float64 `json:"wrong_answer_rate"` // 答案错误比例（0-100）
+	TimeLimitRate   float64 `json:"time_limit_rate"`   // 超时比例（0-100）
+	FailureRate     float64 `json:"failure_rate"`      // 答案错误与超时合计比例（0-100）
+}
+
+// ClassInsights 班级教学洞察
+type ClassInsights struct {
+	ClassId          string                  `json:"class_id"`
+	ClassName        string                  `json:"class_name"`
+	Options          InsightOptions          `json:"options"`
+	StuckStudents    []*StuckStudent         `json:"stuck_students"`
+	ErrorClusters    []*ProblemErrorClusters `json:"error_clusters"`
+	HardProblems     []*ProblemFailureRate   `json:"hard_problems"`
+	DigestSubscribed bool                    `json:"digest_subscribed"` // 当前教师是否订阅了每日摘要
+	GenerateTime     string                  `json:"generate_time"`
+}
+
+// GetClassInsights 查询班级教学洞察（需成绩查看权限）
+func (s *ClassInsightService) GetClassInsights(teacherId, classId string, opts InsightOptions) (*ClassInsights, error) {
+	cls, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermGradeView)
+	if err != nil {
+		return nil, err
+	}
+	insights, err := s.buildInsights(cls, normalizeInsightOptions(opts), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	insights.DigestSubscribed, _ = s.insightDAO.IsSubscribed(classId, teacherId)
+	return insights, nil
+}
+
+// SetDigestSubscription 订阅或取消订阅班级每日摘要（需成绩查看权限）
+func (s *ClassInsightService) SetDigestSubscription(teacherId, classId string, enabled bool) error {
+	if !enabled {
+		if err := s.insightDAO.Unsubscribe(classId, teacherId); err != nil {
+			return errs.NewCommonError(errs.ErrInternal, "取消订阅失败: "+err.Error())
+		}
+		return nil
+	}
+	if _, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermGradeView); err != nil {
+		return err
+	}
+	if err := s.insightDAO.Subscribe(classId, teacherId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "订阅失败: "+err.Error())
+	}
+	return nil
+}
+
+// ListDigests 分页查询教师收到的每日摘要，返回列表、总数与未读数
+func (s *ClassInsightService) ListDigests(teacherId string, unreadOnly bool, page, pageSize int32) ([]*classModel.ClassInsightDigest, int64, int64, error) {
+	if teacherId == "" {
+		return nil, 0, 0, errs.NewCommonError(errs.ErrBadRequest, "教师ID不能为空")
+	}
+	p, size := normalizePage(int(page), int(pageSize))
+	list, err := s.insightDAO.ListDigests(teacherId, unreadOnly, int32(size), int32((p-1)*size))
+	if err != nil {
+		return nil, 0, 0, errs.NewCommonError(errs.ErrInternal, "查询摘要失败: "+err.Error())
+	}
+	total, err := s.insightDAO.CountDigests(teacherId, unreadOnly)
+	if err != nil {
+		return nil, 0, 0, errs.NewCommonError(errs.ErrInternal, "统计摘要数量失败: "+err.Error())
+	}
+	unread, err := s.insightDAO.CountDigests(teacherId, true)
+	if err != nil {
+		return nil, 0, 0, errs.NewCommonError(errs.ErrInternal, "统计未读摘要失败: "+err.Error())
+	}
+	return list, total, unread, nil
+}
+
+// MarkDigestsRead 标记每日摘要已读（ids 为空表示全部）
+func (s *ClassInsightService) MarkDigestsRead(teacherId string, ids []int64) error {
+	if teacherId == "" {
+		return errs.NewCommonError(errs.ErrBadRequest, "教师ID不能为空")
+	}
+	if err := s.insightDAO.MarkDigestsRead(teacherId, ids); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "标记摘要已读失败: "+err.Error())
+	}
+	return nil
+}
+
+// ==================== 每日摘要任务 ====================
+
+// StartClassInsightDigestScheduler 启动每日摘要后台任务（每天 classInsightDigestHour 点后生成一次；多实例部署时通过 Redis 锁保证每天只执行一次）
+func StartClassInsightDigestScheduler() {
+	s := NewClassInsightService()
+	go func() {
+		ticker := time.NewTicker(classInsightDigestInterval)
+		defer ticker.Stop()
+		for {
+			s.runScheduled(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// runScheduled 到达生成时间且获取当天的执行锁后生成摘要
+func (s *ClassInsightService) runScheduled(now time.Time) {
+	if now.Hour() < classInsightDigestHour {
+		return
+	}
+	if redisClient := client.GetRedisClient(); redisClient != nil {
+		lockKey := fmt.Sprintf(classInsightDigestLockKey, now.Format("2006-01-02"))
+		ok, err := redisClient.Client.SetNX(context.Background(), lockKey, now.Unix(), 25*time.Hour).Result()
+		if err != nil {
+			log.Printf("[ClassInsight] 获取执行锁失败: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	created, err := s.RunDigestOnce(now)
+	if err != nil {
+		log.Printf("[ClassInsight] 生成每日摘要失败: %v", err)
+		return
+	}
+	if created > 0 {
+		log.Printf("[ClassInsight] 已生成每日摘要 %d 条", created)
+	}
+}
+
+// RunDigestOnce 为全部订阅生成当天的摘要（只处理进行中的班级，订阅者需仍有成绩查看权限，没有需要关注的内容时不生成）
+func (s *ClassInsightService) RunDigestOnce(now time.Time) (int, error) {
+	subs, err := s.insightDAO.ListAllSubscriptions()
+	if err != nil {
+		return 0, err
+	}
+	digestDate := now.Format("2006-01-02")
+	opts := normalizeInsightOptions(InsightOptions{})
+	insightsByClass := make(map[string]*ClassInsights)
+	created := 0
+	for _, sub := range subs {
+		cls, err := s.staffService.CheckPermission(sub.ClassId, sub.TeacherId, consts.ClassPermGradeView)
+		if err != nil || cls.Status != consts.ClassStatusOngoing {
+			continue
+		}
+		insights, ok := insightsByClass[cls.ClassId]
+		if !ok {
+			insights, err = s.buildInsights(cls, opts, now)
+			if err != nil {
+				log.Printf("[ClassInsight] 统计班级洞察失败: class_id=%s, err=%v", cls.ClassId, err)
+			}
+			insightsByClass[cls.ClassId] = insights
+		}
+		if insights == nil || len(insights.StuckStudents)+len(insights.HardProblems) == 0 {
+			continue
+		}
+		content, _ := json.Marshal(insights)
+		ok, err = s.insightDAO.CreateDigest(&classModel.ClassInsightDigest{
+			ClassId:    cls.ClassId,
+			TeacherId:  sub.TeacherId,
+			DigestDate: digestDate,
+			Summary:    digestSummary(insights),
+			Content:    string(content),
+		})
+		if err != nil {
+			log.Printf("[ClassInsight] 创建每日摘要失败: class_id=%s, teacher_id=%s, err=%v", cls.ClassId, sub.TeacherId, err)
+			continue
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// ==================== 统计 ====================
+
+// insightGroup 同一小节（题目）下的提交统计
+type insightGroup struct {
+	problemId int64
+	sectionId string
+	runs      []*codeModel.CodeRun
+}
+
+// buildInsights 统计班级教学洞察（不校验权限）
+func (s *ClassInsightService) buildInsights(cls *classModel.Class, opts InsightOptions, now time.Time) (*ClassInsights, error) {
+	statsSince := now.AddDate(0, 0, -int(opts.Days))
+	inactiveSince := now.AddDate(0, 0, -int(opts.InactiveDays))
+	loadSince := statsSince
+	if inactiveSince.Before(loadSince) {
+		loadSince = inactiveSince
+	}
+	runs, err := s.codeRunDAO.ListClassSubmitsSince(cls.ClassId, loadSince)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询提交记录失败: "+err.Error())
+	}
+	sections, err := s.chapterDAO.ListSectionsByClassId(cls.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询小节失败: "+err.Error())
+	}
+	sectionTitle := sectionTitles(sections)
+
+	groups := make(map[string]*insightGroup)
+	groupKeys := make([]string, 0)
+	lastActive := make(map[string]time.Time)
+	for _, run := range runs {
+		if run.CreatedAt.After(lastActive[run.StudentId]) {
+			lastActive[run.StudentId] = run.CreatedAt
+		}
+		if run.CreatedAt.Before(statsSince) {
+			continue
+		}
+		key := fmt.Sprintf("%s#%d", run.SectionId, run.ProblemId)
+		g, ok := groups[key]
+		if !ok {
+			g = &insightGroup{problemId: run.ProblemId, sectionId: run.SectionId}
+			groups[key] = g
+			groupKeys = append(groupKeys, key)
+		}
+		g.runs = append(g.runs, run)
+	}
+
+	problemTitles := make(map[int64]string)
+	problemTitle := func(id int64) string {
+		if title, ok := problemTitles[id]; ok {
+			return title
+		}
+		title := ""
+		if p, err := s.problemDAO.GetProblemById(id); err == nil && p != nil {
+			title = p.Title
+		}
+		problemTitles[id] = title
+		return title
+	}
+
+	insights := &ClassInsights{
+		ClassId:       cls.ClassId,
+		ClassName:     cls.ClassName,
+		Options:       opts,
+		StuckStudents: make([]*StuckStudent, 0),
+		ErrorClusters: make([]*ProblemErrorClusters, 0),
+		HardProblems:  make([]*ProblemFailureRate, 0),
+		GenerateTime:  formatTime(now),
+	}
+
+	for _, key := range groupKeys {
+		g := groups[key]
+		if clusters := clusterRunErrors(g.runs); clusters != nil {
+			clusters.ProblemId = g.problemId
+			clusters.ProblemTitle = problemTitle(g.problemId)
+			clusters.SectionId = g.sectionId
+			clusters.SectionTitle = sectionTitle[g.sectionId]
+			insights.ErrorClusters = append(insights.ErrorClusters, clusters)
+		}
+		if rate := problemFailureRate(g.runs); rate != nil && rate.Submits >= insightMinSubmits && rate.FailureRate > 0 {
+			rate.ProblemId = g.problemId
+			rate.ProblemTitle = problemTitle(g.problemId)
+			rate.SectionId = g.sectionId
+			rate.SectionTitle = sectionTitle[g.sectionId]
+			insights.HardProblems = append(insights.HardProblems, rate)
+		}
+	}
+	sort.SliceStable(insights.ErrorClusters, func(i, j int) bool {
+		return insights.ErrorClusters[i].TotalErrors > insights.ErrorClusters[j].TotalErrors
+	})
+	sort.SliceStable(insights.HardProblems, func(i, j int) bool {
+		return insights.HardProblems[i].FailureRate > insights.HardProblems[j].FailureRate
+	})
+	if len(insights.HardProblems) > insightTopProblems {
+		insights.HardProblems = insights.HardProblems[:insightTopProblems]
+	}
+
+	stuck, err := s.findStuckStudents(cls, opts, groups, groupKeys, lastActive, inactiveSince)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stuck {
+		if st.SectionId != "" {
+			st.SectionTitle = sectionTitle[st.SectionId]
+		}
+	}
+	insights.StuckStudents = stuck
+	return insights, nil
+}
+
+// findStuckStudents 识别可能遇到困难的学生：
+// 1. 同一题在 WindowMinutes 分钟内失败提交达到 FailThreshold 次，且之后没有通过；
+// 2. 加入班级已超过 InactiveDays 天，期间没有提交、测验、发帖或查看资源，且班级进度未完成
+func (s *ClassInsightService) findStuckStudents(cls *classModel.Class, opts InsightOptions, groups map[string]*insightGroup,
+	groupKeys []string, lastActive map[string]time.Time, inactiveSince time.Time) ([]*StuckStudent, error) {
+	members, err := s.classMemberDAO.ListAllMembersByClassId(cls.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	progressList, err := s.progressService.listMemberProgress(cls)
+	if err != nil {
+		return nil, err
+	}
+	progressMap := make(map[string]*MemberProgress, len(progressList))
+	for _, p := range progressList {
+		progressMap[p.StudentId] = p
+	}
+	memberSet := make(map[string]bool, len(members))
+	for _, m := range members {
+		memberSet[m.StudentId] = true
+	}
+
+	stuck := make([]*StuckStudent, 0)
+	newStuck := func(studentId, reason, reasonText string) *StuckStudent {
+		st := &StuckStudent{StudentId: studentId, Reason: reason, ReasonText: reasonText}
+		if p, ok := progressMap[studentId]; ok {
+			st.StudentName = p.StudentName
+			st.StudentNumber = p.StudentNumber
+			if p.ClassProgress != nil {
+				st.ProgressPercent = p.Percent
+			}
+		}
+		if t, ok := lastActive[studentId]; ok {
+			st.LastActiveTime = formatTime(t)
+		}
+		return st
+	}
+
+	window := time.Duration(opts.WindowMinutes) * time.Minute
+	for _, key := range groupKeys {
+		g := groups[key]
+		byStudent := make(map[string][]*codeModel.CodeRun)
+		order := make([]string, 0)
+		for _, run := range g.runs {
+			if !memberSet[run.StudentId] {
+				continue
+			}
+			if _, ok := byStudent[run.StudentId]; !ok {
+				order = append(order, run.StudentId)
+			}
+			byStudent[run.StudentId] = append(byStudent[run.StudentId], run)
+		}
+		for _, studentId := range order {
+			count, burstStart := maxFailureBurst(byStudent[studentId], window)
+			if count < opts.FailThreshold || acceptedSince(byStudent[studentId], burstStart) {
+				continue
+			}
+			st := newStuck(studentId, StuckReasonRepeatedFailures,
+				fmt.Sprintf("%d 分钟内失败提交 %d 次，尚未通过", opts.WindowMinutes, count))
+			st.SectionId = g.sectionId
+			st.ProblemId = g.problemId
+			st.FailedCount = count
+			stuck = append(stuck, st)
+		}
+	}
+
+	active := make(map[string]bool)
+	for studentId, t := range lastActive {
+		if !t.Before(inactiveSince) {
+			active[studentId] = true
+		}
+	}
+	for _, load := range []func(string, time.Time) (map[string]bool, error){
+		s.quizDAO.ListActiveStudentIdsSince,
+		s.discussionDAO.ListActiveStudentIdsSince,
+		s.sectionViewDAO.ListActiveStudentIdsSince,
+	} {
+		ids, err := load(cls.ClassId, inactiveSince)
+		if err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "查询学习活动失败: "+err.Error())
+		}
+		for id := range ids {
+			active[id] = true
+		}
+	}
+	for _, m := range members {
+		if active[m.StudentId] || m.JoinTime.After(inactiveSince) {
+			continue
+		}
+		p, ok := progressMap[m.StudentId]
+		if !ok || p.ClassProgress == nil || p.TotalSections == 0 || p.CompletedSections >= p.TotalSections {
+			continue
+		}
+		stuck = append(stuck, newStuck(m.StudentId, StuckReasonInactive,
+			fmt.Sprintf("超过 %d 天没有学习活动，进度 %.2f%%", opts.InactiveDays, p.Percent)))
+	}
+	return stuck, nil
+}
+
+// maxFailureBurst 返回时间窗口内失败提交的最多次数及该窗口的起始时间（runs 按提交时间升序）
+func maxFailureBurst(runs []*codeModel.CodeRun, window time.Duration) (int32, time.Time) {
+	failures := make([]time.Time, 0, len(runs))
+	for _, run := range runs {
+		if isFailedVerdict(run.Status) {
+			failures = append(failures, run.CreatedAt)
+		}
+	}
+	var best int32
+	var bestStart time.Time
+	left := 0
+	for right, t := range failures {
+		for t.Sub(failures[left]) > window {
+			left++
+		}
+		if count := int32(right - left + 1); count > best {
+			best = count
+			bestStart = failures[left]
+		}
+	}
+	return best, bestStart
+}
+
+// acceptedSince 判断学生在指定时间之后是否有通过的提交
+func acceptedSince(runs []*codeModel.CodeRun, since time.Time) bool {
+	for _, run := range runs {
+		if run.Status == "accepted" && !run.CreatedAt.Before(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFailedVerdict 判断评测结果是否为失败（评测中的记录不计入）
+func isFailedVerdict(status string) bool {
+	return status != "accepted" && status != "pending" && status != "running"
+}
+
+// clusterRunErrors 按归一化后的错误信息聚类编译错误与运行错误（没有错误时返回 nil）
+func clusterRunErrors(runs []*codeModel.CodeRun) *ProblemErrorClusters {
+	clusterMap := make(map[string]*ErrorCluster)
+	students := make(map[string]map[string]bool)
+	var total int32
+	for _, run := range runs {
+		if run.Status != "compile_error" && run.Status != "runtime_error" {
+			continue
+		}
+		message, example := normalizeErrorMessage(run.ErrorMsg)
+		if message == "" {
+			continue
+		}
+		key := run.Status + "#" + message
+		c, ok := clusterMap[key]
+		if !ok {
+			c = &ErrorCluster{Status: run.Status, Message: message, Example: example}
+			clusterMap[key] = c
+			students[key] = make(map[string]bool)
+		}
+		c.Count++
+		students[key][run.StudentId] = true
+		total++
+	}
+	if total == 0 {
+		return nil
+	}
+	clusters := make([]*ErrorCluster, 0, len(clusterMap))
+	for key, c := range clusterMap {
+		c.StudentCount = int32(len(students[key]))
+		clusters = append(clusters, c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Count != clusters[j].Count {
+			return clusters[i].Count > clusters[j].Count
+		}
+		return clusters[i].Message < clusters[j].Message
+	})
+	if len(clusters) > insightTopErrors {
+		clusters = clusters[:insightTopErrors]
+	}
+	return &ProblemErrorClusters{TotalErrors: total, Clusters: clusters}
+}
+
+// normalizeErrorMessage 取错误信息中的关键行（优先包含 error 的行），替换文件路径、引号内容与数字，返回归一化信息与原始示例
+func normalizeErrorMessage(raw string) (string, string) {
+	line := ""
+	for _, l := range strings.Split(raw, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if line == "" {
+			line = l
+		}
+		if strings.Contains(strings.ToLower(l), "error") {
+			line = l
+			break
+		}
+	}
+	if line == "" {
+		return "", ""
+	}
+	example := truncateRunes(line, insightErrorMaxRunes)
+	normalized := errorPathPattern.ReplaceAllString(line, "<file>")
+	normalized = errorQuotedPattern.ReplaceAllString(normalized, "'?'")
+	normalized = errorNumberPattern.ReplaceAllString(normalized, "N")
+	normalized = errorSpacePattern.ReplaceAllString(normalized, " ")
+	return truncateRunes(strings.TrimSpace(normalized), insightErrorMaxRunes), example
+}
+
+// problemFailureRate 统计题目的答案错误、超时比例（只统计已评测的提交）
+func problemFailureRate(runs []*codeModel.CodeRun) *ProblemFailureRate {
+	rate := &ProblemFailureRate{}
+	students := make(map[string]bool)
+	for _, run := range runs {
+		if run.Status == "pending" || run.Status == "running" {
+			continue
+		}
+		rate.Submits++
+		students[run.StudentId] = true
+		switch run.Status {
+		case "accepted":
+			rate.Accepted++
+		case "wrong_answer":
+			rate.WrongAnswer++
+		case "time_limit_exceeded":
+			rate.TimeLimit++
+		}
+	}
+	if rate.Submits == 0 {
+		return nil
+	}
+	rate.Students = int32(len(students))
+	rate.WrongAnswerRate = progressPercent(rate.WrongAnswer, rate.Submits)
+	rate.TimeLimitRate = progressPercent(rate.TimeLimit, rate.Submits)
+	rate.FailureRate = progressPercent(rate.WrongAnswer+rate.TimeLimit, rate.Submits)
+	return rate
+}
+
+// normalizeInsightOptions 填充统计参数的默认值
+func normalizeInsightOptions(opts InsightOptions) InsightOptions {
+	if opts.FailThreshold <= 0 {
+		opts.FailThreshold = defaultInsightFailThreshold
+	}
+	if opts.WindowMinutes <= 0 {
+		opts.WindowMinutes = defaultInsightWindowMinutes
+	}
+	if opts.InactiveDays <= 0 {
+		opts.InactiveDays = defaultInsightInactiveDays
+	}
+	if opts.Days <= 0 {
+		opts.Days = defaultInsightDays
+	}
+	if opts.Days > maxInsightDays {
+		opts.Days = maxInsightDays
+	}
+	if opts.InactiveDays > maxInsightDays {
+		opts.InactiveDays = maxInsightDays
+	}
+	return opts
+}
+
+// digestSummary 每日摘要文字
+func digestSummary(insights *ClassInsights) string {
+	parts := make([]string, 0, 2)
+	if n := len(insights.StuckStudents); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d 名学生可能遇到困难", n))
+	}
+	if n := len(insights.HardProblems); n > 0 {
+		top := insights.HardProblems[0]
+		title := top.SectionTitle
+		if title == "" {
+			title = top.ProblemTitle
+		}
+		parts = append(parts, fmt.Sprintf("%d 道题错误率较高（最高：%s，%.2f%%）", n, title, top.FailureRate))
+	}
+	return truncateRunes(insights.ClassName+"："+strings.Join(parts, "；"), 200)
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max]) + "…"
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	classCodeLength  = 6     // 班级验证码长度
+	inviteCodeLength = 8     // 邀请链接码长度（与班级验证码长度不同，避免冲突）
+	joinCodeRetry    = 5     // 生成唯一编码的最大重试次数
+	qrCodeScale      = 8     // 二维码每个模块的像素数
+	maxInviteUses    = 10000 // 邀请链接使用次数上限的最大值
+)
+
+// defaultClassJoinURL 默认加班页面地址（可通过环境变量 CLASS_JOIN_URL 覆盖）
+const defaultClassJoinURL = "http://localhost:5173/class/join"
+
+// ClassInviteService 班级邀请服务（班级验证码轮换、邀请链接与二维码）
+type ClassInviteService struct {
+	classDAO     dao.ClassDAO
+	staffService *ClassStaffService
+	inviteDAO    dao.ClassInviteDAO
+}
+
+// NewClassInviteService 创建班级邀请服务
+func NewClassInviteService() *ClassInviteService {
+	return &ClassInviteService{
+		classDAO:     dao.NewClassDAO(),
+		staffService: NewClassStaffService(),
+		inviteDAO:    dao.NewClassInviteDAO(),
+	}
+}
+
+// InviteView 邀请链接视图
+type InviteView struct {
+	*classModel.ClassInvite
+	JoinUrl     string `json:"join_url"`
+	QrCodeUrl   string `json:"qr_code_url"`
+	IsExpired   bool   `json:"is_expired"`
+	IsAvailable bool   `json:"is_available"` // 是否仍可用于加入班级
+}
+
+// ==================== 教师操作 ====================
+
+// RotateClassCode 轮换班级验证码（旧验证码立即失效）
+func (s *ClassInviteService) RotateClassCode(teacherId, classId string) (*classModel.Class, error) {
+	class, err := s.getOwnedClass(teacherId, classId)
+	if err != nil {
+		return nil, err
+	}
+	code, err := s.generateClassCode()
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{
+		"class_code":  code,
+		"qr_code_url": BuildClassQRCodeURL(code),
+	}
+	if err := s.classDAO.UpdateClass(classId, updates); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "更新班级验证码失败: "+err.Error())
+	}
+	class.ClassCode = code
+	class.QrCodeUrl = BuildClassQRCodeURL(code)
+	return class, nil
+}
+
+// CreateInvite 创建邀请链接（expireTime 为空表示不过期，maxUses 为 0 表示不限次数）
+func (s *ClassInviteService) CreateInvite(teacherId, classId, expireTime string, maxUses int32, remark string) (*InviteView, error) {
+	class, err := s.getOwnedClass(teacherId, classId)
+	if err != nil {
+		return nil, err
+	}
+	if class.Status != consts.ClassStatusOngoing {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级已结束或已归档")
+	}
+	if maxUses < 0 || maxUses > maxInviteUses {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "使用次数上限不合法")
+	}
+	expire, err := parseOptionalTime(expireTime)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "过期时间格式错误，应为 2006-01-02 15:04:05")
+	}
+	if expire != nil && !expire.After(time.Now()) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "过期时间必须晚于当前时间")
+	}
+	code, err := s.generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	invite := &classModel.ClassInvite{
+		InviteCode: code,
+		ClassId:    classId,
+		TeacherId:  teacherId,
+		ExpireTime: expire,
+		MaxUses:    maxUses,
+		Status:     consts.ClassInviteStatusActive,
+		Remark:     remark,
+	}
+	if err := s.inviteDAO.CreateInvite(invite); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "创建邀请链接失败: "+err.Error())
+	}
+	return buildInviteView(invite, time.Now()), nil
+}
+
+// ListInvites 查询班级邀请链接列表
+func (s *ClassInviteService) ListInvites(teacherId, classId string) ([]*InviteView, error) {
+	if _, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermClassManage); err != nil {
+		return nil, err
+	}
+	invites, err := s.inviteDAO.ListInvitesByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询邀请链接失败: "+err.Error())
+	}
+	now := time.Now()
+	views := make([]*InviteView, 0, len(invites))
+	for _, invite := range invites {
+		views = append(views, buildInviteView(invite, now))
+	}
+	return views, nil
+}
+
+// RevokeInvite 撤销邀请链接（已通过该链接加入的学生不受影响）
+func (s *ClassInviteService) RevokeInvite(teacherId, inviteCode string) error {
+	invite, err := s.inviteDAO.GetInviteByCode(inviteCode)
+	if err != nil || invite == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "邀请链接不存在")
+	}
+	if _, err := s.getOwnedClass(teacherId, invite.ClassId); err != nil {
+		return err
+	}
+	if invite.Status == consts.ClassInviteStatusRevoked {
+		return nil
+	}
+	if err := s.inviteDAO.UpdateInvite(inviteCode, map[string]interface{}{"status": consts.ClassInviteStatusRevoked}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "撤销邀请链接失败: "+err.Error())
+	}
+	return nil
+}
+
+// ==================== 加班校验与二维码 ====================
+
+// ResolveJoinCode 解析加班编码：先匹配班级验证码，再匹配邀请链接码（邀请链接需有效、未过期且未达使用上限）
+func (s *ClassInviteService) ResolveJoinCode(code string) (*classModel.Class, *classModel.ClassInvite, error) {
+	if code == "" {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "验证码不能为空")
+	}
+	if class, err := s.classDAO.GetClassByCode(code); err == nil && class != nil {
+		return class, nil, nil
+	}
+
+	invite, err := s.inviteDAO.GetInviteByCode(code)
+	if err != nil || invite == nil {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在或验证码错误")
+	}
+	if invite.Status != consts.ClassInviteStatusActive {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "邀请链接已失效")
+	}
+	if invite.ExpireTime != nil && !invite.ExpireTime.After(time.Now()) {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "邀请链接已过期")
+	}
+	if invite.MaxUses > 0 && invite.UsedCount >= invite.MaxUses {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "邀请链接使用次数已达上限")
+	}
+	class, err := s.classDAO.GetClassById(invite.ClassId)
+	if err != nil || class == nil {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在或验证码错误")
+	}
+	return class, invite, nil
+}
+
+// ConsumeInvite 占用一次邀请链接使用次数（并发下由数据库条件更新保证不超过上限）
+func (s *ClassInviteService) ConsumeInvite(inviteCode string) error {
+	ok, err := s.inviteDAO.IncrUsedCount(inviteCode)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新邀请链接使用次数失败: "+err.Error())
+	}
+	if !ok {
+		return errs.NewCommonError(errs.ErrBadRequest, "邀请链接已失效或使用次数已达上限")
+	}
+	return nil
+}
+
+// GenerateJoinQRCode 生成加班二维码 PNG（仅对当前可用的班级验证码或邀请链接生成）
+func (s *ClassInviteService) GenerateJoinQRCode(code string) ([]byte, error) {
+	if _, _, err := s.ResolveJoinCode(code); err != nil {
+		return nil, err
+	}
+	png, err := utils.GenerateQRCodePNG(BuildClassJoinURL(code), qrCodeScale)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "生成二维码失败: "+err.Error())
+	}
+	return png, nil
+}
+
+// BuildClassJoinURL 构造加班页面链接（二维码内容）
+func BuildClassJoinURL(code string) string {
+	base := os.Getenv("CLASS_JOIN_URL")
+	if base == "" {
+		base = defaultClassJoinURL
+	}
+	return base + "?code=" + url.QueryEscape(code)
+}
+
+// BuildClassQRCodeURL 构造加班二维码图片地址（公开接口）
+func BuildClassQRCodeURL(code string) string {
+	return "/api/class/qrcode?code=" + url.QueryEscape(code)
+}
+
+// ==================== 内部工具 ====================
+
+// getOwnedClass 查询班级并校验教师拥有班级管理权限且班级未归档
+func (s *ClassInviteService) getOwnedClass(teacherId, classId string) (*classModel.Class, error) {
+	return s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermClassManage)
+}
+
+// generateClassCode 生成未被占用的班级验证码
+func (s *ClassInviteService) generateClassCode() (string, error) {
+	for i := 0; i < joinCodeRetry; i++ {
+		code, err := randomJoinCode(classCodeLength)
+		if err != nil {
+			return "", errs.NewCommonError(errs.ErrInternal, "生成班级验证码失败: "+err.Error())
+		}
+		_, err = s.classDAO.GetClassByCode(code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return code, nil
+		}
+		if err != nil {
+			return "", errs.NewCommonError(errs.ErrInternal, "校验班级验证码失败: "+err.Error())
+		}
+	}
+	return "", errs.NewCommonError(errs.ErrInternal, "生成班级验证码失败，请重试")
+}
+
+// generateInviteCode 生成未被占用的邀请链接码
+func (s *ClassInviteService) generateInviteCode() (string, error) {
+	for i := 0; i < joinCodeRetry; i++ {
+		code, err := randomJoinCode(inviteCodeLength)
+		if err != nil {
+			return "", errs.NewCommonError(errs.ErrInternal, "生成邀请链接失败: "+err.Error())
+		}
+		_, err = s.inviteDAO.GetInviteByCode(code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return code, nil
+		}
+		if err != nil {
+			return "", errs.NewCommonError(errs.ErrInternal, "校验邀请链接码失败: "+err.Error())
+		}
+	}
+	return "", errs.NewCommonError(errs.ErrInternal, "生成邀请链接失败，请重试")
+}
+
+// randomJoinCode 生成指定长度的随机编码（大写字母+数字，去除易混淆的 0/O/1/I）
+func randomJoinCode(length int) (string, error) {
+	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = charset[int(b[i])%len(charset)]
+	}
+	return string(b), nil
+}
+
+// buildInviteView 构造邀请链接视图
+func buildInviteView(invite *classModel.ClassInvite, now time.Time) *InviteView {
+	expired := invite.ExpireTime != nil && !invite.ExpireTime.After(now)
+	exhausted := invite.MaxUses > 0 && invite.UsedCount >= invite.MaxUses
+	return &InviteView{
+		ClassInvite: invite,
+		JoinUrl:     BuildClassJoinURL(invite.InviteCode),
+		QrCodeUrl:   BuildClassQRCodeURL(invite.InviteCode),
+		IsExpired:   expired,
+		IsAvailable: invite.Status == consts.ClassInviteStatusActive && !expired && !exhausted,
+	}
+}
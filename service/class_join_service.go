@@ -0,0 +1,405 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+)
+
+// 加入班级结果
+const (
+	JoinResultJoined     = "joined"     // 已加入班级
+	JoinResultPending    = "pending"    // 已提交申请，等待教师审批
+	JoinResultWaitlisted = "waitlisted" // 班级已满，已进入候补队列
+)
+
+// waitlistHandler 候补自动递补的处理人标识
+const waitlistHandler = "system"
+
+// ClassJoinService 班级加入服务（成员写入、加入审批与满员候补）
+type ClassJoinService struct {
+	classDAO       dao.ClassDAO
+	staffService   *ClassStaffService
+	classMemberDAO dao.ClassMemberDAO
+	joinRequestDAO dao.ClassJoinRequestDAO
+	studentDAO     dao.StudentDAO
+	inviteService  *ClassInviteService
+}
+
+// NewClassJoinService 创建班级加入服务
+func NewClassJoinService() *ClassJoinService {
+	return &ClassJoinService{
+		classDAO:       dao.NewClassDAO(),
+		staffService:   NewClassStaffService(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		joinRequestDAO: dao.NewClassJoinRequestDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+		inviteService:  NewClassInviteService(),
+	}
+}
+
+// JoinRequestView 加入申请视图
+type JoinRequestView struct {
+	*classModel.ClassJoinRequest
+	StudentName      string `json:"student_name,omitempty"`
+	StudentNumber    string `json:"student_number,omitempty"`
+	ClassName        string `json:"class_name,omitempty"`
+	WaitlistPosition int32  `json:"waitlist_position,omitempty"` // 候补排位（从 1 开始，仅候补中有效）
+}
+
+// ==================== 学生操作 ====================
+
+// Join 按班级加入策略处理加入：开放班级直接加入（满员时进入候补），审批班级提交申请，关闭班级拒绝加入
+// 调用方需已校验班级状态、学生身份及是否已是成员
+func (s *ClassJoinService) Join(class *classModel.Class, invite *classModel.ClassInvite, studentId, code, message string) (string, error) {
+	if class.JoinPolicy == consts.ClassJoinPolicyClosed {
+		return "", errs.NewCommonError(errs.ErrBadRequest, "该班级已关闭加入")
+	}
+
+	// 已有待处理申请时不重复提交
+	if open, err := s.joinRequestDAO.GetOpenRequest(class.ClassId, studentId); err == nil && open != nil {
+		if open.Status == consts.ClassJoinRequestWaitlisted {
+			return "", errs.NewCommonError(errs.ErrBadRequest, "已在候补队列中，请耐心等待")
+		}
+		return "", errs.NewCommonError(errs.ErrBadRequest, "已提交加入申请，请等待教师审批")
+	}
+
+	// 先确定处理方式，再占用邀请链接次数
+	result := JoinResultJoined
+	if class.JoinPolicy == consts.ClassJoinPolicyApproval {
+		result = JoinResultPending
+	} else if class.CurrentStudents >= class.MaxStudents {
+		if !class.WaitlistEnabled {
+			return "", errs.NewCommonError(errs.ErrBadRequest, "班级人数已满")
+		}
+		result = JoinResultWaitlisted
+	}
+
+	inviteCode := ""
+	if invite != nil {
+		inviteCode = invite.InviteCode
+	}
+	// 直接加入时邀请次数在成员写入事务内占用，其余情况先占用再创建申请
+	if inviteCode != "" && result != JoinResultJoined {
+		if err := s.inviteService.ConsumeInvite(inviteCode); err != nil {
+			return "", err
+		}
+	}
+
+	switch result {
+	case JoinResultPending:
+		if err := s.createRequest(class.ClassId, studentId, code, message, consts.ClassJoinRequestPending); err != nil {
+			return "", err
+		}
+	case JoinResultWaitlisted:
+		if err := s.createRequest(class.ClassId, studentId, code, message, consts.ClassJoinRequestWaitlisted); err != nil {
+			return "", err
+		}
+	default:
+		if err := s.EnrollStudent(class, studentId, code, inviteCode); err != nil {
+			return "", err
+		}
+	}
+	return result, nil
+}
+
+// CancelRequest 学生撤回待审批或候补中的申请
+func (s *ClassJoinService) CancelRequest(studentId, requestId string) error {
+	r, err := s.joinRequestDAO.GetRequestById(requestId)
+	if err != nil || r == nil || r.StudentId != studentId {
+		return errs.NewCommonError(errs.ErrBadRequest, "申请不存在")
+	}
+	now := time.Now()
+	ok, err := s.joinRequestDAO.UpdateRequestIfStatus(requestId,
+		[]int32{consts.ClassJoinRequestPending, consts.ClassJoinRequestWaitlisted},
+		map[string]interface{}{"status": consts.ClassJoinRequestCancelled, "handled_by": studentId, "handle_time": now})
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "撤回申请失败: "+err.Error())
+	}
+	if !ok {
+		return errs.NewCommonError(errs.ErrBadRequest, "申请已处理，无法撤回")
+	}
+	return nil
+}
+
+// ListStudentRequests 查询学生的加入申请（候补中的申请带排位）
+func (s *ClassJoinService) ListStudentRequests(studentId string) ([]*JoinRequestView, error) {
+	list, err := s.joinRequestDAO.ListRequestsByStudentId(studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询加入申请失败: "+err.Error())
+	}
+	views := make([]*JoinRequestView, 0, len(list))
+	classNames := make(map[string]string)
+	for _, r := range list {
+		view := &JoinRequestView{ClassJoinRequest: r}
+		if name, ok := classNames[r.ClassId]; ok {
+			view.ClassName = name
+		} else if c, err := s.classDAO.GetClassById(r.ClassId); err == nil && c != nil {
+			classNames[r.ClassId] = c.ClassName
+			view.ClassName = c.ClassName
+		}
+		if r.Status == consts.ClassJoinRequestWaitlisted {
+			if ahead, err := s.joinRequestDAO.CountWaitlistAhead(r); err == nil {
+				view.WaitlistPosition = int32(ahead) + 1
+			}
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// ==================== 教师操作 ====================
+
+// SetJoinPolicy 设置班级加入策略与候补开关
+func (s *ClassJoinService) SetJoinPolicy(teacherId, classId string, policy int32, waitlistEnabled bool) error {
+	if policy != consts.ClassJoinPolicyOpen && policy != consts.ClassJoinPolicyApproval && policy != consts.ClassJoinPolicyClosed {
+		return errs.NewCommonError(errs.ErrBadRequest, "加入策略不合法")
+	}
+	if _, err := s.getOwnedClass(teacherId, classId); err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"join_policy":      policy,
+		"waitlist_enabled": waitlistEnabled,
+	}
+	if err := s.classDAO.UpdateClass(classId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新加入策略失败: "+err.Error())
+	}
+	return nil
+}
+
+// ListClassRequests 分页查询班级加入申请（status 为 -1 时查询全部）
+func (s *ClassJoinService) ListClassRequests(teacherId, classId string, status int32, page, pageSize int) ([]*JoinRequestView, int64, error) {
+	if _, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermClassManage); err != nil {
+		return nil, 0, err
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	list, err := s.joinRequestDAO.ListRequestsByClassId(classId, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "查询加入申请失败: "+err.Error())
+	}
+	total, err := s.joinRequestDAO.CountRequestsByClassId(classId, status)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "统计加入申请失败: "+err.Error())
+	}
+
+	// 候补排位按整个候补队列计算
+	positions := make(map[string]int32)
+	if status < 0 || status == consts.ClassJoinRequestWaitlisted {
+		if waitlist, err := s.joinRequestDAO.ListWaitlist(classId, 0); err == nil {
+			for i, r := range waitlist {
+				positions[r.RequestId] = int32(i + 1)
+			}
+		}
+	}
+
+	studentIds := make([]string, 0, len(list))
+	for _, r := range list {
+		studentIds = append(studentIds, r.StudentId)
+	}
+	students := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		if stus, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds}); err == nil {
+			for _, stu := range stus {
+				students[stu.StudentId] = [2]string{stu.StudentName, stu.StudentNumber}
+			}
+		}
+	}
+
+	views := make([]*JoinRequestView, 0, len(list))
+	for _, r := range list {
+		info := students[r.StudentId]
+		views = append(views, &JoinRequestView{
+			ClassJoinRequest: r,
+			StudentName:      info[0],
+			StudentNumber:    info[1],
+			WaitlistPosition: positions[r.RequestId],
+		})
+	}
+	return views, total, nil
+}
+
+// ApproveRequest 通过加入申请；班级已满时转入候补队列（未开启候补则提示先调整人数上限），返回处理结果
+func (s *ClassJoinService) ApproveRequest(teacherId, requestId string) (string, error) {
+	r, err := s.joinRequestDAO.GetRequestById(requestId)
+	if err != nil || r == nil {
+		return "", errs.NewCommonError(errs.ErrBadRequest, "申请不存在")
+	}
+	class, err := s.getOwnedClass(teacherId, r.ClassId)
+	if err != nil {
+		return "", err
+	}
+	if r.Status != consts.ClassJoinRequestPending {
+		return "", errs.NewCommonError(errs.ErrBadRequest, "申请已处理")
+	}
+	if class.Status != consts.ClassStatusOngoing {
+		return "", errs.NewCommonError(errs.ErrBadRequest, "班级已结束或已归档")
+	}
+
+	now := time.Now()
+	member, _ := s.classMemberDAO.GetMember(class.ClassId, r.StudentId)
+	alreadyMember := member != nil && member.Status == consts.ClassMemberStatusActive
+
+	if !alreadyMember && class.CurrentStudents >= class.MaxStudents {
+		if !class.WaitlistEnabled {
+			return "", errs.NewCommonError(errs.ErrBadRequest, "班级人数已满，请先调整人数上限或开启候补")
+		}
+		ok, err := s.joinRequestDAO.UpdateRequestIfStatus(requestId, []int32{consts.ClassJoinRequestPending},
+			map[string]interface{}{"status": consts.ClassJoinRequestWaitlisted, "waitlist_time": now, "handled_by": teacherId, "handle_time": now})
+		if err != nil {
+			return "", errs.NewCommonError(errs.ErrInternal, "处理申请失败: "+err.Error())
+		}
+		if !ok {
+			return "", errs.NewCommonError(errs.ErrBadRequest, "申请已处理")
+		}
+		return JoinResultWaitlisted, nil
+	}
+
+	ok, err := s.joinRequestDAO.UpdateRequestIfStatus(requestId, []int32{consts.ClassJoinRequestPending},
+		map[string]interface{}{"status": consts.ClassJoinRequestApproved, "handled_by": teacherId, "handle_time": now})
+	if err != nil {
+		return "", errs.NewCommonError(errs.ErrInternal, "处理申请失败: "+err.Error())
+	}
+	if !ok {
+		return "", errs.NewCommonError(errs.ErrBadRequest, "申请已处理")
+	}
+	if !alreadyMember {
+		if err := s.EnrollStudent(class, r.StudentId, r.InviteCode, ""); err != nil {
+			// 写入失败（如名额已被占满）时恢复为待审批
+			_, _ = s.joinRequestDAO.UpdateRequestIfStatus(requestId, []int32{consts.ClassJoinRequestApproved},
+				map[string]interface{}{"status": consts.ClassJoinRequestPending, "handled_by": "", "handle_time": nil})
+			return "", err
+		}
+	}
+	return JoinResultJoined, nil
+}
+
+// RejectRequest 拒绝加入申请（待审批或候补中的申请均可拒绝）
+func (s *ClassJoinService) RejectRequest(teacherId, requestId, reason string) error {
+	r, err := s.joinRequestDAO.GetRequestById(requestId)
+	if err != nil || r == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "申请不存在")
+	}
+	if _, err := s.getOwnedClass(teacherId, r.ClassId); err != nil {
+		return err
+	}
+	ok, err := s.joinRequestDAO.UpdateRequestIfStatus(requestId,
+		[]int32{consts.ClassJoinRequestPending, consts.ClassJoinRequestWaitlisted},
+		map[string]interface{}{"status": consts.ClassJoinRequestRejected, "reject_reason": reason, "handled_by": teacherId, "handle_time": time.Now()})
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "处理申请失败: "+err.Error())
+	}
+	if !ok {
+		return errs.NewCommonError(errs.ErrBadRequest, "申请已处理")
+	}
+	return nil
+}
+
+// ==================== 成员写入与候补递补 ====================
+
+// EnrollStudent 将学生写入班级成员并更新班级人数（曾退出的学生恢复成员状态）
+// 名额以数据库条件更新为准；inviteCode 不为空时在同一事务内占用一次邀请链接使用次数
+func (s *ClassJoinService) EnrollStudent(class *classModel.Class, studentId, code, inviteCode string) error {
+	member := &classModel.ClassMember{
+		ClassId:    class.ClassId,
+		StudentId:  studentId,
+		Status:     consts.ClassMemberStatusActive,
+		InviteCode: code,
+	}
+	result, err := s.classMemberDAO.EnrollMember(member, inviteCode)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "加入班级失败: "+err.Error())
+	}
+	switch result {
+	case dao.EnrollResultClassFull:
+		return errs.NewCommonError(errs.ErrBadRequest, "班级人数已满")
+	case dao.EnrollResultInviteInvalid:
+		return errs.NewCommonError(errs.ErrBadRequest, "邀请链接已失效或使用次数已达上限")
+	}
+	class.CurrentStudents++
+	return nil
+}
+
+// PromoteWaitlist 班级有空余名额时按候补先后依次递补，返回递补人数
+func (s *ClassJoinService) PromoteWaitlist(classId string) (int, error) {
+	class, err := s.classDAO.GetClassById(classId)
+	if err != nil || class == nil {
+		return 0, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	if class.Status != consts.ClassStatusOngoing {
+		return 0, nil
+	}
+
+	promoted := 0
+	for class.CurrentStudents < class.MaxStudents {
+		next, err := s.joinRequestDAO.ListWaitlist(classId, 1)
+		if err != nil {
+			return promoted, errs.NewCommonError(errs.ErrInternal, "查询候补队列失败: "+err.Error())
+		}
+		if len(next) == 0 {
+			break
+		}
+		r := next[0]
+		ok, err := s.joinRequestDAO.UpdateRequestIfStatus(r.RequestId, []int32{consts.ClassJoinRequestWaitlisted},
+			map[string]interface{}{"status": consts.ClassJoinRequestApproved, "handled_by": waitlistHandler, "handle_time": time.Now()})
+		if err != nil {
+			return promoted, errs.NewCommonError(errs.ErrInternal, "递补候补学生失败: "+err.Error())
+		}
+		if !ok {
+			continue // 已被其他请求处理（撤回/拒绝/递补）
+		}
+		member, _ := s.classMemberDAO.GetMember(classId, r.StudentId)
+		if member != nil && member.Status == consts.ClassMemberStatusActive {
+			continue
+		}
+		if err := s.EnrollStudent(class, r.StudentId, r.InviteCode, ""); err != nil {
+			// 写入失败（如名额已被并发占满）时放回候补队列
+			_, _ = s.joinRequestDAO.UpdateRequestIfStatus(r.RequestId, []int32{consts.ClassJoinRequestApproved},
+				map[string]interface{}{"status": consts.ClassJoinRequestWaitlisted})
+			return promoted, err
+		}
+		promoted++
+	}
+	return promoted, nil
+}
+
+// ==================== 内部工具 ====================
+
+// createRequest 创建加入申请（候补申请记录进入队列时间）
+func (s *ClassJoinService) createRequest(classId, studentId, code, message string, status int32) error {
+	r := &classModel.ClassJoinRequest{
+		RequestId:  fmt.Sprintf("cjr_%d", time.Now().UnixNano()),
+		ClassId:    classId,
+		StudentId:  studentId,
+		InviteCode: code,
+		Message:    message,
+		Status:     status,
+	}
+	if status == consts.ClassJoinRequestWaitlisted {
+		now := time.Now()
+		r.WaitlistTime = &now
+	}
+	if err := s.joinRequestDAO.CreateRequest(r); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "提交加入申请失败: "+err.Error())
+	}
+	return nil
+}
+
+// closeOpenRequest 学生被教师直接加入班级后，将其待审批/候补中的申请标记为已通过
+func (s *ClassJoinService) closeOpenRequest(classId, studentId, handledBy string) {
+	open, err := s.joinRequestDAO.GetOpenRequest(classId, studentId)
+	if err != nil || open == nil {
+		return
+	}
+	s.joinRequestDAO.UpdateRequestIfStatus(open.RequestId, []int32{open.Status},
+		map[string]interface{}{"status": consts.ClassJoinRequestApproved, "handled_by": handledBy, "handle_time": time.Now()})
+}
+
+// getOwnedClass 查询班级并校验教师拥有班级管理权限且班级未归档
+func (s *ClassJoinService) getOwnedClass(teacherId, classId string) (*classModel.Class, error) {
+	return s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermClassManage)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	subjectModel "github.com/yzf120/elysia-backend/model/subject"
+)
+
+const (
+	defaultClassArchiveGraceDays = 30        // 学期结束后归档班级的默认宽限天数（可通过环境变量 CLASS_ARCHIVE_GRACE_DAYS 覆盖）
+	classLifecycleInterval       = time.Hour // 班级生命周期任务执行间隔
+	classLifecycleLockKey        = "class:lifecycle:lock"
+)
+
+// ClassLifecycleService 班级生命周期服务（按学期日期自动结束、归档班级）
+type ClassLifecycleService struct {
+	classDAO    dao.ClassDAO
+	semesterDAO dao.SemesterDAO
+}
+
+// NewClassLifecycleService 创建班级生命周期服务
+func NewClassLifecycleService() *ClassLifecycleService {
+	return &ClassLifecycleService{
+		classDAO:    dao.NewClassDAO(),
+		semesterDAO: dao.NewSemesterDAO(),
+	}
+}
+
+// StartClassLifecycleScheduler 启动班级生命周期后台任务（启动时立即执行一次，之后按固定间隔执行；多实例部署时通过 Redis 锁保证同一周期只执行一次）
+func StartClassLifecycleScheduler() {
+	s := NewClassLifecycleService()
+	go func() {
+		ticker := time.NewTicker(classLifecycleInterval)
+		defer ticker.Stop()
+		for {
+			s.runScheduled()
+			<-ticker.C
+		}
+	}()
+}
+
+// runScheduled 获取执行锁后执行一次状态流转
+func (s *ClassLifecycleService) runScheduled() {
+	if redisClient := client.GetRedisClient(); redisClient != nil {
+		ok, err := redisClient.Client.SetNX(context.Background(), classLifecycleLockKey, time.Now().Unix(), classLifecycleInterval-time.Minute).Result()
+		if err != nil {
+			log.Printf("[ClassLifecycle] 获取执行锁失败: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	ended, archived, err := s.RunOnce(time.Now())
+	if err != nil {
+		log.Printf("[ClassLifecycle] 班级状态流转失败: %v", err)
+		return
+	}
+	if ended > 0 || archived > 0 {
+		log.Printf("[ClassLifecycle] 已结束班级 %d 个，已归档班级 %d 个", ended, archived)
+	}
+}
+
+// RunOnce 执行一次状态流转：学期结束日期已过的进行中班级置为已结束，超过宽限期的已结束班级置为已归档
+func (s *ClassLifecycleService) RunOnce(now time.Time) (int64, int64, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	endedSemesters, err := s.semesterDAO.ListSemestersEndedBefore(today)
+	if err != nil {
+		return 0, 0, err
+	}
+	ended, err := s.classDAO.UpdateClassStatusBySemesters(semesterNames(endedSemesters), consts.ClassStatusOngoing, consts.ClassStatusEnded)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	archiveSemesters, err := s.semesterDAO.ListSemestersEndedBefore(today.AddDate(0, 0, -classArchiveGraceDays()))
+	if err != nil {
+		return ended, 0, err
+	}
+	archived, err := s.classDAO.UpdateClassStatusBySemesters(semesterNames(archiveSemesters), consts.ClassStatusEnded, consts.ClassStatusArchived)
+	if err != nil {
+		return ended, 0, err
+	}
+	return ended, archived, nil
+}
+
+// GetCurrentSemester 按日期解析当前学期：优先取日期所在学期，处于假期时取下一个即将开始的学期，均不存在时取最近结束的学期
+func (s *ClassLifecycleService) GetCurrentSemester(now time.Time) (*subjectModel.Semester, error) {
+	if semester, err := s.semesterDAO.GetSemesterByDate(now); err == nil && semester != nil {
+		return semester, nil
+	}
+	semesters, err := s.semesterDAO.ListSemesters(1)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询学期列表失败: "+err.Error())
+	}
+	var upcoming, latest *subjectModel.Semester
+	for _, sem := range semesters {
+		if sem.StartDate.After(now) {
+			if upcoming == nil || sem.StartDate.Before(upcoming.StartDate) {
+				upcoming = sem
+			}
+		} else if latest == nil || sem.EndDate.After(latest.EndDate) {
+			latest = sem
+		}
+	}
+	if upcoming != nil {
+		return upcoming, nil
+	}
+	if latest != nil {
+		return latest, nil
+	}
+	return nil, errs.NewCommonError(errs.ErrBadRequest, "暂无可用学期")
+}
+
+// checkClassWritable 校验班级未归档（归档班级只读）
+func checkClassWritable(class *classModel.Class) error {
+	if class.Status == consts.ClassStatusArchived {
+		return errs.NewCommonError(errs.ErrBadRequest, "班级已归档，仅支持查看")
+	}
+	return nil
+}
+
+// classArchiveGraceDays 读取归档宽限天数
+func classArchiveGraceDays() int {
+	if days, err := strconv.Atoi(os.Getenv("CLASS_ARCHIVE_GRACE_DAYS")); err == nil && days >= 0 {
+		return days
+	}
+	return defaultClassArchiveGraceDays
+}
+
+// semesterNames 提取学期名称列表（班级按学期名称关联学期）
+func semesterNames(semesters []*subjectModel.Semester) []string {
+	names := make([]string, 0, len(semesters))
+	for _, sem := range semesters {
+		names = append(names, sem.SemesterName)
+	}
+	return names
+}
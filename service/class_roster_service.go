@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/model/student"
+)
+
+// 名单导入行处理结果
+const (
+	RosterRowEnrolled      = "enrolled"       // 已加入班级
+	RosterRowAlreadyMember = "already_member" // 已是班级成员
+	RosterRowNotFound      = "not_found"      // 未找到学生
+	RosterRowInvalid       = "invalid"        // 数据不合法
+	RosterRowClassFull     = "class_full"     // 班级人数已满
+)
+
+// rosterMaxRows 单次导入的最大数据行数
+const rosterMaxRows = 2000
+
+// rosterPhonePattern 手机号格式
+var rosterPhonePattern = regexp.MustCompile(`^1\d{10}$`)
+
+// 名单表头别名（不区分大小写），未识别到表头时按 学号、姓名、手机号 的列顺序读取
+var (
+	rosterNumberHeaders = []string{"学号", "student_number", "number"}
+	rosterNameHeaders   = []string{"姓名", "student_name", "name"}
+	rosterPhoneHeaders  = []string{"手机号", "手机", "phone_number", "phone"}
+)
+
+// ClassRosterService 班级名单导入服务
+type ClassRosterService struct {
+	classDAO       dao.ClassDAO
+	staffService   *ClassStaffService
+	classMemberDAO dao.ClassMemberDAO
+	studentDAO     dao.StudentDAO
+	joinService    *ClassJoinService
+}
+
+// NewClassRosterService 创建班级名单导入服务
+func NewClassRosterService() *ClassRosterService {
+	return &ClassRosterService{
+		classDAO:       dao.NewClassDAO(),
+		staffService:   NewClassStaffService(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+		joinService:    NewClassJoinService(),
+	}
+}
+
+// RosterRowResult 名单导入单行结果
+type RosterRowResult struct {
+	RowNo         int    `json:"row_no"` // 文件中的行号（从 1 开始，含表头）
+	StudentNumber string `json:"student_number"`
+	StudentName   string `json:"student_name"`
+	PhoneNumber   string `json:"phone_number"`
+	Status        string `json:"status"` // enrolled / already_member / not_found / invalid / class_full
+	Reason        string `json:"reason,omitempty"`
+	StudentId     string `json:"student_id,omitempty"`
+	Created       bool   `json:"created"` // 是否为本次导入新建的学生账号
+}
+
+// RosterImportReport 名单导入报告
+type RosterImportReport struct {
+	Total   int                `json:"total"`
+	Summary map[string]int     `json:"summary"` // 按处理结果统计行数
+	Created int                `json:"created"` // 新建账号数
+	Rows    []*RosterRowResult `json:"rows"`
+}
+
+// rosterRow 名单数据行
+type rosterRow struct {
+	rowNo  int
+	number string
+	name   string
+	phone  string
+}
+
+// ImportRoster 按名单批量将学生加入班级（按学号、手机号匹配已有学生，createMissing 时为未注册学生预建账号）
+// 预建账号不设密码，学生使用手机验证码登录；教师导入不受加入策略限制，但受班级人数上限限制
+func (s *ClassRosterService) ImportRoster(teacherId, classId, fileName string, content []byte, createMissing bool) (*RosterImportReport, error) {
+	class, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermClassManage)
+	if err != nil {
+		return nil, err
+	}
+	if class.Status != consts.ClassStatusOngoing {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级已结束或已归档")
+	}
+
+	rows, err := parseRosterFile(fileName, content)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "名单中没有数据行")
+	}
+	if len(rows) > rosterMaxRows {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("单次最多导入 %d 行", rosterMaxRows))
+	}
+
+	report := &RosterImportReport{
+		Total:   len(rows),
+		Summary: make(map[string]int),
+		Rows:    make([]*RosterRowResult, 0, len(rows)),
+	}
+	seenNumbers := make(map[string]int)
+	seenPhones := make(map[string]int)
+	for _, row := range rows {
+		result := &RosterRowResult{
+			RowNo:         row.rowNo,
+			StudentNumber: row.number,
+			StudentName:   row.name,
+			PhoneNumber:   row.phone,
+		}
+		s.importRow(class, teacherId, row, createMissing, seenNumbers, seenPhones, result)
+		report.Summary[result.Status]++
+		if result.Created {
+			report.Created++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return report, nil
+}
+
+// importRow 处理单行名单（operatorId 为执行导入的教师/助教ID）
+func (s *ClassRosterService) importRow(class *classModel.Class, operatorId string, row *rosterRow, createMissing bool, seenNumbers, seenPhones map[string]int, result *RosterRowResult) {
+	invalid := func(reason string) {
+		result.Status = RosterRowInvalid
+		result.Reason = reason
+	}
+	if row.number == "" && row.phone == "" {
+		invalid("学号和手机号不能同时为空")
+		return
+	}
+	if row.phone != "" && !rosterPhonePattern.MatchString(row.phone) {
+		invalid("手机号格式不正确")
+		return
+	}
+	if prev, ok := seenNumbers[row.number]; ok && row.number != "" {
+		invalid(fmt.Sprintf("学号与第 %d 行重复", prev))
+		return
+	}
+	if prev, ok := seenPhones[row.phone]; ok && row.phone != "" {
+		invalid(fmt.Sprintf("手机号与第 %d 行重复", prev))
+		return
+	}
+	if row.number != "" {
+		seenNumbers[row.number] = row.rowNo
+	}
+	if row.phone != "" {
+		seenPhones[row.phone] = row.rowNo
+	}
+
+	// 按学号、手机号匹配学生，两者均填写时必须指向同一学生
+	var byNumber, byPhone *student.Student
+	if row.number != "" {
+		byNumber, _ = s.studentDAO.GetStudentByStudentNumber(row.number)
+	}
+	if row.phone != "" {
+		byPhone, _ = s.studentDAO.GetStudentByPhoneNumber(row.phone)
+	}
+	if byNumber != nil && byPhone != nil && byNumber.StudentId != byPhone.StudentId {
+		invalid("学号与手机号对应不同的学生")
+		return
+	}
+	stu := byNumber
+	if stu == nil {
+		stu = byPhone
+	}
+
+	if stu == nil {
+		if !createMissing {
+			result.Status = RosterRowNotFound
+			result.Reason = "未找到学生"
+			return
+		}
+		if row.number == "" || row.phone == "" {
+			result.Status = RosterRowNotFound
+			result.Reason = "未找到学生，预建账号需同时提供学号和手机号"
+			return
+		}
+		if class.CurrentStudents >= class.MaxStudents {
+			result.Status = RosterRowClassFull
+			result.Reason = "班级人数已满"
+			return
+		}
+		created, err := s.createStudent(row)
+		if err != nil {
+			_, msg := errs.ParseCommonError(err.Error())
+			invalid(msg)
+			return
+		}
+		stu = created
+		result.Created = true
+	}
+	result.StudentId = stu.StudentId
+
+	member, _ := s.classMemberDAO.GetMember(class.ClassId, stu.StudentId)
+	if member != nil && member.Status == consts.ClassMemberStatusActive {
+		result.Status = RosterRowAlreadyMember
+		return
+	}
+	if class.CurrentStudents >= class.MaxStudents {
+		result.Status = RosterRowClassFull
+		result.Reason = "班级人数已满"
+		return
+	}
+	if err := s.joinService.EnrollStudent(class, stu.StudentId, "", ""); err != nil {
+		_, msg := errs.ParseCommonError(err.Error())
+		invalid(msg)
+		return
+	}
+	// 学生已有的待审批/候补申请随导入一并通过
+	s.joinService.closeOpenRequest(class.ClassId, stu.StudentId, operatorId)
+	result.Status = RosterRowEnrolled
+}
+
+// createStudent 为名单中未注册的学生预建账号（不设密码，使用手机验证码登录）
+func (s *ClassRosterService) createStudent(row *rosterRow) (*student.Student, error) {
+	name := row.name
+	if name == "" {
+		name = fmt.Sprintf("学生_%s", row.phone[len(row.phone)-4:])
+	}
+	newStudent := &student.Student{
+		StudentId:        fmt.Sprintf("stu_%d", time.Now().UnixNano()),
+		StudentNumber:    row.number,
+		PhoneNumber:      row.phone,
+		StudentName:      name,
+		Major:            "未设置",
+		Grade:            "未设置",
+		ProgrammingLevel: "初级",
+		Interests:        "[]",
+		LearningTags:     "[]",
+		Status:           1, // 正常状态
+	}
+	if err := s.studentDAO.CreateStudent(newStudent); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "创建学生账号失败: "+err.Error())
+	}
+	return newStudent, nil
+}
+
+// parseRosterFile 按扩展名解析 CSV/XLSX 名单，识别表头列并返回数据行（跳过空行）
+func parseRosterFile(fileName string, content []byte) ([]*rosterRow, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte{0xEF, 0xBB, 0xBF})))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		parsed, err := reader.ReadAll()
+		if err != nil {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "CSV 文件解析失败: "+err.Error())
+		}
+		records = parsed
+	case ".xlsx":
+		parsed, err := parseXLSX(content)
+		if err != nil {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "XLSX 文件解析失败: "+err.Error())
+		}
+		records = parsed
+	default:
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "仅支持 csv 或 xlsx 文件")
+	}
+
+	numberCol, nameCol, phoneCol := 0, 1, 2
+	start := 0
+	if len(records) > 0 {
+		if n, m, p, ok := detectRosterHeader(records[0]); ok {
+			numberCol, nameCol, phoneCol = n, m, p
+			start = 1
+		}
+	}
+
+	cell := func(record []string, col int) string {
+		if col < 0 || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+	rows := make([]*rosterRow, 0, len(records))
+	for i := start; i < len(records); i++ {
+		row := &rosterRow{
+			rowNo:  i + 1,
+			number: cell(records[i], numberCol),
+			name:   cell(records[i], nameCol),
+			phone:  cell(records[i], phoneCol),
+		}
+		if row.number == "" && row.name == "" && row.phone == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// detectRosterHeader 识别表头中的学号、姓名、手机号列（未出现的列返回 -1）
+func detectRosterHeader(header []string) (int, int, int, bool) {
+	find := func(aliases []string) int {
+		for i, h := range header {
+			h = strings.ToLower(strings.TrimSpace(h))
+			for _, alias := range aliases {
+				if h == alias {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	numberCol, nameCol, phoneCol := find(rosterNumberHeaders), find(rosterNameHeaders), find(rosterPhoneHeaders)
+	if numberCol < 0 && phoneCol < 0 {
+		return 0, 0, 0, false
+	}
+	return numberCol, nameCol, phoneCol, true
+}
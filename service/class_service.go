@@ -1,10 +1,11 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
-	"math/rand"
 	"time"
 
+	"github.com/yzf120/elysia-backend/consts"
 	"github.com/yzf120/elysia-backend/dao"
 	"github.com/yzf120/elysia-backend/errs"
 	classModel "github.com/yzf120/elysia-backend/model/class"
@@ -20,6 +21,12 @@ type ClassService struct {
 	subjectDAO        dao.SubjectDAO
 	teacherSubjectDAO dao.TeacherSubjectDAO
 	semesterDAO       dao.SemesterDAO
+	chapterDAO        dao.ChapterDAO
+	quizDAO           dao.QuizDAO
+	inviteService     *ClassInviteService
+	joinService       *ClassJoinService
+	staffService      *ClassStaffService
+	groupService      *ClassGroupService
 }
 
 // NewClassService 创建班级服务
@@ -32,6 +39,12 @@ func NewClassService() *ClassService {
 		subjectDAO:        dao.NewSubjectDAO(),
 		teacherSubjectDAO: dao.NewTeacherSubjectDAO(),
 		semesterDAO:       dao.NewSemesterDAO(),
+		chapterDAO:        dao.NewChapterDAO(),
+		quizDAO:           dao.NewQuizDAO(),
+		inviteService:     NewClassInviteService(),
+		joinService:       NewClassJoinService(),
+		staffService:      NewClassStaffService(),
+		groupService:      NewClassGroupService(),
 	}
 }
 
@@ -42,27 +55,47 @@ func (s *ClassService) CreateClass(teacherId, className, subjectId, semester, de
 		return nil, errs.NewCommonError(errs.ErrBadRequest, "必填参数不能为空")
 	}
 
+	class, _, err := s.buildNewClass(teacherId, className, subjectId, semester, description, maxStudents)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建班级
+	if err := s.classDAO.CreateClass(class); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "创建班级失败: "+err.Error())
+	}
+
+	// 写入班级创建者为教学团队成员
+	if err := s.staffService.AddOwner(class); err != nil {
+		return nil, err
+	}
+
+	return class, nil
+}
+
+// buildNewClass 校验教师、科目与学期并构建新班级模型（自动补充教师科目关联，返回学期信息）
+func (s *ClassService) buildNewClass(teacherId, className, subjectId, semester, description string, maxStudents int32) (*classModel.Class, *subjectModel.Semester, error) {
 	// 检查教师是否存在
 	teacher, err := s.teacherDAO.GetTeacherById(teacherId)
 	if err != nil || teacher == nil {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "教师信息不存在")
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "教师信息不存在")
 	}
 
 	// 检查教师状态
 	if teacher.Status != 1 {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "教师账号未激活")
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "教师账号未激活")
 	}
 
 	// 检查科目是否存在
 	subject, err := s.subjectDAO.GetSubjectById(subjectId)
 	if err != nil || subject == nil {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "科目不存在")
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "科目不存在")
 	}
 
 	// 查询学期信息（获取起止日期），semester 传的是学期名称如 "2026春"
 	semesterInfo, err := s.semesterDAO.GetSemesterByName(semester)
 	if err != nil || semesterInfo == nil {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "学期不存在或已禁用")
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "学期不存在或已禁用")
 	}
 
 	// 检查教师是否已有该科目关联，若无则自动创建
@@ -78,15 +111,18 @@ func (s *ClassService) CreateClass(teacherId, className, subjectId, semester, de
 			Remark:    semesterInfo.SemesterName,
 		}
 		if createErr := s.teacherSubjectDAO.CreateTeacherSubject(newTS); createErr != nil {
-			return nil, errs.NewCommonError(errs.ErrInternal, "写入教师科目关联失败: "+createErr.Error())
+			return nil, nil, errs.NewCommonError(errs.ErrInternal, "写入教师科目关联失败: "+createErr.Error())
 		}
 	} else if teacherSubject.Status != 1 {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "教师未被分配该科目或科目状态异常")
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "教师未被分配该科目或科目状态异常")
 	}
 
 	// 生成班级ID和验证码
 	classId := fmt.Sprintf("cls_%d", time.Now().UnixNano())
-	classCode := s.generateClassCode()
+	classCode, err := s.inviteService.generateClassCode()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// 构建班级模型
 	class := &classModel.Class{
@@ -100,92 +136,59 @@ func (s *ClassService) CreateClass(teacherId, className, subjectId, semester, de
 		MaxStudents:     maxStudents,
 		CurrentStudents: 0,
 		Description:     description,
+		QrCodeUrl:       BuildClassQRCodeURL(classCode),
 		Status:          1, // 进行中
 	}
 
-	// 创建班级
-	if err := s.classDAO.CreateClass(class); err != nil {
-		return nil, errs.NewCommonError(errs.ErrInternal, "创建班级失败: "+err.Error())
-	}
-
-	return class, nil
-}
-
-// generateClassCode 生成班级验证码（6位数字+字母）
-func (s *ClassService) generateClassCode() string {
-	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	rand.Seed(time.Now().UnixNano())
-	code := make([]byte, 6)
-	for i := range code {
-		code[i] = charset[rand.Intn(len(charset))]
-	}
-	return string(code)
+	return class, semesterInfo, nil
 }
 
-// JoinClass 学生加入班级
-func (s *ClassService) JoinClass(studentId, classCode string) error {
-	// 查询班级
-	class, err := s.classDAO.GetClassByCode(classCode)
-	if err != nil || class == nil {
-		return errs.NewCommonError(errs.ErrBadRequest, "班级不存在或验证码错误")
+// JoinClass 学生加入班级（code 可为班级验证码或邀请链接码，按班级加入策略直接加入、提交审批或进入候补），返回加入结果
+func (s *ClassService) JoinClass(studentId, code, message string) (string, error) {
+	// 解析验证码/邀请链接
+	class, invite, err := s.inviteService.ResolveJoinCode(code)
+	if err != nil {
+		return "", err
 	}
 
 	// 检查班级状态
 	if class.Status != 1 {
-		return errs.NewCommonError(errs.ErrBadRequest, "班级已结束或已归档")
+		return "", errs.NewCommonError(errs.ErrBadRequest, "班级已结束或已归档")
 	}
 
 	// 检查学生是否存在
 	student, err := s.studentDAO.GetStudentById(studentId)
 	if err != nil || student == nil {
-		return errs.NewCommonError(errs.ErrBadRequest, "学生信息不存在")
+		return "", errs.NewCommonError(errs.ErrBadRequest, "学生信息不存在")
 	}
 
 	// 检查是否已加入
 	existingMember, _ := s.classMemberDAO.GetMember(class.ClassId, studentId)
 	if existingMember != nil && existingMember.Status == 1 {
-		return errs.NewCommonError(errs.ErrBadRequest, "已加入该班级")
-	}
-
-	// 检查班级人数是否已满
-	if class.CurrentStudents >= class.MaxStudents {
-		return errs.NewCommonError(errs.ErrBadRequest, "班级人数已满")
+		return "", errs.NewCommonError(errs.ErrBadRequest, "已加入该班级")
 	}
 
-	// 添加班级成员
-	member := &classModel.ClassMember{
-		ClassId:   class.ClassId,
-		StudentId: studentId,
-		Status:    1,
-	}
-
-	if err := s.classMemberDAO.AddMember(member); err != nil {
-		return errs.NewCommonError(errs.ErrInternal, "加入班级失败: "+err.Error())
-	}
-
-	// 更新班级人数
-	updates := map[string]interface{}{
-		"current_students": class.CurrentStudents + 1,
-	}
-	if err := s.classDAO.UpdateClass(class.ClassId, updates); err != nil {
-		return errs.NewCommonError(errs.ErrInternal, "更新班级人数失败: "+err.Error())
-	}
-
-	return nil
+	return s.joinService.Join(class, invite, studentId, code, message)
 }
 
 // LeaveClass 学生退出班级
 func (s *ClassService) LeaveClass(studentId, classId string) error {
 	// 查询班级成员
 	member, err := s.classMemberDAO.GetMember(classId, studentId)
-	if err != nil || member == nil {
+	if err != nil || member == nil || member.Status != 1 {
 		return errs.NewCommonError(errs.ErrBadRequest, "未加入该班级")
 	}
 
+	// 归档班级只读，不能退出
+	if err := s.staffService.CheckClassWritable(classId); err != nil {
+		return err
+	}
+
 	// 更新成员状态为已退出
 	if err := s.classMemberDAO.UpdateMemberStatus(classId, studentId, 0); err != nil {
 		return errs.NewCommonError(errs.ErrInternal, "退出班级失败: "+err.Error())
 	}
+	s.groupService.OnMemberLeft(classId, studentId)
 
 	// 更新班级人数
 	class, err := s.classDAO.GetClassById(classId)
@@ -196,26 +199,31 @@ func (s *ClassService) LeaveClass(studentId, classId string) error {
 		s.classDAO.UpdateClass(classId, updates)
 	}
 
+	// 空出名额，递补候补学生
+	s.joinService.PromoteWaitlist(classId)
+
 	return nil
 }
 
 // RemoveStudent 教师移除学生
 func (s *ClassService) RemoveStudent(teacherId, classId, studentId string) error {
-	// 查询班级
-	class, err := s.classDAO.GetClassById(classId)
-	if err != nil || class == nil {
-		return errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	// 查询班级并校验班级管理权限
+	class, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermClassManage)
+	if err != nil {
+		return err
 	}
 
-	// 检查是否是班级创建者
-	if class.TeacherId != teacherId {
-		return errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 检查学生是否为班级成员
+	member, err := s.classMemberDAO.GetMember(classId, studentId)
+	if err != nil || member == nil || member.Status != 1 {
+		return errs.NewCommonError(errs.ErrBadRequest, "该学生不是班级成员")
 	}
 
 	// 移除学生
 	if err := s.classMemberDAO.RemoveMember(classId, studentId); err != nil {
 		return errs.NewCommonError(errs.ErrInternal, "移除学生失败: "+err.Error())
 	}
+	s.groupService.OnMemberLeft(classId, studentId)
 
 	// 更新班级人数
 	updates := map[string]interface{}{
@@ -225,6 +233,9 @@ func (s *ClassService) RemoveStudent(teacherId, classId, studentId string) error
 		return errs.NewCommonError(errs.ErrInternal, "更新班级人数失败: "+err.Error())
 	}
 
+	// 空出名额，递补候补学生
+	s.joinService.PromoteWaitlist(classId)
+
 	return nil
 }
 
@@ -283,7 +294,7 @@ func (s *ClassService) GetStudentClasses(studentId string, page, pageSize int32)
 	return members, total, nil
 }
 
-// GetTeacherClasses 获取教师创建的班级列表
+// GetTeacherClasses 获取教师创建或参与教学的班级列表（含协同教师、助教身份的班级）
 func (s *ClassService) GetTeacherClasses(teacherId string, page, pageSize int32) ([]*classModel.Class, int32, error) {
 	// 参数校验和默认值设置
 	if page <= 0 {
@@ -298,28 +309,35 @@ func (s *ClassService) GetTeacherClasses(teacherId string, page, pageSize int32)
 
 	// 查询教师班级
 	offset := (page - 1) * pageSize
-	classes, err := s.classDAO.ListClassesByTeacherId(teacherId, pageSize, offset)
+	whereClause := "teacher_id = ? OR class_id IN (SELECT class_id FROM class_staff WHERE staff_id = ?)"
+	args := []interface{}{teacherId, teacherId}
+	classes, err := s.classDAO.ListClasses(whereClause, args, pageSize, offset)
 	if err != nil {
 		return nil, 0, errs.NewCommonError(errs.ErrInternal, "查询教师班级失败: "+err.Error())
 	}
-
-	// 这里简化处理，实际应该统计总数
-	total := int32(len(classes))
+	total, err := s.classDAO.CountClasses(whereClause, args)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "统计教师班级失败: "+err.Error())
+	}
 
 	return classes, total, nil
 }
 
 // UpdateClass 更新班级信息
 func (s *ClassService) UpdateClass(teacherId, classId string, updates map[string]interface{}) (*classModel.Class, error) {
-	// 查询班级
-	class, err := s.classDAO.GetClassById(classId)
-	if err != nil || class == nil {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	// 校验班级管理权限
+	class, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermClassManage)
+	if err != nil {
+		return nil, err
 	}
 
-	// 检查是否是班级创建者
-	if class.TeacherId != teacherId {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	// 归档班级只读，仅允许修改状态（恢复班级）
+	if class.Status == consts.ClassStatusArchived {
+		for key := range updates {
+			if key != "status" {
+				return nil, errs.NewCommonError(errs.ErrBadRequest, "班级已归档，仅支持恢复班级状态")
+			}
+		}
 	}
 
 	// 执行更新
@@ -327,6 +345,11 @@ func (s *ClassService) UpdateClass(teacherId, classId string, updates map[string
 		return nil, errs.NewCommonError(errs.ErrInternal, "更新班级信息失败: "+err.Error())
 	}
 
+	// 调整人数上限后递补候补学生
+	if _, ok := updates["max_students"]; ok {
+		s.joinService.PromoteWaitlist(classId)
+	}
+
 	// 查询更新后的班级信息
 	updatedClass, err := s.classDAO.GetClassById(classId)
 	if err != nil {
@@ -348,14 +371,161 @@ func (s *ClassService) GetClassById(classId string) (*classModel.Class, error) {
 	return class, nil
 }
 
-// GetClassByCode 根据验证码获取班级信息
+// GetClassByCode 根据验证码获取班级信息（支持邀请链接码）
 func (s *ClassService) GetClassByCode(classCode string) (*classModel.Class, error) {
-	class, err := s.classDAO.GetClassByCode(classCode)
+	class, _, err := s.inviteService.ResolveJoinCode(classCode)
 	if err != nil {
-		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级失败: "+err.Error())
-	}
-	if class == nil {
-		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+		return nil, err
 	}
 	return class, nil
 }
+
+// CloneClass 克隆班级到新学期（复制班级设置、章节与小节及题目关联，不复制成员与公告；作业时间按新旧学期开始日期的差值平移）
+// 返回新班级及时间平移天数（原班级学期不存在时不平移）
+func (s *ClassService) CloneClass(teacherId, sourceClassId, className, semester string) (*classModel.Class, int, error) {
+	if teacherId == "" || sourceClassId == "" || semester == "" {
+		return nil, 0, errs.NewCommonError(errs.ErrBadRequest, "必填参数不能为空")
+	}
+
+	// 校验原班级章节编辑权限（克隆需读取完整课程结构）
+	source, err := s.staffService.CheckPermission(sourceClassId, teacherId, consts.ClassPermChapterEdit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if className == "" {
+		className = source.ClassName
+	}
+
+	// 构建新班级（新班级由操作教师创建）
+	class, semesterInfo, err := s.buildNewClass(teacherId, className, source.SubjectId, semester, source.Description, source.MaxStudents)
+	if err != nil {
+		return nil, 0, err
+	}
+	class.JoinPolicy = source.JoinPolicy
+	class.WaitlistEnabled = source.WaitlistEnabled
+
+	// 计算时间平移量（按学期开始日期对齐）
+	var shift time.Duration
+	if sourceSemester, err := s.semesterDAO.GetSemesterByName(source.Semester); err == nil && sourceSemester != nil {
+		shift = semesterInfo.StartDate.Sub(sourceSemester.StartDate)
+	}
+	shiftTime := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		shifted := t.Add(shift)
+		return &shifted
+	}
+
+	// 复制章节与小节（生成新ID，保留排序）
+	chapters, err := s.chapterDAO.ListChaptersByClassId(sourceClassId)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "查询原班级章节失败: "+err.Error())
+	}
+	seq := time.Now().UnixNano()
+	newChapters := make([]*classModel.ClassChapter, 0, len(chapters))
+	newSections := make([]*classModel.ClassSection, 0)
+	chapterIds := make([]string, 0, len(chapters))
+	sectionIdMap := make(map[string]string) // 原小节ID -> 新小节ID
+	sourcePrereqs := make([]string, 0)      // 与 newSections 一一对应的原前置小节列表
+	for _, ch := range chapters {
+		seq++
+		newChapter := &classModel.ClassChapter{
+			ChapterId:   fmt.Sprintf("chap_%d", seq),
+			ClassId:     class.ClassId,
+			Title:       ch.Title,
+			Description: ch.Description,
+			SortOrder:   ch.SortOrder,
+			Weight:      ch.Weight,
+			ReleaseAt:   shiftTime(ch.ReleaseAt),
+			Status:      ch.Status,
+		}
+		newChapters = append(newChapters, newChapter)
+		chapterIds = append(chapterIds, newChapter.ChapterId)
+
+		sections, err := s.chapterDAO.ListSectionsByChapterId(ch.ChapterId)
+		if err != nil {
+			return nil, 0, errs.NewCommonError(errs.ErrInternal, "查询原班级小节失败: "+err.Error())
+		}
+		for _, sec := range sections {
+			seq++
+			sectionIdMap[sec.SectionId] = fmt.Sprintf("sec_%d", seq)
+			sourcePrereqs = append(sourcePrereqs, sec.PrerequisiteIds)
+			newSections = append(newSections, &classModel.ClassSection{
+				SectionId:         sectionIdMap[sec.SectionId],
+				ChapterId:         newChapter.ChapterId,
+				ClassId:           class.ClassId,
+				Title:             sec.Title,
+				Description:       sec.Description,
+				SectionType:       sec.SectionType,
+				ProblemId:         sec.ProblemId,
+				DiscussionTitle:   sec.DiscussionTitle,
+				DiscussionContent: sec.DiscussionContent,
+				OpenTime:          shiftTime(sec.OpenTime),
+				DueTime:           shiftTime(sec.DueTime),
+				LatePolicy:        sec.LatePolicy,
+				LatePenalty:       sec.LatePenalty,
+				MaxAttempts:       sec.MaxAttempts,
+				QuizShuffle:       sec.QuizShuffle,
+				QuizTimeLimit:     sec.QuizTimeLimit,
+				// 上传文件与原班级共用同一份存储
+				ResourceSource:        sec.ResourceSource,
+				BookshelfItemId:       sec.BookshelfItemId,
+				AttachmentName:        sec.AttachmentName,
+				AttachmentStorageName: sec.AttachmentStorageName,
+				AttachmentMimeType:    sec.AttachmentMimeType,
+				AttachmentSize:        sec.AttachmentSize,
+				VideoUrl:              sec.VideoUrl,
+				GroupMode:             sec.GroupMode,
+				ReleaseAt:             shiftTime(sec.ReleaseAt),
+				SortOrder:             sec.SortOrder,
+				Status:                sec.Status,
+			})
+		}
+	}
+	// 前置小节映射为新班级中的小节ID
+	for i, sec := range newSections {
+		prereqs := make([]string, 0)
+		for _, id := range parseStringList(sourcePrereqs[i]) {
+			if newId, ok := sectionIdMap[id]; ok {
+				prereqs = append(prereqs, newId)
+			}
+		}
+		prereqsJSON, _ := json.Marshal(prereqs)
+		sec.PrerequisiteIds = string(prereqsJSON)
+	}
+	// 复制测验题目
+	sourceSectionIds := make([]string, 0, len(sectionIdMap))
+	for id := range sectionIdMap {
+		sourceSectionIds = append(sourceSectionIds, id)
+	}
+	questions, err := s.quizDAO.ListQuestionsBySectionIds(sourceSectionIds)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "查询原班级测验题目失败: "+err.Error())
+	}
+	newQuestions := make([]*classModel.ClassQuizQuestion, 0, len(questions))
+	for _, q := range questions {
+		seq++
+		newQuestions = append(newQuestions, &classModel.ClassQuizQuestion{
+			QuestionId:   fmt.Sprintf("qq_%d", seq),
+			SectionId:    sectionIdMap[q.SectionId],
+			ClassId:      class.ClassId,
+			QuestionType: q.QuestionType,
+			Content:      q.Content,
+			Options:      q.Options,
+			Answer:       q.Answer,
+			Explanation:  q.Explanation,
+			Score:        q.Score,
+			SortOrder:    q.SortOrder,
+		})
+	}
+	idsJSON, _ := json.Marshal(chapterIds)
+	class.ChapterIds = string(idsJSON)
+
+	// 同一事务中写入班级、创建者记录与课程结构
+	if err := s.classDAO.CreateClassWithContent(class, newOwnerStaff(class), newChapters, newSections, newQuestions); err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "克隆班级失败: "+err.Error())
+	}
+
+	return class, int(shift.Hours() / 24), nil
+}
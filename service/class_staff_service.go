@@ -0,0 +1,395 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+)
+
+// 教学团队成员类型
+const (
+	StaffTypeTeacher = "teacher"
+	StaffTypeStudent = "student"
+)
+
+// allClassPermissions 全部班级权限（按展示顺序）
+var allClassPermissions = []string{
+	consts.ClassPermClassManage,
+	consts.ClassPermChapterEdit,
+	consts.ClassPermAnnouncement,
+	consts.ClassPermGradeView,
+	consts.ClassPermGradeEdit,
+	consts.ClassPermContest,
+	consts.ClassPermDiscussion,
+	consts.ClassPermAttendance,
+}
+
+// defaultStaffPermissions 各角色未指定权限时的默认权限
+var defaultStaffPermissions = map[string][]string{
+	consts.ClassStaffRoleCoTeacher: allClassPermissions,
+	consts.ClassStaffRoleTA:        {consts.ClassPermGradeView, consts.ClassPermDiscussion, consts.ClassPermAttendance},
+}
+
+// ClassStaffService 班级教学团队服务（协同教师、助教及统一权限校验）
+type ClassStaffService struct {
+	classDAO   dao.ClassDAO
+	staffDAO   dao.ClassStaffDAO
+	teacherDAO dao.TeacherDAO
+	studentDAO dao.StudentDAO
+}
+
+// NewClassStaffService 创建班级教学团队服务
+func NewClassStaffService() *ClassStaffService {
+	return &ClassStaffService{
+		classDAO:   dao.NewClassDAO(),
+		staffDAO:   dao.NewClassStaffDAO(),
+		teacherDAO: dao.NewTeacherDAO(),
+		studentDAO: dao.NewStudentDAO(),
+	}
+}
+
+// StaffView 教学团队成员视图
+type StaffView struct {
+	ClassId     string   `json:"class_id"`
+	StaffId     string   `json:"staff_id"`
+	StaffType   string   `json:"staff_type"`
+	StaffName   string   `json:"staff_name"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	AddedBy     string   `json:"added_by"`
+	CreateTime  string   `json:"create_time"`
+}
+
+// ==================== 权限校验 ====================
+
+// CheckPermission 查询班级并校验操作人拥有指定权限（班级创建者拥有全部权限，其余按教学团队角色与权限判断）
+func (s *ClassStaffService) CheckPermission(classId, staffId, perm string) (*classModel.Class, error) {
+	if classId == "" || staffId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级ID和操作人ID不能为空")
+	}
+	class, err := s.classDAO.GetClassById(classId)
+	if err != nil || class == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	if class.TeacherId == staffId {
+		return class, nil
+	}
+	staff, err := s.staffDAO.GetStaff(classId, staffId)
+	if err != nil || staff == nil || !hasStaffPermission(staff, perm) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "无权限操作该班级")
+	}
+	return class, nil
+}
+
+// CheckWritePermission 校验操作人拥有指定权限且班级未归档（用于修改类操作）
+func (s *ClassStaffService) CheckWritePermission(classId, staffId, perm string) (*classModel.Class, error) {
+	class, err := s.CheckPermission(classId, staffId, perm)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkClassWritable(class); err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+// CheckClassWritable 校验班级存在且未归档（用于学生在班级内的提交、发帖等操作）
+func (s *ClassStaffService) CheckClassWritable(classId string) error {
+	class, err := s.classDAO.GetClassById(classId)
+	if err != nil || class == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	return checkClassWritable(class)
+}
+
+// GetMyPermissions 查询操作人在班级中的角色与权限（非教学团队成员返回错误）
+func (s *ClassStaffService) GetMyPermissions(classId, staffId string) (*StaffView, error) {
+	if classId == "" || staffId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级ID和操作人ID不能为空")
+	}
+	class, err := s.classDAO.GetClassById(classId)
+	if err != nil || class == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	if class.TeacherId == staffId {
+		return &StaffView{
+			ClassId:     classId,
+			StaffId:     staffId,
+			StaffType:   StaffTypeTeacher,
+			StaffName:   s.staffName(StaffTypeTeacher, staffId),
+			Role:        consts.ClassStaffRoleOwner,
+			Permissions: allClassPermissions,
+		}, nil
+	}
+	staff, err := s.staffDAO.GetStaff(classId, staffId)
+	if err != nil || staff == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "不是该班级的教学团队成员")
+	}
+	return s.buildStaffView(staff), nil
+}
+
+// ==================== 团队管理（仅班级创建者） ====================
+
+// AddOwner 写入班级创建者记录（创建班级时调用）
+func (s *ClassStaffService) AddOwner(class *classModel.Class) error {
+	if err := s.staffDAO.AddStaff(newOwnerStaff(class)); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "写入班级教学团队失败: "+err.Error())
+	}
+	return nil
+}
+
+// AddStaff 添加协同教师或助教（staffId 为教师ID或学生ID，学生只能担任助教；permissions 为空时使用角色默认权限）
+func (s *ClassStaffService) AddStaff(ownerId, classId, staffId, role string, permissions []string) (*StaffView, error) {
+	class, err := s.getOwnedClass(ownerId, classId)
+	if err != nil {
+		return nil, err
+	}
+	if staffId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "成员ID不能为空")
+	}
+	if staffId == class.TeacherId {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级创建者已拥有全部权限")
+	}
+	if existing, err := s.staffDAO.GetStaff(classId, staffId); err == nil && existing != nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该用户已是班级教学团队成员")
+	}
+
+	staffType, err := s.resolveStaffType(staffId)
+	if err != nil {
+		return nil, err
+	}
+	permJSON, err := normalizeStaffPermissions(staffType, role, permissions)
+	if err != nil {
+		return nil, err
+	}
+	staff := &classModel.ClassStaff{
+		ClassId:     classId,
+		StaffId:     staffId,
+		StaffType:   staffType,
+		Role:        role,
+		Permissions: permJSON,
+		AddedBy:     ownerId,
+	}
+	if err := s.staffDAO.AddStaff(staff); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "添加教学团队成员失败: "+err.Error())
+	}
+	return s.buildStaffView(staff), nil
+}
+
+// UpdateStaff 修改协同教师或助教的角色与权限
+func (s *ClassStaffService) UpdateStaff(ownerId, classId, staffId, role string, permissions []string) error {
+	class, err := s.getOwnedClass(ownerId, classId)
+	if err != nil {
+		return err
+	}
+	if staffId == class.TeacherId {
+		return errs.NewCommonError(errs.ErrBadRequest, "不能修改班级创建者的权限")
+	}
+	staff, err := s.staffDAO.GetStaff(classId, staffId)
+	if err != nil || staff == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "该用户不是班级教学团队成员")
+	}
+	if role == "" {
+		role = staff.Role
+	}
+	permJSON, err := normalizeStaffPermissions(staff.StaffType, role, permissions)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"role":        role,
+		"permissions": permJSON,
+	}
+	if err := s.staffDAO.UpdateStaff(classId, staffId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新教学团队成员失败: "+err.Error())
+	}
+	return nil
+}
+
+// RemoveStaff 移除协同教师或助教
+func (s *ClassStaffService) RemoveStaff(ownerId, classId, staffId string) error {
+	class, err := s.getOwnedClass(ownerId, classId)
+	if err != nil {
+		return err
+	}
+	if staffId == class.TeacherId {
+		return errs.NewCommonError(errs.ErrBadRequest, "不能移除班级创建者")
+	}
+	staff, err := s.staffDAO.GetStaff(classId, staffId)
+	if err != nil || staff == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "该用户不是班级教学团队成员")
+	}
+	if err := s.staffDAO.RemoveStaff(classId, staffId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "移除教学团队成员失败: "+err.Error())
+	}
+	return nil
+}
+
+// ListStaff 查询班级教学团队（教学团队成员均可查看）
+func (s *ClassStaffService) ListStaff(operatorId, classId string) ([]*StaffView, error) {
+	class, err := s.classDAO.GetClassById(classId)
+	if err != nil || class == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	if class.TeacherId != operatorId {
+		if staff, err := s.staffDAO.GetStaff(classId, operatorId); err != nil || staff == nil {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "无权限操作该班级")
+		}
+	}
+	list, err := s.staffDAO.ListStaffByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询教学团队失败: "+err.Error())
+	}
+	views := make([]*StaffView, 0, len(list))
+	for _, staff := range list {
+		views = append(views, s.buildStaffView(staff))
+	}
+	return views, nil
+}
+
+// ==================== 内部工具 ====================
+
+// getOwnedClass 查询班级并校验操作人为班级创建者（教学团队仅由创建者管理）
+func (s *ClassStaffService) getOwnedClass(ownerId, classId string) (*classModel.Class, error) {
+	if ownerId == "" || classId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "教师ID和班级ID不能为空")
+	}
+	class, err := s.classDAO.GetClassById(classId)
+	if err != nil || class == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	if class.TeacherId != ownerId {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "仅班级创建者可管理教学团队")
+	}
+	if err := checkClassWritable(class); err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+// resolveStaffType 根据用户ID判断成员类型并校验账号存在
+func (s *ClassStaffService) resolveStaffType(staffId string) (string, error) {
+	switch {
+	case strings.HasPrefix(staffId, "tea_"):
+		if t, err := s.teacherDAO.GetTeacherById(staffId); err != nil || t == nil {
+			return "", errs.NewCommonError(errs.ErrBadRequest, "教师不存在")
+		}
+		return StaffTypeTeacher, nil
+	case strings.HasPrefix(staffId, "stu_"):
+		if stu, err := s.studentDAO.GetStudentById(staffId); err != nil || stu == nil {
+			return "", errs.NewCommonError(errs.ErrBadRequest, "学生不存在")
+		}
+		return StaffTypeStudent, nil
+	}
+	return "", errs.NewCommonError(errs.ErrBadRequest, "成员ID不合法")
+}
+
+// staffName 查询成员姓名
+func (s *ClassStaffService) staffName(staffType, staffId string) string {
+	if staffType == StaffTypeStudent {
+		if stu, err := s.studentDAO.GetStudentById(staffId); err == nil && stu != nil {
+			return stu.StudentName
+		}
+		return ""
+	}
+	if t, err := s.teacherDAO.GetTeacherById(staffId); err == nil && t != nil {
+		return t.TeacherName
+	}
+	return ""
+}
+
+// buildStaffView 构造教学团队成员视图
+func (s *ClassStaffService) buildStaffView(staff *classModel.ClassStaff) *StaffView {
+	perms := allClassPermissions
+	if staff.Role != consts.ClassStaffRoleOwner {
+		perms = parseStaffPermissions(staff.Permissions)
+	}
+	return &StaffView{
+		ClassId:     staff.ClassId,
+		StaffId:     staff.StaffId,
+		StaffType:   staff.StaffType,
+		StaffName:   s.staffName(staff.StaffType, staff.StaffId),
+		Role:        staff.Role,
+		Permissions: perms,
+		AddedBy:     staff.AddedBy,
+		CreateTime:  staff.CreateTime.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// newOwnerStaff 构造班级创建者记录
+func newOwnerStaff(class *classModel.Class) *classModel.ClassStaff {
+	return &classModel.ClassStaff{
+		ClassId:     class.ClassId,
+		StaffId:     class.TeacherId,
+		StaffType:   StaffTypeTeacher,
+		Role:        consts.ClassStaffRoleOwner,
+		Permissions: "[]",
+		AddedBy:     class.TeacherId,
+	}
+}
+
+// normalizeStaffPermissions 校验角色与权限并序列化（学生只能担任助教且不能管理班级）
+func normalizeStaffPermissions(staffType, role string, permissions []string) (string, error) {
+	defaults, ok := defaultStaffPermissions[role]
+	if !ok {
+		return "", errs.NewCommonError(errs.ErrBadRequest, "角色不合法（co_teacher-协同教师，ta-助教）")
+	}
+	if staffType == StaffTypeStudent && role != consts.ClassStaffRoleTA {
+		return "", errs.NewCommonError(errs.ErrBadRequest, "学生只能担任助教")
+	}
+	if len(permissions) == 0 {
+		permissions = defaults
+	}
+	seen := make(map[string]bool, len(permissions))
+	perms := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if !isClassPermission(p) {
+			return "", errs.NewCommonError(errs.ErrBadRequest, "权限不合法: "+p)
+		}
+		if staffType == StaffTypeStudent && p == consts.ClassPermClassManage {
+			return "", errs.NewCommonError(errs.ErrBadRequest, "学生助教不能拥有班级管理权限")
+		}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	data, _ := json.Marshal(perms)
+	return string(data), nil
+}
+
+// hasStaffPermission 判断教学团队成员是否拥有指定权限
+func hasStaffPermission(staff *classModel.ClassStaff, perm string) bool {
+	if staff.Role == consts.ClassStaffRoleOwner {
+		return true
+	}
+	for _, p := range parseStaffPermissions(staff.Permissions) {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStaffPermissions 解析权限列表
+func parseStaffPermissions(raw string) []string {
+	perms := []string{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &perms)
+	}
+	return perms
+}
+
+// isClassPermission 判断是否为已定义的班级权限
+func isClassPermission(perm string) bool {
+	for _, p := range allClassPermissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/yzf120/elysia-backend/dao"
 	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
 	codeModel "github.com/yzf120/elysia-backend/model/code"
 	"github.com/yzf120/elysia-backend/model/problem"
 )
@@ -69,21 +70,31 @@ var langConfigs = map[string]langConfig{
 
 // CodeRunService 代码运行服务
 type CodeRunService struct {
-	codeRunDAO dao.CodeRunDAO
-	problemDAO dao.ProblemDAO
+	codeRunDAO         dao.CodeRunDAO
+	problemDAO         dao.ProblemDAO
+	mistakeService     *MistakeService
+	assignmentService  *AssignmentService
+	contestService     *ContestService
+	leaderboardService *LeaderboardService
 }
 
 // NewCodeRunService 创建代码运行服务
 func NewCodeRunService() *CodeRunService {
 	return &CodeRunService{
-		codeRunDAO: dao.NewCodeRunDAO(),
-		problemDAO: dao.NewProblemDAO(),
+		codeRunDAO:         dao.NewCodeRunDAO(),
+		problemDAO:         dao.NewProblemDAO(),
+		mistakeService:     NewMistakeService(),
+		assignmentService:  NewAssignmentService(),
+		contestService:     NewContestService(),
+		leaderboardService: NewLeaderboardService(),
 	}
 }
 
 // SubmitCodeRun 提交代码运行任务（异步执行）
 // testInput：测试模式下直接传入的样例输入（已废弃，改为从 showcase 字段读取）
-func (s *CodeRunService) SubmitCodeRun(ctx context.Context, studentId string, problemId int64, language, code, runType, testInput string) (*codeModel.CodeRun, error) {
+// sectionId：班级作业小节ID（可选），传入时校验作业设置并记录小节成绩
+// contestId：比赛ID（可选），传入时校验比赛状态，submit 类型记录为比赛提交
+func (s *CodeRunService) SubmitCodeRun(ctx context.Context, studentId string, problemId int64, language, code, runType, testInput, sectionId, contestId string) (*codeModel.CodeRun, error) {
 	// 校验语言
 	if _, ok := langConfigs[language]; !ok {
 		return nil, errs.NewCommonError(errs.ErrBadRequest, "不支持的编程语言: "+language)
@@ -93,23 +104,63 @@ func (s *CodeRunService) SubmitCodeRun(ctx context.Context, studentId string, pr
 		return nil, errs.NewCommonError(errs.ErrBadRequest, "run_type 必须为 test 或 submit")
 	}
 
+	if sectionId != "" && contestId != "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "作业小节与比赛不能同时指定")
+	}
+
 	// 查询题目（test 和 submit 都需要）
 	p, err := s.problemDAO.GetProblemById(problemId)
 	if err != nil || p == nil {
 		return nil, errs.NewCommonError(errs.ErrBadRequest, "题目不存在")
 	}
 
+	// 班级作业内提交：校验开放时间、截止时间与提交次数
+	classId := ""
+	var section *classModel.ClassSection
+	if sectionId != "" {
+		section, err = s.assignmentService.CheckSubmission(studentId, sectionId, problemId, runType)
+		if err != nil {
+			return nil, err
+		}
+		classId = section.ClassId
+	}
+
+	// 比赛内提交：校验比赛进行中且题目属于比赛
+	if contestId != "" {
+		contest, err := s.contestService.CheckSubmission(ctx, studentId, contestId, problemId)
+		if err != nil {
+			return nil, err
+		}
+		classId = contest.ClassId
+	}
+
 	// 创建运行记录（pending 状态）
 	record := &codeModel.CodeRun{
 		ProblemId: problemId,
 		StudentId: studentId,
+		ClassId:   classId,
+		SectionId: sectionId,
+		ContestId: contestId,
 		Language:  language,
 		Code:      code,
 		RunType:   runType,
 		Status:    "pending",
 	}
-	if err := s.codeRunDAO.CreateCodeRun(record); err != nil {
-		return nil, errs.NewCommonError(errs.ErrInternal, "创建运行记录失败: "+err.Error())
+	switch {
+	case section != nil:
+		// 作业提交：提交次数的统计与插入在同一事务内完成
+		if err := s.assignmentService.CreateSubmitRun(section, record); err != nil {
+			return nil, err
+		}
+	case contestId != "" && runType == "submit":
+		// 比赛提交：运行记录与比赛提交记录在同一事务内创建
+		if err := s.contestService.CreateSubmitRun(record); err != nil {
+			return nil, err
+		}
+	default:
+		if err := s.codeRunDAO.CreateCodeRun(record); err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "创建运行记录失败: "+err.Error())
+		}
 	}
 
 	if runType == "test" {
@@ -117,18 +168,48 @@ func (s *CodeRunService) SubmitCodeRun(ctx context.Context, studentId string, pr
 		go s.executeCodeWithShowcase(record.Id, p, language, code)
 	} else {
 		// 提交模式：执行 test_cases 全部用例
-		go s.executeCode(record.Id, p, language, code, runType)
+		go func() {
+			s.executeCode(record.Id, p, language, code, runType)
+			s.afterJudge(record.Id)
+		}()
 	}
 
 	return record, nil
 }
 
+// afterJudge 提交评测完成后的后续处理（错题本、作业成绩、比赛榜单、班级排行榜等）
+func (s *CodeRunService) afterJudge(runId int64) {
+	s.mistakeService.OnCodeRunJudged(runId)
+	s.assignmentService.OnCodeRunJudged(runId)
+	s.contestService.OnCodeRunJudged(runId)
+	s.leaderboardService.OnCodeRunJudged(runId)
+}
+
+// calcRunScore 计算提交得分（0-100）：通过为满分，编译错误为 0，其余按通过用例比例计算
+func calcRunScore(run *codeModel.CodeRun) int32 {
+	if run.Status == "accepted" {
+		return 100
+	}
+	var caseResults []showcaseCaseResult
+	if run.Output == "" || json.Unmarshal([]byte(run.Output), &caseResults) != nil || len(caseResults) == 0 {
+		return 0
+	}
+	passed := 0
+	for _, c := range caseResults {
+		if c.Passed {
+			passed++
+		}
+	}
+	return int32(passed * 100 / len(caseResults))
+}
+
 // GetCodeRunResult 查询代码运行结果
 func (s *CodeRunService) GetCodeRunResult(runId int64) (*codeModel.CodeRun, error) {
 	record, err := s.codeRunDAO.GetCodeRunById(runId)
 	if err != nil {
 		return nil, errs.NewCommonError(errs.ErrBadRequest, "运行记录不存在")
 	}
+	s.maskContestVerdict(record)
 	return record, nil
 }
 
@@ -138,9 +219,27 @@ func (s *CodeRunService) ListCodeRunRecords(studentId string, problemId int64, l
 	if err != nil {
 		return nil, errs.NewCommonError(errs.ErrInternal, "查询运行记录失败: "+err.Error())
 	}
+	for _, r := range records {
+		s.maskContestVerdict(r)
+	}
 	return records, nil
 }
 
+// maskContestVerdict OI 赛制比赛结束前隐藏评测结果（状态显示为 submitted）
+func (s *CodeRunService) maskContestVerdict(record *codeModel.CodeRun) {
+	if record.ContestId == "" || record.Status == "pending" || record.Status == "running" {
+		return
+	}
+	if !s.contestService.ShouldHideVerdict(record) {
+		return
+	}
+	record.Status = "submitted"
+	record.Output = ""
+	record.ErrorMsg = ""
+	record.TimeCost = 0
+	record.MemoryUsed = 0
+}
+
 // BatchGetAcceptedProblems 批量查询学生已完全通过的题目ID集合
 func (s *CodeRunService) BatchGetAcceptedProblems(studentId string, problemIds []int64) (map[int64]bool, error) {
 	result, err := s.codeRunDAO.BatchGetAcceptedProblems(studentId, problemIds)
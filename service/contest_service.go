@@ -0,0 +1,850 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	codeModel "github.com/yzf120/elysia-backend/model/code"
+	contestModel "github.com/yzf120/elysia-backend/model/contest"
+)
+
+// 比赛阶段
+const (
+	ContestPhaseNotStarted = "not_started" // 未开始
+	ContestPhaseRunning    = "running"     // 进行中
+	ContestPhaseEnded      = "ended"       // 已结束
+)
+
+// contestBoardInitField 榜单 hash 中的初始化标记字段（区分“已构建但无提交”和“缓存不存在”）
+const contestBoardInitField = "__init"
+
+const (
+	contestBoardLockKey  = "contest:scoreboard:lock:%s" // 比赛榜单锁（多实例间串行化重建与增量更新）
+	contestBoardLockTTL  = 30 * time.Second
+	contestBoardLockWait = 5 * time.Second
+)
+
+// contestBoardUnlockScript 只释放自己持有的榜单锁
+var contestBoardUnlockScript = redis.NewScript(`if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) end return 0`)
+
+// ContestService 比赛服务
+type ContestService struct {
+	contestDAO     dao.ContestDAO
+	classDAO       dao.ClassDAO
+	staffService   *ClassStaffService
+	classMemberDAO dao.ClassMemberDAO
+	problemDAO     dao.ProblemDAO
+	codeRunDAO     dao.CodeRunDAO
+	studentDAO     dao.StudentDAO
+	examDAO        dao.ExamDAO
+	redisClient    *client.RedisClient
+	examService    *ExamService
+}
+
+// NewContestService 创建比赛服务
+func NewContestService() *ContestService {
+	return &ContestService{
+		contestDAO:     dao.NewContestDAO(),
+		classDAO:       dao.NewClassDAO(),
+		staffService:   NewClassStaffService(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		problemDAO:     dao.NewProblemDAO(),
+		codeRunDAO:     dao.NewCodeRunDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+		examDAO:        dao.NewExamDAO(),
+		redisClient:    client.GetRedisClient(),
+		examService:    NewExamService(),
+	}
+}
+
+// ContestInput 创建/更新比赛参数
+type ContestInput struct {
+	Title          string
+	Description    string
+	RuleType       string
+	StartTime      string // 格式 2006-01-02 15:04:05
+	EndTime        string // 格式 2006-01-02 15:04:05
+	FreezeMinutes  int32
+	PenaltyMinutes int32
+	ProblemIds     []int64
+	IsExam         bool // 考试模式
+}
+
+// ContestProblem 比赛题目简要信息
+type ContestProblem struct {
+	Label      string `json:"label"` // 题号：A、B、C…
+	ProblemId  int64  `json:"problem_id"`
+	Title      string `json:"title"`
+	Difficulty string `json:"difficulty"`
+}
+
+// ContestDetail 比赛详情
+type ContestDetail struct {
+	Contest  *contestModel.Contest `json:"contest"`
+	Phase    string                `json:"phase"`    // 比赛阶段：not_started / running / ended
+	Problems []*ContestProblem     `json:"problems"` // 学生在比赛开始前不可见
+}
+
+// ContestView 比赛列表项（学生在比赛开始前不返回题目列表）
+type ContestView struct {
+	*contestModel.Contest
+	Phase string `json:"phase"` // 比赛阶段：not_started / running / ended
+}
+
+// ScoreboardCell 榜单单元格（某学生在某题上的情况）
+type ScoreboardCell struct {
+	ProblemId    int64  `json:"problem_id"`
+	Label        string `json:"label"`
+	Accepted     bool   `json:"accepted"`
+	AcceptMinute int64  `json:"accept_minute"` // 通过时间（距比赛开始的分钟数）
+	Tries        int32  `json:"tries"`         // 错误尝试次数（ACM：通过前的错误次数）
+	Score        int32  `json:"score"`         // 最高得分（OI）
+	Frozen       int32  `json:"frozen"`        // 封榜后的提交次数（结果未公布）
+}
+
+// ScoreboardRow 榜单行
+type ScoreboardRow struct {
+	Rank          int               `json:"rank"`
+	StudentId     string            `json:"student_id"`
+	StudentName   string            `json:"student_name"`
+	StudentNumber string            `json:"student_number"`
+	Solved        int32             `json:"solved"`
+	Penalty       int64             `json:"penalty"`     // ACM 总罚时（分钟）
+	TotalScore    int32             `json:"total_score"` // OI 总分
+	Cells         []*ScoreboardCell `json:"cells"`
+}
+
+// Scoreboard 比赛榜单
+type Scoreboard struct {
+	ContestId string            `json:"contest_id"`
+	RuleType  string            `json:"rule_type"`
+	Phase     string            `json:"phase"`
+	Frozen    bool              `json:"frozen"` // 当前是否处于封榜状态
+	Hidden    bool              `json:"hidden"` // OI 赛制比赛结束前对学生隐藏榜单
+	Problems  []*ContestProblem `json:"problems"`
+	Rows      []*ScoreboardRow  `json:"rows"`
+}
+
+// contestProblemState 榜单中学生在某题的累计状态（存入 Redis）
+type contestProblemState struct {
+	Accepted   bool    `json:"accepted"`
+	AcceptAt   int64   `json:"accept_at"`   // 最早通过提交的时间戳（秒）
+	WrongTimes []int64 `json:"wrong_times"` // 错误提交的时间戳（秒），按通过时间计算罚时
+	BestScore  int32   `json:"best_score"`
+	Frozen     int32   `json:"frozen"`
+}
+
+// ==================== 教师操作 ====================
+
+// CreateContest 创建比赛（教师操作）
+func (s *ContestService) CreateContest(teacherId, classId string, input *ContestInput) (*contestModel.Contest, error) {
+	if teacherId == "" || classId == "" || input.Title == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "必填参数不能为空")
+	}
+	if _, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermContest); err != nil {
+		return nil, err
+	}
+
+	c := &contestModel.Contest{
+		ContestId: fmt.Sprintf("ctt_%d", time.Now().UnixNano()),
+		ClassId:   classId,
+		TeacherId: teacherId,
+	}
+	if err := s.fillContest(c, input); err != nil {
+		return nil, err
+	}
+	if err := s.contestDAO.CreateContest(c); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "创建比赛失败: "+err.Error())
+	}
+	return c, nil
+}
+
+// UpdateContest 更新比赛（教师操作，比赛开始后不能修改赛制和题目）
+func (s *ContestService) UpdateContest(teacherId, contestId string, input *ContestInput) error {
+	c, err := s.getOwnedContest(teacherId, contestId)
+	if err != nil {
+		return err
+	}
+	if err := s.staffService.CheckClassWritable(c.ClassId); err != nil {
+		return err
+	}
+	if contestPhase(c, time.Now()) != ContestPhaseNotStarted {
+		if input.RuleType != c.RuleType || input.IsExam != c.IsExam || !sameProblemIds(parseContestProblemIds(c), input.ProblemIds) {
+			return errs.NewCommonError(errs.ErrBadRequest, "比赛已开始，不能修改赛制、考试模式和题目")
+		}
+	}
+	if err := s.fillContest(c, input); err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"title":           c.Title,
+		"description":     c.Description,
+		"rule_type":       c.RuleType,
+		"start_time":      c.StartTime,
+		"end_time":        c.EndTime,
+		"freeze_minutes":  c.FreezeMinutes,
+		"penalty_minutes": c.PenaltyMinutes,
+		"problem_ids":     c.ProblemIds,
+		"is_exam":         c.IsExam,
+	}
+	if err := s.contestDAO.UpdateContest(contestId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新比赛失败: "+err.Error())
+	}
+	// 时间、封榜或罚时设置可能变化，清除榜单缓存，下次读取时重建
+	s.invalidateBoard(contestId)
+	return nil
+}
+
+// DeleteContest 删除比赛（教师操作，同时删除比赛提交记录与考试会话日志）
+func (s *ContestService) DeleteContest(teacherId, contestId string) error {
+	c, err := s.getOwnedContest(teacherId, contestId)
+	if err != nil {
+		return err
+	}
+	if err := s.staffService.CheckClassWritable(c.ClassId); err != nil {
+		return err
+	}
+	if err := s.contestDAO.DeleteSubmissionsByContestId(contestId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "删除比赛提交失败: "+err.Error())
+	}
+	if err := s.examDAO.DeleteByContestId(contestId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "删除考试记录失败: "+err.Error())
+	}
+	if err := s.contestDAO.DeleteContest(contestId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "删除比赛失败: "+err.Error())
+	}
+	s.invalidateBoard(contestId)
+	return nil
+}
+
+// GetTeacherScoreboard 查询实时榜单（教师操作，不受封榜影响）
+func (s *ContestService) GetTeacherScoreboard(teacherId, contestId string) (*Scoreboard, error) {
+	c, err := s.getOwnedContest(teacherId, contestId)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildScoreboard(c, false)
+}
+
+// ListContestSubmissions 查询比赛提交记录（教师操作，studentId 为空表示全部学生）
+func (s *ContestService) ListContestSubmissions(teacherId, contestId, studentId string) ([]*contestModel.ContestSubmission, error) {
+	if _, err := s.getOwnedContest(teacherId, contestId); err != nil {
+		return nil, err
+	}
+	var list []*contestModel.ContestSubmission
+	var err error
+	if studentId != "" {
+		list, err = s.contestDAO.ListSubmissionsByStudent(contestId, studentId)
+	} else {
+		list, err = s.contestDAO.ListSubmissionsByContestId(contestId)
+	}
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询比赛提交失败: "+err.Error())
+	}
+	return list, nil
+}
+
+// ==================== 师生共用 ====================
+
+// ListClassContests 查询班级下的比赛列表（教学团队需有比赛权限，学生需为班级成员）
+func (s *ContestService) ListClassContests(viewerType, viewerId, classId string) ([]*ContestView, error) {
+	if classId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级ID不能为空")
+	}
+	switch viewerType {
+	case consts.RoleTeacher:
+		if _, err := s.staffService.CheckPermission(classId, viewerId, consts.ClassPermContest); err != nil {
+			return nil, err
+		}
+	case consts.RoleStudent:
+		member, err := s.classMemberDAO.GetMember(classId, viewerId)
+		if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+		}
+	default:
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "无权限查看该班级比赛")
+	}
+
+	list, err := s.contestDAO.ListContestsByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询比赛列表失败: "+err.Error())
+	}
+	now := time.Now()
+	views := make([]*ContestView, 0, len(list))
+	for _, c := range list {
+		view := &ContestView{Contest: c, Phase: contestPhase(c, now)}
+		if viewerType == consts.RoleStudent && view.Phase == ContestPhaseNotStarted {
+			hidden := *c
+			hidden.ProblemIds = ""
+			view.Contest = &hidden
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// ==================== 学生操作 ====================
+
+// GetStudentContestDetail 查询比赛详情（学生操作，比赛开始前不返回题目；考试进行中首次查看即绑定会话）
+func (s *ContestService) GetStudentContestDetail(ctx context.Context, studentId, contestId string) (*ContestDetail, error) {
+	c, err := s.getMemberContest(studentId, contestId)
+	if err != nil {
+		return nil, err
+	}
+	phase := contestPhase(c, time.Now())
+	if c.IsExam && phase == ContestPhaseRunning {
+		if _, err := s.examService.BindSession(ctx, studentId, c); err != nil {
+			return nil, err
+		}
+	}
+	detail := &ContestDetail{Contest: c, Phase: phase}
+	if phase != ContestPhaseNotStarted {
+		detail.Problems = s.loadContestProblems(c)
+	}
+	return detail, nil
+}
+
+// GetStudentScoreboard 查询榜单（学生操作，封榜期间显示封榜前结果，OI 赛制结束前隐藏）
+func (s *ContestService) GetStudentScoreboard(studentId, contestId string) (*Scoreboard, error) {
+	c, err := s.getMemberContest(studentId, contestId)
+	if err != nil {
+		return nil, err
+	}
+	phase := contestPhase(c, time.Now())
+	if c.RuleType == contestModel.RuleTypeOI && phase != ContestPhaseEnded {
+		return &Scoreboard{ContestId: c.ContestId, RuleType: c.RuleType, Phase: phase, Hidden: true}, nil
+	}
+	return s.buildScoreboard(c, phase == ContestPhaseRunning)
+}
+
+// ListStudentSubmissions 查询学生自己在比赛中的提交（OI 赛制结束前隐藏评测结果）
+func (s *ContestService) ListStudentSubmissions(studentId, contestId string) ([]*contestModel.ContestSubmission, error) {
+	c, err := s.getMemberContest(studentId, contestId)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.contestDAO.ListSubmissionsByStudent(contestId, studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询比赛提交失败: "+err.Error())
+	}
+	if isVerdictHidden(c, time.Now()) {
+		for _, sub := range list {
+			if sub.Status != "pending" && sub.Status != "running" {
+				sub.Status = "submitted"
+			}
+			sub.Score = 0
+		}
+	}
+	return list, nil
+}
+
+// ==================== 评测联动 ====================
+
+// CheckSubmission 校验比赛内的提交是否允许（比赛进行中、班级成员、题目属于比赛、考试会话一致）
+func (s *ContestService) CheckSubmission(ctx context.Context, studentId, contestId string, problemId int64) (*contestModel.Contest, error) {
+	c, err := s.getMemberContest(studentId, contestId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.staffService.CheckClassWritable(c.ClassId); err != nil {
+		return nil, err
+	}
+	switch contestPhase(c, time.Now()) {
+	case ContestPhaseNotStarted:
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "比赛尚未开始")
+	case ContestPhaseEnded:
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "比赛已结束")
+	}
+	if c.IsExam {
+		if _, err := s.examService.BindSession(ctx, studentId, c); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range parseContestProblemIds(c) {
+		if id == problemId {
+			return c, nil
+		}
+	}
+	return nil, errs.NewCommonError(errs.ErrBadRequest, "题目不属于该比赛")
+}
+
+// CreateSubmitRun 创建比赛内的 submit 运行记录，并在同一事务内记录比赛提交，避免留下无提交记录的运行
+func (s *ContestService) CreateSubmitRun(run *codeModel.CodeRun) error {
+	sub := &contestModel.ContestSubmission{
+		ContestId: run.ContestId,
+		StudentId: run.StudentId,
+		ProblemId: run.ProblemId,
+		Status:    run.Status,
+	}
+	if err := s.contestDAO.CreateRunWithSubmission(run, sub); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "记录比赛提交失败: "+err.Error())
+	}
+	return nil
+}
+
+// OnCodeRunJudged 评测完成后更新比赛提交记录并增量更新榜单缓存
+func (s *ContestService) OnCodeRunJudged(runId int64) {
+	run, err := s.codeRunDAO.GetCodeRunById(runId)
+	if err != nil || run == nil || run.ContestId == "" || run.RunType != "submit" {
+		return
+	}
+	score := calcRunScore(run)
+	if err := s.contestDAO.UpdateSubmissionByRunId(runId, map[string]interface{}{
+		"status": run.Status,
+		"score":  score,
+	}); err != nil {
+		log.Printf("[ContestService] 更新比赛提交失败: run_id=%d, err=%v", runId, err)
+		return
+	}
+	c, err := s.contestDAO.GetContestById(run.ContestId)
+	if err != nil || c == nil {
+		return
+	}
+	sub, err := s.contestDAO.GetSubmissionByRunId(runId)
+	if err != nil || sub == nil {
+		return
+	}
+
+	unlock, ok := s.lockBoard(c.ContestId)
+	if !ok {
+		// 拿不到锁时清除榜单缓存，由下次查询全量重建，避免漏记本次提交
+		s.invalidateBoard(c.ContestId)
+		log.Printf("[ContestService] 获取榜单锁超时，已清除榜单缓存: contest_id=%s", c.ContestId)
+		return
+	}
+	defer unlock()
+
+	ctx := context.Background()
+	liveKey, frozenKey := contestBoardKeys(c.ContestId)
+	exists, err := s.redisClient.Exists(liveKey, frozenKey)
+	if err != nil || exists < 2 {
+		// 缓存不存在时整体重建（数据库中已包含本次结果）
+		if err := s.rebuildBoard(c); err != nil {
+			log.Printf("[ContestService] 重建榜单失败: contest_id=%s, err=%v", c.ContestId, err)
+		}
+		return
+	}
+	for _, view := range []struct {
+		key    string
+		frozen bool
+	}{{liveKey, false}, {frozenKey, true}} {
+		state := make(map[int64]*contestProblemState)
+		if raw, err := s.redisClient.Client.HGet(ctx, view.key, sub.StudentId).Result(); err == nil {
+			_ = json.Unmarshal([]byte(raw), &state)
+		}
+		applyContestSubmission(c, state, sub, view.frozen)
+		stateBytes, _ := json.Marshal(state)
+		if err := s.redisClient.Client.HSet(ctx, view.key, sub.StudentId, string(stateBytes)).Err(); err != nil {
+			log.Printf("[ContestService] 更新榜单缓存失败: contest_id=%s, err=%v", c.ContestId, err)
+			s.invalidateBoard(c.ContestId)
+			return
+		}
+	}
+}
+
+// ShouldHideVerdict 判断运行记录的评测结果是否需要对学生隐藏（OI 赛制比赛结束前）
+func (s *ContestService) ShouldHideVerdict(run *codeModel.CodeRun) bool {
+	if run == nil || run.ContestId == "" {
+		return false
+	}
+	c, err := s.contestDAO.GetContestById(run.ContestId)
+	if err != nil || c == nil {
+		return false
+	}
+	return isVerdictHidden(c, time.Now())
+}
+
+// ==================== 榜单 ====================
+
+// buildScoreboard 构建榜单（frozen=true 时使用封榜视图）
+func (s *ContestService) buildScoreboard(c *contestModel.Contest, frozen bool) (*Scoreboard, error) {
+	states, err := s.loadBoardStates(c, frozen)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询榜单失败: "+err.Error())
+	}
+	members, err := s.classMemberDAO.ListAllMembersByClassId(c.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+
+	now := time.Now()
+	board := &Scoreboard{
+		ContestId: c.ContestId,
+		RuleType:  c.RuleType,
+		Phase:     contestPhase(c, now),
+		Frozen:    frozen && c.FreezeMinutes > 0 && !now.Before(contestFreezeStart(c)),
+		Problems:  s.loadContestProblems(c),
+	}
+
+	studentIds := make([]string, 0, len(members))
+	for _, m := range members {
+		studentIds = append(studentIds, m.StudentId)
+	}
+	studentInfo := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		if students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds}); err == nil {
+			for _, st := range students {
+				studentInfo[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+			}
+		}
+	}
+
+	startUnix := c.StartTime.Unix()
+	for _, m := range members {
+		row := &ScoreboardRow{
+			StudentId:     m.StudentId,
+			StudentName:   studentInfo[m.StudentId][0],
+			StudentNumber: studentInfo[m.StudentId][1],
+		}
+		state := states[m.StudentId]
+		for _, p := range board.Problems {
+			cell := &ScoreboardCell{ProblemId: p.ProblemId, Label: p.Label}
+			if ps := state[p.ProblemId]; ps != nil {
+				cell.Score = ps.BestScore
+				cell.Frozen = ps.Frozen
+				cell.Accepted = ps.Accepted
+				for _, t := range ps.WrongTimes {
+					if !ps.Accepted || t < ps.AcceptAt {
+						cell.Tries++
+					}
+				}
+				if ps.Accepted {
+					cell.AcceptMinute = (ps.AcceptAt - startUnix) / 60
+					row.Solved++
+					row.Penalty += cell.AcceptMinute + int64(cell.Tries)*int64(c.PenaltyMinutes)
+				}
+			}
+			row.TotalScore += cell.Score
+			row.Cells = append(row.Cells, cell)
+		}
+		board.Rows = append(board.Rows, row)
+	}
+
+	less := func(a, b *ScoreboardRow) bool {
+		if c.RuleType == contestModel.RuleTypeOI {
+			return a.TotalScore > b.TotalScore
+		}
+		if a.Solved != b.Solved {
+			return a.Solved > b.Solved
+		}
+		return a.Penalty < b.Penalty
+	}
+	sort.SliceStable(board.Rows, func(i, j int) bool { return less(board.Rows[i], board.Rows[j]) })
+	for i, row := range board.Rows {
+		// 成绩相同名次相同
+		if i > 0 && !less(board.Rows[i-1], row) {
+			row.Rank = board.Rows[i-1].Rank
+		} else {
+			row.Rank = i + 1
+		}
+	}
+	return board, nil
+}
+
+// loadBoardStates 从 Redis 读取榜单状态，缓存不存在时从数据库重建
+func (s *ContestService) loadBoardStates(c *contestModel.Contest, frozen bool) (map[string]map[int64]*contestProblemState, error) {
+	liveKey, frozenKey := contestBoardKeys(c.ContestId)
+	key := liveKey
+	if frozen {
+		key = frozenKey
+	}
+	ctx := context.Background()
+	raw, err := s.redisClient.Client.HGetAll(ctx, key).Result()
+	if err != nil || raw[contestBoardInitField] == "" {
+		unlock, ok := s.lockBoard(c.ContestId)
+		if !ok {
+			return nil, errors.New("获取榜单锁超时，请稍后重试")
+		}
+		// 等锁期间其他实例可能已完成重建
+		raw, err = s.redisClient.Client.HGetAll(ctx, key).Result()
+		if err != nil || raw[contestBoardInitField] == "" {
+			if err = s.rebuildBoard(c); err == nil {
+				raw, err = s.redisClient.Client.HGetAll(ctx, key).Result()
+			}
+		}
+		unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	states := make(map[string]map[int64]*contestProblemState, len(raw))
+	for studentId, value := range raw {
+		if studentId == contestBoardInitField {
+			continue
+		}
+		state := make(map[int64]*contestProblemState)
+		if err := json.Unmarshal([]byte(value), &state); err == nil {
+			states[studentId] = state
+		}
+	}
+	return states, nil
+}
+
+// rebuildBoard 根据数据库中的比赛提交全量重建榜单缓存（调用方需持有比赛榜单锁）
+func (s *ContestService) rebuildBoard(c *contestModel.Contest) error {
+	subs, err := s.contestDAO.ListSubmissionsByContestId(c.ContestId)
+	if err != nil {
+		return err
+	}
+	live := make(map[string]map[int64]*contestProblemState)
+	frozen := make(map[string]map[int64]*contestProblemState)
+	for _, sub := range subs {
+		if live[sub.StudentId] == nil {
+			live[sub.StudentId] = make(map[int64]*contestProblemState)
+			frozen[sub.StudentId] = make(map[int64]*contestProblemState)
+		}
+		applyContestSubmission(c, live[sub.StudentId], sub, false)
+		applyContestSubmission(c, frozen[sub.StudentId], sub, true)
+	}
+
+	ctx := context.Background()
+	liveKey, frozenKey := contestBoardKeys(c.ContestId)
+	ttl := time.Until(c.EndTime) + 7*24*time.Hour
+	if ttl < time.Hour {
+		ttl = time.Hour
+	}
+	pipe := s.redisClient.Client.TxPipeline()
+	for _, view := range []struct {
+		key    string
+		states map[string]map[int64]*contestProblemState
+	}{{liveKey, live}, {frozenKey, frozen}} {
+		fields := map[string]interface{}{contestBoardInitField: "1"}
+		for studentId, state := range view.states {
+			stateBytes, _ := json.Marshal(state)
+			fields[studentId] = string(stateBytes)
+		}
+		pipe.Del(ctx, view.key)
+		pipe.HSet(ctx, view.key, fields)
+		pipe.Expire(ctx, view.key, ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// lockBoard 获取比赛榜单锁（Redis SetNX，等待至多 contestBoardLockWait），返回释放函数与是否获取成功
+func (s *ContestService) lockBoard(contestId string) (func(), bool) {
+	ctx := context.Background()
+	key := fmt.Sprintf(contestBoardLockKey, contestId)
+	token := strconv.FormatInt(time.Now().UnixNano(), 10)
+	deadline := time.Now().Add(contestBoardLockWait)
+	for {
+		ok, err := s.redisClient.Client.SetNX(ctx, key, token, contestBoardLockTTL).Result()
+		if err != nil {
+			log.Printf("[ContestService] 获取榜单锁失败: contest_id=%s, err=%v", contestId, err)
+			return nil, false
+		}
+		if ok {
+			return func() {
+				_ = contestBoardUnlockScript.Run(ctx, s.redisClient.Client, []string{key}, token).Err()
+			}, true
+		}
+		if time.Now().After(deadline) {
+			return nil, false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// invalidateBoard 清除榜单缓存
+func (s *ContestService) invalidateBoard(contestId string) {
+	liveKey, frozenKey := contestBoardKeys(contestId)
+	_ = s.redisClient.Del(liveKey, frozenKey)
+}
+
+// applyContestSubmission 将一次已评测的提交累加到学生的榜单状态
+// frozenView=true 时，封榜后的提交只计入 Frozen 次数，不公布结果
+func applyContestSubmission(c *contestModel.Contest, state map[int64]*contestProblemState, sub *contestModel.ContestSubmission, frozenView bool) {
+	if sub.Status == "pending" || sub.Status == "running" {
+		return
+	}
+	ps := state[sub.ProblemId]
+	if ps == nil {
+		ps = &contestProblemState{}
+		state[sub.ProblemId] = ps
+	}
+	if frozenView && c.FreezeMinutes > 0 && !sub.SubmitTime.Before(contestFreezeStart(c)) {
+		ps.Frozen++
+		return
+	}
+	if sub.Score > ps.BestScore {
+		ps.BestScore = sub.Score
+	}
+	submitAt := sub.SubmitTime.Unix()
+	if sub.Status == "accepted" {
+		if !ps.Accepted || submitAt < ps.AcceptAt {
+			ps.Accepted = true
+			ps.AcceptAt = submitAt
+		}
+		return
+	}
+	// 编译错误不计罚时
+	if sub.Status != "compile_error" {
+		ps.WrongTimes = append(ps.WrongTimes, submitAt)
+	}
+}
+
+// ==================== 内部工具 ====================
+
+// fillContest 校验并填充比赛参数
+func (s *ContestService) fillContest(c *contestModel.Contest, input *ContestInput) error {
+	if input.Title == "" {
+		return errs.NewCommonError(errs.ErrBadRequest, "比赛标题不能为空")
+	}
+	ruleType := strings.ToLower(input.RuleType)
+	if ruleType == "" {
+		ruleType = contestModel.RuleTypeACM
+	}
+	if ruleType != contestModel.RuleTypeACM && ruleType != contestModel.RuleTypeOI {
+		return errs.NewCommonError(errs.ErrBadRequest, "赛制不合法（acm / oi）")
+	}
+	startAt, err := parseOptionalTime(input.StartTime)
+	if err != nil || startAt == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "开始时间格式错误")
+	}
+	endAt, err := parseOptionalTime(input.EndTime)
+	if err != nil || endAt == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "结束时间格式错误")
+	}
+	if !endAt.After(*startAt) {
+		return errs.NewCommonError(errs.ErrBadRequest, "结束时间必须晚于开始时间")
+	}
+	if input.FreezeMinutes < 0 || time.Duration(input.FreezeMinutes)*time.Minute > endAt.Sub(*startAt) {
+		return errs.NewCommonError(errs.ErrBadRequest, "封榜时长不合法")
+	}
+	if input.PenaltyMinutes < 0 {
+		return errs.NewCommonError(errs.ErrBadRequest, "罚时不能为负数")
+	}
+	if len(input.ProblemIds) == 0 {
+		return errs.NewCommonError(errs.ErrBadRequest, "比赛题目不能为空")
+	}
+	seen := make(map[int64]bool, len(input.ProblemIds))
+	for _, id := range input.ProblemIds {
+		if seen[id] {
+			return errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("题目重复: %d", id))
+		}
+		seen[id] = true
+		if p, err := s.problemDAO.GetProblemById(id); err != nil || p == nil {
+			return errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("题目不存在: %d", id))
+		}
+	}
+	problemIdsJSON, _ := json.Marshal(input.ProblemIds)
+
+	c.Title = input.Title
+	c.Description = input.Description
+	c.RuleType = ruleType
+	c.StartTime = *startAt
+	c.EndTime = *endAt
+	c.FreezeMinutes = input.FreezeMinutes
+	c.PenaltyMinutes = input.PenaltyMinutes
+	c.ProblemIds = string(problemIdsJSON)
+	c.IsExam = input.IsExam
+	return nil
+}
+
+// getOwnedContest 查询比赛并校验教师归属
+func (s *ContestService) getOwnedContest(teacherId, contestId string) (*contestModel.Contest, error) {
+	c, err := s.contestDAO.GetContestById(contestId)
+	if err != nil || c == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "比赛不存在")
+	}
+	if _, err := s.staffService.CheckPermission(c.ClassId, teacherId, consts.ClassPermContest); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// getMemberContest 查询比赛并校验学生为班级成员
+func (s *ContestService) getMemberContest(studentId, contestId string) (*contestModel.Contest, error) {
+	c, err := s.contestDAO.GetContestById(contestId)
+	if err != nil || c == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "比赛不存在")
+	}
+	member, err := s.classMemberDAO.GetMember(c.ClassId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	return c, nil
+}
+
+// loadContestProblems 查询比赛题目简要信息（按题号顺序）
+func (s *ContestService) loadContestProblems(c *contestModel.Contest) []*ContestProblem {
+	ids := parseContestProblemIds(c)
+	problems := make([]*ContestProblem, 0, len(ids))
+	for i, id := range ids {
+		item := &ContestProblem{Label: contestProblemLabel(i), ProblemId: id}
+		if p, err := s.problemDAO.GetProblemById(id); err == nil && p != nil {
+			item.Title = p.Title
+			item.Difficulty = p.Difficulty
+		}
+		problems = append(problems, item)
+	}
+	return problems
+}
+
+// contestBoardKeys 榜单缓存 key（实时视图、封榜视图）
+func contestBoardKeys(contestId string) (string, string) {
+	return fmt.Sprintf("contest:scoreboard:%s:live", contestId), fmt.Sprintf("contest:scoreboard:%s:frozen", contestId)
+}
+
+// contestPhase 计算比赛阶段
+func contestPhase(c *contestModel.Contest, now time.Time) string {
+	if now.Before(c.StartTime) {
+		return ContestPhaseNotStarted
+	}
+	if now.Before(c.EndTime) {
+		return ContestPhaseRunning
+	}
+	return ContestPhaseEnded
+}
+
+// contestFreezeStart 封榜开始时间
+func contestFreezeStart(c *contestModel.Contest) time.Time {
+	return c.EndTime.Add(-time.Duration(c.FreezeMinutes) * time.Minute)
+}
+
+// isVerdictHidden OI 赛制比赛结束前不公布评测结果
+func isVerdictHidden(c *contestModel.Contest, now time.Time) bool {
+	return c.RuleType == contestModel.RuleTypeOI && now.Before(c.EndTime)
+}
+
+// parseContestProblemIds 解析比赛题目ID列表
+func parseContestProblemIds(c *contestModel.Contest) []int64 {
+	var ids []int64
+	if c.ProblemIds != "" {
+		_ = json.Unmarshal([]byte(c.ProblemIds), &ids)
+	}
+	return ids
+}
+
+// sameProblemIds 判断题目列表是否一致（含顺序）
+func sameProblemIds(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// contestProblemLabel 题号：A-Z，超过 26 题使用 P27、P28…
+func contestProblemLabel(index int) string {
+	if index < 26 {
+		return string(rune('A' + index))
+	}
+	return fmt.Sprintf("P%d", index+1)
+}
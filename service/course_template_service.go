@@ -0,0 +1,506 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/model/problem"
+)
+
+const (
+	courseTemplateFormat      = "elysia-course-template"
+	courseTemplateVersion     = 1             // 当前模板格式版本（导入时拒绝更高版本）
+	courseTemplateEntryName   = "course.json" // 压缩包内的模板文件名
+	courseTemplateMaxFileSize = 20 << 20
+)
+
+// CourseTemplate 课程模板（班级的章节、小节、关联题目及测验题目，不含成员、成绩与作业时间）
+type CourseTemplate struct {
+	Format      string                   `json:"format"`
+	Version     int                      `json:"version"`
+	ExportTime  string                   `json:"export_time"`
+	ClassName   string                   `json:"class_name"`
+	Subject     string                   `json:"subject"`
+	Description string                   `json:"description"`
+	Chapters    []*CourseTemplateChapter `json:"chapters"`
+	Problems    []*CourseTemplateProblem `json:"problems"` // 小节关联的题目（含测试用例，按 title_slug 引用）
+}
+
+// CourseTemplateChapter 模板章节
+type CourseTemplateChapter struct {
+	Key         string                   `json:"key"` // 模板内唯一标识（导出时为原章节ID）
+	Title       string                   `json:"title"`
+	Description string                   `json:"description"`
+	Weight      int32                    `json:"weight"`
+	Sections    []*CourseTemplateSection `json:"sections"`
+}
+
+// CourseTemplateSection 模板小节
+type CourseTemplateSection struct {
+	Key               string                    `json:"key"` // 模板内唯一标识（导出时为原小节ID）
+	Title             string                    `json:"title"`
+	Description       string                    `json:"description"`
+	SectionType       int32                     `json:"section_type"`
+	ProblemSlug       string                    `json:"problem_slug,omitempty"` // 关联题目的 title_slug（算法题小节）
+	DiscussionTitle   string                    `json:"discussion_title,omitempty"`
+	DiscussionContent string                    `json:"discussion_content,omitempty"`
+	LatePolicy        int32                     `json:"late_policy"`
+	LatePenalty       int32                     `json:"late_penalty"`
+	MaxAttempts       int32                     `json:"max_attempts"`
+	QuizShuffle       bool                      `json:"quiz_shuffle"`
+	QuizTimeLimit     int32                     `json:"quiz_time_limit"`
+	BookshelfItemId   string                    `json:"bookshelf_item_id,omitempty"`
+	VideoUrl          string                    `json:"video_url,omitempty"`
+	GroupMode         bool                      `json:"group_mode,omitempty"`
+	Prerequisites     []string                  `json:"prerequisites"` // 前置小节的 key
+	Questions         []*CourseTemplateQuestion `json:"questions,omitempty"`
+}
+
+// CourseTemplateQuestion 模板测验题目
+type CourseTemplateQuestion struct {
+	QuestionType string          `json:"question_type"`
+	Content      string          `json:"content"`
+	Options      json.RawMessage `json:"options"`
+	Answer       json.RawMessage `json:"answer"`
+	Explanation  string          `json:"explanation"`
+	Score        int32           `json:"score"`
+}
+
+// CourseTemplateProblem 模板题目
+type CourseTemplateProblem struct {
+	TitleSlug           string          `json:"title_slug"`
+	Title               string          `json:"title"`
+	Difficulty          string          `json:"difficulty"`
+	Tags                string          `json:"tags"`
+	Description         string          `json:"description"`
+	Explanation         string          `json:"explanation"`
+	Hint                string          `json:"hint"`
+	Constraints         string          `json:"constraints"`
+	AdvancedRequirement string          `json:"advanced_requirement"`
+	TestCases           json.RawMessage `json:"test_cases"`
+	Showcase            json.RawMessage `json:"showcase"`
+	TimeLimit           int             `json:"time_limit"`
+	MemoryLimit         int             `json:"memory_limit"`
+}
+
+// CourseTemplateImportResult 课程模板导入结果
+type CourseTemplateImportResult struct {
+	ChaptersCreated  int      `json:"chapters_created"`
+	SectionsCreated  int      `json:"sections_created"`
+	QuestionsCreated int      `json:"questions_created"`
+	ProblemsCreated  int      `json:"problems_created"` // 题库中新建的题目数
+	ProblemsReused   int      `json:"problems_reused"`  // 按 title_slug 复用已有题目数
+	Warnings         []string `json:"warnings"`         // 未能完整导入的内容说明
+}
+
+// ExportCourseTemplate 导出班级课程模板（需章节编辑权限），format 为 zip（默认）或 json，返回 (文件内容, 文件名, 错误)
+// 上传的阅读资料文件、作业与发布时间不导出
+func (s *ChapterService) ExportCourseTemplate(teacherId, classId, format string) ([]byte, string, error) {
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "json" {
+		return nil, "", errs.NewCommonError(errs.ErrBadRequest, "导出格式不合法（zip/json）")
+	}
+	cls, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermChapterEdit)
+	if err != nil {
+		return nil, "", err
+	}
+	tpl, err := s.buildCourseTemplate(cls)
+	if err != nil {
+		return nil, "", err
+	}
+	content, err := json.MarshalIndent(tpl, "", "  ")
+	if err != nil {
+		return nil, "", errs.NewCommonError(errs.ErrInternal, "生成课程模板失败: "+err.Error())
+	}
+	fileName := cls.ClassName + "-课程模板"
+	if format == "json" {
+		return content, fileName + ".json", nil
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	entry, err := zw.Create(courseTemplateEntryName)
+	if err == nil {
+		_, err = entry.Write(content)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		return nil, "", errs.NewCommonError(errs.ErrInternal, "生成课程模板压缩包失败: "+err.Error())
+	}
+	return buf.Bytes(), fileName + ".zip", nil
+}
+
+// buildCourseTemplate 读取班级课程结构生成模板
+func (s *ChapterService) buildCourseTemplate(cls *classModel.Class) (*CourseTemplate, error) {
+	chapters, err := s.chapterDAO.ListChaptersByClassId(cls.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询章节失败: "+err.Error())
+	}
+	sections, err := s.chapterDAO.ListSectionsByClassId(cls.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询小节失败: "+err.Error())
+	}
+	sectionIds := make([]string, 0, len(sections))
+	for _, sec := range sections {
+		sectionIds = append(sectionIds, sec.SectionId)
+	}
+	questions, err := s.quizDAO.ListQuestionsBySectionIds(sectionIds)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询测验题目失败: "+err.Error())
+	}
+	questionMap := make(map[string][]*CourseTemplateQuestion)
+	for _, q := range questions {
+		questionMap[q.SectionId] = append(questionMap[q.SectionId], &CourseTemplateQuestion{
+			QuestionType: q.QuestionType,
+			Content:      q.Content,
+			Options:      rawJSON(q.Options, "[]"),
+			Answer:       rawJSON(q.Answer, "[]"),
+			Explanation:  q.Explanation,
+			Score:        q.Score,
+		})
+	}
+	sectionMap := make(map[string][]*classModel.ClassSection)
+	for _, sec := range sections {
+		sectionMap[sec.ChapterId] = append(sectionMap[sec.ChapterId], sec)
+	}
+
+	tpl := &CourseTemplate{
+		Format:      courseTemplateFormat,
+		Version:     courseTemplateVersion,
+		ExportTime:  formatTime(time.Now()),
+		ClassName:   cls.ClassName,
+		Subject:     cls.Subject,
+		Description: cls.Description,
+		Chapters:    make([]*CourseTemplateChapter, 0, len(chapters)),
+		Problems:    make([]*CourseTemplateProblem, 0),
+	}
+	exported := make(map[string]bool) // 已导出的题目 title_slug
+	for _, ch := range chapters {
+		tch := &CourseTemplateChapter{
+			Key:         ch.ChapterId,
+			Title:       ch.Title,
+			Description: ch.Description,
+			Weight:      ch.Weight,
+			Sections:    make([]*CourseTemplateSection, 0, len(sectionMap[ch.ChapterId])),
+		}
+		for _, sec := range sectionMap[ch.ChapterId] {
+			tsec := &CourseTemplateSection{
+				Key:               sec.SectionId,
+				Title:             sec.Title,
+				Description:       sec.Description,
+				SectionType:       sec.SectionType,
+				DiscussionTitle:   sec.DiscussionTitle,
+				DiscussionContent: sec.DiscussionContent,
+				LatePolicy:        sec.LatePolicy,
+				LatePenalty:       sec.LatePenalty,
+				MaxAttempts:       sec.MaxAttempts,
+				QuizShuffle:       sec.QuizShuffle,
+				QuizTimeLimit:     sec.QuizTimeLimit,
+				VideoUrl:          sec.VideoUrl,
+				GroupMode:         sec.GroupMode,
+				Prerequisites:     parseStringList(sec.PrerequisiteIds),
+				Questions:         questionMap[sec.SectionId],
+			}
+			if sec.ResourceSource == classModel.ResourceSourceBookshelf {
+				tsec.BookshelfItemId = sec.BookshelfItemId
+			}
+			if sec.ProblemId != "" {
+				if id, err := strconv.ParseInt(sec.ProblemId, 10, 64); err == nil {
+					if p, err := s.problemDAO.GetProblemById(id); err == nil && p != nil {
+						tsec.ProblemSlug = p.TitleSlug
+						if !exported[p.TitleSlug] {
+							exported[p.TitleSlug] = true
+							tpl.Problems = append(tpl.Problems, toTemplateProblem(p))
+						}
+					}
+				}
+			}
+			tch.Sections = append(tch.Sections, tsec)
+		}
+		tpl.Chapters = append(tpl.Chapters, tch)
+	}
+	return tpl, nil
+}
+
+// ImportCourseTemplate 将课程模板导入班级（需章节编辑权限，章节追加在已有章节之后）
+// 题目按 title_slug 去重：题库已有同标识的题目时直接引用，否则新建；章节、小节与测验题目生成新ID并重建前置小节关系
+func (s *ChapterService) ImportCourseTemplate(teacherId, classId, fileName string, content []byte) (*CourseTemplateImportResult, error) {
+	if _, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return nil, err
+	}
+	tpl, err := parseCourseTemplate(fileName, content)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CourseTemplateImportResult{Warnings: make([]string, 0)}
+	problemIds, err := s.importTemplateProblems(tpl.Problems, result)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, _ := s.chapterDAO.ListChaptersByClassId(classId)
+	chapterSort := int32(0)
+	if len(existing) > 0 {
+		chapterSort = existing[len(existing)-1].SortOrder
+	}
+
+	seq := time.Now().UnixNano()
+	sectionIdMap := make(map[string]string) // 模板小节 key -> 新小节ID
+	for _, tch := range tpl.Chapters {
+		for _, tsec := range tch.Sections {
+			seq++
+			if tsec.Key == "" {
+				tsec.Key = fmt.Sprintf("auto_%d", seq)
+			}
+			sectionIdMap[tsec.Key] = fmt.Sprintf("sec_%d", seq)
+		}
+	}
+
+	newChapters := make([]*classModel.ClassChapter, 0, len(tpl.Chapters))
+	newSections := make([]*classModel.ClassSection, 0)
+	newQuestions := make([]*classModel.ClassQuizQuestion, 0)
+	for _, tch := range tpl.Chapters {
+		if strings.TrimSpace(tch.Title) == "" {
+			result.Warnings = append(result.Warnings, "已跳过标题为空的章节")
+			continue
+		}
+		seq++
+		chapterSort += 10
+		chapter := &classModel.ClassChapter{
+			ChapterId:   fmt.Sprintf("chap_%d", seq),
+			ClassId:     classId,
+			Title:       tch.Title,
+			Description: tch.Description,
+			SortOrder:   chapterSort,
+			Weight:      tch.Weight,
+			Status:      1,
+		}
+		newChapters = append(newChapters, chapter)
+
+		for i, tsec := range tch.Sections {
+			if tsec.SectionType < classModel.SectionTypeProblem || tsec.SectionType > classModel.SectionTypeVideo || strings.TrimSpace(tsec.Title) == "" {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("已跳过不合法的小节：%s", tsec.Title))
+				delete(sectionIdMap, tsec.Key)
+				continue
+			}
+			section := &classModel.ClassSection{
+				SectionId:         sectionIdMap[tsec.Key],
+				ChapterId:         chapter.ChapterId,
+				ClassId:           classId,
+				Title:             tsec.Title,
+				Description:       tsec.Description,
+				SectionType:       tsec.SectionType,
+				DiscussionTitle:   tsec.DiscussionTitle,
+				DiscussionContent: tsec.DiscussionContent,
+				LatePolicy:        tsec.LatePolicy,
+				LatePenalty:       tsec.LatePenalty,
+				MaxAttempts:       tsec.MaxAttempts,
+				QuizShuffle:       tsec.QuizShuffle,
+				QuizTimeLimit:     tsec.QuizTimeLimit,
+				VideoUrl:          tsec.VideoUrl,
+				GroupMode:         tsec.GroupMode,
+				SortOrder:         int32(i+1) * 10,
+				Status:            1,
+			}
+			if tsec.BookshelfItemId != "" {
+				section.ResourceSource = classModel.ResourceSourceBookshelf
+				section.BookshelfItemId = tsec.BookshelfItemId
+			} else if tsec.SectionType == classModel.SectionTypeDocument {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("小节「%s」的上传资料未包含在模板中，请重新上传", tsec.Title))
+			}
+			if tsec.ProblemSlug != "" {
+				if id, ok := problemIds[tsec.ProblemSlug]; ok {
+					section.ProblemId = strconv.FormatInt(id, 10)
+				} else {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("小节「%s」关联的题目 %s 未能导入，请重新关联", tsec.Title, tsec.ProblemSlug))
+				}
+			}
+			newSections = append(newSections, section)
+
+			for j, q := range tsec.Questions {
+				seq++
+				newQuestions = append(newQuestions, &classModel.ClassQuizQuestion{
+					QuestionId:   fmt.Sprintf("qq_%d", seq),
+					SectionId:    section.SectionId,
+					ClassId:      classId,
+					QuestionType: q.QuestionType,
+					Content:      q.Content,
+					Options:      string(rawJSON(string(q.Options), "[]")),
+					Answer:       string(rawJSON(string(q.Answer), "[]")),
+					Explanation:  q.Explanation,
+					Score:        q.Score,
+					SortOrder:    int32(j+1) * 10,
+				})
+			}
+		}
+	}
+
+	// 前置小节映射为新小节ID（模板外或已跳过的前置小节忽略）
+	sectionKeys := make(map[string]string, len(sectionIdMap))
+	for key, id := range sectionIdMap {
+		sectionKeys[id] = key
+	}
+	templateSections := make(map[string]*CourseTemplateSection)
+	for _, tch := range tpl.Chapters {
+		for _, tsec := range tch.Sections {
+			templateSections[tsec.Key] = tsec
+		}
+	}
+	for _, sec := range newSections {
+		prereqs := make([]string, 0)
+		for _, key := range templateSections[sectionKeys[sec.SectionId]].Prerequisites {
+			if id, ok := sectionIdMap[key]; ok && id != sec.SectionId {
+				prereqs = append(prereqs, id)
+			}
+		}
+		prereqsJSON, _ := json.Marshal(prereqs)
+		sec.PrerequisiteIds = string(prereqsJSON)
+	}
+
+	if err := s.chapterDAO.CreateContent(newChapters, newSections, newQuestions); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "导入课程模板失败: "+err.Error())
+	}
+	s.syncChapterIds(classId)
+
+	result.ChaptersCreated = len(newChapters)
+	result.SectionsCreated = len(newSections)
+	result.QuestionsCreated = len(newQuestions)
+	return result, nil
+}
+
+// importTemplateProblems 按 title_slug 复用或新建模板中的题目，返回 title_slug 到题目ID的映射
+func (s *ChapterService) importTemplateProblems(problems []*CourseTemplateProblem, result *CourseTemplateImportResult) (map[string]int64, error) {
+	ids := make(map[string]int64, len(problems))
+	for _, tp := range problems {
+		slug := strings.TrimSpace(tp.TitleSlug)
+		if slug == "" {
+			continue
+		}
+		if _, ok := ids[slug]; ok {
+			continue
+		}
+		if p, err := s.problemDAO.GetProblemBySlug(slug); err == nil && p != nil {
+			ids[slug] = p.Id
+			result.ProblemsReused++
+			continue
+		}
+		if tp.Title == "" || tp.Description == "" || len(tp.TestCases) == 0 || string(tp.TestCases) == "null" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("题目 %s 缺少标题、描述或测试用例，未导入", slug))
+			continue
+		}
+		p := &problem.Problem{
+			Title:               tp.Title,
+			TitleSlug:           slug,
+			Difficulty:          tp.Difficulty,
+			Tags:                tp.Tags,
+			Description:         tp.Description,
+			Explanation:         tp.Explanation,
+			Hint:                tp.Hint,
+			Constraints:         tp.Constraints,
+			AdvancedRequirement: tp.AdvancedRequirement,
+			TestCases:           string(tp.TestCases),
+			Showcase:            string(rawJSON(string(tp.Showcase), "[]")),
+			TimeLimit:           tp.TimeLimit,
+			MemoryLimit:         tp.MemoryLimit,
+		}
+		if p.Difficulty == "" {
+			p.Difficulty = "简单"
+		}
+		if err := s.problemDAO.CreateProblem(p); err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, fmt.Sprintf("创建题目 %s 失败: %s", slug, err.Error()))
+		}
+		ids[slug] = p.Id
+		result.ProblemsCreated++
+	}
+	return ids, nil
+}
+
+// parseCourseTemplate 解析上传的模板文件（zip 压缩包内的 course.json，或直接上传 JSON）并校验格式与版本
+func parseCourseTemplate(fileName string, content []byte) (*CourseTemplate, error) {
+	if len(content) == 0 {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "模板文件为空")
+	}
+	data := content
+	if strings.EqualFold(filepath.Ext(fileName), ".zip") || bytes.HasPrefix(content, []byte("PK")) {
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "模板压缩包解析失败: "+err.Error())
+		}
+		data = nil
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) != courseTemplateEntryName {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, errs.NewCommonError(errs.ErrBadRequest, "读取模板文件失败: "+err.Error())
+			}
+			data, err = io.ReadAll(io.LimitReader(rc, courseTemplateMaxFileSize+1))
+			rc.Close()
+			if err != nil {
+				return nil, errs.NewCommonError(errs.ErrBadRequest, "读取模板文件失败: "+err.Error())
+			}
+			break
+		}
+		if data == nil {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "压缩包中缺少 "+courseTemplateEntryName)
+		}
+		if len(data) > courseTemplateMaxFileSize {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "模板文件过大")
+		}
+	}
+
+	tpl := &CourseTemplate{}
+	if err := json.Unmarshal(data, tpl); err != nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "模板文件格式错误: "+err.Error())
+	}
+	if tpl.Format != courseTemplateFormat {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "不是有效的课程模板文件")
+	}
+	if tpl.Version <= 0 || tpl.Version > courseTemplateVersion {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("不支持的模板版本：%d（当前支持 %d 及以下）", tpl.Version, courseTemplateVersion))
+	}
+	return tpl, nil
+}
+
+// toTemplateProblem 题目转换为模板题目
+func toTemplateProblem(p *problem.Problem) *CourseTemplateProblem {
+	return &CourseTemplateProblem{
+		TitleSlug:           p.TitleSlug,
+		Title:               p.Title,
+		Difficulty:          p.Difficulty,
+		Tags:                p.Tags,
+		Description:         p.Description,
+		Explanation:         p.Explanation,
+		Hint:                p.Hint,
+		Constraints:         p.Constraints,
+		AdvancedRequirement: p.AdvancedRequirement,
+		TestCases:           rawJSON(p.TestCases, "[]"),
+		Showcase:            rawJSON(p.Showcase, "[]"),
+		TimeLimit:           p.TimeLimit,
+		MemoryLimit:         p.MemoryLimit,
+	}
+}
+
+// rawJSON 将数据库中的 JSON 字符串转为 RawMessage（为空或不合法时使用默认值）
+func rawJSON(s, fallback string) json.RawMessage {
+	if s == "" || !json.Valid([]byte(s)) {
+		return json.RawMessage(fallback)
+	}
+	return json.RawMessage(s)
+}
@@ -0,0 +1,579 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/model/discussion"
+)
+
+// discussionContentMaxLen 帖子正文最大长度（字符）
+const discussionContentMaxLen = 20000
+
+// discussionExcerptLen 通知中的帖子摘要长度（字符）
+const discussionExcerptLen = 80
+
+// mentionPattern 匹配 @学号 或 @姓名（以空白或标点结束）
+var mentionPattern = regexp.MustCompile(`@([^\s@,，。.:：;；!！?？()（）\[\]]+)`)
+
+// 教师审核操作
+const (
+	ModerateActionHide   = "hide"
+	ModerateActionUnhide = "unhide"
+	ModerateActionDelete = "delete"
+)
+
+// DiscussionService 讨论区服务（讨论类小节下的主题帖与回复）
+type DiscussionService struct {
+	discussionDAO   dao.DiscussionDAO
+	chapterDAO      dao.ChapterDAO
+	classDAO        dao.ClassDAO
+	staffService    *ClassStaffService
+	classMemberDAO  dao.ClassMemberDAO
+	studentDAO      dao.StudentDAO
+	teacherDAO      dao.TeacherDAO
+	accessService   *SectionAccessService
+	progressService *ProgressService
+	groupService    *ClassGroupService
+}
+
+// NewDiscussionService 创建讨论区服务
+func NewDiscussionService() *DiscussionService {
+	return &DiscussionService{
+		discussionDAO:   dao.NewDiscussionDAO(),
+		chapterDAO:      dao.NewChapterDAO(),
+		classDAO:        dao.NewClassDAO(),
+		staffService:    NewClassStaffService(),
+		classMemberDAO:  dao.NewClassMemberDAO(),
+		studentDAO:      dao.NewStudentDAO(),
+		teacherDAO:      dao.NewTeacherDAO(),
+		accessService:   NewSectionAccessService(),
+		progressService: NewProgressService(),
+		groupService:    NewClassGroupService(),
+	}
+}
+
+// PostView 帖子视图
+type PostView struct {
+	*discussion.DiscussionPost
+	AuthorName string `json:"author_name"`
+	Liked      bool   `json:"liked"` // 当前用户是否已点赞
+}
+
+// ThreadDetail 主题帖详情（主题帖与分页回复，回复按 parent_id 组织嵌套）
+type ThreadDetail struct {
+	Thread  *PostView   `json:"thread"`
+	Replies []*PostView `json:"replies"`
+	Total   int64       `json:"total"`
+}
+
+// ==================== 师生共用 ====================
+
+// CreatePost 发帖或回复（authorType 为 student / teacher，parentId 为空表示发主题帖）
+func (s *DiscussionService) CreatePost(authorType, authorId, sectionId, parentId, content string) (*PostView, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "内容不能为空")
+	}
+	if len([]rune(content)) > discussionContentMaxLen {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("内容不能超过%d字", discussionContentMaxLen))
+	}
+	section, err := s.getAccessibleSection(authorType, authorId, sectionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.staffService.CheckClassWritable(section.ClassId); err != nil {
+		return nil, err
+	}
+
+	post := &discussion.DiscussionPost{
+		PostId:     fmt.Sprintf("dsp_%d", time.Now().UnixNano()),
+		ClassId:    section.ClassId,
+		SectionId:  section.SectionId,
+		AuthorId:   authorId,
+		AuthorType: authorType,
+		Content:    content,
+		Status:     discussion.PostStatusNormal,
+	}
+	post.RootId = post.PostId
+
+	var parent *discussion.DiscussionPost
+	if parentId != "" {
+		parent, err = s.discussionDAO.GetPostById(parentId)
+		if err != nil || parent == nil || parent.SectionId != section.SectionId || parent.Status != discussion.PostStatusNormal ||
+			!s.canSeeGroup(authorType, authorId, section, parent.GroupId) {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "回复的帖子不存在")
+		}
+		post.ParentId = parent.PostId
+		post.RootId = parent.RootId
+		post.GroupId = parent.GroupId
+	} else if section.GroupMode && authorType == discussion.AuthorTypeStudent {
+		// 小组模式下学生发布的主题帖仅本组可见，教师发布的主题帖全班可见
+		member := s.groupService.GetStudentGroup(section.ClassId, authorId)
+		if member == nil {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "该讨论为小组讨论，请先加入小组")
+		}
+		post.GroupId = member.GroupId
+	}
+
+	if err := s.discussionDAO.CreatePost(post); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "发帖失败: "+err.Error())
+	}
+	if parent != nil {
+		if err := s.discussionDAO.IncrReplyCount(post.RootId, 1); err != nil {
+			log.Printf("[DiscussionService] 更新回复数失败: root_id=%s, err=%v", post.RootId, err)
+		}
+	}
+
+	if authorType == discussion.AuthorTypeStudent {
+		s.progressService.RefreshStudentProgress(post.ClassId, authorId)
+	}
+
+	authorName := s.loadAuthorNames([]*discussion.DiscussionPost{post})[authorKey(post)]
+	s.notify(post, parent, authorName)
+	return &PostView{DiscussionPost: post, AuthorName: authorName}, nil
+}
+
+// ListThreads 分页查询小节下的主题帖（教师可见已隐藏的帖子）
+func (s *DiscussionService) ListThreads(viewerType, viewerId, sectionId string, page, pageSize int32) ([]*PostView, int64, error) {
+	section, err := s.getAccessibleSection(viewerType, viewerId, sectionId)
+	if err != nil {
+		return nil, 0, err
+	}
+	p, size := normalizePage(int(page), int(pageSize))
+	statuses := visiblePostStatuses(viewerType)
+	groupIds := s.visibleGroupIds(viewerType, viewerId, section)
+	list, err := s.discussionDAO.ListThreads(sectionId, groupIds, statuses, int32(size), int32((p-1)*size))
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "查询讨论列表失败: "+err.Error())
+	}
+	total, err := s.discussionDAO.CountThreads(sectionId, groupIds, statuses)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "统计讨论数量失败: "+err.Error())
+	}
+	return s.buildPostViews(viewerId, list), total, nil
+}
+
+// GetThread 查询主题帖及分页回复
+func (s *DiscussionService) GetThread(viewerType, viewerId, postId string, page, pageSize int32) (*ThreadDetail, error) {
+	root, err := s.discussionDAO.GetPostById(postId)
+	if err != nil || root == nil || root.ParentId != "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "主题帖不存在")
+	}
+	section, err := s.getAccessibleSection(viewerType, viewerId, root.SectionId)
+	if err != nil {
+		return nil, err
+	}
+	statuses := visiblePostStatuses(viewerType)
+	if !containsStatus(statuses, root.Status) || !s.canSeeGroup(viewerType, viewerId, section, root.GroupId) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "主题帖不存在")
+	}
+	p, size := normalizePage(int(page), int(pageSize))
+	replies, err := s.discussionDAO.ListReplies(root.PostId, statuses, int32(size), int32((p-1)*size))
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询回复失败: "+err.Error())
+	}
+	total, err := s.discussionDAO.CountReplies(root.PostId, statuses)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "统计回复数量失败: "+err.Error())
+	}
+	views := s.buildPostViews(viewerId, append([]*discussion.DiscussionPost{root}, replies...))
+	return &ThreadDetail{Thread: views[0], Replies: views[1:], Total: total}, nil
+}
+
+// SetLike 点赞/取消点赞，返回最新点赞数
+func (s *DiscussionService) SetLike(viewerType, viewerId, postId string, like bool) (int32, error) {
+	post, err := s.getVisiblePost(viewerType, viewerId, postId)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.staffService.CheckClassWritable(post.ClassId); err != nil {
+		return 0, err
+	}
+	var changed bool
+	if like {
+		changed, err = s.discussionDAO.AddLike(post.PostId, viewerId)
+	} else {
+		changed, err = s.discussionDAO.RemoveLike(post.PostId, viewerId)
+	}
+	if err != nil {
+		return 0, errs.NewCommonError(errs.ErrInternal, "点赞失败: "+err.Error())
+	}
+	count := post.LikeCount
+	if changed && like {
+		count++
+	} else if changed && count > 0 {
+		count--
+	}
+	return count, nil
+}
+
+// ==================== 学生操作 ====================
+
+// DeleteOwnPost 删除自己的帖子（学生操作）
+func (s *DiscussionService) DeleteOwnPost(studentId, postId string) error {
+	post, err := s.getVisiblePost(discussion.AuthorTypeStudent, studentId, postId)
+	if err != nil {
+		return err
+	}
+	if post.AuthorType != discussion.AuthorTypeStudent || post.AuthorId != studentId {
+		return errs.NewCommonError(errs.ErrBadRequest, "只能删除自己的帖子")
+	}
+	if err := s.staffService.CheckClassWritable(post.ClassId); err != nil {
+		return err
+	}
+	return s.changePostStatus(post, discussion.PostStatusDeleted)
+}
+
+// ListNotifications 分页查询讨论通知（学生操作）
+func (s *DiscussionService) ListNotifications(studentId string, unreadOnly bool, page, pageSize int32) ([]*discussion.DiscussionNotification, int64, int64, error) {
+	p, size := normalizePage(int(page), int(pageSize))
+	list, err := s.discussionDAO.ListNotifications(studentId, unreadOnly, int32(size), int32((p-1)*size))
+	if err != nil {
+		return nil, 0, 0, errs.NewCommonError(errs.ErrInternal, "查询通知失败: "+err.Error())
+	}
+	total, err := s.discussionDAO.CountNotifications(studentId, unreadOnly)
+	if err != nil {
+		return nil, 0, 0, errs.NewCommonError(errs.ErrInternal, "统计通知数量失败: "+err.Error())
+	}
+	unread, err := s.discussionDAO.CountNotifications(studentId, true)
+	if err != nil {
+		return nil, 0, 0, errs.NewCommonError(errs.ErrInternal, "统计未读通知失败: "+err.Error())
+	}
+	return list, total, unread, nil
+}
+
+// MarkNotificationsRead 标记通知已读（学生操作，ids 为空表示全部）
+func (s *DiscussionService) MarkNotificationsRead(studentId string, ids []int64) error {
+	if err := s.discussionDAO.MarkNotificationsRead(studentId, ids); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "标记通知已读失败: "+err.Error())
+	}
+	return nil
+}
+
+// ==================== 教师操作 ====================
+
+// PinThread 置顶/取消置顶主题帖（教师操作）
+func (s *DiscussionService) PinThread(teacherId, postId string, pinned bool) error {
+	post, err := s.getVisiblePost(discussion.AuthorTypeTeacher, teacherId, postId)
+	if err != nil {
+		return err
+	}
+	if err := s.staffService.CheckClassWritable(post.ClassId); err != nil {
+		return err
+	}
+	if post.ParentId != "" {
+		return errs.NewCommonError(errs.ErrBadRequest, "只能置顶主题帖")
+	}
+	if err := s.discussionDAO.UpdatePost(post.PostId, map[string]interface{}{"is_pinned": pinned}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "置顶失败: "+err.Error())
+	}
+	return nil
+}
+
+// MarkAnswer 标记/取消标记回复为答案（教师操作，每个主题帖最多一个答案）
+func (s *DiscussionService) MarkAnswer(teacherId, postId string, isAnswer bool) error {
+	post, err := s.getVisiblePost(discussion.AuthorTypeTeacher, teacherId, postId)
+	if err != nil {
+		return err
+	}
+	if err := s.staffService.CheckClassWritable(post.ClassId); err != nil {
+		return err
+	}
+	if post.ParentId == "" {
+		return errs.NewCommonError(errs.ErrBadRequest, "只能将回复标记为答案")
+	}
+	if isAnswer {
+		if err := s.discussionDAO.ClearAnswer(post.RootId); err != nil {
+			return errs.NewCommonError(errs.ErrInternal, "标记答案失败: "+err.Error())
+		}
+	}
+	if err := s.discussionDAO.UpdatePost(post.PostId, map[string]interface{}{"is_answer": isAnswer}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "标记答案失败: "+err.Error())
+	}
+	return nil
+}
+
+// ModeratePost 审核帖子（教师操作：hide-隐藏，unhide-取消隐藏，delete-删除）
+func (s *DiscussionService) ModeratePost(teacherId, postId, action string) error {
+	post, err := s.getVisiblePost(discussion.AuthorTypeTeacher, teacherId, postId)
+	if err != nil {
+		return err
+	}
+	if err := s.staffService.CheckClassWritable(post.ClassId); err != nil {
+		return err
+	}
+	switch action {
+	case ModerateActionHide:
+		return s.changePostStatus(post, discussion.PostStatusHidden)
+	case ModerateActionUnhide:
+		return s.changePostStatus(post, discussion.PostStatusNormal)
+	case ModerateActionDelete:
+		return s.changePostStatus(post, discussion.PostStatusDeleted)
+	}
+	return errs.NewCommonError(errs.ErrBadRequest, "审核操作不合法（hide / unhide / delete）")
+}
+
+// ==================== 内部工具 ====================
+
+// getAccessibleSection 查询讨论小节并校验访问权限（教师需拥有班级讨论权限，学生为班级成员）
+func (s *DiscussionService) getAccessibleSection(viewerType, viewerId, sectionId string) (*classModel.ClassSection, error) {
+	if viewerId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "用户ID不能为空")
+	}
+	section, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil || section == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
+	}
+	if section.SectionType != classModel.SectionTypeDiscussion {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该小节不是讨论小节")
+	}
+	switch viewerType {
+	case discussion.AuthorTypeTeacher:
+		if _, err := s.staffService.CheckPermission(section.ClassId, viewerId, consts.ClassPermDiscussion); err != nil {
+			return nil, err
+		}
+	case discussion.AuthorTypeStudent:
+		member, err := s.classMemberDAO.GetMember(section.ClassId, viewerId)
+		if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+		}
+		if err := s.accessService.CheckSectionAccess(section, viewerId); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "无权限操作")
+	}
+	return section, nil
+}
+
+// getVisiblePost 查询当前用户可见的帖子
+func (s *DiscussionService) getVisiblePost(viewerType, viewerId, postId string) (*discussion.DiscussionPost, error) {
+	post, err := s.discussionDAO.GetPostById(postId)
+	if err != nil || post == nil || !containsStatus(visiblePostStatuses(viewerType), post.Status) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "帖子不存在")
+	}
+	section, err := s.getAccessibleSection(viewerType, viewerId, post.SectionId)
+	if err != nil {
+		return nil, err
+	}
+	if !s.canSeeGroup(viewerType, viewerId, section, post.GroupId) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "帖子不存在")
+	}
+	return post, nil
+}
+
+// visibleGroupIds 小组模式下学生可见的帖子所属小组（本组与全班可见的帖子），返回 nil 表示不按小组过滤
+func (s *DiscussionService) visibleGroupIds(viewerType, viewerId string, section *classModel.ClassSection) []string {
+	if !section.GroupMode || viewerType != discussion.AuthorTypeStudent {
+		return nil
+	}
+	groupIds := []string{""}
+	if member := s.groupService.GetStudentGroup(section.ClassId, viewerId); member != nil {
+		groupIds = append(groupIds, member.GroupId)
+	}
+	return groupIds
+}
+
+// canSeeGroup 校验当前用户能否查看指定小组的帖子
+func (s *DiscussionService) canSeeGroup(viewerType, viewerId string, section *classModel.ClassSection, groupId string) bool {
+	groupIds := s.visibleGroupIds(viewerType, viewerId, section)
+	if groupIds == nil {
+		return true
+	}
+	for _, id := range groupIds {
+		if id == groupId {
+			return true
+		}
+	}
+	return false
+}
+
+// changePostStatus 修改帖子状态，并同步主题帖的可见回复数
+func (s *DiscussionService) changePostStatus(post *discussion.DiscussionPost, status int32) error {
+	if post.Status == status {
+		return nil
+	}
+	if err := s.discussionDAO.UpdatePost(post.PostId, map[string]interface{}{"status": status}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新帖子状态失败: "+err.Error())
+	}
+	if post.ParentId != "" {
+		delta := 0
+		if post.Status == discussion.PostStatusNormal {
+			delta = -1
+		} else if status == discussion.PostStatusNormal {
+			delta = 1
+		}
+		if delta != 0 {
+			if err := s.discussionDAO.IncrReplyCount(post.RootId, delta); err != nil {
+				log.Printf("[DiscussionService] 更新回复数失败: root_id=%s, err=%v", post.RootId, err)
+			}
+		}
+	}
+	// 帖子删除后学生可能不再满足讨论小节的完成条件
+	if post.AuthorType == discussion.AuthorTypeStudent && (status == discussion.PostStatusDeleted || post.Status == discussion.PostStatusDeleted) {
+		s.progressService.InvalidateStudentProgress(post.ClassId, post.AuthorId)
+	}
+	return nil
+}
+
+// buildPostViews 组装帖子视图（作者姓名、当前用户点赞状态）
+func (s *DiscussionService) buildPostViews(viewerId string, posts []*discussion.DiscussionPost) []*PostView {
+	names := s.loadAuthorNames(posts)
+	ids := make([]string, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.PostId)
+	}
+	liked, err := s.discussionDAO.ListLikedPostIds(viewerId, ids)
+	if err != nil {
+		liked = map[string]bool{}
+	}
+	views := make([]*PostView, 0, len(posts))
+	for _, p := range posts {
+		views = append(views, &PostView{DiscussionPost: p, AuthorName: names[authorKey(p)], Liked: liked[p.PostId]})
+	}
+	return views
+}
+
+// loadAuthorNames 批量查询帖子作者姓名，key 为 authorKey
+func (s *DiscussionService) loadAuthorNames(posts []*discussion.DiscussionPost) map[string]string {
+	var studentIds, teacherIds []string
+	for _, p := range posts {
+		if p.AuthorType == discussion.AuthorTypeTeacher {
+			teacherIds = append(teacherIds, p.AuthorId)
+		} else {
+			studentIds = append(studentIds, p.AuthorId)
+		}
+	}
+	names := make(map[string]string)
+	if len(studentIds) > 0 {
+		if students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds}); err == nil {
+			for _, st := range students {
+				names[discussion.AuthorTypeStudent+":"+st.StudentId] = st.StudentName
+			}
+		}
+	}
+	if len(teacherIds) > 0 {
+		if teachers, err := s.teacherDAO.ListTeachersAll("teacher_id IN ?", []interface{}{teacherIds}); err == nil {
+			for _, t := range teachers {
+				names[discussion.AuthorTypeTeacher+":"+t.TeacherId] = t.TeacherName
+			}
+		}
+	}
+	return names
+}
+
+// notify 发送回复与 @提及 通知（只通知学生，不通知自己，同一帖子对同一学生只发一条）
+func (s *DiscussionService) notify(post, parent *discussion.DiscussionPost, authorName string) {
+	notified := make(map[string]bool)
+	notified[post.AuthorId] = true
+	var list []*discussion.DiscussionNotification
+	newNotification := func(recipientId, notifyType string) *discussion.DiscussionNotification {
+		return &discussion.DiscussionNotification{
+			RecipientId: recipientId,
+			Type:        notifyType,
+			ClassId:     post.ClassId,
+			SectionId:   post.SectionId,
+			PostId:      post.PostId,
+			RootId:      post.RootId,
+			ActorId:     post.AuthorId,
+			ActorName:   authorName,
+			Excerpt:     discussionExcerpt(post.Content),
+		}
+	}
+
+	for _, studentId := range s.resolveMentions(post.ClassId, post.Content) {
+		if notified[studentId] {
+			continue
+		}
+		// 小组帖子只通知本组成员
+		if post.GroupId != "" {
+			if member := s.groupService.GetStudentGroup(post.ClassId, studentId); member == nil || member.GroupId != post.GroupId {
+				continue
+			}
+		}
+		notified[studentId] = true
+		list = append(list, newNotification(studentId, discussion.NotificationTypeMention))
+	}
+	if parent != nil && parent.AuthorType == discussion.AuthorTypeStudent && !notified[parent.AuthorId] {
+		list = append(list, newNotification(parent.AuthorId, discussion.NotificationTypeReply))
+	}
+	if err := s.discussionDAO.BatchCreateNotifications(list); err != nil {
+		log.Printf("[DiscussionService] 创建通知失败: post_id=%s, err=%v", post.PostId, err)
+	}
+}
+
+// resolveMentions 解析正文中的 @学号 / @姓名，返回被提及的班级成员学生ID
+func (s *DiscussionService) resolveMentions(classId, content string) []string {
+	matches := mentionPattern.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	members, err := s.classMemberDAO.ListAllMembersByClassId(classId)
+	if err != nil || len(members) == 0 {
+		return nil
+	}
+	memberIds := make([]string, 0, len(members))
+	for _, m := range members {
+		memberIds = append(memberIds, m.StudentId)
+	}
+	students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{memberIds})
+	if err != nil {
+		return nil
+	}
+	byKey := make(map[string]string, len(students)*2)
+	for _, st := range students {
+		if st.StudentNumber != "" {
+			byKey[st.StudentNumber] = st.StudentId
+		}
+		if st.StudentName != "" {
+			byKey[st.StudentName] = st.StudentId
+		}
+	}
+	var result []string
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		if id, ok := byKey[m[1]]; ok && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// visiblePostStatuses 不同角色可见的帖子状态（教师可见已隐藏帖子，已删除帖子均不可见）
+func visiblePostStatuses(viewerType string) []int32 {
+	if viewerType == discussion.AuthorTypeTeacher {
+		return []int32{discussion.PostStatusNormal, discussion.PostStatusHidden}
+	}
+	return []int32{discussion.PostStatusNormal}
+}
+
+func containsStatus(statuses []int32, status int32) bool {
+	for _, st := range statuses {
+		if st == status {
+			return true
+		}
+	}
+	return false
+}
+
+func authorKey(p *discussion.DiscussionPost) string {
+	return p.AuthorType + ":" + p.AuthorId
+}
+
+// discussionExcerpt 截取帖子摘要
+func discussionExcerpt(content string) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) > discussionExcerptLen {
+		return string(runes[:discussionExcerptLen]) + "…"
+	}
+	return string(runes)
+}
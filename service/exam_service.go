@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/yzf120/elysia-backend/authen"
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	contestModel "github.com/yzf120/elysia-backend/model/contest"
+)
+
+// examActiveCacheTTL 学生“当前是否处于考试中”的缓存时长（AI答疑、书架等高频接口会查询）
+const examActiveCacheTTL = 30 * time.Second
+
+// examEventBatchLimit 单次上报的事件数上限
+const examEventBatchLimit = 50
+
+// examEventDetailMaxLen 事件详情最大长度（字符）
+const examEventDetailMaxLen = 1024
+
+var examEventTypes = map[string]bool{
+	contestModel.ExamEventBlur:           true,
+	contestModel.ExamEventFocus:          true,
+	contestModel.ExamEventPaste:          true,
+	contestModel.ExamEventCopy:           true,
+	contestModel.ExamEventFullscreenExit: true,
+}
+
+// ExamService 考试防作弊服务（会话绑定、事件日志）
+type ExamService struct {
+	examDAO        dao.ExamDAO
+	contestDAO     dao.ContestDAO
+	classDAO       dao.ClassDAO
+	staffService   *ClassStaffService
+	classMemberDAO dao.ClassMemberDAO
+	studentDAO     dao.StudentDAO
+	redisClient    *client.RedisClient
+}
+
+// NewExamService 创建考试服务
+func NewExamService() *ExamService {
+	return &ExamService{
+		examDAO:        dao.NewExamDAO(),
+		contestDAO:     dao.NewContestDAO(),
+		classDAO:       dao.NewClassDAO(),
+		staffService:   NewClassStaffService(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+		redisClient:    client.GetRedisClient(),
+	}
+}
+
+// ExamEventInput 客户端上报的考试事件
+type ExamEventInput struct {
+	EventType  string
+	Detail     string
+	ClientTime string // 格式 2006-01-02 15:04:05（可选）
+}
+
+// ExamSessionItem 教师查看的学生考试会话
+type ExamSessionItem struct {
+	StudentId      string           `json:"student_id"`
+	StudentName    string           `json:"student_name"`
+	StudentNumber  string           `json:"student_number"`
+	Entered        bool             `json:"entered"` // 是否已进入考试
+	BindIp         string           `json:"bind_ip"`
+	RejectCount    int32            `json:"reject_count"` // 其他设备/IP 被拒绝的次数
+	EnterTime      *time.Time       `json:"enter_time"`
+	LastActiveTime *time.Time       `json:"last_active_time"`
+	EventCounts    map[string]int32 `json:"event_counts"` // 各类事件次数
+}
+
+// ==================== 学生操作 ====================
+
+// EnterExam 进入考试（学生操作，首次进入时绑定当前 IP 与登录令牌）
+func (s *ExamService) EnterExam(ctx context.Context, studentId, contestId string) (*contestModel.ExamSession, error) {
+	c, err := s.getRunningExam(studentId, contestId)
+	if err != nil {
+		return nil, err
+	}
+	return s.BindSession(ctx, studentId, c)
+}
+
+// BindSession 绑定或校验考试会话：首次调用时绑定 IP 与令牌，之后只允许同一 IP、同一令牌访问
+// 重新登录会更换令牌，此时需教师重置会话
+func (s *ExamService) BindSession(ctx context.Context, studentId string, c *contestModel.Contest) (*contestModel.ExamSession, error) {
+	token, _ := authen.GetTokenFromContext(ctx)
+	ip, _ := authen.GetClientIPFromContext(ctx)
+	tokenHash := hashExamToken(token)
+	now := time.Now()
+
+	session, err := s.examDAO.GetSession(c.ContestId, studentId)
+	if err != nil || session == nil {
+		session = &contestModel.ExamSession{
+			ContestId:      c.ContestId,
+			StudentId:      studentId,
+			BindIp:         ip,
+			TokenHash:      tokenHash,
+			EnterTime:      now,
+			LastActiveTime: now,
+		}
+		if err := s.examDAO.CreateSession(session); err == nil {
+			return session, nil
+		}
+		// 并发首次进入时唯一索引冲突，重新读取已创建的会话再校验
+		session, err = s.examDAO.GetSession(c.ContestId, studentId)
+		if err != nil || session == nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "创建考试会话失败")
+		}
+	}
+
+	if session.BindIp != ip || session.TokenHash != tokenHash {
+		_ = s.examDAO.UpdateSession(session.Id, map[string]interface{}{"reject_count": session.RejectCount + 1})
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "已在其他设备或网络进入考试，如需更换请联系教师重置")
+	}
+	if err := s.examDAO.UpdateSession(session.Id, map[string]interface{}{"last_active_time": now}); err == nil {
+		session.LastActiveTime = now
+	}
+	return session, nil
+}
+
+// ReportEvents 上报考试事件（切屏、粘贴等，只在考试进行中且会话校验通过时记录）
+func (s *ExamService) ReportEvents(ctx context.Context, studentId, contestId string, inputs []*ExamEventInput) (int, error) {
+	if len(inputs) == 0 {
+		return 0, nil
+	}
+	if len(inputs) > examEventBatchLimit {
+		return 0, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("单次最多上报%d条事件", examEventBatchLimit))
+	}
+	c, err := s.getRunningExam(studentId, contestId)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.BindSession(ctx, studentId, c); err != nil {
+		return 0, err
+	}
+
+	ip, _ := authen.GetClientIPFromContext(ctx)
+	events := make([]*contestModel.ExamEvent, 0, len(inputs))
+	for _, in := range inputs {
+		if !examEventTypes[in.EventType] {
+			return 0, errs.NewCommonError(errs.ErrBadRequest, "事件类型不合法: "+in.EventType)
+		}
+		clientTime, err := parseOptionalTime(in.ClientTime)
+		if err != nil {
+			return 0, errs.NewCommonError(errs.ErrBadRequest, "事件时间格式错误")
+		}
+		detail := []rune(in.Detail)
+		if len(detail) > examEventDetailMaxLen {
+			detail = detail[:examEventDetailMaxLen]
+		}
+		events = append(events, &contestModel.ExamEvent{
+			ContestId:  contestId,
+			StudentId:  studentId,
+			EventType:  in.EventType,
+			Detail:     string(detail),
+			ClientTime: clientTime,
+			Ip:         ip,
+		})
+	}
+	if err := s.examDAO.BatchCreateEvents(events); err != nil {
+		return 0, errs.NewCommonError(errs.ErrInternal, "记录考试事件失败: "+err.Error())
+	}
+	return len(events), nil
+}
+
+// GetActiveExam 查询学生当前正在参加的考试（无则返回 nil，结果短暂缓存）
+func (s *ExamService) GetActiveExam(studentId string) *contestModel.Contest {
+	cacheKey := fmt.Sprintf("exam:active:%s", studentId)
+	if contestId, err := s.redisClient.Get(cacheKey); err == nil {
+		if contestId == "" {
+			return nil
+		}
+		if c, err := s.contestDAO.GetContestById(contestId); err == nil && c != nil && contestPhase(c, time.Now()) == ContestPhaseRunning {
+			return c
+		}
+	}
+
+	list, err := s.contestDAO.ListRunningExamsByStudentId(studentId, time.Now())
+	if err != nil {
+		return nil
+	}
+	var active *contestModel.Contest
+	if len(list) > 0 {
+		active = list[0]
+	}
+	contestId := ""
+	ttl := examActiveCacheTTL
+	if active != nil {
+		contestId = active.ContestId
+		if remain := time.Until(active.EndTime); remain < ttl && remain > time.Second {
+			ttl = remain
+		}
+	}
+	_ = s.redisClient.Set(cacheKey, contestId, ttl)
+	return active
+}
+
+// ==================== 教师操作 ====================
+
+// ListExamSessions 查询考试中全部学生的会话情况（教师操作）
+func (s *ExamService) ListExamSessions(teacherId, contestId string) ([]*ExamSessionItem, error) {
+	c, err := s.getOwnedExam(teacherId, contestId)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.classMemberDAO.ListAllMembersByClassId(c.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	sessions, err := s.examDAO.ListSessionsByContestId(contestId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询考试会话失败: "+err.Error())
+	}
+	events, err := s.examDAO.ListEvents(contestId, "")
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询考试事件失败: "+err.Error())
+	}
+
+	sessionMap := make(map[string]*contestModel.ExamSession, len(sessions))
+	for _, sess := range sessions {
+		sessionMap[sess.StudentId] = sess
+	}
+	eventCounts := make(map[string]map[string]int32)
+	for _, e := range events {
+		if eventCounts[e.StudentId] == nil {
+			eventCounts[e.StudentId] = make(map[string]int32)
+		}
+		eventCounts[e.StudentId][e.EventType]++
+	}
+	studentIds := make([]string, 0, len(members))
+	for _, m := range members {
+		studentIds = append(studentIds, m.StudentId)
+	}
+	studentInfo := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		if students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds}); err == nil {
+			for _, st := range students {
+				studentInfo[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+			}
+		}
+	}
+
+	items := make([]*ExamSessionItem, 0, len(members))
+	for _, m := range members {
+		item := &ExamSessionItem{
+			StudentId:     m.StudentId,
+			StudentName:   studentInfo[m.StudentId][0],
+			StudentNumber: studentInfo[m.StudentId][1],
+			EventCounts:   eventCounts[m.StudentId],
+		}
+		if sess, ok := sessionMap[m.StudentId]; ok {
+			enterTime, lastActive := sess.EnterTime, sess.LastActiveTime
+			item.Entered = true
+			item.BindIp = sess.BindIp
+			item.RejectCount = sess.RejectCount
+			item.EnterTime = &enterTime
+			item.LastActiveTime = &lastActive
+		}
+		if item.EventCounts == nil {
+			item.EventCounts = map[string]int32{}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ListExamEvents 查询考试事件日志（教师操作，studentId 为空表示全部学生）
+func (s *ExamService) ListExamEvents(teacherId, contestId, studentId string) ([]*contestModel.ExamEvent, error) {
+	if _, err := s.getOwnedExam(teacherId, contestId); err != nil {
+		return nil, err
+	}
+	list, err := s.examDAO.ListEvents(contestId, studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询考试事件失败: "+err.Error())
+	}
+	return list, nil
+}
+
+// ResetExamSession 重置学生的考试会话（教师操作，学生下次进入时重新绑定 IP 与令牌）
+func (s *ExamService) ResetExamSession(teacherId, contestId, studentId string) error {
+	if studentId == "" {
+		return errs.NewCommonError(errs.ErrBadRequest, "学生ID不能为空")
+	}
+	if _, err := s.getOwnedExam(teacherId, contestId); err != nil {
+		return err
+	}
+	if err := s.examDAO.DeleteSession(contestId, studentId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "重置考试会话失败: "+err.Error())
+	}
+	return nil
+}
+
+// ==================== 内部工具 ====================
+
+// getRunningExam 查询进行中的考试并校验学生为班级成员
+func (s *ExamService) getRunningExam(studentId, contestId string) (*contestModel.Contest, error) {
+	c, err := s.contestDAO.GetContestById(contestId)
+	if err != nil || c == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "比赛不存在")
+	}
+	if !c.IsExam {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该比赛未开启考试模式")
+	}
+	member, err := s.classMemberDAO.GetMember(c.ClassId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	if contestPhase(c, time.Now()) != ContestPhaseRunning {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "考试不在进行中")
+	}
+	return c, nil
+}
+
+// getOwnedExam 查询考试并校验教师归属
+func (s *ExamService) getOwnedExam(teacherId, contestId string) (*contestModel.Contest, error) {
+	c, err := s.contestDAO.GetContestById(contestId)
+	if err != nil || c == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "比赛不存在")
+	}
+	if _, err := s.staffService.CheckPermission(c.ClassId, teacherId, consts.ClassPermContest); err != nil {
+		return nil, err
+	}
+	if !c.IsExam {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该比赛未开启考试模式")
+	}
+	return c, nil
+}
+
+// hashExamToken 计算登录令牌摘要（不落库明文令牌）
+func hashExamToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+)
+
+// GradebookService 班级成绩册服务
+type GradebookService struct {
+	classDAO         dao.ClassDAO
+	staffService     *ClassStaffService
+	classMemberDAO   dao.ClassMemberDAO
+	chapterDAO       dao.ChapterDAO
+	studentDAO       dao.StudentDAO
+	sectionResultDAO dao.SectionResultDAO
+	groupService     *ClassGroupService
+}
+
+// NewGradebookService 创建成绩册服务
+func NewGradebookService() *GradebookService {
+	return &GradebookService{
+		classDAO:         dao.NewClassDAO(),
+		staffService:     NewClassStaffService(),
+		classMemberDAO:   dao.NewClassMemberDAO(),
+		chapterDAO:       dao.NewChapterDAO(),
+		studentDAO:       dao.NewStudentDAO(),
+		sectionResultDAO: dao.NewSectionResultDAO(),
+		groupService:     NewClassGroupService(),
+	}
+}
+
+// GradebookColumn 成绩册列（一个算法题小节）
+type GradebookColumn struct {
+	SectionId    string `json:"section_id"`
+	Title        string `json:"title"`
+	ChapterId    string `json:"chapter_id"`
+	ChapterTitle string `json:"chapter_title"`
+	ProblemId    string `json:"problem_id"`
+	DueTime      string `json:"due_time"`
+	GroupMode    bool   `json:"group_mode"` // 小组作业（成绩来自组内任一成员的提交）
+}
+
+// GradebookChapter 成绩册章节（含权重）
+type GradebookChapter struct {
+	ChapterId string `json:"chapter_id"`
+	Title     string `json:"title"`
+	Weight    int32  `json:"weight"`
+}
+
+// GradebookCell 成绩册单元格（学生在某小节的成绩）
+type GradebookCell struct {
+	SectionId  string `json:"section_id"`
+	BestStatus string `json:"best_status"` // 为空表示未提交
+	Score      int32  `json:"score"`
+	Attempts   int32  `json:"attempts"`
+	SubmitTime string `json:"submit_time"`
+	IsLate     bool   `json:"is_late"`
+	LateDays   int32  `json:"late_days"`
+}
+
+// GradebookRow 成绩册行（一个学生）
+type GradebookRow struct {
+	StudentId     string             `json:"student_id"`
+	StudentName   string             `json:"student_name"`
+	StudentNumber string             `json:"student_number"`
+	GroupName     string             `json:"group_name"` // 所在小组（未分组为空）
+	Cells         []*GradebookCell   `json:"cells"`
+	ChapterScores map[string]float64 `json:"chapter_scores"` // chapter_id -> 章节平均分
+	TotalScore    float64            `json:"total_score"`    // 按章节权重加权后的总评
+}
+
+// Gradebook 班级成绩册
+type Gradebook struct {
+	ClassId   string              `json:"class_id"`
+	ClassName string              `json:"class_name"`
+	Chapters  []*GradebookChapter `json:"chapters"`
+	Columns   []*GradebookColumn  `json:"columns"`
+	Rows      []*GradebookRow     `json:"rows"`
+}
+
+// GetGradebook 查询班级成绩册（教师操作）
+func (s *GradebookService) GetGradebook(teacherId, classId string) (*Gradebook, error) {
+	class, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermGradeView)
+	if err != nil {
+		return nil, err
+	}
+
+	chapters, err := s.chapterDAO.ListChaptersByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询章节失败: "+err.Error())
+	}
+	book := &Gradebook{ClassId: classId, ClassName: class.ClassName}
+	chapterSections := make(map[string][]string)
+	for _, ch := range chapters {
+		sections, err := s.chapterDAO.ListSectionsByChapterId(ch.ChapterId)
+		if err != nil {
+			continue
+		}
+		for _, sec := range sections {
+			if sec.SectionType != classModel.SectionTypeProblem {
+				continue
+			}
+			column := &GradebookColumn{
+				SectionId:    sec.SectionId,
+				Title:        sec.Title,
+				ChapterId:    ch.ChapterId,
+				ChapterTitle: ch.Title,
+				ProblemId:    sec.ProblemId,
+				GroupMode:    sec.GroupMode,
+			}
+			if sec.DueTime != nil {
+				column.DueTime = formatTime(*sec.DueTime)
+			}
+			book.Columns = append(book.Columns, column)
+			chapterSections[ch.ChapterId] = append(chapterSections[ch.ChapterId], sec.SectionId)
+		}
+		// 没有算法题的章节不参与成绩计算
+		if len(chapterSections[ch.ChapterId]) > 0 {
+			book.Chapters = append(book.Chapters, &GradebookChapter{ChapterId: ch.ChapterId, Title: ch.Title, Weight: ch.Weight})
+		}
+	}
+
+	members, err := s.classMemberDAO.ListAllMembersByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	results, err := s.sectionResultDAO.ListResultsByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询作业成绩失败: "+err.Error())
+	}
+	resultMap := make(map[string]map[string]*classModel.ClassSectionResult)
+	for _, r := range results {
+		if resultMap[r.StudentId] == nil {
+			resultMap[r.StudentId] = make(map[string]*classModel.ClassSectionResult)
+		}
+		resultMap[r.StudentId][r.SectionId] = r
+	}
+
+	studentIds := make([]string, 0, len(members))
+	for _, m := range members {
+		studentIds = append(studentIds, m.StudentId)
+	}
+	studentInfo := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds})
+		if err == nil {
+			for _, st := range students {
+				studentInfo[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+			}
+		}
+	}
+
+	groupNames := s.groupService.ListGroupNames(classId)
+
+	for _, m := range members {
+		row := &GradebookRow{
+			StudentId:     m.StudentId,
+			StudentName:   studentInfo[m.StudentId][0],
+			StudentNumber: studentInfo[m.StudentId][1],
+			GroupName:     groupNames[m.StudentId],
+			ChapterScores: make(map[string]float64),
+		}
+		sectionScores := make(map[string]int32)
+		for _, col := range book.Columns {
+			cell := &GradebookCell{SectionId: col.SectionId}
+			if r := resultMap[m.StudentId][col.SectionId]; r != nil {
+				cell.BestStatus = r.BestStatus
+				cell.Score = r.BestScore
+				cell.Attempts = r.Attempts
+				cell.IsLate = r.IsLate
+				cell.LateDays = r.LateDays
+				if r.BestSubmitTime != nil {
+					cell.SubmitTime = formatTime(*r.BestSubmitTime)
+				}
+			}
+			sectionScores[col.SectionId] = cell.Score
+			row.Cells = append(row.Cells, cell)
+		}
+
+		var weightedSum float64
+		var weightTotal int32
+		for _, ch := range book.Chapters {
+			sectionIds := chapterSections[ch.ChapterId]
+			var sum int32
+			for _, id := range sectionIds {
+				sum += sectionScores[id]
+			}
+			chapterScore := roundScore(float64(sum) / float64(len(sectionIds)))
+			row.ChapterScores[ch.ChapterId] = chapterScore
+			if ch.Weight > 0 {
+				weightedSum += chapterScore * float64(ch.Weight)
+				weightTotal += ch.Weight
+			}
+		}
+		if weightTotal > 0 {
+			row.TotalScore = roundScore(weightedSum / float64(weightTotal))
+		}
+		book.Rows = append(book.Rows, row)
+	}
+	return book, nil
+}
+
+// ExportGradebook 导出班级成绩册（format: csv / xlsx），返回 (文件内容, 文件名)
+func (s *GradebookService) ExportGradebook(teacherId, classId, format string) ([]byte, string, error) {
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		return nil, "", errs.NewCommonError(errs.ErrBadRequest, "导出格式仅支持 csv 或 xlsx")
+	}
+	book, err := s.GetGradebook(teacherId, classId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// numericCols 记录 xlsx 中按数值写入的列（得分、次数、天数），学号等其余列按文本写入
+	header := []string{"学号", "姓名", "小组"}
+	var numericCols []int
+	for _, col := range book.Columns {
+		base := len(header)
+		numericCols = append(numericCols, base+1, base+2, base+4)
+		header = append(header, col.Title+" 状态", col.Title+" 得分", col.Title+" 提交次数", col.Title+" 提交时间", col.Title+" 迟交天数")
+	}
+	for _, ch := range book.Chapters {
+		numericCols = append(numericCols, len(header))
+		header = append(header, fmt.Sprintf("%s 得分（权重%d）", ch.Title, ch.Weight))
+	}
+	numericCols = append(numericCols, len(header))
+	header = append(header, "总评")
+
+	rows := make([][]string, 0, len(book.Rows))
+	for _, r := range book.Rows {
+		row := []string{r.StudentNumber, r.StudentName, r.GroupName}
+		for _, cell := range r.Cells {
+			row = append(row,
+				runStatusLabel(cell.BestStatus),
+				strconv.Itoa(int(cell.Score)),
+				strconv.Itoa(int(cell.Attempts)),
+				cell.SubmitTime,
+				strconv.Itoa(int(cell.LateDays)),
+			)
+		}
+		for _, ch := range book.Chapters {
+			row = append(row, strconv.FormatFloat(r.ChapterScores[ch.ChapterId], 'f', -1, 64))
+		}
+		row = append(row, strconv.FormatFloat(r.TotalScore, 'f', -1, 64))
+		rows = append(rows, row)
+	}
+
+	fileName := fmt.Sprintf("%s_成绩册_%s.%s", book.ClassName, time.Now().Format("20060102150405"), format)
+	var content []byte
+	if format == "xlsx" {
+		content, err = buildXLSX("成绩册", header, rows, numericCols...)
+	} else {
+		content, err = buildCSV(header, rows)
+	}
+	if err != nil {
+		return nil, "", errs.NewCommonError(errs.ErrInternal, "导出成绩册失败: "+err.Error())
+	}
+	return content, fileName, nil
+}
+
+// UpdateChapterWeights 批量设置章节成绩权重（教师操作）
+func (s *GradebookService) UpdateChapterWeights(teacherId, classId string, weights map[string]int32) error {
+	if _, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermGradeEdit); err != nil {
+		return err
+	}
+	for chapterId, weight := range weights {
+		if weight < 0 {
+			return errs.NewCommonError(errs.ErrBadRequest, "章节权重不能为负数")
+		}
+		chapter, err := s.chapterDAO.GetChapterById(chapterId)
+		if err != nil || chapter == nil || chapter.ClassId != classId {
+			return errs.NewCommonError(errs.ErrBadRequest, "章节不存在: "+chapterId)
+		}
+	}
+	for chapterId, weight := range weights {
+		if err := s.chapterDAO.UpdateChapter(chapterId, map[string]interface{}{"weight": weight}); err != nil {
+			return errs.NewCommonError(errs.ErrInternal, "更新章节权重失败: "+err.Error())
+		}
+	}
+	return nil
+}
+
+// runStatusLabel 评测状态中文标签
+func runStatusLabel(status string) string {
+	switch status {
+	case "":
+		return "未提交"
+	case "pending", "running":
+		return "评测中"
+	case "accepted":
+		return "通过"
+	case "wrong_answer":
+		return "答案错误"
+	case "time_limit_exceeded":
+		return "超时"
+	case "memory_limit_exceeded":
+		return "超内存"
+	case "compile_error":
+		return "编译错误"
+	case "runtime_error":
+		return "运行错误"
+	default:
+		return status
+	}
+}
+
+// roundScore 分数保留两位小数
+func roundScore(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,522 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	codeModel "github.com/yzf120/elysia-backend/model/code"
+)
+
+// 排行方式
+const (
+	LeaderboardModeSolved = "solved" // 通过题目数
+	LeaderboardModeScore  = "score"  // 总得分（每道题取最高得分）
+	LeaderboardModeStreak = "streak" // 连续通过天数（截至今天或昨天）
+)
+
+// 统计时间范围
+const (
+	LeaderboardWindowWeek     = "week"     // 本周（周一起）
+	LeaderboardWindowSemester = "semester" // 本学期（学期开始日期起，学期不存在时为班级创建时间起）
+)
+
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 200
+
+	leaderboardInitMember   = "#init" // 占位成员（区分空榜单与缓存不存在）
+	leaderboardAnonymous    = "匿名同学"
+	leaderboardSemesterTTL  = 24 * time.Hour // 学期榜单定期全量重建，修正成员变动等带来的偏差
+	leaderboardExpireMargin = time.Hour
+
+	leaderboardLockKey  = "class:leaderboard:lock:%s" // 班级榜单锁（多实例间串行化重建与增量更新）
+	leaderboardLockTTL  = 30 * time.Second
+	leaderboardLockWait = 5 * time.Second
+)
+
+// leaderboardModes 全部排行方式（同一时间范围的榜单一起重建）
+var leaderboardModes = []string{LeaderboardModeSolved, LeaderboardModeScore, LeaderboardModeStreak}
+
+// leaderboardUnlockScript 只释放自己持有的榜单锁
+var leaderboardUnlockScript = redis.NewScript(`if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) end return 0`)
+
+// LeaderboardService 班级排行榜服务（数据来自班级内的提交记录，榜单以 Redis 有序集合维护）
+type LeaderboardService struct {
+	classDAO       dao.ClassDAO
+	classMemberDAO dao.ClassMemberDAO
+	codeRunDAO     dao.CodeRunDAO
+	studentDAO     dao.StudentDAO
+	semesterDAO    dao.SemesterDAO
+	staffService   *ClassStaffService
+	redisClient    *client.RedisClient
+}
+
+// NewLeaderboardService 创建班级排行榜服务
+func NewLeaderboardService() *LeaderboardService {
+	return &LeaderboardService{
+		classDAO:       dao.NewClassDAO(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		codeRunDAO:     dao.NewCodeRunDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+		semesterDAO:    dao.NewSemesterDAO(),
+		staffService:   NewClassStaffService(),
+		redisClient:    client.GetRedisClient(),
+	}
+}
+
+// LeaderboardRow 排行榜条目
+type LeaderboardRow struct {
+	Rank          int    `json:"rank"`           // 名次（成绩相同名次相同）
+	StudentId     string `json:"student_id"`     // 匿名学生对其他学生不返回
+	StudentName   string `json:"student_name"`   // 匿名学生显示为“匿名同学”
+	StudentNumber string `json:"student_number"` // 匿名学生对其他学生不返回
+	Value         int64  `json:"value"`          // 排行数值（通过题数/总得分/连续天数）
+	Anonymous     bool   `json:"anonymous"`      // 是否匿名展示
+	IsMe          bool   `json:"is_me"`          // 是否为当前学生
+}
+
+// Leaderboard 班级排行榜
+type Leaderboard struct {
+	ClassId      string            `json:"class_id"`
+	Mode         string            `json:"mode"`
+	Window       string            `json:"window"`
+	WindowStart  string            `json:"window_start"` // 统计开始时间
+	Participants int               `json:"participants"` // 参与排行的人数
+	Rows         []*LeaderboardRow `json:"rows"`
+	MyRow        *LeaderboardRow   `json:"my_row,omitempty"`        // 当前学生的名次（学生查看且参与排行时返回）
+	MyVisibility *int32            `json:"my_visibility,omitempty"` // 当前学生的展示方式（学生查看时返回）
+}
+
+// leaderboardMetrics 学生在统计范围内的排行数值
+type leaderboardMetrics struct {
+	Solved int64
+	Score  int64
+	Streak int64
+}
+
+// value 按排行方式取数值
+func (m *leaderboardMetrics) value(mode string) int64 {
+	switch mode {
+	case LeaderboardModeScore:
+		return m.Score
+	case LeaderboardModeStreak:
+		return m.Streak
+	default:
+		return m.Solved
+	}
+}
+
+// ==================== 查询 ====================
+
+// GetStudentLeaderboard 学生查看班级排行榜（需为班级成员，教师关闭排行榜后不可查看）
+func (s *LeaderboardService) GetStudentLeaderboard(studentId, classId, mode, window string, limit int) (*Leaderboard, error) {
+	member, err := s.classMemberDAO.GetMember(classId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	cls, err := s.classDAO.GetClassById(classId)
+	if err != nil || cls == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	if cls.LeaderboardOff {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "教师已关闭班级排行榜")
+	}
+	board, err := s.buildLeaderboard(cls, studentId, mode, window, limit)
+	if err != nil {
+		return nil, err
+	}
+	visibility := member.LeaderboardVisibility
+	board.MyVisibility = &visibility
+	return board, nil
+}
+
+// GetTeacherLeaderboard 教学团队查看班级排行榜（匿名学生显示真实姓名并标记为匿名，排行榜关闭时仍可查看）
+func (s *LeaderboardService) GetTeacherLeaderboard(teacherId, classId, mode, window string, limit int) (*Leaderboard, error) {
+	if _, err := s.staffService.GetMyPermissions(classId, teacherId); err != nil {
+		return nil, err
+	}
+	cls, err := s.classDAO.GetClassById(classId)
+	if err != nil || cls == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "班级不存在")
+	}
+	return s.buildLeaderboard(cls, "", mode, window, limit)
+}
+
+// ==================== 设置 ====================
+
+// SetLeaderboardEnabled 教师开启或关闭班级排行榜（需班级管理权限）
+func (s *LeaderboardService) SetLeaderboardEnabled(teacherId, classId string, enabled bool) error {
+	if _, err := s.staffService.CheckWritePermission(classId, teacherId, consts.ClassPermClassManage); err != nil {
+		return err
+	}
+	if err := s.classDAO.UpdateClass(classId, map[string]interface{}{"leaderboard_off": !enabled}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新排行榜设置失败: "+err.Error())
+	}
+	return nil
+}
+
+// SetLeaderboardVisibility 学生设置自己在班级排行榜中的展示方式（实名/匿名/不参与）
+func (s *LeaderboardService) SetLeaderboardVisibility(studentId, classId string, visibility int32) error {
+	if visibility != consts.LeaderboardVisibilityPublic && visibility != consts.LeaderboardVisibilityAnonymous &&
+		visibility != consts.LeaderboardVisibilityHidden {
+		return errs.NewCommonError(errs.ErrBadRequest, "展示方式不合法（0-实名，1-匿名，2-不参与）")
+	}
+	member, err := s.classMemberDAO.GetMember(classId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	if err := s.classMemberDAO.UpdateMember(classId, studentId, map[string]interface{}{"leaderboard_visibility": visibility}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新排行榜展示方式失败: "+err.Error())
+	}
+	return nil
+}
+
+// ==================== 榜单维护 ====================
+
+// OnCodeRunJudged 评测完成后增量更新榜单（只处理班级内的 submit 记录，榜单缓存不存在时等待查询时重建）
+func (s *LeaderboardService) OnCodeRunJudged(runId int64) {
+	run, err := s.codeRunDAO.GetCodeRunById(runId)
+	if err != nil || run == nil || run.ClassId == "" || run.ContestId != "" || run.RunType != "submit" {
+		return
+	}
+	cls, err := s.classDAO.GetClassById(run.ClassId)
+	if err != nil || cls == nil {
+		return
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	unlock, ok := s.lockBoards(cls.ClassId)
+	if !ok {
+		// 拿不到锁时删除榜单缓存，由下次查询全量重建，避免漏记本次提交
+		for _, window := range []string{LeaderboardWindowWeek, LeaderboardWindowSemester} {
+			_ = s.redisClient.Del(leaderboardKeys(cls.ClassId, window, s.windowStart(cls, window, now), now)...)
+		}
+		log.Printf("[LeaderboardService] 获取榜单锁超时，已清除榜单缓存: class_id=%s", cls.ClassId)
+		return
+	}
+	defer unlock()
+
+	for _, window := range []string{LeaderboardWindowWeek, LeaderboardWindowSemester} {
+		start := s.windowStart(cls, window, now)
+		keys := leaderboardKeys(cls.ClassId, window, start, now)
+		exists, err := s.redisClient.Exists(keys...)
+		if err != nil || exists < int64(len(keys)) {
+			continue
+		}
+		runs, err := s.codeRunDAO.ListClassJudgedSubmitsSince(cls.ClassId, run.StudentId, start)
+		if err != nil {
+			log.Printf("[LeaderboardService] 查询提交记录失败: class_id=%s, err=%v", cls.ClassId, err)
+			continue
+		}
+		metrics := computeLeaderboardMetrics(runs, start, now)[run.StudentId]
+		if metrics == nil {
+			metrics = &leaderboardMetrics{}
+		}
+		pipe := s.redisClient.Client.TxPipeline()
+		for i, mode := range leaderboardModes {
+			pipe.ZAdd(ctx, keys[i], redis.Z{Score: float64(metrics.value(mode)), Member: run.StudentId})
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("[LeaderboardService] 更新榜单缓存失败: class_id=%s, err=%v", cls.ClassId, err)
+			_ = s.redisClient.Del(keys...)
+		}
+	}
+}
+
+// buildLeaderboard 读取榜单并按成员展示方式过滤（viewerId 为空表示教学团队查看）
+func (s *LeaderboardService) buildLeaderboard(cls *classModel.Class, viewerId, mode, window string, limit int) (*Leaderboard, error) {
+	if mode == "" {
+		mode = LeaderboardModeSolved
+	}
+	if mode != LeaderboardModeSolved && mode != LeaderboardModeScore && mode != LeaderboardModeStreak {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "排行方式不合法（solved/score/streak）")
+	}
+	if window == "" {
+		window = LeaderboardWindowWeek
+	}
+	if window != LeaderboardWindowWeek && window != LeaderboardWindowSemester {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "统计范围不合法（week/semester）")
+	}
+	if limit <= 0 {
+		limit = defaultLeaderboardLimit
+	}
+	if limit > maxLeaderboardLimit {
+		limit = maxLeaderboardLimit
+	}
+
+	now := time.Now()
+	start := s.windowStart(cls, window, now)
+	entries, err := s.loadBoard(cls, window, mode, start, now)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询排行榜失败: "+err.Error())
+	}
+	members, err := s.classMemberDAO.ListAllMembersByClassId(cls.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+
+	visibility := make(map[string]int32, len(members))
+	studentIds := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.LeaderboardVisibility == consts.LeaderboardVisibilityHidden {
+			continue
+		}
+		visibility[m.StudentId] = m.LeaderboardVisibility
+		studentIds = append(studentIds, m.StudentId)
+	}
+	studentInfo := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		if students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds}); err == nil {
+			for _, st := range students {
+				studentInfo[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+			}
+		}
+	}
+
+	// 有序集合按数值降序返回；新加入尚未写入榜单的成员数值为 0，排在最后
+	ordered := make([]redis.Z, 0, len(studentIds))
+	listed := make(map[string]bool, len(entries))
+	for _, z := range entries {
+		studentId, _ := z.Member.(string)
+		if _, ok := visibility[studentId]; ok {
+			ordered = append(ordered, z)
+			listed[studentId] = true
+		}
+	}
+	for _, studentId := range studentIds {
+		if !listed[studentId] {
+			ordered = append(ordered, redis.Z{Member: studentId})
+		}
+	}
+
+	board := &Leaderboard{
+		ClassId:      cls.ClassId,
+		Mode:         mode,
+		Window:       window,
+		WindowStart:  formatTime(start),
+		Participants: len(ordered),
+		Rows:         make([]*LeaderboardRow, 0, limit),
+	}
+	prevRank := 0
+	for i, z := range ordered {
+		studentId := z.Member.(string)
+		row := &LeaderboardRow{
+			Rank:          i + 1,
+			StudentId:     studentId,
+			StudentName:   studentInfo[studentId][0],
+			StudentNumber: studentInfo[studentId][1],
+			Value:         int64(z.Score),
+			Anonymous:     visibility[studentId] == consts.LeaderboardVisibilityAnonymous,
+			IsMe:          viewerId != "" && studentId == viewerId,
+		}
+		// 数值相同名次相同
+		if i > 0 && ordered[i-1].Score == z.Score {
+			row.Rank = prevRank
+		}
+		prevRank = row.Rank
+		if row.Anonymous && viewerId != "" && !row.IsMe {
+			row.StudentId = ""
+			row.StudentName = leaderboardAnonymous
+			row.StudentNumber = ""
+		}
+		if i < limit {
+			board.Rows = append(board.Rows, row)
+		}
+		if row.IsMe {
+			board.MyRow = row
+		}
+	}
+	return board, nil
+}
+
+// loadBoard 从 Redis 读取榜单（降序），缓存不存在时从提交记录重建
+func (s *LeaderboardService) loadBoard(cls *classModel.Class, window, mode string, start, now time.Time) ([]redis.Z, error) {
+	ctx := context.Background()
+	keys := leaderboardKeys(cls.ClassId, window, start, now)
+	key := keys[0]
+	for i, m := range leaderboardModes {
+		if m == mode {
+			key = keys[i]
+		}
+	}
+	exists, err := s.redisClient.Exists(keys...)
+	if err != nil || exists < int64(len(keys)) {
+		unlock, ok := s.lockBoards(cls.ClassId)
+		if !ok {
+			return nil, errors.New("获取榜单锁超时，请稍后重试")
+		}
+		// 等锁期间其他实例可能已完成重建
+		exists, err = s.redisClient.Exists(keys...)
+		if err != nil || exists < int64(len(keys)) {
+			err = s.rebuildBoards(cls, window, start, now)
+		}
+		unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	entries, err := s.redisClient.Client.ZRevRangeWithScores(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]redis.Z, 0, len(entries))
+	for _, z := range entries {
+		if z.Member != leaderboardInitMember {
+			result = append(result, z)
+		}
+	}
+	return result, nil
+}
+
+// lockBoards 获取班级榜单锁（Redis SetNX，等待至多 leaderboardLockWait），返回释放函数与是否获取成功
+func (s *LeaderboardService) lockBoards(classId string) (func(), bool) {
+	ctx := context.Background()
+	key := fmt.Sprintf(leaderboardLockKey, classId)
+	token := strconv.FormatInt(time.Now().UnixNano(), 10)
+	deadline := time.Now().Add(leaderboardLockWait)
+	for {
+		ok, err := s.redisClient.Client.SetNX(ctx, key, token, leaderboardLockTTL).Result()
+		if err != nil {
+			log.Printf("[LeaderboardService] 获取榜单锁失败: class_id=%s, err=%v", classId, err)
+			return nil, false
+		}
+		if ok {
+			return func() {
+				_ = leaderboardUnlockScript.Run(ctx, s.redisClient.Client, []string{key}, token).Err()
+			}, true
+		}
+		if time.Now().After(deadline) {
+			return nil, false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// rebuildBoards 根据提交记录全量重建同一时间范围内各排行方式的榜单（调用方需持有班级榜单锁）
+func (s *LeaderboardService) rebuildBoards(cls *classModel.Class, window string, start, now time.Time) error {
+	runs, err := s.codeRunDAO.ListClassJudgedSubmitsSince(cls.ClassId, "", start)
+	if err != nil {
+		return err
+	}
+	members, err := s.classMemberDAO.ListAllMembersByClassId(cls.ClassId)
+	if err != nil {
+		return err
+	}
+	metrics := computeLeaderboardMetrics(runs, start, now)
+
+	ctx := context.Background()
+	keys := leaderboardKeys(cls.ClassId, window, start, now)
+	pipe := s.redisClient.Client.TxPipeline()
+	for i, mode := range leaderboardModes {
+		zs := []redis.Z{{Score: -1, Member: leaderboardInitMember}}
+		for _, m := range members {
+			value := int64(0)
+			if mt := metrics[m.StudentId]; mt != nil {
+				value = mt.value(mode)
+			}
+			zs = append(zs, redis.Z{Score: float64(value), Member: m.StudentId})
+		}
+		pipe.Del(ctx, keys[i])
+		pipe.ZAdd(ctx, keys[i], zs...)
+		pipe.Expire(ctx, keys[i], leaderboardTTL(mode, window, start, now))
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// windowStart 统计范围的开始时间
+func (s *LeaderboardService) windowStart(cls *classModel.Class, window string, now time.Time) time.Time {
+	if window == LeaderboardWindowSemester {
+		if semester, err := s.semesterDAO.GetSemesterByName(cls.Semester); err == nil && semester != nil && !semester.StartDate.IsZero() {
+			start := semester.StartDate
+			return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, now.Location())
+		}
+		return cls.CreateTime
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := (int(today.Weekday()) + 6) % 7 // 周一为一周开始
+	return today.AddDate(0, 0, -offset)
+}
+
+// leaderboardKeys 各排行方式的榜单缓存键（按 leaderboardModes 顺序）
+// 周榜按周开始日期区分，学期榜按学期开始日期区分；连续天数会随日期变化，额外按当天日期区分
+func leaderboardKeys(classId, window string, start, now time.Time) []string {
+	keys := make([]string, 0, len(leaderboardModes))
+	for _, mode := range leaderboardModes {
+		key := fmt.Sprintf("class:leaderboard:%s:%s:%s:%s", classId, window, start.Format("20060102"), mode)
+		if mode == LeaderboardModeStreak {
+			key += ":" + now.Format("20060102")
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// leaderboardTTL 榜单缓存有效期（周榜到本周结束，连续天数榜到当天结束，学期榜定期重建）
+func leaderboardTTL(mode, window string, start, now time.Time) time.Duration {
+	var expireAt time.Time
+	switch {
+	case mode == LeaderboardModeStreak:
+		expireAt = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	case window == LeaderboardWindowWeek:
+		expireAt = start.AddDate(0, 0, 7)
+	default:
+		return leaderboardSemesterTTL
+	}
+	return time.Until(expireAt) + leaderboardExpireMargin
+}
+
+// computeLeaderboardMetrics 按学生统计通过题数、总得分（每道题取最高得分）与连续通过天数
+func computeLeaderboardMetrics(runs []*codeModel.CodeRun, start, now time.Time) map[string]*leaderboardMetrics {
+	type studentState struct {
+		best     map[int64]int32
+		accepted map[int64]bool
+		acDays   map[string]bool
+	}
+	states := make(map[string]*studentState)
+	for _, run := range runs {
+		st, ok := states[run.StudentId]
+		if !ok {
+			st = &studentState{best: make(map[int64]int32), accepted: make(map[int64]bool), acDays: make(map[string]bool)}
+			states[run.StudentId] = st
+		}
+		if score := calcRunScore(run); score > st.best[run.ProblemId] {
+			st.best[run.ProblemId] = score
+		}
+		if run.Status == "accepted" {
+			st.accepted[run.ProblemId] = true
+			st.acDays[run.CreatedAt.In(now.Location()).Format("2006-01-02")] = true
+		}
+	}
+
+	result := make(map[string]*leaderboardMetrics, len(states))
+	firstDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, now.Location())
+	for studentId, st := range states {
+		m := &leaderboardMetrics{Solved: int64(len(st.accepted))}
+		for _, score := range st.best {
+			m.Score += int64(score)
+		}
+		// 今天还没有通过时从昨天开始计算，连续天数不早于统计开始日期
+		day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if !st.acDays[day.Format("2006-01-02")] {
+			day = day.AddDate(0, 0, -1)
+		}
+		for !day.Before(firstDay) && st.acDays[day.Format("2006-01-02")] {
+			m.Streak++
+			day = day.AddDate(0, 0, -1)
+		}
+		result[studentId] = m
+	}
+	return result
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	codeModel "github.com/yzf120/elysia-backend/model/code"
+	"github.com/yzf120/elysia-backend/model/mistake"
+)
+
+// failStatusReasons 运行状态到错题原因分类的映射
+var failStatusReasons = map[string]string{
+	"wrong_answer":          mistake.FailReasonWA,
+	"time_limit_exceeded":   mistake.FailReasonTLE,
+	"memory_limit_exceeded": mistake.FailReasonMLE,
+	"runtime_error":         mistake.FailReasonRE,
+	"compile_error":         mistake.FailReasonCE,
+}
+
+// MistakeService 错题本服务
+type MistakeService struct {
+	mistakeDAO dao.StudentMistakeDAO
+	codeRunDAO dao.CodeRunDAO
+	problemDAO dao.ProblemDAO
+}
+
+// NewMistakeService 创建错题本服务
+func NewMistakeService() *MistakeService {
+	return &MistakeService{
+		mistakeDAO: dao.NewStudentMistakeDAO(),
+		codeRunDAO: dao.NewCodeRunDAO(),
+		problemDAO: dao.NewProblemDAO(),
+	}
+}
+
+// MistakeDetail 错题详情（失败版本与通过版本并排展示）
+type MistakeDetail struct {
+	Mistake      *mistake.StudentMistake `json:"mistake"`
+	ProblemTitle string                  `json:"problem_title"`
+	FailedRun    *codeModel.CodeRun      `json:"failed_run"`
+	PassedRun    *codeModel.CodeRun      `json:"passed_run"`
+}
+
+// OnCodeRunJudged 评测完成后根据判题结果维护错题本（只处理 submit 类型，比赛内提交不计入）
+// 失败：新建或累加错题，已订正/已掌握的错题重新打开；通过：未订正的错题标记为已订正
+func (s *MistakeService) OnCodeRunJudged(runId int64) {
+	run, err := s.codeRunDAO.GetCodeRunById(runId)
+	if err != nil || run == nil || run.RunType != "submit" || run.ContestId != "" {
+		return
+	}
+
+	now := time.Now()
+
+	if run.Status == "accepted" {
+		existing, _ := s.mistakeDAO.GetMistakeByStudentProblem(run.StudentId, run.ProblemId)
+		if existing == nil || existing.Status != mistake.MistakeStatusUnresolved {
+			return
+		}
+		err = s.mistakeDAO.UpdateMistake(existing.MistakeId, map[string]interface{}{
+			"status":        mistake.MistakeStatusFixed,
+			"passed_run_id": run.Id,
+			"fixed_time":    now,
+		})
+		if err != nil {
+			log.Printf("[MistakeService] 标记错题已订正失败: mistake_id=%s, err=%v", existing.MistakeId, err)
+		}
+		return
+	}
+
+	reason, ok := failStatusReasons[run.Status]
+	if !ok {
+		return
+	}
+
+	// 新建与累加合并为一条 upsert，避免并发失败判题撞唯一键；已订正/已掌握的错题会被重新打开
+	stats, _ := json.Marshal(map[string]int32{reason: 1})
+	m := &mistake.StudentMistake{
+		MistakeId:    fmt.Sprintf("mis_%d", now.UnixNano()),
+		StudentId:    run.StudentId,
+		ProblemId:    run.ProblemId,
+		FailReason:   reason,
+		ReasonStats:  string(stats),
+		FailCount:    1,
+		FailedRunId:  run.Id,
+		Status:       mistake.MistakeStatusUnresolved,
+		LastFailTime: now,
+	}
+	if err := s.mistakeDAO.RecordFailure(m); err != nil {
+		log.Printf("[MistakeService] 记录错题失败: run_id=%d, err=%v", run.Id, err)
+	}
+}
+
+// ListMistakes 分页查询学生错题列表
+func (s *MistakeService) ListMistakes(studentId string, status int32, reason string, page, pageSize int32) ([]*mistake.StudentMistake, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+	list, err := s.mistakeDAO.ListMistakesByStudent(studentId, status, reason, pageSize, offset)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "查询错题列表失败: "+err.Error())
+	}
+	total, err := s.mistakeDAO.CountMistakesByStudent(studentId, status, reason)
+	if err != nil {
+		return nil, 0, errs.NewCommonError(errs.ErrInternal, "统计错题数量失败: "+err.Error())
+	}
+	return list, total, nil
+}
+
+// GetMistakeDetail 查询错题详情（含失败代码与通过代码）
+func (s *MistakeService) GetMistakeDetail(studentId, mistakeId string) (*MistakeDetail, error) {
+	m, err := s.getOwnedMistake(studentId, mistakeId)
+	if err != nil {
+		return nil, err
+	}
+	detail := &MistakeDetail{Mistake: m}
+	if p, err := s.problemDAO.GetProblemById(m.ProblemId); err == nil && p != nil {
+		detail.ProblemTitle = p.Title
+	}
+	if m.FailedRunId > 0 {
+		detail.FailedRun, _ = s.codeRunDAO.GetCodeRunById(m.FailedRunId)
+	}
+	if m.PassedRunId > 0 {
+		detail.PassedRun, _ = s.codeRunDAO.GetCodeRunById(m.PassedRunId)
+	}
+	return detail, nil
+}
+
+// UpdateMistakeNote 更新错题笔记
+func (s *MistakeService) UpdateMistakeNote(studentId, mistakeId, note string) error {
+	if _, err := s.getOwnedMistake(studentId, mistakeId); err != nil {
+		return err
+	}
+	if err := s.mistakeDAO.UpdateMistake(mistakeId, map[string]interface{}{"note": note}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新错题笔记失败: "+err.Error())
+	}
+	return nil
+}
+
+// SetMistakeMastered 标记/取消标记错题为已掌握
+// 取消标记时根据是否已有通过记录恢复为已订正或未订正
+func (s *MistakeService) SetMistakeMastered(studentId, mistakeId string, mastered bool) error {
+	m, err := s.getOwnedMistake(studentId, mistakeId)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{}
+	if mastered {
+		updates["status"] = mistake.MistakeStatusMastered
+		updates["mastered_time"] = time.Now()
+	} else {
+		if m.Status != mistake.MistakeStatusMastered {
+			return nil
+		}
+		updates["status"] = mistake.MistakeStatusUnresolved
+		if m.PassedRunId > 0 {
+			updates["status"] = mistake.MistakeStatusFixed
+		}
+		updates["mastered_time"] = nil
+	}
+	if err := s.mistakeDAO.UpdateMistake(mistakeId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新错题状态失败: "+err.Error())
+	}
+	return nil
+}
+
+// getOwnedMistake 查询错题并校验归属
+func (s *MistakeService) getOwnedMistake(studentId, mistakeId string) (*mistake.StudentMistake, error) {
+	if mistakeId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "mistake_id 不能为空")
+	}
+	m, err := s.mistakeDAO.GetMistakeById(mistakeId)
+	if err != nil || m == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "错题不存在")
+	}
+	if m.StudentId != studentId {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "无权限操作该错题")
+	}
+	return m, nil
+}
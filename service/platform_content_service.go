@@ -519,6 +519,11 @@ func validateBookshelfInput(input SaveBookshelfItemInput, fileHeader *multipart.
 }
 
 func storeAttachment(fileHeader *multipart.FileHeader) (string, string, string, int64, error) {
+	return storeAttachmentTo(platformBookshelfUploadDir, fileHeader)
+}
+
+// storeAttachmentTo 将上传文件保存到指定目录，返回原文件名、存储名、MIME 类型和大小
+func storeAttachmentTo(dir string, fileHeader *multipart.FileHeader) (string, string, string, int64, error) {
 	if fileHeader == nil {
 		return "", "", "", 0, nil
 	}
@@ -529,11 +534,11 @@ func storeAttachment(fileHeader *multipart.FileHeader) (string, string, string,
 	if originalName == "" || originalName == "." {
 		return "", "", "", 0, errs.NewCommonError(http.StatusBadRequest, "附件文件名无效")
 	}
-	if err := os.MkdirAll(platformBookshelfUploadDir, 0755); err != nil {
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", "", "", 0, errs.NewCommonError(http.StatusInternalServerError, "创建附件目录失败: "+err.Error())
 	}
 	storageName := fmt.Sprintf("%d%s", time.Now().UnixNano(), strings.ToLower(filepath.Ext(originalName)))
-	fullPath := filepath.Join(platformBookshelfUploadDir, storageName)
+	fullPath := filepath.Join(dir, storageName)
 
 	src, err := fileHeader.Open()
 	if err != nil {
@@ -548,18 +553,22 @@ func storeAttachment(fileHeader *multipart.FileHeader) (string, string, string,
 	defer dst.Close()
 
 	if _, err := io.Copy(dst, src); err != nil {
-		removeStoredAttachment(storageName)
+		removeStoredAttachmentFrom(dir, storageName)
 		return "", "", "", 0, errs.NewCommonError(http.StatusInternalServerError, "保存附件失败: "+err.Error())
 	}
 	return originalName, storageName, fileHeader.Header.Get("Content-Type"), fileHeader.Size, nil
 }
 
 func removeStoredAttachment(storageName string) {
+	removeStoredAttachmentFrom(platformBookshelfUploadDir, storageName)
+}
+
+func removeStoredAttachmentFrom(dir, storageName string) {
 	storageName = strings.TrimSpace(filepath.Base(storageName))
 	if storageName == "" || storageName == "." {
 		return
 	}
-	_ = os.Remove(filepath.Join(platformBookshelfUploadDir, storageName))
+	_ = os.Remove(filepath.Join(dir, storageName))
 }
 
 func normalizePage(page, pageSize int) (int, int) {
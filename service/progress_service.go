@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yzf120/elysia-backend/client"
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+)
+
+// progressCacheTTL 学习进度缓存的最长有效期（有小节即将定时发布时提前过期）
+const progressCacheTTL = 30 * time.Minute
+
+// ProgressService 学习进度服务（按班级、章节统计学生完成的小节，由服务端根据评测结果等计算）
+type ProgressService struct {
+	classDAO       dao.ClassDAO
+	classMemberDAO dao.ClassMemberDAO
+	chapterDAO     dao.ChapterDAO
+	studentDAO     dao.StudentDAO
+	staffService   *ClassStaffService
+	accessService  *SectionAccessService
+	redisClient    *client.RedisClient
+}
+
+// NewProgressService 创建学习进度服务
+func NewProgressService() *ProgressService {
+	return &ProgressService{
+		classDAO:       dao.NewClassDAO(),
+		classMemberDAO: dao.NewClassMemberDAO(),
+		chapterDAO:     dao.NewChapterDAO(),
+		studentDAO:     dao.NewStudentDAO(),
+		staffService:   NewClassStaffService(),
+		accessService:  NewSectionAccessService(),
+		redisClient:    client.GetRedisClient(),
+	}
+}
+
+// ChapterProgress 章节完成进度
+type ChapterProgress struct {
+	ChapterId         string  `json:"chapter_id"`
+	Title             string  `json:"title"`
+	TotalSections     int32   `json:"total_sections"`     // 已发布的小节数
+	CompletedSections int32   `json:"completed_sections"` // 已完成的小节数
+	Percent           float64 `json:"percent"`            // 完成百分比（0-100）
+}
+
+// ClassProgress 学生在一个班级的学习进度
+type ClassProgress struct {
+	ClassId           string             `json:"class_id"`
+	ClassName         string             `json:"class_name"`
+	TotalSections     int32              `json:"total_sections"`
+	CompletedSections int32              `json:"completed_sections"`
+	Percent           float64            `json:"percent"`
+	Chapters          []*ChapterProgress `json:"chapters"`
+	ComputeTime       string             `json:"compute_time"` // 计算时间
+}
+
+// MemberProgress 班级成员的学习进度（教师查看）
+type MemberProgress struct {
+	StudentId     string `json:"student_id"`
+	StudentName   string `json:"student_name"`
+	StudentNumber string `json:"student_number"`
+	*ClassProgress
+}
+
+// ListMyProgress 查询学生在所加入的各班级的学习进度
+func (s *ProgressService) ListMyProgress(studentId string) ([]*ClassProgress, error) {
+	if studentId == "" {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "学生ID不能为空")
+	}
+	members, err := s.classMemberDAO.ListAllClassesByStudentId(studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级失败: "+err.Error())
+	}
+	list := make([]*ClassProgress, 0, len(members))
+	for _, m := range members {
+		cls, err := s.classDAO.GetClassById(m.ClassId)
+		if err != nil || cls == nil {
+			continue
+		}
+		if cached := s.getCached(cls.ClassId, studentId); cached != nil {
+			list = append(list, cached)
+			continue
+		}
+		chapters, sections, err := s.loadClassContent(cls.ClassId)
+		if err != nil {
+			return nil, err
+		}
+		progress, err := s.compute(cls, studentId, chapters, sections)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, progress)
+	}
+	return list, nil
+}
+
+// ListClassProgress 查询班级全部成员的学习进度（需成绩查看权限）
+func (s *ProgressService) ListClassProgress(teacherId, classId string) ([]*MemberProgress, error) {
+	cls, err := s.staffService.CheckPermission(classId, teacherId, consts.ClassPermGradeView)
+	if err != nil {
+		return nil, err
+	}
+	return s.listMemberProgress(cls)
+}
+
+// listMemberProgress 查询班级全部成员的学习进度（不校验权限，缓存未命中时计算）
+func (s *ProgressService) listMemberProgress(cls *classModel.Class) ([]*MemberProgress, error) {
+	classId := cls.ClassId
+	members, err := s.classMemberDAO.ListAllMembersByClassId(classId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+
+	studentIds := make([]string, 0, len(members))
+	for _, m := range members {
+		studentIds = append(studentIds, m.StudentId)
+	}
+	studentInfo := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds})
+		if err == nil {
+			for _, st := range students {
+				studentInfo[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+			}
+		}
+	}
+
+	var chapters []*classModel.ClassChapter
+	var sections []*classModel.ClassSection
+	loaded := false
+	list := make([]*MemberProgress, 0, len(members))
+	for _, m := range members {
+		progress := s.getCached(classId, m.StudentId)
+		if progress == nil {
+			if !loaded {
+				if chapters, sections, err = s.loadClassContent(classId); err != nil {
+					return nil, err
+				}
+				loaded = true
+			}
+			if progress, err = s.compute(cls, m.StudentId, chapters, sections); err != nil {
+				return nil, err
+			}
+		}
+		info := studentInfo[m.StudentId]
+		list = append(list, &MemberProgress{
+			StudentId:     m.StudentId,
+			StudentName:   info[0],
+			StudentNumber: info[1],
+			ClassProgress: progress,
+		})
+	}
+	return list, nil
+}
+
+// RefreshStudentProgress 重新计算并缓存学生在班级的学习进度（评测完成、交卷、发帖、查看资源后调用）
+func (s *ProgressService) RefreshStudentProgress(classId, studentId string) {
+	if classId == "" || studentId == "" {
+		return
+	}
+	cls, err := s.classDAO.GetClassById(classId)
+	if err != nil || cls == nil {
+		return
+	}
+	chapters, sections, err := s.loadClassContent(classId)
+	if err != nil {
+		s.InvalidateStudentProgress(classId, studentId)
+		return
+	}
+	if _, err := s.compute(cls, studentId, chapters, sections); err != nil {
+		log.Printf("[ProgressService] 重新计算学习进度失败: class_id=%s, student_id=%s, err=%v", classId, studentId, err)
+		s.InvalidateStudentProgress(classId, studentId)
+	}
+}
+
+// InvalidateStudentProgress 清除学生在班级的进度缓存
+func (s *ProgressService) InvalidateStudentProgress(classId, studentId string) {
+	if s.redisClient == nil {
+		return
+	}
+	_ = s.redisClient.Client.HDel(context.Background(), progressCacheKey(classId), studentId).Err()
+}
+
+// InvalidateClassProgress 清除班级全部学生的进度缓存（章节、小节结构或发布设置变化时调用）
+func (s *ProgressService) InvalidateClassProgress(classId string) {
+	if s.redisClient == nil || classId == "" {
+		return
+	}
+	_ = s.redisClient.Del(progressCacheKey(classId))
+}
+
+// loadClassContent 查询班级的章节与小节
+func (s *ProgressService) loadClassContent(classId string) ([]*classModel.ClassChapter, []*classModel.ClassSection, error) {
+	chapters, err := s.chapterDAO.ListChaptersByClassId(classId)
+	if err != nil {
+		return nil, nil, errs.NewCommonError(errs.ErrInternal, "查询章节失败: "+err.Error())
+	}
+	sections, err := s.chapterDAO.ListSectionsByClassId(classId)
+	if err != nil {
+		return nil, nil, errs.NewCommonError(errs.ErrInternal, "查询小节失败: "+err.Error())
+	}
+	return chapters, sections, nil
+}
+
+// compute 计算学生在班级的学习进度并写入缓存（只统计已对学生发布的章节与小节）
+func (s *ProgressService) compute(cls *classModel.Class, studentId string,
+	chapters []*classModel.ClassChapter, sections []*classModel.ClassSection) (*ClassProgress, error) {
+	completed, err := s.accessService.LoadCompletedSections(cls.ClassId, studentId, sections)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	visible, sectionMap := s.accessService.BuildStudentView(chapters, sections, completed, now)
+
+	progress := &ClassProgress{
+		ClassId:     cls.ClassId,
+		ClassName:   cls.ClassName,
+		Chapters:    make([]*ChapterProgress, 0, len(visible)),
+		ComputeTime: formatTime(now),
+	}
+	for _, ch := range visible {
+		item := &ChapterProgress{ChapterId: ch.ChapterId, Title: ch.Title}
+		for _, view := range sectionMap[ch.ChapterId] {
+			item.TotalSections++
+			if view.Completed {
+				item.CompletedSections++
+			}
+		}
+		item.Percent = progressPercent(item.CompletedSections, item.TotalSections)
+		progress.TotalSections += item.TotalSections
+		progress.CompletedSections += item.CompletedSections
+		progress.Chapters = append(progress.Chapters, item)
+	}
+	progress.Percent = progressPercent(progress.CompletedSections, progress.TotalSections)
+
+	s.setCached(progress, studentId, progressCacheExpiration(chapters, sections, now))
+	return progress, nil
+}
+
+// getCached 读取进度缓存（未命中或 Redis 不可用时返回 nil）
+func (s *ProgressService) getCached(classId, studentId string) *ClassProgress {
+	if s.redisClient == nil {
+		return nil
+	}
+	raw, err := s.redisClient.Client.HGet(context.Background(), progressCacheKey(classId), studentId).Result()
+	if err != nil {
+		return nil
+	}
+	var progress ClassProgress
+	if err := json.Unmarshal([]byte(raw), &progress); err != nil {
+		return nil
+	}
+	return &progress
+}
+
+// setCached 写入进度缓存
+func (s *ProgressService) setCached(progress *ClassProgress, studentId string, expiration time.Duration) {
+	if s.redisClient == nil {
+		return
+	}
+	ctx := context.Background()
+	key := progressCacheKey(progress.ClassId)
+	data, _ := json.Marshal(progress)
+	pipe := s.redisClient.Client.TxPipeline()
+	pipe.HSet(ctx, key, studentId, string(data))
+	pipe.Expire(ctx, key, expiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[ProgressService] 写入进度缓存失败: class_id=%s, err=%v", progress.ClassId, err)
+	}
+}
+
+// progressCacheExpiration 缓存有效期：不超过 progressCacheTTL，且在下一个定时发布时间到达时过期
+func progressCacheExpiration(chapters []*classModel.ClassChapter, sections []*classModel.ClassSection, now time.Time) time.Duration {
+	expiration := progressCacheTTL
+	check := func(releaseAt *time.Time) {
+		if releaseAt != nil && releaseAt.After(now) && releaseAt.Sub(now) < expiration {
+			expiration = releaseAt.Sub(now)
+		}
+	}
+	for _, ch := range chapters {
+		check(ch.ReleaseAt)
+	}
+	for _, sec := range sections {
+		check(sec.ReleaseAt)
+	}
+	if expiration < time.Second {
+		expiration = time.Second
+	}
+	return expiration
+}
+
+// progressPercent 完成百分比（保留两位小数，无小节时为 0）
+func progressPercent(completed, total int32) float64 {
+	if total == 0 {
+		return 0
+	}
+	return roundScore(float64(completed) * 100 / float64(total))
+}
+
+// progressCacheKey 班级进度缓存 key（hash：student_id -> 进度 JSON）
+func progressCacheKey(classId string) string {
+	return fmt.Sprintf("class:progress:%s", classId)
+}
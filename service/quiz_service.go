@@ -0,0 +1,882 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+)
+
+const (
+	quizMaxOptions  = 10               // 选择题最多选项数
+	quizSubmitGrace = 30 * time.Second // 超过作答时限后仍接受交卷的宽限时间（网络延迟）
+)
+
+// QuizService 测验服务（客观题测验小节的题目管理、作答与自动判分）
+type QuizService struct {
+	chapterDAO      dao.ChapterDAO
+	quizDAO         dao.QuizDAO
+	classMemberDAO  dao.ClassMemberDAO
+	studentDAO      dao.StudentDAO
+	staffService    *ClassStaffService
+	accessService   *SectionAccessService
+	progressService *ProgressService
+}
+
+// NewQuizService 创建测验服务
+func NewQuizService() *QuizService {
+	return &QuizService{
+		chapterDAO:      dao.NewChapterDAO(),
+		quizDAO:         dao.NewQuizDAO(),
+		classMemberDAO:  dao.NewClassMemberDAO(),
+		studentDAO:      dao.NewStudentDAO(),
+		staffService:    NewClassStaffService(),
+		accessService:   NewSectionAccessService(),
+		progressService: NewProgressService(),
+	}
+}
+
+// QuizQuestionInput 题目编辑参数
+type QuizQuestionInput struct {
+	QuestionType string   `json:"question_type"` // 题型：single/multiple/true_false/fill_blank
+	Content      string   `json:"content"`       // 题干
+	Options      []string `json:"options"`       // 选项（选择题必填，按 A、B、C… 编号）
+	Answer       []string `json:"answer"`        // 标准答案（选择题为选项字母，判断题为 true/false，填空题每空一项，多个可接受答案用 | 分隔）
+	Explanation  string   `json:"explanation"`   // 答案解析
+	Score        int32    `json:"score"`         // 分值（默认 1）
+}
+
+// QuizQuestionView 题目视图（学生作答时不含答案与解析）
+type QuizQuestionView struct {
+	QuestionId   string   `json:"question_id"`
+	QuestionType string   `json:"question_type"`
+	Content      string   `json:"content"`
+	Options      []string `json:"options"`
+	Score        int32    `json:"score"`
+	SortOrder    int32    `json:"sort_order"`
+	Answer       []string `json:"answer,omitempty"`
+	Explanation  string   `json:"explanation,omitempty"`
+}
+
+// QuizPaper 学生作答中的试卷
+type QuizPaper struct {
+	AttemptId   string              `json:"attempt_id"`
+	SectionId   string              `json:"section_id"`
+	AttemptNo   int32               `json:"attempt_no"`
+	StartTime   string              `json:"start_time"`
+	Deadline    string              `json:"deadline"` // 作答截止时间（为空表示不限时）
+	TotalScore  int32               `json:"total_score"`
+	Questions   []*QuizQuestionView `json:"questions"`
+	Answers     map[string][]string `json:"answers"` // 已保存的答案
+	MaxAttempts int32               `json:"max_attempts"`
+}
+
+// QuizAnswerItem 单题判分结果
+type QuizAnswerItem struct {
+	QuestionId    string   `json:"question_id"`
+	Answer        []string `json:"answer"`         // 学生答案
+	Correct       bool     `json:"correct"`        // 是否完全正确
+	Score         int32    `json:"score"`          // 得分
+	FullScore     int32    `json:"full_score"`     // 分值
+	CorrectAnswer []string `json:"correct_answer"` // 标准答案（学生作答次数用完或测验截止后才返回）
+	Explanation   string   `json:"explanation"`
+}
+
+// QuizAttemptResult 作答结果
+type QuizAttemptResult struct {
+	AttemptId   string            `json:"attempt_id"`
+	SectionId   string            `json:"section_id"`
+	StudentId   string            `json:"student_id"`
+	AttemptNo   int32             `json:"attempt_no"`
+	Status      int32             `json:"status"` // 0-作答中，1-已交卷
+	Score       int32             `json:"score"`
+	TotalScore  int32             `json:"total_score"`
+	StartTime   string            `json:"start_time"`
+	SubmitTime  string            `json:"submit_time"`
+	Items       []*QuizAnswerItem `json:"items,omitempty"` // 逐题结果（仅已交卷时返回）
+	TimeExpired bool              `json:"time_expired"`    // 是否因超时按已保存答案交卷
+	Revealed    bool              `json:"revealed"`        // 是否已公布标准答案与解析
+}
+
+// QuizStudentResult 学生测验成绩汇总（教师查看）
+type QuizStudentResult struct {
+	StudentId      string `json:"student_id"`
+	StudentName    string `json:"student_name"`
+	StudentNumber  string `json:"student_number"`
+	Attempts       int32  `json:"attempts"`         // 已交卷次数
+	BestScore      int32  `json:"best_score"`       // 最高得分
+	TotalScore     int32  `json:"total_score"`      // 满分
+	BestAttemptId  string `json:"best_attempt_id"`  // 最高分对应的作答
+	LastSubmitTime string `json:"last_submit_time"` // 最近交卷时间（为空表示未交卷）
+}
+
+// ==================== 教师操作 ====================
+
+// SetQuizSettings 设置测验参数（教师操作）
+// openTime/dueTime 格式为 "2006-01-02 15:04:05"，传空字符串表示不限制；timeLimit 单位为分钟，0 表示不限时
+func (s *QuizService) SetQuizSettings(teacherId, sectionId, openTime, dueTime string, maxAttempts int32, shuffle bool, timeLimit int32) error {
+	section, err := s.getQuizSection(sectionId)
+	if err != nil {
+		return err
+	}
+	if _, err := s.staffService.CheckWritePermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
+	}
+	if maxAttempts < 0 {
+		return errs.NewCommonError(errs.ErrBadRequest, "最大作答次数不能为负数")
+	}
+	if timeLimit < 0 {
+		return errs.NewCommonError(errs.ErrBadRequest, "作答时限不能为负数")
+	}
+	openAt, err := parseOptionalTime(openTime)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "开放时间格式错误: "+err.Error())
+	}
+	dueAt, err := parseOptionalTime(dueTime)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "截止时间格式错误: "+err.Error())
+	}
+	if openAt != nil && dueAt != nil && !dueAt.After(*openAt) {
+		return errs.NewCommonError(errs.ErrBadRequest, "截止时间必须晚于开放时间")
+	}
+
+	updates := map[string]interface{}{
+		"open_time":       openAt,
+		"due_time":        dueAt,
+		"max_attempts":    maxAttempts,
+		"quiz_shuffle":    shuffle,
+		"quiz_time_limit": timeLimit,
+	}
+	if err := s.chapterDAO.UpdateSection(sectionId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新测验设置失败: "+err.Error())
+	}
+	return nil
+}
+
+// AddQuestion 添加测验题目（教师操作）
+func (s *QuizService) AddQuestion(teacherId, sectionId string, input *QuizQuestionInput) (*classModel.ClassQuizQuestion, error) {
+	section, err := s.getQuizSection(sectionId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.staffService.CheckWritePermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return nil, err
+	}
+	options, answer, err := normalizeQuizQuestion(input)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, _ := s.quizDAO.ListQuestionsBySectionId(sectionId)
+	sortOrder := int32(10)
+	if len(questions) > 0 {
+		sortOrder = questions[len(questions)-1].SortOrder + 10
+	}
+
+	optionsJSON, _ := json.Marshal(options)
+	answerJSON, _ := json.Marshal(answer)
+	question := &classModel.ClassQuizQuestion{
+		QuestionId:   fmt.Sprintf("qq_%d", time.Now().UnixNano()),
+		SectionId:    sectionId,
+		ClassId:      section.ClassId,
+		QuestionType: input.QuestionType,
+		Content:      input.Content,
+		Options:      string(optionsJSON),
+		Answer:       string(answerJSON),
+		Explanation:  input.Explanation,
+		Score:        input.Score,
+		SortOrder:    sortOrder,
+	}
+	if err := s.quizDAO.CreateQuestion(question); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "添加题目失败: "+err.Error())
+	}
+	return question, nil
+}
+
+// UpdateQuestion 更新测验题目（教师操作，整体替换题目内容；sortOrder 为 0 时保持原排序）
+func (s *QuizService) UpdateQuestion(teacherId, questionId string, input *QuizQuestionInput, sortOrder int32) error {
+	question, err := s.quizDAO.GetQuestionById(questionId)
+	if err != nil || question == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "题目不存在")
+	}
+	if _, err := s.staffService.CheckWritePermission(question.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
+	}
+	options, answer, err := normalizeQuizQuestion(input)
+	if err != nil {
+		return err
+	}
+
+	optionsJSON, _ := json.Marshal(options)
+	answerJSON, _ := json.Marshal(answer)
+	updates := map[string]interface{}{
+		"question_type": input.QuestionType,
+		"content":       input.Content,
+		"options":       string(optionsJSON),
+		"answer":        string(answerJSON),
+		"explanation":   input.Explanation,
+		"score":         input.Score,
+	}
+	if sortOrder > 0 {
+		updates["sort_order"] = sortOrder
+	}
+	if err := s.quizDAO.UpdateQuestion(questionId, updates); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "更新题目失败: "+err.Error())
+	}
+	return nil
+}
+
+// DeleteQuestion 删除测验题目（教师操作，已交卷的成绩不受影响）
+func (s *QuizService) DeleteQuestion(teacherId, questionId string) error {
+	question, err := s.quizDAO.GetQuestionById(questionId)
+	if err != nil || question == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "题目不存在")
+	}
+	if _, err := s.staffService.CheckWritePermission(question.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return err
+	}
+	if err := s.quizDAO.DeleteQuestion(questionId); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "删除题目失败: "+err.Error())
+	}
+	return nil
+}
+
+// ListQuestions 查询测验题目（教师操作，含答案与解析）
+func (s *QuizService) ListQuestions(teacherId, sectionId string) ([]*QuizQuestionView, error) {
+	section, err := s.getQuizSection(sectionId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.staffService.CheckPermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return nil, err
+	}
+	questions, err := s.quizDAO.ListQuestionsBySectionId(sectionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询题目失败: "+err.Error())
+	}
+	views := make([]*QuizQuestionView, 0, len(questions))
+	for _, q := range questions {
+		views = append(views, buildQuizQuestionView(q, true))
+	}
+	return views, nil
+}
+
+// ListQuizResults 查询测验小节的学生成绩汇总（教师操作）
+func (s *QuizService) ListQuizResults(teacherId, sectionId string) ([]*QuizStudentResult, error) {
+	section, err := s.getQuizSection(sectionId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.staffService.CheckPermission(section.ClassId, teacherId, consts.ClassPermGradeView); err != nil {
+		return nil, err
+	}
+
+	members, err := s.classMemberDAO.ListAllMembersByClassId(section.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	attempts, err := s.quizDAO.ListAttemptsBySectionId(sectionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询作答记录失败: "+err.Error())
+	}
+	questions, err := s.quizDAO.ListQuestionsBySectionId(sectionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询题目失败: "+err.Error())
+	}
+	var fullScore int32
+	for _, q := range questions {
+		fullScore += q.Score
+	}
+
+	studentIds := make([]string, 0, len(members))
+	for _, m := range members {
+		studentIds = append(studentIds, m.StudentId)
+	}
+	studentInfo := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds})
+		if err == nil {
+			for _, st := range students {
+				studentInfo[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+			}
+		}
+	}
+
+	resultMap := make(map[string]*QuizStudentResult, len(members))
+	results := make([]*QuizStudentResult, 0, len(members))
+	for _, m := range members {
+		r := &QuizStudentResult{
+			StudentId:     m.StudentId,
+			StudentName:   studentInfo[m.StudentId][0],
+			StudentNumber: studentInfo[m.StudentId][1],
+			TotalScore:    fullScore,
+		}
+		resultMap[m.StudentId] = r
+		results = append(results, r)
+	}
+	for _, a := range attempts {
+		r := resultMap[a.StudentId]
+		if r == nil || a.Status != classModel.QuizAttemptSubmitted {
+			continue
+		}
+		r.Attempts++
+		if r.BestAttemptId == "" || a.Score > r.BestScore {
+			r.BestScore = a.Score
+			r.TotalScore = a.TotalScore
+			r.BestAttemptId = a.AttemptId
+		}
+		if a.SubmitTime != nil {
+			submitTime := formatTime(*a.SubmitTime)
+			if submitTime > r.LastSubmitTime {
+				r.LastSubmitTime = submitTime
+			}
+		}
+	}
+	return results, nil
+}
+
+// GetTeacherAttempt 查询学生某次作答的逐题结果（教师操作）
+func (s *QuizService) GetTeacherAttempt(teacherId, attemptId string) (*QuizAttemptResult, error) {
+	attempt, err := s.quizDAO.GetAttemptById(attemptId)
+	if err != nil || attempt == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "作答记录不存在")
+	}
+	if _, err := s.staffService.CheckPermission(attempt.ClassId, teacherId, consts.ClassPermGradeView); err != nil {
+		return nil, err
+	}
+	return s.buildAttemptResult(attempt, false, true)
+}
+
+// ==================== 学生操作 ====================
+
+// StartQuiz 开始或继续作答（存在未超时的作答时直接返回该试卷）
+func (s *QuizService) StartQuiz(studentId, sectionId string) (*QuizPaper, error) {
+	section, err := s.checkStudentSection(studentId, sectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.quizDAO.ListAttemptsByStudent(sectionId, studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询作答记录失败: "+err.Error())
+	}
+	now := time.Now()
+	for _, a := range attempts {
+		if a.Status != classModel.QuizAttemptInProgress {
+			continue
+		}
+		if a.Deadline == nil || now.Before(a.Deadline.Add(quizSubmitGrace)) {
+			return s.buildPaper(section, a)
+		}
+		// 已超时的作答按已保存答案自动交卷
+		if _, err := s.finishAttempt(a, nil, *a.Deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if section.OpenTime != nil && now.Before(*section.OpenTime) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "测验尚未开放，开放时间: "+formatTime(*section.OpenTime))
+	}
+	if section.DueTime != nil && now.After(*section.DueTime) {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "测验已截止")
+	}
+	if section.MaxAttempts > 0 && int32(len(attempts)) >= section.MaxAttempts {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "已达到最大作答次数")
+	}
+
+	questions, err := s.quizDAO.ListQuestionsBySectionId(sectionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询题目失败: "+err.Error())
+	}
+	if len(questions) == 0 {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "测验暂无题目")
+	}
+	questionIds := make([]string, 0, len(questions))
+	var totalScore int32
+	for _, q := range questions {
+		questionIds = append(questionIds, q.QuestionId)
+		totalScore += q.Score
+	}
+	if section.QuizShuffle {
+		rand.Shuffle(len(questionIds), func(i, j int) {
+			questionIds[i], questionIds[j] = questionIds[j], questionIds[i]
+		})
+	}
+
+	// 作答截止时间取时限与测验截止时间中较早者
+	var deadline *time.Time
+	if section.QuizTimeLimit > 0 {
+		t := now.Add(time.Duration(section.QuizTimeLimit) * time.Minute)
+		deadline = &t
+	}
+	if section.DueTime != nil && (deadline == nil || section.DueTime.Before(*deadline)) {
+		t := *section.DueTime
+		deadline = &t
+	}
+
+	idsJSON, _ := json.Marshal(questionIds)
+	attempt := &classModel.ClassQuizAttempt{
+		AttemptId:   fmt.Sprintf("qa_%d", now.UnixNano()),
+		SectionId:   sectionId,
+		StudentId:   studentId,
+		ClassId:     section.ClassId,
+		AttemptNo:   int32(len(attempts)) + 1,
+		Status:      classModel.QuizAttemptInProgress,
+		QuestionIds: string(idsJSON),
+		Answers:     "{}",
+		TotalScore:  totalScore,
+		StartTime:   now,
+		Deadline:    deadline,
+	}
+	if err := s.quizDAO.CreateAttempt(attempt); err != nil {
+		// 并发开始作答时 attempt_no 唯一键冲突：返回另一请求已创建的作答
+		if dao.IsDuplicateKeyError(err) {
+			return s.resumeStartedAttempt(section, studentId)
+		}
+		return nil, errs.NewCommonError(errs.ErrInternal, "开始作答失败: "+err.Error())
+	}
+	return s.buildPaper(section, attempt)
+}
+
+// resumeStartedAttempt 返回学生当前作答中的试卷（并发开始作答冲突后调用）
+func (s *QuizService) resumeStartedAttempt(section *classModel.ClassSection, studentId string) (*QuizPaper, error) {
+	attempts, err := s.quizDAO.ListAttemptsByStudent(section.SectionId, studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询作答记录失败: "+err.Error())
+	}
+	for _, a := range attempts {
+		if a.Status == classModel.QuizAttemptInProgress {
+			return s.buildPaper(section, a)
+		}
+	}
+	return nil, errs.NewCommonError(errs.ErrBadRequest, "作答已开始，请刷新后重试")
+}
+
+// SaveAnswers 保存作答中的答案（超时交卷时按最近保存的答案判分）
+func (s *QuizService) SaveAnswers(studentId, attemptId string, answers map[string][]string) error {
+	attempt, err := s.getOwnAttempt(studentId, attemptId)
+	if err != nil {
+		return err
+	}
+	if attempt.Status != classModel.QuizAttemptInProgress {
+		return errs.NewCommonError(errs.ErrBadRequest, "该作答已交卷")
+	}
+	if attempt.Deadline != nil && time.Now().After(attempt.Deadline.Add(quizSubmitGrace)) {
+		return errs.NewCommonError(errs.ErrBadRequest, "已超过作答时限")
+	}
+	answersJSON, _ := json.Marshal(filterQuizAnswers(attempt, answers))
+	if err := s.quizDAO.UpdateAttempt(attemptId, map[string]interface{}{"answers": string(answersJSON)}); err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "保存答案失败: "+err.Error())
+	}
+	return nil
+}
+
+// SubmitQuiz 交卷并自动判分（超过作答时限时按最近保存的答案判分）
+func (s *QuizService) SubmitQuiz(studentId, attemptId string, answers map[string][]string) (*QuizAttemptResult, error) {
+	attempt, err := s.getOwnAttempt(studentId, attemptId)
+	if err != nil {
+		return nil, err
+	}
+	if attempt.Status != classModel.QuizAttemptInProgress {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该作答已交卷")
+	}
+	if err := s.staffService.CheckClassWritable(attempt.ClassId); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if attempt.Deadline != nil && now.After(attempt.Deadline.Add(quizSubmitGrace)) {
+		result, err := s.finishAttempt(attempt, nil, *attempt.Deadline)
+		if err != nil {
+			return nil, err
+		}
+		result.TimeExpired = true
+		return result, nil
+	}
+	return s.finishAttempt(attempt, answers, now)
+}
+
+// ListMyAttempts 查询学生在测验小节的作答记录（已交卷的含逐题结果）
+func (s *QuizService) ListMyAttempts(studentId, sectionId string) ([]*QuizAttemptResult, error) {
+	section, err := s.checkStudentSection(studentId, sectionId)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.quizDAO.ListAttemptsByStudent(sectionId, studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询作答记录失败: "+err.Error())
+	}
+	reveal := quizAnswersRevealed(section, attempts, time.Now())
+	results := make([]*QuizAttemptResult, 0, len(attempts))
+	for _, a := range attempts {
+		result, err := s.buildAttemptResult(a, true, reveal)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ==================== 内部方法 ====================
+
+// getQuizSection 查询测验小节
+func (s *QuizService) getQuizSection(sectionId string) (*classModel.ClassSection, error) {
+	section, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil || section == nil {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
+	}
+	if section.SectionType != classModel.SectionTypeQuiz {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该小节不是测验小节")
+	}
+	return section, nil
+}
+
+// checkStudentSection 校验学生可以访问测验小节（班级成员、班级未归档、小节已发布并解锁）
+func (s *QuizService) checkStudentSection(studentId, sectionId string) (*classModel.ClassSection, error) {
+	section, err := s.getQuizSection(sectionId)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.classMemberDAO.GetMember(section.ClassId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	if err := s.staffService.CheckClassWritable(section.ClassId); err != nil {
+		return nil, err
+	}
+	if err := s.accessService.CheckSectionAccess(section, studentId); err != nil {
+		return nil, err
+	}
+	return section, nil
+}
+
+// getOwnAttempt 查询学生本人的作答记录
+func (s *QuizService) getOwnAttempt(studentId, attemptId string) (*classModel.ClassQuizAttempt, error) {
+	attempt, err := s.quizDAO.GetAttemptById(attemptId)
+	if err != nil || attempt == nil || attempt.StudentId != studentId {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "作答记录不存在")
+	}
+	return attempt, nil
+}
+
+// finishAttempt 判分并交卷（answers 为空时使用已保存的答案）
+func (s *QuizService) finishAttempt(attempt *classModel.ClassQuizAttempt, answers map[string][]string, submitTime time.Time) (*QuizAttemptResult, error) {
+	if answers == nil {
+		answers = parseQuizAnswers(attempt.Answers)
+	}
+	answers = filterQuizAnswers(attempt, answers)
+	questions, err := s.loadAttemptQuestions(attempt)
+	if err != nil {
+		return nil, err
+	}
+	var score, totalScore int32
+	for _, q := range questions {
+		got, _ := gradeQuizQuestion(q, answers[q.QuestionId])
+		score += got
+		totalScore += q.Score
+	}
+
+	answersJSON, _ := json.Marshal(answers)
+	updates := map[string]interface{}{
+		"status":      classModel.QuizAttemptSubmitted,
+		"answers":     string(answersJSON),
+		"score":       score,
+		"total_score": totalScore,
+		"submit_time": submitTime,
+	}
+	ok, err := s.quizDAO.SubmitAttempt(attempt.AttemptId, updates)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "交卷失败: "+err.Error())
+	}
+	if !ok {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该作答已交卷")
+	}
+	attempt.Status = classModel.QuizAttemptSubmitted
+	attempt.Answers = string(answersJSON)
+	attempt.Score = score
+	attempt.TotalScore = totalScore
+	attempt.SubmitTime = &submitTime
+	s.progressService.RefreshStudentProgress(attempt.ClassId, attempt.StudentId)
+
+	reveal := false
+	if section, err := s.getQuizSection(attempt.SectionId); err == nil {
+		if attempts, err := s.quizDAO.ListAttemptsByStudent(attempt.SectionId, attempt.StudentId); err == nil {
+			reveal = quizAnswersRevealed(section, attempts, time.Now())
+		}
+	}
+	return s.buildAttemptResult(attempt, false, reveal)
+}
+
+// quizAnswersRevealed 学生是否可查看标准答案与解析：已交卷次数用完作答上限，或测验已截止（含交卷宽限时间）
+// 不限次数且不设截止时间的测验不公布答案，避免学生看完答案后重新作答；仍可交卷的作答存在时也不公布
+func quizAnswersRevealed(section *classModel.ClassSection, attempts []*classModel.ClassQuizAttempt, now time.Time) bool {
+	var submitted int32
+	for _, a := range attempts {
+		switch a.Status {
+		case classModel.QuizAttemptSubmitted:
+			submitted++
+		case classModel.QuizAttemptInProgress:
+			if a.Deadline == nil || now.Before(a.Deadline.Add(quizSubmitGrace)) {
+				return false
+			}
+		}
+	}
+	if section.DueTime != nil && now.After(section.DueTime.Add(quizSubmitGrace)) {
+		return true
+	}
+	return section.MaxAttempts > 0 && submitted >= section.MaxAttempts
+}
+
+// loadAttemptQuestions 按作答时的题目顺序加载题目（已删除的题目跳过）
+func (s *QuizService) loadAttemptQuestions(attempt *classModel.ClassQuizAttempt) ([]*classModel.ClassQuizQuestion, error) {
+	questions, err := s.quizDAO.ListQuestionsBySectionId(attempt.SectionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询题目失败: "+err.Error())
+	}
+	questionMap := make(map[string]*classModel.ClassQuizQuestion, len(questions))
+	for _, q := range questions {
+		questionMap[q.QuestionId] = q
+	}
+	ordered := make([]*classModel.ClassQuizQuestion, 0, len(questions))
+	for _, id := range parseStringList(attempt.QuestionIds) {
+		if q, ok := questionMap[id]; ok {
+			ordered = append(ordered, q)
+		}
+	}
+	return ordered, nil
+}
+
+// buildPaper 生成学生作答中的试卷（不含答案）
+func (s *QuizService) buildPaper(section *classModel.ClassSection, attempt *classModel.ClassQuizAttempt) (*QuizPaper, error) {
+	questions, err := s.loadAttemptQuestions(attempt)
+	if err != nil {
+		return nil, err
+	}
+	paper := &QuizPaper{
+		AttemptId:   attempt.AttemptId,
+		SectionId:   attempt.SectionId,
+		AttemptNo:   attempt.AttemptNo,
+		StartTime:   formatTime(attempt.StartTime),
+		TotalScore:  attempt.TotalScore,
+		Questions:   make([]*QuizQuestionView, 0, len(questions)),
+		Answers:     parseQuizAnswers(attempt.Answers),
+		MaxAttempts: section.MaxAttempts,
+	}
+	if attempt.Deadline != nil {
+		paper.Deadline = formatTime(*attempt.Deadline)
+	}
+	for _, q := range questions {
+		paper.Questions = append(paper.Questions, buildQuizQuestionView(q, false))
+	}
+	return paper, nil
+}
+
+// buildAttemptResult 生成作答结果（已交卷时附带逐题判分；hideInProgress 为 true 时作答中的记录不返回答案，
+// reveal 为 false 时不返回标准答案与解析）
+func (s *QuizService) buildAttemptResult(attempt *classModel.ClassQuizAttempt, hideInProgress, reveal bool) (*QuizAttemptResult, error) {
+	result := &QuizAttemptResult{
+		AttemptId:  attempt.AttemptId,
+		SectionId:  attempt.SectionId,
+		StudentId:  attempt.StudentId,
+		AttemptNo:  attempt.AttemptNo,
+		Status:     attempt.Status,
+		Score:      attempt.Score,
+		TotalScore: attempt.TotalScore,
+		StartTime:  formatTime(attempt.StartTime),
+		Revealed:   reveal,
+	}
+	if attempt.SubmitTime != nil {
+		result.SubmitTime = formatTime(*attempt.SubmitTime)
+	}
+	if attempt.Status != classModel.QuizAttemptSubmitted && hideInProgress {
+		return result, nil
+	}
+
+	questions, err := s.loadAttemptQuestions(attempt)
+	if err != nil {
+		return nil, err
+	}
+	answers := parseQuizAnswers(attempt.Answers)
+	for _, q := range questions {
+		got, correct := gradeQuizQuestion(q, answers[q.QuestionId])
+		item := &QuizAnswerItem{
+			QuestionId: q.QuestionId,
+			Answer:     answers[q.QuestionId],
+			Correct:    correct,
+			Score:      got,
+			FullScore:  q.Score,
+		}
+		if reveal {
+			item.CorrectAnswer = parseStringList(q.Answer)
+			item.Explanation = q.Explanation
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+// buildQuizQuestionView 生成题目视图
+func buildQuizQuestionView(q *classModel.ClassQuizQuestion, withAnswer bool) *QuizQuestionView {
+	view := &QuizQuestionView{
+		QuestionId:   q.QuestionId,
+		QuestionType: q.QuestionType,
+		Content:      q.Content,
+		Options:      parseStringList(q.Options),
+		Score:        q.Score,
+		SortOrder:    q.SortOrder,
+	}
+	if withAnswer {
+		view.Answer = parseStringList(q.Answer)
+		view.Explanation = q.Explanation
+	}
+	return view
+}
+
+// normalizeQuizQuestion 校验并规范化题目内容，返回 (选项, 标准答案)
+func normalizeQuizQuestion(input *QuizQuestionInput) ([]string, []string, error) {
+	if input == nil || strings.TrimSpace(input.Content) == "" {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "题干不能为空")
+	}
+	if input.Score < 0 {
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "分值不能为负数")
+	}
+	if input.Score == 0 {
+		input.Score = 1
+	}
+
+	switch input.QuestionType {
+	case classModel.QuizQuestionSingle, classModel.QuizQuestionMultiple:
+		options := make([]string, 0, len(input.Options))
+		for _, opt := range input.Options {
+			if opt = strings.TrimSpace(opt); opt != "" {
+				options = append(options, opt)
+			}
+		}
+		if len(options) < 2 || len(options) > quizMaxOptions {
+			return nil, nil, errs.NewCommonError(errs.ErrBadRequest, fmt.Sprintf("选择题选项数量需在 2-%d 之间", quizMaxOptions))
+		}
+		answer := normalizeQuizAnswer(input.QuestionType, input.Answer)
+		for _, a := range answer {
+			if len(a) != 1 || a[0] < 'A' || int(a[0]-'A') >= len(options) {
+				return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "答案需为有效的选项字母")
+			}
+		}
+		if input.QuestionType == classModel.QuizQuestionSingle && len(answer) != 1 {
+			return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "单选题只能有一个正确答案")
+		}
+		if len(answer) == 0 {
+			return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "请设置正确答案")
+		}
+		return options, answer, nil
+	case classModel.QuizQuestionTrueFalse:
+		answer := normalizeQuizAnswer(input.QuestionType, input.Answer)
+		if len(answer) != 1 || (answer[0] != "true" && answer[0] != "false") {
+			return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "判断题答案需为 true 或 false")
+		}
+		return []string{}, answer, nil
+	case classModel.QuizQuestionFillBlank:
+		answer := make([]string, 0, len(input.Answer))
+		for _, a := range input.Answer {
+			a = strings.TrimSpace(a)
+			if a == "" {
+				return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "填空题每个空都需要设置答案")
+			}
+			answer = append(answer, a)
+		}
+		if len(answer) == 0 {
+			return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "请设置正确答案")
+		}
+		return []string{}, answer, nil
+	default:
+		return nil, nil, errs.NewCommonError(errs.ErrBadRequest, "题型不合法（single/multiple/true_false/fill_blank）")
+	}
+}
+
+// normalizeQuizAnswer 规范化选择题、判断题答案（选项字母大写、去重排序；判断题小写）
+func normalizeQuizAnswer(questionType string, answer []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(answer))
+	for _, a := range answer {
+		a = strings.TrimSpace(a)
+		if questionType == classModel.QuizQuestionTrueFalse {
+			a = strings.ToLower(a)
+		} else {
+			a = strings.ToUpper(a)
+		}
+		if a == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		result = append(result, a)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// gradeQuizQuestion 判分，返回 (得分, 是否完全正确)
+// 选择题、判断题答案完全一致得满分；填空题按答对的空数比例得分，每空忽略首尾空格与大小写
+func gradeQuizQuestion(q *classModel.ClassQuizQuestion, answer []string) (int32, bool) {
+	expected := parseStringList(q.Answer)
+	if len(expected) == 0 {
+		return 0, false
+	}
+	if q.QuestionType != classModel.QuizQuestionFillBlank {
+		got := normalizeQuizAnswer(q.QuestionType, answer)
+		if strings.Join(got, ",") == strings.Join(expected, ",") {
+			return q.Score, true
+		}
+		return 0, false
+	}
+
+	correct := 0
+	for i, blank := range expected {
+		if i >= len(answer) {
+			break
+		}
+		given := strings.ToLower(strings.TrimSpace(answer[i]))
+		for _, accepted := range strings.Split(blank, "|") {
+			if given != "" && given == strings.ToLower(strings.TrimSpace(accepted)) {
+				correct++
+				break
+			}
+		}
+	}
+	return q.Score * int32(correct) / int32(len(expected)), correct == len(expected)
+}
+
+// filterQuizAnswers 只保留本次作答题目范围内的答案
+func filterQuizAnswers(attempt *classModel.ClassQuizAttempt, answers map[string][]string) map[string][]string {
+	filtered := make(map[string][]string)
+	for _, id := range parseStringList(attempt.QuestionIds) {
+		if a, ok := answers[id]; ok {
+			filtered[id] = a
+		}
+	}
+	return filtered
+}
+
+// parseQuizAnswers 解析学生答案（JSON对象）
+func parseQuizAnswers(raw string) map[string][]string {
+	answers := make(map[string][]string)
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &answers)
+	}
+	return answers
+}
+
+// parseStringList 解析字符串列表（JSON数组）
+func parseStringList(raw string) []string {
+	list := []string{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &list)
+	}
+	return list
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+)
+
+// SectionView 小节视图（学生视角附带完成与解锁状态）
+type SectionView struct {
+	*classModel.ClassSection
+	Completed  bool   `json:"completed"`             // 学生是否已完成该小节
+	Locked     bool   `json:"locked"`                // 前置小节未全部完成时锁定（锁定时不返回题目、讨论内容与资源链接）
+	LockReason string `json:"lock_reason,omitempty"` // 锁定原因
+}
+
+// SectionAccessService 小节发布与解锁服务（定时发布、前置小节、学生完成情况）
+type SectionAccessService struct {
+	chapterDAO       dao.ChapterDAO
+	sectionResultDAO dao.SectionResultDAO
+	discussionDAO    dao.DiscussionDAO
+	quizDAO          dao.QuizDAO
+	sectionViewDAO   dao.SectionViewDAO
+}
+
+// NewSectionAccessService 创建小节发布与解锁服务
+func NewSectionAccessService() *SectionAccessService {
+	return &SectionAccessService{
+		chapterDAO:       dao.NewChapterDAO(),
+		sectionResultDAO: dao.NewSectionResultDAO(),
+		discussionDAO:    dao.NewDiscussionDAO(),
+		quizDAO:          dao.NewQuizDAO(),
+		sectionViewDAO:   dao.NewSectionViewDAO(),
+	}
+}
+
+// LoadCompletedSections 查询学生在班级内已完成的小节
+// 算法题小节以提交通过（accepted）为完成，讨论小节以发过帖（含回复）为完成，测验小节以交卷为完成，
+// 阅读资料与视频小节以打开过为完成
+func (s *SectionAccessService) LoadCompletedSections(classId, studentId string, sections []*classModel.ClassSection) (map[string]bool, error) {
+	completed := make(map[string]bool)
+	if studentId == "" {
+		return completed, nil
+	}
+	results, err := s.sectionResultDAO.ListResultsByStudent(classId, studentId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询学生成绩失败: "+err.Error())
+	}
+	for _, r := range results {
+		if r.BestStatus == "accepted" || r.OnTimeStatus == "accepted" {
+			completed[r.SectionId] = true
+		}
+	}
+
+	discussionIds := make([]string, 0)
+	quizIds := make([]string, 0)
+	resourceIds := make([]string, 0)
+	for _, sec := range sections {
+		switch sec.SectionType {
+		case classModel.SectionTypeDiscussion:
+			discussionIds = append(discussionIds, sec.SectionId)
+		case classModel.SectionTypeQuiz:
+			quizIds = append(quizIds, sec.SectionId)
+		case classModel.SectionTypeDocument, classModel.SectionTypeVideo:
+			resourceIds = append(resourceIds, sec.SectionId)
+		}
+	}
+	posted, err := s.discussionDAO.ListPostedSectionIds(studentId, discussionIds)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询讨论记录失败: "+err.Error())
+	}
+	for id := range posted {
+		completed[id] = true
+	}
+	submitted, err := s.quizDAO.ListSubmittedSectionIds(studentId, quizIds)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询测验记录失败: "+err.Error())
+	}
+	for id := range submitted {
+		completed[id] = true
+	}
+	viewed, err := s.sectionViewDAO.ListViewedSectionIds(studentId, resourceIds)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询资源查看记录失败: "+err.Error())
+	}
+	for id := range viewed {
+		completed[id] = true
+	}
+	return completed, nil
+}
+
+// BuildStudentView 生成学生视角的章节列表：隐藏未发布的章节与小节，前置小节未完成的小节标记为锁定
+func (s *SectionAccessService) BuildStudentView(chapters []*classModel.ClassChapter, sections []*classModel.ClassSection,
+	completed map[string]bool, now time.Time) ([]*classModel.ClassChapter, map[string][]*SectionView) {
+	titles := sectionTitles(sections)
+	visible := make([]*classModel.ClassChapter, 0, len(chapters))
+	released := make(map[string]bool, len(chapters))
+	for _, ch := range chapters {
+		if isReleased(ch.ReleaseAt, now) {
+			visible = append(visible, ch)
+			released[ch.ChapterId] = true
+		}
+	}
+
+	sectionMap := make(map[string][]*SectionView)
+	for _, sec := range sections {
+		if !released[sec.ChapterId] || !isReleased(sec.ReleaseAt, now) {
+			continue
+		}
+		view := &SectionView{ClassSection: sec, Completed: completed[sec.SectionId]}
+		if unmet := unmetPrerequisites(sec, completed, titles); len(unmet) > 0 {
+			// 锁定的小节只保留标题等概要信息
+			locked := *sec
+			locked.ProblemId = ""
+			locked.DiscussionContent = ""
+			locked.BookshelfItemId = ""
+			locked.VideoUrl = ""
+			view.ClassSection = &locked
+			view.Locked = true
+			view.LockReason = "需先完成：" + strings.Join(unmet, "、")
+		}
+		sectionMap[sec.ChapterId] = append(sectionMap[sec.ChapterId], view)
+	}
+	return visible, sectionMap
+}
+
+// CheckSectionAccess 校验学生可以进入小节（小节及所属章节已发布，且前置小节均已完成）
+func (s *SectionAccessService) CheckSectionAccess(section *classModel.ClassSection, studentId string) error {
+	now := time.Now()
+	if !isReleased(section.ReleaseAt, now) {
+		return errs.NewCommonError(errs.ErrBadRequest, "小节尚未发布")
+	}
+	chapter, err := s.chapterDAO.GetChapterById(section.ChapterId)
+	if err != nil || chapter == nil {
+		return errs.NewCommonError(errs.ErrBadRequest, "章节不存在")
+	}
+	if !isReleased(chapter.ReleaseAt, now) {
+		return errs.NewCommonError(errs.ErrBadRequest, "章节尚未发布")
+	}
+	if len(parseStringList(section.PrerequisiteIds)) == 0 {
+		return nil
+	}
+
+	sections, err := s.chapterDAO.ListSectionsByClassId(section.ClassId)
+	if err != nil {
+		return errs.NewCommonError(errs.ErrInternal, "查询小节失败: "+err.Error())
+	}
+	completed, err := s.LoadCompletedSections(section.ClassId, studentId, sections)
+	if err != nil {
+		return err
+	}
+	if unmet := unmetPrerequisites(section, completed, sectionTitles(sections)); len(unmet) > 0 {
+		return errs.NewCommonError(errs.ErrBadRequest, "小节未解锁，需先完成："+strings.Join(unmet, "、"))
+	}
+	return nil
+}
+
+// isReleased 判断发布时间是否已到（为空表示立即发布）
+func isReleased(releaseAt *time.Time, now time.Time) bool {
+	return releaseAt == nil || !now.Before(*releaseAt)
+}
+
+// unmetPrerequisites 返回未完成的前置小节标题（已删除或禁用的前置小节忽略）
+func unmetPrerequisites(section *classModel.ClassSection, completed map[string]bool, titles map[string]string) []string {
+	unmet := make([]string, 0)
+	for _, id := range parseStringList(section.PrerequisiteIds) {
+		title, ok := titles[id]
+		if ok && !completed[id] {
+			unmet = append(unmet, title)
+		}
+	}
+	return unmet
+}
+
+// sectionTitles 小节ID到标题的映射
+func sectionTitles(sections []*classModel.ClassSection) map[string]string {
+	titles := make(map[string]string, len(sections))
+	for _, sec := range sections {
+		titles[sec.SectionId] = sec.Title
+	}
+	return titles
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/dao"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"gorm.io/gorm"
+)
+
+// classSectionUploadDir 班级私有阅读资料的存储目录
+const classSectionUploadDir = "uploads/class-section"
+
+// SectionResourceService 学习资源小节服务（阅读资料、视频的设置、打开与查看记录）
+type SectionResourceService struct {
+	chapterDAO      dao.ChapterDAO
+	sectionViewDAO  dao.SectionViewDAO
+	classMemberDAO  dao.ClassMemberDAO
+	studentDAO      dao.StudentDAO
+	platformDAO     *dao.PlatformContentDAO
+	staffService    *ClassStaffService
+	accessService   *SectionAccessService
+	progressService *ProgressService
+}
+
+// NewSectionResourceService 创建学习资源小节服务
+func NewSectionResourceService() *SectionResourceService {
+	return &SectionResourceService{
+		chapterDAO:      dao.NewChapterDAO(),
+		sectionViewDAO:  dao.NewSectionViewDAO(),
+		classMemberDAO:  dao.NewClassMemberDAO(),
+		studentDAO:      dao.NewStudentDAO(),
+		platformDAO:     dao.NewPlatformContentDAO(),
+		staffService:    NewClassStaffService(),
+		accessService:   NewSectionAccessService(),
+		progressService: NewProgressService(),
+	}
+}
+
+// SectionResource 学生打开的学习资源
+type SectionResource struct {
+	SectionId      string            `json:"section_id"`
+	SectionType    int32             `json:"section_type"`
+	ResourceSource string            `json:"resource_source"` // 阅读资料来源：bookshelf/upload
+	BookshelfItem  *BookshelfItemDTO `json:"bookshelf_item"`  // 引用的平台书架条目（resource_source=bookshelf）
+	AttachmentName string            `json:"attachment_name"` // 上传文件名（resource_source=upload）
+	AttachmentSize int64             `json:"attachment_size"` // 上传文件大小
+	VideoUrl       string            `json:"video_url"`       // 外部视频链接（section_type=5）
+	FirstView      bool              `json:"first_view"`      // 是否为首次打开（首次打开即完成该小节）
+}
+
+// SectionViewRecord 学生查看情况（教师查看）
+type SectionViewRecord struct {
+	StudentId     string `json:"student_id"`
+	StudentName   string `json:"student_name"`
+	StudentNumber string `json:"student_number"`
+	Viewed        bool   `json:"viewed"`
+	ViewCount     int32  `json:"view_count"`
+	FirstViewTime string `json:"first_view_time"`
+	LastViewTime  string `json:"last_view_time"`
+}
+
+// SetSectionResource 设置学习资源（教师操作）
+// 阅读资料小节需指定 bookshelfItemId 或上传文件（二选一），视频小节需指定 videoUrl
+func (s *SectionResourceService) SetSectionResource(teacherId, sectionId, bookshelfItemId, videoUrl string,
+	fileHeader *multipart.FileHeader) (*classModel.ClassSection, error) {
+	section, err := s.getResourceSection(sectionId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.staffService.CheckWritePermission(section.ClassId, teacherId, consts.ClassPermChapterEdit); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	switch section.SectionType {
+	case classModel.SectionTypeDocument:
+		bookshelfItemId = strings.TrimSpace(bookshelfItemId)
+		if (bookshelfItemId == "") == (fileHeader == nil) {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "请选择平台书架资料或上传文件（二选一）")
+		}
+		if bookshelfItemId != "" {
+			item, err := s.platformDAO.GetBookshelfItemByItemId(bookshelfItemId)
+			if err != nil || item == nil || item.Status != platformBookshelfPublished {
+				return nil, errs.NewCommonError(errs.ErrBadRequest, "平台书架资料不存在或未发布")
+			}
+			updates["resource_source"] = classModel.ResourceSourceBookshelf
+			updates["bookshelf_item_id"] = bookshelfItemId
+			updates["attachment_name"] = ""
+			updates["attachment_storage_name"] = ""
+			updates["attachment_mime_type"] = ""
+			updates["attachment_size"] = 0
+		} else {
+			// 克隆的班级可能共用同一份上传文件，替换时不删除原文件
+			name, storageName, mimeType, size, err := storeAttachmentTo(classSectionUploadDir, fileHeader)
+			if err != nil {
+				return nil, err
+			}
+			updates["resource_source"] = classModel.ResourceSourceUpload
+			updates["bookshelf_item_id"] = ""
+			updates["attachment_name"] = name
+			updates["attachment_storage_name"] = storageName
+			updates["attachment_mime_type"] = mimeType
+			updates["attachment_size"] = size
+		}
+	case classModel.SectionTypeVideo:
+		link := normalizeURL(videoUrl)
+		parsed, err := url.ParseRequestURI(link)
+		if link == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "请输入有效的视频链接")
+		}
+		updates["video_url"] = link
+	}
+
+	if err := s.chapterDAO.UpdateSection(sectionId, updates); err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "更新学习资源失败: "+err.Error())
+	}
+	updated, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询小节失败: "+err.Error())
+	}
+	return updated, nil
+}
+
+// ListSectionViews 查询班级学生对学习资源的查看情况（需成绩查看权限）
+func (s *SectionResourceService) ListSectionViews(teacherId, sectionId string) ([]*SectionViewRecord, error) {
+	section, err := s.getResourceSection(sectionId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.staffService.CheckPermission(section.ClassId, teacherId, consts.ClassPermGradeView); err != nil {
+		return nil, err
+	}
+
+	members, err := s.classMemberDAO.ListAllMembersByClassId(section.ClassId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询班级成员失败: "+err.Error())
+	}
+	views, err := s.sectionViewDAO.ListViewsBySectionId(sectionId)
+	if err != nil {
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询查看记录失败: "+err.Error())
+	}
+	viewMap := make(map[string]*classModel.ClassSectionView, len(views))
+	for _, v := range views {
+		viewMap[v.StudentId] = v
+	}
+
+	studentIds := make([]string, 0, len(members))
+	for _, m := range members {
+		studentIds = append(studentIds, m.StudentId)
+	}
+	studentInfo := make(map[string][2]string)
+	if len(studentIds) > 0 {
+		students, err := s.studentDAO.ListStudentsAll("student_id IN ?", []interface{}{studentIds})
+		if err == nil {
+			for _, st := range students {
+				studentInfo[st.StudentId] = [2]string{st.StudentName, st.StudentNumber}
+			}
+		}
+	}
+
+	records := make([]*SectionViewRecord, 0, len(members))
+	for _, m := range members {
+		info := studentInfo[m.StudentId]
+		record := &SectionViewRecord{StudentId: m.StudentId, StudentName: info[0], StudentNumber: info[1]}
+		if v, ok := viewMap[m.StudentId]; ok {
+			record.Viewed = true
+			record.ViewCount = v.ViewCount
+			record.FirstViewTime = formatTime(v.FirstViewTime)
+			record.LastViewTime = formatTime(v.LastViewTime)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// OpenSectionResource 学生打开学习资源（记录查看，首次打开即完成该小节；已归档班级只读，不再记录）
+func (s *SectionResourceService) OpenSectionResource(studentId, sectionId string) (*SectionResource, error) {
+	section, err := s.checkStudentSection(studentId, sectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := &SectionResource{
+		SectionId:      section.SectionId,
+		SectionType:    section.SectionType,
+		ResourceSource: section.ResourceSource,
+		AttachmentName: section.AttachmentName,
+		AttachmentSize: section.AttachmentSize,
+		VideoUrl:       section.VideoUrl,
+	}
+	switch {
+	case section.SectionType == classModel.SectionTypeVideo:
+		if section.VideoUrl == "" {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "教师尚未设置视频链接")
+		}
+	case section.ResourceSource == classModel.ResourceSourceBookshelf:
+		item, err := s.platformDAO.GetBookshelfItemByItemId(section.BookshelfItemId)
+		if err != nil || item == nil || item.Status != platformBookshelfPublished {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "引用的平台书架资料已下架")
+		}
+		resource.BookshelfItem = mapBookshelfItem(item)
+	case section.ResourceSource == classModel.ResourceSourceUpload:
+		// 上传文件通过 /class/section/{section_id}/file 接口查看或下载
+	default:
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "教师尚未设置阅读资料")
+	}
+
+	if s.staffService.CheckClassWritable(section.ClassId) == nil {
+		firstView, err := s.sectionViewDAO.RecordView(section.ClassId, section.SectionId, studentId, time.Now())
+		if err != nil {
+			return nil, errs.NewCommonError(errs.ErrInternal, "记录查看失败: "+err.Error())
+		}
+		resource.FirstView = firstView
+		if firstView {
+			s.progressService.RefreshStudentProgress(section.ClassId, studentId)
+		}
+	}
+	return resource, nil
+}
+
+// GetSectionResourceFile 获取阅读资料小节的上传文件（教学团队或可访问该小节的学生）
+func (s *SectionResourceService) GetSectionResourceFile(viewerType, viewerId, sectionId string) (*classModel.ClassSection, string, error) {
+	var section *classModel.ClassSection
+	var err error
+	switch viewerType {
+	case consts.RoleTeacher:
+		section, err = s.getResourceSection(sectionId)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := s.staffService.GetMyPermissions(section.ClassId, viewerId); err != nil {
+			return nil, "", err
+		}
+	case consts.RoleStudent:
+		section, err = s.checkStudentSection(viewerId, sectionId)
+		if err != nil {
+			return nil, "", err
+		}
+	default:
+		return nil, "", errs.NewCommonError(errs.ErrBadRequest, "无权限查看该资料")
+	}
+	if section.ResourceSource != classModel.ResourceSourceUpload || section.AttachmentStorageName == "" {
+		return nil, "", errs.NewCommonError(errs.ErrBadRequest, "该小节没有上传文件")
+	}
+	filePath := filepath.Join(classSectionUploadDir, filepath.Base(section.AttachmentStorageName))
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", errs.NewCommonError(errs.ErrBadRequest, "资料文件不存在")
+		}
+		return nil, "", errs.NewCommonError(errs.ErrInternal, "读取资料文件失败: "+err.Error())
+	}
+	return section, filePath, nil
+}
+
+// getResourceSection 查询学习资源小节（阅读资料或视频）
+func (s *SectionResourceService) getResourceSection(sectionId string) (*classModel.ClassSection, error) {
+	section, err := s.chapterDAO.GetSectionById(sectionId)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errs.NewCommonError(errs.ErrBadRequest, "小节不存在")
+		}
+		return nil, errs.NewCommonError(errs.ErrInternal, "查询小节失败: "+err.Error())
+	}
+	if section.SectionType != classModel.SectionTypeDocument && section.SectionType != classModel.SectionTypeVideo {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "该小节不是阅读资料或视频小节")
+	}
+	return section, nil
+}
+
+// checkStudentSection 校验学生可以访问学习资源小节（班级成员、小节已发布并解锁）
+func (s *SectionResourceService) checkStudentSection(studentId, sectionId string) (*classModel.ClassSection, error) {
+	section, err := s.getResourceSection(sectionId)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.classMemberDAO.GetMember(section.ClassId, studentId)
+	if err != nil || member == nil || member.Status != consts.ClassMemberStatusActive {
+		return nil, errs.NewCommonError(errs.ErrBadRequest, "您不是该班级成员")
+	}
+	if err := s.accessService.CheckSectionAccess(section, studentId); err != nil {
+		return nil, err
+	}
+	return section, nil
+}
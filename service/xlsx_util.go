@@ -0,0 +1,302 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsx 最小文件结构（单工作表，内联字符串，无样式）
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+)
+
+// buildXLSX 生成单工作表的 xlsx 文件
+// 只有 numericCols 指定的列按数值写入（值非有限数时仍按文本），其余列一律按文本写入，避免学号等标识丢失前导零或精度
+func buildXLSX(sheetName string, header []string, rows [][]string, numericCols ...int) ([]byte, error) {
+	numeric := make(map[int]bool, len(numericCols))
+	for _, col := range numericCols {
+		numeric[col] = true
+	}
+	var sheet bytes.Buffer
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	writeRow := func(rowIndex int, cells []string) {
+		fmt.Fprintf(&sheet, `<row r="%d">`, rowIndex)
+		for colIndex, value := range cells {
+			ref := xlsxColumnName(colIndex) + strconv.Itoa(rowIndex)
+			if rowIndex > 1 && numeric[colIndex] {
+				if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+					fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(f, 'f', -1, 64))
+					continue
+				}
+			}
+			fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(value))
+		}
+		sheet.WriteString(`</row>`)
+	}
+	writeRow(1, header)
+	for i, row := range rows {
+		writeRow(i+2, row)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	buffer := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buffer)
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", []byte(xlsxContentTypes)},
+		{"_rels/.rels", []byte(xlsxRootRels)},
+		{"xl/workbook.xml", []byte(fmt.Sprintf(xlsxWorkbook, xmlEscape(sheetName)))},
+		{"xl/_rels/workbook.xml.rels", []byte(xlsxWorkbookRels)},
+		{"xl/worksheets/sheet1.xml", sheet.Bytes()},
+	}
+	for _, f := range files {
+		writer, err := zipWriter.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(f.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// xlsxColumnName 列序号（从0开始）转换为 A、B、…、AA 形式的列名
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xmlEscape 转义 XML 文本
+func xmlEscape(value string) string {
+	var buffer bytes.Buffer
+	_ = xml.EscapeText(&buffer, []byte(value))
+	return buffer.String()
+}
+
+// xlsx 读取限制（防止异常文件占用过多内存）
+const (
+	xlsxMaxPartSize = 32 << 20 // 单个 XML 部件解压后的最大字节数
+	xlsxMaxRows     = 100000   // 读取的最大行号
+	xlsxMaxColumns  = 16384    // 最大列数（XFD）
+)
+
+// xlsx 读取用的 XML 结构
+type xlsxSheetXML struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline struct {
+				Text string `xml:"t"`
+				Runs []struct {
+					Text string `xml:"t"`
+				} `xml:"r"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxSharedStringsXML struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxWorkbookXML struct {
+	Sheets []struct {
+		RelId string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelsXML struct {
+	Items []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// parseXLSX 读取 xlsx 文件第一个工作表的全部行（按单元格引用对齐列，空单元格为空字符串）
+func parseXLSX(content []byte) ([][]string, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, errors.New("文件不是有效的 xlsx 格式")
+	}
+	files := make(map[string]*zip.File, len(zipReader.File))
+	for _, f := range zipReader.File {
+		files[f.Name] = f
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst xlsxSharedStringsXML
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			text := item.Text
+			for _, run := range item.Runs {
+				text += run.Text
+			}
+			shared = append(shared, text)
+		}
+	}
+
+	sheetFile, ok := files[xlsxFirstSheetPath(files)]
+	if !ok {
+		return nil, errors.New("xlsx 文件中未找到工作表")
+	}
+	var sheet xlsxSheetXML
+	if err := decodeZipXML(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		if row.Index > xlsxMaxRows || len(rows) >= xlsxMaxRows {
+			break
+		}
+		// 补齐中间被省略的空行，保证行号与表格一致
+		for row.Index > 0 && len(rows) < row.Index-1 {
+			rows = append(rows, nil)
+		}
+		var cells []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = xlsxColumnIndex(c.Ref)
+			}
+			if col < 0 || col >= xlsxMaxColumns {
+				return nil, fmt.Errorf("xlsx 单元格引用不合法: %s", c.Ref)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			switch c.Type {
+			case "s":
+				if idx, err := strconv.Atoi(strings.TrimSpace(c.Value)); err == nil && idx >= 0 && idx < len(shared) {
+					cells[col] = shared[idx]
+				}
+			case "inlineStr":
+				text := c.Inline.Text
+				for _, run := range c.Inline.Runs {
+					text += run.Text
+				}
+				cells[col] = text
+			default:
+				cells[col] = xlsxNumberText(c.Value)
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// xlsxFirstSheetPath 通过 workbook 关系定位第一个工作表，失败时回退到 sheet1.xml
+func xlsxFirstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wbFile, ok1 := files["xl/workbook.xml"]
+	relsFile, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 {
+		return fallback
+	}
+	var wb xlsxWorkbookXML
+	var rels xlsxRelsXML
+	if decodeZipXML(wbFile, &wb) != nil || decodeZipXML(relsFile, &rels) != nil || len(wb.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range rels.Items {
+		if rel.Id != wb.Sheets[0].RelId {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+// decodeZipXML 解析 zip 内的 XML 文件
+func decodeZipXML(f *zip.File, v interface{}) error {
+	reader, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, xlsxMaxPartSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > xlsxMaxPartSize {
+		return errors.New("xlsx 文件内容过大")
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return errors.New("xlsx 文件内容解析失败")
+	}
+	return nil
+}
+
+// xlsxColumnIndex 单元格引用（如 AB12，不区分大小写）转换为列序号（从0开始），引用不合法或超出 XFD 列时返回 -1
+func xlsxColumnIndex(ref string) int {
+	index := 0
+	letters := 0
+	for _, ch := range strings.ToUpper(ref) {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		index = index*26 + int(ch-'A'+1)
+		letters++
+		if index > xlsxMaxColumns {
+			return -1
+		}
+	}
+	if letters == 0 {
+		return -1
+	}
+	if _, err := strconv.Atoi(ref[letters:]); err != nil {
+		return -1
+	}
+	return index - 1
+}
+
+// xlsxNumberText 数值单元格转文本（科学计数法还原为整数，避免学号/手机号失真）
+func xlsxNumberText(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.ContainsAny(value, "Ee") {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	return value
+}
@@ -0,0 +1,74 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// AssignmentServiceImpl 作业服务实现（只做出入参处理）
+type AssignmentServiceImpl struct {
+	assignmentService *service.AssignmentService
+}
+
+// NewAssignmentServiceImpl 创建作业服务实现
+func NewAssignmentServiceImpl() *AssignmentServiceImpl {
+	return &AssignmentServiceImpl{
+		assignmentService: service.NewAssignmentService(),
+	}
+}
+
+// SetAssignmentRequest 设置作业请求
+type SetAssignmentRequest struct {
+	TeacherId   string `json:"teacher_id"`   // 教师ID（必填）
+	SectionId   string `json:"section_id"`   // 小节ID（必填）
+	OpenTime    string `json:"open_time"`    // 开放时间（可选，格式 2006-01-02 15:04:05）
+	DueTime     string `json:"due_time"`     // 截止时间（可选，格式 2006-01-02 15:04:05）
+	LatePolicy  int32  `json:"late_policy"`  // 迟交策略：0-允许迟交，1-按天扣分，2-截止后禁止提交
+	LatePenalty int32  `json:"late_penalty"` // 每迟交一天扣除的分数百分比
+	MaxAttempts int32  `json:"max_attempts"` // 最大提交次数（0-不限）
+}
+
+// SetAssignmentResponse 设置作业响应
+type SetAssignmentResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// SetAssignment 设置小节作业参数
+func (s *AssignmentServiceImpl) SetAssignment(ctx context.Context, req *SetAssignmentRequest) (*SetAssignmentResponse, error) {
+	if err := s.assignmentService.SetAssignment(req.TeacherId, req.SectionId, req.OpenTime, req.DueTime,
+		req.LatePolicy, req.LatePenalty, req.MaxAttempts); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &SetAssignmentResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &SetAssignmentResponse{Code: consts.SuccessCode, Message: "作业设置成功"}, nil
+}
+
+// GetStudentAssignmentResponse 查询学生作业情况响应
+type GetStudentAssignmentResponse struct {
+	Code         int32                          `json:"code"`
+	Message      string                         `json:"message"`
+	Section      *classModel.ClassSection       `json:"section"`
+	Result       *classModel.ClassSectionResult `json:"result"`        // 尚未提交时为空
+	AttemptsUsed int64                          `json:"attempts_used"` // 已用提交次数
+}
+
+// GetStudentAssignment 查询学生在某作业小节的情况
+func (s *AssignmentServiceImpl) GetStudentAssignment(ctx context.Context, studentId, sectionId string) (*GetStudentAssignmentResponse, error) {
+	section, result, used, err := s.assignmentService.GetStudentAssignment(studentId, sectionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetStudentAssignmentResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetStudentAssignmentResponse{
+		Code:         consts.SuccessCode,
+		Message:      consts.MessageQuerySuccess,
+		Section:      section,
+		Result:       result,
+		AttemptsUsed: used,
+	}, nil
+}
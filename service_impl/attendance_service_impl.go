@@ -0,0 +1,228 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// AttendanceServiceImpl 课堂签到服务实现（只做出入参处理）
+type AttendanceServiceImpl struct {
+	attendanceService *service.AttendanceService
+}
+
+// NewAttendanceServiceImpl 创建课堂签到服务实现
+func NewAttendanceServiceImpl() *AttendanceServiceImpl {
+	return &AttendanceServiceImpl{
+		attendanceService: service.NewAttendanceService(),
+	}
+}
+
+// AttendanceCommonResponse 通用响应
+type AttendanceCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// ==================== 教师操作 ====================
+
+// OpenAttendanceRequest 发起签到请求
+type OpenAttendanceRequest struct {
+	TeacherId    string `json:"teacher_id"`    // 教师ID（必填）
+	ClassId      string `json:"class_id"`      // 班级ID（必填）
+	Title        string `json:"title"`         // 签到标题（为空时按时间生成）
+	Duration     int32  `json:"duration"`      // 签到时长（分钟，默认 10）
+	LateAfter    int32  `json:"late_after"`    // 开始后多少分钟签到记为迟到（默认 5）
+	CodeInterval int32  `json:"code_interval"` // 签到码轮换间隔（秒，默认 30，范围 10-300）
+}
+
+// AttendanceSessionResponse 签到场次响应
+type AttendanceSessionResponse struct {
+	Code    int32                          `json:"code"`
+	Message string                         `json:"message"`
+	Session *service.AttendanceSessionView `json:"session"`
+}
+
+// OpenSession 发起课堂签到
+func (s *AttendanceServiceImpl) OpenSession(ctx context.Context, req *OpenAttendanceRequest) (*AttendanceSessionResponse, error) {
+	session, err := s.attendanceService.OpenSession(req.TeacherId, req.ClassId, req.Title, req.Duration, req.LateAfter, req.CodeInterval)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AttendanceSessionResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &AttendanceSessionResponse{Code: consts.SuccessCode, Message: "签到已开始", Session: session}, nil
+}
+
+// AttendanceSessionRequest 签到场次操作请求（查询签到码、结束签到、查询考勤）
+type AttendanceSessionRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	SessionId string `json:"session_id"` // 签到场次ID（必填）
+}
+
+// AttendanceCodeResponse 当前签到码响应
+type AttendanceCodeResponse struct {
+	Code       int32                   `json:"code"`
+	Message    string                  `json:"message"`
+	Attendance *service.AttendanceCode `json:"attendance"`
+}
+
+// GetSessionCode 查询当前签到码
+func (s *AttendanceServiceImpl) GetSessionCode(ctx context.Context, req *AttendanceSessionRequest) (*AttendanceCodeResponse, error) {
+	result, err := s.attendanceService.GetSessionCode(req.TeacherId, req.SessionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AttendanceCodeResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &AttendanceCodeResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Attendance: result}, nil
+}
+
+// CloseSession 结束签到
+func (s *AttendanceServiceImpl) CloseSession(ctx context.Context, req *AttendanceSessionRequest) (*AttendanceCommonResponse, error) {
+	if err := s.attendanceService.CloseSession(req.TeacherId, req.SessionId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AttendanceCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &AttendanceCommonResponse{Code: consts.SuccessCode, Message: "签到已结束"}, nil
+}
+
+// ListAttendanceSessionsRequest 查询签到场次列表请求
+type ListAttendanceSessionsRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+	Page      int32  `json:"page"`
+	PageSize  int32  `json:"page_size"`
+}
+
+// ListAttendanceSessionsResponse 查询签到场次列表响应
+type ListAttendanceSessionsResponse struct {
+	Code     int32                            `json:"code"`
+	Message  string                           `json:"message"`
+	Sessions []*service.AttendanceSessionView `json:"sessions"`
+	Total    int64                            `json:"total"`
+}
+
+// ListSessions 分页查询班级签到场次
+func (s *AttendanceServiceImpl) ListSessions(ctx context.Context, req *ListAttendanceSessionsRequest) (*ListAttendanceSessionsResponse, error) {
+	list, total, err := s.attendanceService.ListSessions(req.TeacherId, req.ClassId, req.Page, req.PageSize)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListAttendanceSessionsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListAttendanceSessionsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Sessions: list, Total: total}, nil
+}
+
+// AttendanceRecordsResponse 签到场次考勤响应
+type AttendanceRecordsResponse struct {
+	Code    int32                          `json:"code"`
+	Message string                         `json:"message"`
+	Records []*service.AttendanceRecordRow `json:"records"`
+}
+
+// GetSessionRecords 查询签到场次内全部学生的考勤
+func (s *AttendanceServiceImpl) GetSessionRecords(ctx context.Context, req *AttendanceSessionRequest) (*AttendanceRecordsResponse, error) {
+	records, err := s.attendanceService.GetSessionRecords(req.TeacherId, req.SessionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AttendanceRecordsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &AttendanceRecordsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Records: records}, nil
+}
+
+// MarkAttendanceRequest 教师手动标记考勤请求
+type MarkAttendanceRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	SessionId string `json:"session_id"` // 签到场次ID（必填）
+	StudentId string `json:"student_id"` // 学生ID（必填）
+	Status    int32  `json:"status"`     // 考勤状态：1-出勤，2-迟到，3-缺勤，4-请假
+}
+
+// MarkAttendance 教师手动标记学生考勤
+func (s *AttendanceServiceImpl) MarkAttendance(ctx context.Context, req *MarkAttendanceRequest) (*AttendanceCommonResponse, error) {
+	if err := s.attendanceService.SetRecordStatus(req.TeacherId, req.SessionId, req.StudentId, req.Status); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AttendanceCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &AttendanceCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// GetAttendanceReportRequest 查询考勤报表请求
+type GetAttendanceReportRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+}
+
+// GetAttendanceReportResponse 查询考勤报表响应
+type GetAttendanceReportResponse struct {
+	Code    int32                     `json:"code"`
+	Message string                    `json:"message"`
+	Report  *service.AttendanceReport `json:"report"`
+}
+
+// GetAttendanceReport 查询班级考勤报表
+func (s *AttendanceServiceImpl) GetAttendanceReport(ctx context.Context, req *GetAttendanceReportRequest) (*GetAttendanceReportResponse, error) {
+	report, err := s.attendanceService.GetAttendanceReport(req.TeacherId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetAttendanceReportResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetAttendanceReportResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Report: report}, nil
+}
+
+// ExportAttendanceReport 导出班级考勤报表，返回 (文件内容, 文件名, 错误)
+func (s *AttendanceServiceImpl) ExportAttendanceReport(ctx context.Context, teacherId, classId, format string) ([]byte, string, error) {
+	return s.attendanceService.ExportAttendanceReport(teacherId, classId, format)
+}
+
+// ==================== 学生操作 ====================
+
+// CheckInRequest 学生签到请求
+type CheckInRequest struct {
+	ClassId string `json:"class_id"` // 班级ID（必填）
+	Code    string `json:"code"`     // 签到码（必填）
+}
+
+// CheckInResponse 学生签到响应
+type CheckInResponse struct {
+	Code    int32                             `json:"code"`
+	Message string                            `json:"message"`
+	Record  *classModel.ClassAttendanceRecord `json:"record"`
+}
+
+// CheckIn 学生输入签到码签到
+func (s *AttendanceServiceImpl) CheckIn(ctx context.Context, studentId string, req *CheckInRequest) (*CheckInResponse, error) {
+	record, err := s.attendanceService.CheckIn(studentId, req.ClassId, req.Code)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &CheckInResponse{Code: int32(code), Message: msg}, nil
+	}
+	message := "签到成功"
+	if record.Status == classModel.AttendanceLate {
+		message = "签到成功（迟到）"
+	}
+	return &CheckInResponse{Code: consts.SuccessCode, Message: message, Record: record}, nil
+}
+
+// GetMyAttendanceRequest 学生查询本人考勤请求
+type GetMyAttendanceRequest struct {
+	ClassId string `json:"class_id"` // 班级ID（必填）
+}
+
+// GetMyAttendanceResponse 学生查询本人考勤响应
+type GetMyAttendanceResponse struct {
+	Code       int32                 `json:"code"`
+	Message    string                `json:"message"`
+	Attendance *service.MyAttendance `json:"attendance"`
+}
+
+// GetMyAttendance 学生查询本人在班级内的考勤
+func (s *AttendanceServiceImpl) GetMyAttendance(ctx context.Context, studentId string, req *GetMyAttendanceRequest) (*GetMyAttendanceResponse, error) {
+	result, err := s.attendanceService.GetMyAttendance(studentId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetMyAttendanceResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetMyAttendanceResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Attendance: result}, nil
+}
@@ -2,6 +2,8 @@ package service_impl
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/yzf120/elysia-backend/consts"
 	"github.com/yzf120/elysia-backend/dao"
@@ -136,7 +138,7 @@ type CreateSectionRequest struct {
 	ChapterId   string `json:"chapter_id"`   // 章节ID（必填）
 	Title       string `json:"title"`        // 小节标题（必填）
 	Description string `json:"description"`  // 小节描述（可选）
-	SectionType int32  `json:"section_type"` // 小节类型：1-算法题，2-讨论话题（必填）
+	SectionType int32  `json:"section_type"` // 小节类型：1-算法题，2-讨论话题，3-测验，4-阅读资料，5-视频（必填）
 	// 算法题关联字段（section_type=1 时填写，关联题库中的题目ID）
 	ProblemId string `json:"problem_id"` // 题库中的题目ID
 	// 讨论内容字段（section_type=2 时填写）
@@ -250,6 +252,53 @@ func (s *ChapterServiceImpl) ReorderSections(ctx context.Context, req *ReorderSe
 	return &ReorderSectionsResponse{Code: consts.SuccessCode, Message: "排序更新成功"}, nil
 }
 
+// ==================== 发布与解锁设置 ====================
+
+// SetChapterReleaseRequest 设置章节发布时间请求
+type SetChapterReleaseRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ChapterId string `json:"chapter_id"` // 章节ID（必填）
+	ReleaseAt string `json:"release_at"` // 发布时间（可选，格式 2006-01-02 15:04:05，为空表示立即发布）
+}
+
+// SetChapterReleaseResponse 设置章节发布时间响应
+type SetChapterReleaseResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// SetChapterRelease 设置章节发布时间
+func (s *ChapterServiceImpl) SetChapterRelease(ctx context.Context, req *SetChapterReleaseRequest) (*SetChapterReleaseResponse, error) {
+	if err := s.chapterService.SetChapterRelease(req.TeacherId, req.ChapterId, req.ReleaseAt); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &SetChapterReleaseResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &SetChapterReleaseResponse{Code: consts.SuccessCode, Message: "发布设置成功"}, nil
+}
+
+// SetSectionReleaseRequest 设置小节发布时间与前置小节请求
+type SetSectionReleaseRequest struct {
+	TeacherId       string   `json:"teacher_id"`       // 教师ID（必填）
+	SectionId       string   `json:"section_id"`       // 小节ID（必填）
+	ReleaseAt       string   `json:"release_at"`       // 发布时间（可选，格式 2006-01-02 15:04:05，为空表示立即发布）
+	PrerequisiteIds []string `json:"prerequisite_ids"` // 前置小节ID列表（可选，全部完成后解锁）
+}
+
+// SetSectionReleaseResponse 设置小节发布时间与前置小节响应
+type SetSectionReleaseResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// SetSectionRelease 设置小节发布时间与前置小节
+func (s *ChapterServiceImpl) SetSectionRelease(ctx context.Context, req *SetSectionReleaseRequest) (*SetSectionReleaseResponse, error) {
+	if err := s.chapterService.SetSectionRelease(req.TeacherId, req.SectionId, req.ReleaseAt, req.PrerequisiteIds); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &SetSectionReleaseResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &SetSectionReleaseResponse{Code: consts.SuccessCode, Message: "发布设置成功"}, nil
+}
+
 // ==================== 查询接口（师生共用） ====================
 
 // SectionInfo 小节信息
@@ -259,15 +308,27 @@ type SectionInfo struct {
 	ClassId     string `json:"class_id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
-	SectionType int32  `json:"section_type"` // 1-算法题，2-讨论话题
+	SectionType int32  `json:"section_type"` // 1-算法题，2-讨论话题，3-测验，4-阅读资料，5-视频
 	// 算法题关联
 	ProblemId string `json:"problem_id"`
 	// 讨论内容
 	DiscussionTitle   string `json:"discussion_title"`
 	DiscussionContent string `json:"discussion_content"`
-	SortOrder   int32  `json:"sort_order"`
-	CreateTime  string `json:"create_time"`
-	UpdateTime  string `json:"update_time"`
+	// 学习资源（阅读资料、视频）
+	ResourceSource  string `json:"resource_source"`   // 阅读资料来源：bookshelf-平台书架，upload-班级上传
+	BookshelfItemId string `json:"bookshelf_item_id"` // 引用的平台书架条目ID
+	AttachmentName  string `json:"attachment_name"`   // 上传文件名
+	AttachmentSize  int64  `json:"attachment_size"`   // 上传文件大小（字节）
+	VideoUrl        string `json:"video_url"`         // 外部视频链接
+	// 发布与解锁
+	ReleaseAt       string   `json:"release_at"`       // 定时发布时间（为空表示立即发布）
+	PrerequisiteIds []string `json:"prerequisite_ids"` // 前置小节ID列表
+	Completed       bool     `json:"completed"`        // 是否已完成（学生视角）
+	Locked          bool     `json:"locked"`           // 是否锁定（学生视角，锁定时不返回题目、讨论内容与资源链接）
+	LockReason      string   `json:"lock_reason"`      // 锁定原因
+	SortOrder       int32    `json:"sort_order"`
+	CreateTime      string   `json:"create_time"`
+	UpdateTime      string   `json:"update_time"`
 }
 
 // ChapterInfo 章节信息（含小节列表）
@@ -277,14 +338,17 @@ type ChapterInfo struct {
 	Title       string         `json:"title"`
 	Description string         `json:"description"`
 	SortOrder   int32          `json:"sort_order"`
-	Sections    []*SectionInfo `json:"sections"` // 小节列表（按 sort_order 升序）
+	ReleaseAt   string         `json:"release_at"` // 定时发布时间（为空表示立即发布）
+	Sections    []*SectionInfo `json:"sections"`   // 小节列表（按 sort_order 升序）
 	CreateTime  string         `json:"create_time"`
 	UpdateTime  string         `json:"update_time"`
 }
 
 // GetClassChaptersRequest 查询班级章节列表请求
 type GetClassChaptersRequest struct {
-	ClassId string `json:"class_id"` // 班级ID（必填）
+	ClassId          string `json:"class_id"`           // 班级ID（必填）
+	Preview          bool   `json:"preview"`            // 教师以学生视角预览（学生请求时忽略）
+	PreviewStudentId string `json:"preview_student_id"` // 预览指定学生的解锁情况（可选，需成绩查看权限）
 }
 
 // GetClassChaptersResponse 查询班级章节列表响应
@@ -295,8 +359,8 @@ type GetClassChaptersResponse struct {
 }
 
 // GetClassChapters 查询班级章节列表（师生共用）
-func (s *ChapterServiceImpl) GetClassChapters(ctx context.Context, req *GetClassChaptersRequest) (*GetClassChaptersResponse, error) {
-	chapters, sectionMap, err := s.chapterService.GetChaptersByClassId(req.ClassId)
+func (s *ChapterServiceImpl) GetClassChapters(ctx context.Context, userType, roleId string, req *GetClassChaptersRequest) (*GetClassChaptersResponse, error) {
+	chapters, sectionMap, err := s.chapterService.GetChaptersByClassId(userType, roleId, req.ClassId, req.Preview, req.PreviewStudentId)
 	if err != nil {
 		code, msg := errs.ParseCommonError(err.Error())
 		return &GetClassChaptersResponse{Code: int32(code), Message: msg}, nil
@@ -310,12 +374,17 @@ func (s *ChapterServiceImpl) GetClassChapters(ctx context.Context, req *GetClass
 			Title:       ch.Title,
 			Description: ch.Description,
 			SortOrder:   ch.SortOrder,
+			ReleaseAt:   formatOptionalTime(ch.ReleaseAt),
 			CreateTime:  ch.CreateTime.Format("2006-01-02 15:04:05"),
 			UpdateTime:  ch.UpdateTime.Format("2006-01-02 15:04:05"),
 			Sections:    make([]*SectionInfo, 0),
 		}
 		if sections, ok := sectionMap[ch.ChapterId]; ok {
 			for _, sec := range sections {
+				prerequisiteIds := []string{}
+				if sec.PrerequisiteIds != "" {
+					json.Unmarshal([]byte(sec.PrerequisiteIds), &prerequisiteIds)
+				}
 				info.Sections = append(info.Sections, &SectionInfo{
 					SectionId:         sec.SectionId,
 					ChapterId:         sec.ChapterId,
@@ -326,6 +395,16 @@ func (s *ChapterServiceImpl) GetClassChapters(ctx context.Context, req *GetClass
 					ProblemId:         sec.ProblemId,
 					DiscussionTitle:   sec.DiscussionTitle,
 					DiscussionContent: sec.DiscussionContent,
+					ResourceSource:    sec.ResourceSource,
+					BookshelfItemId:   sec.BookshelfItemId,
+					AttachmentName:    sec.AttachmentName,
+					AttachmentSize:    sec.AttachmentSize,
+					VideoUrl:          sec.VideoUrl,
+					ReleaseAt:         formatOptionalTime(sec.ReleaseAt),
+					PrerequisiteIds:   prerequisiteIds,
+					Completed:         sec.Completed,
+					Locked:            sec.Locked,
+					LockReason:        sec.LockReason,
 					SortOrder:         sec.SortOrder,
 					CreateTime:        sec.CreateTime.Format("2006-01-02 15:04:05"),
 					UpdateTime:        sec.UpdateTime.Format("2006-01-02 15:04:05"),
@@ -340,4 +419,44 @@ func (s *ChapterServiceImpl) GetClassChapters(ctx context.Context, req *GetClass
 		Message:  consts.MessageQuerySuccess,
 		Chapters: chapterInfos,
 	}, nil
-}
\ No newline at end of file
+}
+
+// formatOptionalTime 格式化可选时间（为空返回空字符串）
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// ==================== 课程模板 ====================
+
+// ExportCourseTemplate 导出班级课程模板，返回 (文件内容, 文件名, 错误)
+func (s *ChapterServiceImpl) ExportCourseTemplate(ctx context.Context, teacherId, classId, format string) ([]byte, string, error) {
+	return s.chapterService.ExportCourseTemplate(teacherId, classId, format)
+}
+
+// ImportCourseTemplateRequest 导入课程模板请求（multipart 表单）
+type ImportCourseTemplateRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 导入到的班级ID（必填）
+	FileName  string `json:"-"`          // 上传文件名（.zip 或 .json）
+	Content   []byte `json:"-"`          // 上传文件内容
+}
+
+// ImportCourseTemplateResponse 导入课程模板响应
+type ImportCourseTemplateResponse struct {
+	Code    int32                               `json:"code"`
+	Message string                              `json:"message"`
+	Result  *service.CourseTemplateImportResult `json:"result,omitempty"`
+}
+
+// ImportCourseTemplate 将课程模板导入班级
+func (s *ChapterServiceImpl) ImportCourseTemplate(ctx context.Context, req *ImportCourseTemplateRequest) (*ImportCourseTemplateResponse, error) {
+	result, err := s.chapterService.ImportCourseTemplate(req.TeacherId, req.ClassId, req.FileName, req.Content)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ImportCourseTemplateResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ImportCourseTemplateResponse{Code: consts.SuccessCode, Message: "课程模板导入完成", Result: result}, nil
+}
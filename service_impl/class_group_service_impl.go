@@ -0,0 +1,267 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// ClassGroupServiceImpl 班级小组服务实现（只做出入参处理）
+type ClassGroupServiceImpl struct {
+	groupService *service.ClassGroupService
+}
+
+// NewClassGroupServiceImpl 创建班级小组服务实现
+func NewClassGroupServiceImpl() *ClassGroupServiceImpl {
+	return &ClassGroupServiceImpl{
+		groupService: service.NewClassGroupService(),
+	}
+}
+
+// ClassGroupCommonResponse 通用响应
+type ClassGroupCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// GroupOverviewResponse 班级分组概览响应
+type GroupOverviewResponse struct {
+	Code     int32                  `json:"code"`
+	Message  string                 `json:"message"`
+	Overview *service.GroupOverview `json:"overview"`
+}
+
+// GroupResponse 单个小组响应
+type GroupResponse struct {
+	Code    int32              `json:"code"`
+	Message string             `json:"message"`
+	Group   *service.GroupView `json:"group"`
+}
+
+// ==================== 教师操作 ====================
+
+// GetGroupOverviewRequest 教师查询班级分组请求
+type GetGroupOverviewRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+}
+
+// GetGroupOverview 教学团队查询班级分组情况
+func (s *ClassGroupServiceImpl) GetGroupOverview(ctx context.Context, req *GetGroupOverviewRequest) (*GroupOverviewResponse, error) {
+	overview, err := s.groupService.GetGroupOverview(req.TeacherId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GroupOverviewResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GroupOverviewResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Overview: overview}, nil
+}
+
+// SetGroupSettingsRequest 设置分组方式请求
+type SetGroupSettingsRequest struct {
+	TeacherId  string `json:"teacher_id"`  // 教师ID（必填）
+	ClassId    string `json:"class_id"`    // 班级ID（必填）
+	SelfEnroll bool   `json:"self_enroll"` // 是否允许学生自建/自选小组
+	MaxSize    int32  `json:"max_size"`    // 学生自建小组的人数上限（0 表示默认 5 人）
+}
+
+// SetGroupSettings 设置是否允许学生自选小组及自建小组人数上限
+func (s *ClassGroupServiceImpl) SetGroupSettings(ctx context.Context, req *SetGroupSettingsRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.SetGroupSettings(req.TeacherId, req.ClassId, req.SelfEnroll, req.MaxSize); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// TeacherCreateGroupRequest 教师创建小组请求
+type TeacherCreateGroupRequest struct {
+	TeacherId   string   `json:"teacher_id"`  // 教师ID（必填）
+	ClassId     string   `json:"class_id"`    // 班级ID（必填）
+	Name        string   `json:"name"`        // 小组名称（必填）
+	Description string   `json:"description"` // 小组简介
+	MaxSize     int32    `json:"max_size"`    // 人数上限（0 表示使用班级设置）
+	StudentIds  []string `json:"student_ids"` // 初始成员（第一个为组长）
+}
+
+// TeacherCreateGroup 教师创建小组
+func (s *ClassGroupServiceImpl) TeacherCreateGroup(ctx context.Context, req *TeacherCreateGroupRequest) (*GroupResponse, error) {
+	group, err := s.groupService.TeacherCreateGroup(req.TeacherId, req.ClassId, req.Name, req.Description, req.MaxSize, req.StudentIds)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GroupResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GroupResponse{Code: consts.SuccessCode, Message: "创建成功", Group: group}, nil
+}
+
+// TeacherUpdateGroupRequest 教师修改小组请求
+type TeacherUpdateGroupRequest struct {
+	TeacherId   string `json:"teacher_id"`  // 教师ID（必填）
+	GroupId     string `json:"group_id"`    // 小组ID（必填）
+	Name        string `json:"name"`        // 小组名称（为空不修改）
+	Description string `json:"description"` // 小组简介（为空不修改）
+	MaxSize     int32  `json:"max_size"`    // 人数上限（0 不修改）
+}
+
+// TeacherUpdateGroup 教师修改小组信息
+func (s *ClassGroupServiceImpl) TeacherUpdateGroup(ctx context.Context, req *TeacherUpdateGroupRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.TeacherUpdateGroup(req.TeacherId, req.GroupId, req.Name, req.Description, req.MaxSize); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// TeacherDeleteGroupRequest 教师解散小组请求
+type TeacherDeleteGroupRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	GroupId   string `json:"group_id"`   // 小组ID（必填）
+}
+
+// TeacherDeleteGroup 教师解散小组
+func (s *ClassGroupServiceImpl) TeacherDeleteGroup(ctx context.Context, req *TeacherDeleteGroupRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.TeacherDeleteGroup(req.TeacherId, req.GroupId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
+
+// TeacherAddGroupMembersRequest 教师分配小组成员请求
+type TeacherAddGroupMembersRequest struct {
+	TeacherId  string   `json:"teacher_id"`  // 教师ID（必填）
+	GroupId    string   `json:"group_id"`    // 小组ID（必填）
+	StudentIds []string `json:"student_ids"` // 学生ID列表（必填，需尚未分组）
+}
+
+// TeacherAddMembers 教师将学生分入小组
+func (s *ClassGroupServiceImpl) TeacherAddMembers(ctx context.Context, req *TeacherAddGroupMembersRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.TeacherAddMembers(req.TeacherId, req.GroupId, req.StudentIds); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
+
+// TeacherGroupMemberRequest 教师操作单个小组成员请求（移出小组、指定组长）
+type TeacherGroupMemberRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	GroupId   string `json:"group_id"`   // 小组ID（必填）
+	StudentId string `json:"student_id"` // 学生ID（必填）
+}
+
+// TeacherRemoveMember 教师将学生移出小组
+func (s *ClassGroupServiceImpl) TeacherRemoveMember(ctx context.Context, req *TeacherGroupMemberRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.TeacherRemoveMember(req.TeacherId, req.GroupId, req.StudentId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
+
+// TeacherSetLeader 教师指定组长
+func (s *ClassGroupServiceImpl) TeacherSetLeader(ctx context.Context, req *TeacherGroupMemberRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.TeacherSetLeader(req.TeacherId, req.GroupId, req.StudentId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// SetSectionGroupModeRequest 设置小节小组模式请求
+type SetSectionGroupModeRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	SectionId string `json:"section_id"` // 小节ID（必填，算法题或讨论小节）
+	Enabled   bool   `json:"enabled"`    // true-小组模式，false-个人模式
+}
+
+// SetSectionGroupMode 设置小节的小组模式
+func (s *ClassGroupServiceImpl) SetSectionGroupMode(ctx context.Context, req *SetSectionGroupModeRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.SetSectionGroupMode(req.TeacherId, req.SectionId, req.Enabled); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// ==================== 学生操作 ====================
+
+// StudentClassGroupRequest 学生班级分组请求（查询分组、退出小组）
+type StudentClassGroupRequest struct {
+	ClassId string `json:"class_id"` // 班级ID（必填）
+}
+
+// StudentGetGroupOverview 学生查询班级分组情况
+func (s *ClassGroupServiceImpl) StudentGetGroupOverview(ctx context.Context, studentId string, req *StudentClassGroupRequest) (*GroupOverviewResponse, error) {
+	overview, err := s.groupService.StudentGetGroupOverview(studentId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GroupOverviewResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GroupOverviewResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Overview: overview}, nil
+}
+
+// StudentLeaveGroup 学生退出所在小组
+func (s *ClassGroupServiceImpl) StudentLeaveGroup(ctx context.Context, studentId string, req *StudentClassGroupRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.StudentLeaveGroup(studentId, req.ClassId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
+
+// StudentGroupInfoRequest 学生自建小组/组长修改小组信息请求
+type StudentGroupInfoRequest struct {
+	ClassId     string `json:"class_id"`    // 班级ID（必填）
+	Name        string `json:"name"`        // 小组名称（创建时必填，修改时为空不修改）
+	Description string `json:"description"` // 小组简介
+}
+
+// StudentCreateGroup 学生自建小组
+func (s *ClassGroupServiceImpl) StudentCreateGroup(ctx context.Context, studentId string, req *StudentGroupInfoRequest) (*GroupResponse, error) {
+	group, err := s.groupService.StudentCreateGroup(studentId, req.ClassId, req.Name, req.Description)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GroupResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GroupResponse{Code: consts.SuccessCode, Message: "创建成功", Group: group}, nil
+}
+
+// StudentUpdateGroup 组长修改小组信息
+func (s *ClassGroupServiceImpl) StudentUpdateGroup(ctx context.Context, studentId string, req *StudentGroupInfoRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.StudentUpdateGroup(studentId, req.ClassId, req.Name, req.Description); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// StudentJoinGroupRequest 学生加入小组请求
+type StudentJoinGroupRequest struct {
+	GroupId string `json:"group_id"` // 小组ID（必填）
+}
+
+// StudentJoinGroup 学生加入小组
+func (s *ClassGroupServiceImpl) StudentJoinGroup(ctx context.Context, studentId string, req *StudentJoinGroupRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.StudentJoinGroup(studentId, req.GroupId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: "加入成功"}, nil
+}
+
+// StudentTransferLeaderRequest 组长转让请求
+type StudentTransferLeaderRequest struct {
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+	StudentId string `json:"student_id"` // 新组长学生ID（必填，需为本组成员）
+}
+
+// StudentTransferLeader 组长转让组长身份
+func (s *ClassGroupServiceImpl) StudentTransferLeader(ctx context.Context, studentId string, req *StudentTransferLeaderRequest) (*ClassGroupCommonResponse, error) {
+	if err := s.groupService.StudentTransferLeader(studentId, req.ClassId, req.StudentId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassGroupCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassGroupCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
@@ -0,0 +1,116 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// ClassInsightServiceImpl 班级教学洞察服务实现（只做出入参处理）
+type ClassInsightServiceImpl struct {
+	insightService *service.ClassInsightService
+}
+
+// NewClassInsightServiceImpl 创建班级教学洞察服务实现
+func NewClassInsightServiceImpl() *ClassInsightServiceImpl {
+	return &ClassInsightServiceImpl{
+		insightService: service.NewClassInsightService(),
+	}
+}
+
+// ClassInsightCommonResponse 通用响应
+type ClassInsightCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// GetClassInsightsRequest 查询班级教学洞察请求
+type GetClassInsightsRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+	service.InsightOptions
+}
+
+// GetClassInsightsResponse 查询班级教学洞察响应
+type GetClassInsightsResponse struct {
+	Code     int32                  `json:"code"`
+	Message  string                 `json:"message"`
+	Insights *service.ClassInsights `json:"insights"`
+}
+
+// GetClassInsights 查询班级教学洞察（卡题学生、常见错误、高错误率题目）
+func (s *ClassInsightServiceImpl) GetClassInsights(ctx context.Context, req *GetClassInsightsRequest) (*GetClassInsightsResponse, error) {
+	insights, err := s.insightService.GetClassInsights(req.TeacherId, req.ClassId, req.InsightOptions)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetClassInsightsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetClassInsightsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Insights: insights}, nil
+}
+
+// SetDigestSubscriptionRequest 订阅每日摘要请求
+type SetDigestSubscriptionRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+	Enabled   bool   `json:"enabled"`    // true-订阅，false-取消订阅
+}
+
+// SetDigestSubscription 订阅或取消订阅班级每日摘要
+func (s *ClassInsightServiceImpl) SetDigestSubscription(ctx context.Context, req *SetDigestSubscriptionRequest) (*ClassInsightCommonResponse, error) {
+	if err := s.insightService.SetDigestSubscription(req.TeacherId, req.ClassId, req.Enabled); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassInsightCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassInsightCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// ListDigestsRequest 查询每日摘要请求
+type ListDigestsRequest struct {
+	TeacherId  string `json:"teacher_id"` // 教师ID（必填）
+	UnreadOnly bool   `json:"unread_only"`
+	Page       int32  `json:"page"`
+	PageSize   int32  `json:"page_size"`
+}
+
+// ListDigestsResponse 查询每日摘要响应
+type ListDigestsResponse struct {
+	Code    int32                            `json:"code"`
+	Message string                           `json:"message"`
+	Digests []*classModel.ClassInsightDigest `json:"digests"`
+	Total   int64                            `json:"total"`
+	Unread  int64                            `json:"unread"` // 未读总数
+}
+
+// ListDigests 查询教师收到的每日摘要
+func (s *ClassInsightServiceImpl) ListDigests(ctx context.Context, req *ListDigestsRequest) (*ListDigestsResponse, error) {
+	list, total, unread, err := s.insightService.ListDigests(req.TeacherId, req.UnreadOnly, req.Page, req.PageSize)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListDigestsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListDigestsResponse{
+		Code:    consts.SuccessCode,
+		Message: consts.MessageQuerySuccess,
+		Digests: list,
+		Total:   total,
+		Unread:  unread,
+	}, nil
+}
+
+// MarkDigestsReadRequest 标记每日摘要已读请求
+type MarkDigestsReadRequest struct {
+	TeacherId string  `json:"teacher_id"` // 教师ID（必填）
+	Ids       []int64 `json:"ids"`        // 摘要ID列表（为空表示全部标记已读）
+}
+
+// MarkDigestsRead 标记每日摘要已读
+func (s *ClassInsightServiceImpl) MarkDigestsRead(ctx context.Context, req *MarkDigestsReadRequest) (*ClassInsightCommonResponse, error) {
+	if err := s.insightService.MarkDigestsRead(req.TeacherId, req.Ids); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassInsightCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassInsightCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
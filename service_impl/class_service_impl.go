@@ -2,31 +2,44 @@ package service_impl
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"strings"
 	"time"
 
 	"github.com/yzf120/elysia-backend/consts"
 	"github.com/yzf120/elysia-backend/dao"
 	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	subjectModel "github.com/yzf120/elysia-backend/model/subject"
 	"github.com/yzf120/elysia-backend/service"
 )
 
 // ClassServiceImpl 班级服务实现（只做出入参处理）
 type ClassServiceImpl struct {
-	classService   *service.ClassService
-	teacherService *service.TeacherService
-	subjectService service.SubjectService
-	studentDAO     dao.StudentDAO
+	classService        *service.ClassService
+	teacherService      *service.TeacherService
+	subjectService      service.SubjectService
+	studentDAO          dao.StudentDAO
+	announcementService *service.AnnouncementService
+	inviteService       *service.ClassInviteService
+	joinService         *service.ClassJoinService
+	rosterService       *service.ClassRosterService
+	staffService        *service.ClassStaffService
+	lifecycleService    *service.ClassLifecycleService
 }
 
 // NewClassServiceImpl 创建班级服务实现
 func NewClassServiceImpl() *ClassServiceImpl {
 	return &ClassServiceImpl{
-		classService:   service.NewClassService(),
-		teacherService: service.NewTeacherService(),
-		subjectService: service.NewSubjectService(),
-		studentDAO:     dao.NewStudentDAO(),
+		classService:        service.NewClassService(),
+		teacherService:      service.NewTeacherService(),
+		subjectService:      service.NewSubjectService(),
+		studentDAO:          dao.NewStudentDAO(),
+		announcementService: service.NewAnnouncementService(),
+		inviteService:       service.NewClassInviteService(),
+		joinService:         service.NewClassJoinService(),
+		rosterService:       service.NewClassRosterService(),
+		staffService:        service.NewClassStaffService(),
+		lifecycleService:    service.NewClassLifecycleService(),
 	}
 }
 
@@ -75,22 +88,61 @@ func (s *ClassServiceImpl) CreateClass(ctx context.Context, req *CreateClassRequ
 	}, nil
 }
 
+// CloneClassRequest 克隆班级请求
+type CloneClassRequest struct {
+	TeacherId     string `json:"teacher_id"`      // 教师ID（必填）
+	SourceClassId string `json:"source_class_id"` // 原班级ID（必填）
+	ClassName     string `json:"class_name"`      // 新班级名称（可选，默认沿用原班级名称）
+	Semester      string `json:"semester"`        // 新学期（必填）
+}
+
+// CloneClassResponse 克隆班级响应
+type CloneClassResponse struct {
+	Code          int32  `json:"code"`            // 响应码 0-成功 其他-失败
+	Message       string `json:"message"`         // 响应消息
+	ClassId       string `json:"class_id"`        // 新班级ID
+	ClassCode     string `json:"class_code"`      // 新班级验证码
+	DateShiftDays int    `json:"date_shift_days"` // 作业时间平移天数
+}
+
+// CloneClass 克隆班级到新学期（教师操作）
+func (s *ClassServiceImpl) CloneClass(ctx context.Context, req *CloneClassRequest) (*CloneClassResponse, error) {
+	class, shiftDays, err := s.classService.CloneClass(req.TeacherId, req.SourceClassId, req.ClassName, req.Semester)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &CloneClassResponse{
+			Code:    int32(code),
+			Message: msg,
+		}, nil
+	}
+
+	return &CloneClassResponse{
+		Code:          consts.SuccessCode,
+		Message:       "克隆班级成功",
+		ClassId:       class.ClassId,
+		ClassCode:     class.ClassCode,
+		DateShiftDays: shiftDays,
+	}, nil
+}
+
 // JoinClassRequest 学生加入班级请求
 type JoinClassRequest struct {
 	StudentId string `json:"student_id"` // 学生ID（必填）
-	ClassCode string `json:"class_code"` // 班级验证码（必填）
+	ClassCode string `json:"class_code"` // 班级验证码或邀请链接码（必填）
+	Message   string `json:"message"`    // 申请留言（可选，需审批班级使用）
 }
 
 // JoinClassResponse 学生加入班级响应
 type JoinClassResponse struct {
 	Code    int32  `json:"code"`    // 响应码 0-成功 其他-失败
 	Message string `json:"message"` // 响应消息
+	Result  string `json:"result"`  // 加入结果：joined-已加入，pending-等待审批，waitlisted-已进入候补
 }
 
 // JoinClass 学生加入班级
 func (s *ClassServiceImpl) JoinClass(ctx context.Context, req *JoinClassRequest) (*JoinClassResponse, error) {
 	// 调用service层处理业务逻辑
-	err := s.classService.JoinClass(req.StudentId, req.ClassCode)
+	result, err := s.classService.JoinClass(req.StudentId, req.ClassCode, req.Message)
 	if err != nil {
 		code, msg := errs.ParseCommonError(err.Error())
 		return &JoinClassResponse{
@@ -99,9 +151,17 @@ func (s *ClassServiceImpl) JoinClass(ctx context.Context, req *JoinClassRequest)
 		}, nil
 	}
 
+	message := "加入班级成功"
+	switch result {
+	case service.JoinResultPending:
+		message = "已提交加入申请，请等待教师审批"
+	case service.JoinResultWaitlisted:
+		message = "班级人数已满，已进入候补队列"
+	}
 	return &JoinClassResponse{
 		Code:    consts.SuccessCode,
-		Message: "加入班级成功",
+		Message: message,
+		Result:  result,
 	}, nil
 }
 
@@ -289,6 +349,11 @@ func (s *ClassServiceImpl) GetStudentClasses(ctx context.Context, req *GetStuden
 			Description:     class.Description,
 			Announcement:    class.Announcement,
 			QrCodeUrl:       class.QrCodeUrl,
+			JoinPolicy:      class.JoinPolicy,
+			WaitlistEnabled: class.WaitlistEnabled,
+			LeaderboardOff:  class.LeaderboardOff,
+			GroupSelfEnroll: class.GroupSelfEnroll,
+			GroupMaxSize:    class.GroupMaxSize,
 			Status:          class.Status,
 			CreateTime:      class.CreateTime.Format("2006-01-02 15:04:05"),
 			UpdateTime:      class.UpdateTime.Format("2006-01-02 15:04:05"),
@@ -325,23 +390,28 @@ type GetTeacherClassesRequest struct {
 
 // ClassInfo 班级信息
 type ClassInfo struct {
-	ClassId         string `json:"class_id"`         // 班级ID
-	ClassName       string `json:"class_name"`       // 班级名称
-	ClassCode       string `json:"class_code"`       // 班级验证码
-	TeacherId       string `json:"teacher_id"`       // 教师ID
-	TeacherName     string `json:"teacher_name"`     // 教师姓名
-	SubjectId       string `json:"subject_id"`       // 科目ID
-	SubjectName     string `json:"subject_name"`     // 科目名称
-	Subject         string `json:"subject"`          // 科目名称（冗余字段，方便展示）
-	Semester        string `json:"semester"`         // 学期
-	MaxStudents     int32  `json:"max_students"`     // 学生人数上限
-	CurrentStudents int32  `json:"current_students"` // 当前学生人数
-	Description     string `json:"description"`      // 班级描述
-	Announcement    string `json:"announcement"`     // 班级公告
-	QrCodeUrl       string `json:"qr_code_url"`      // 二维码URL
-	Status          int32  `json:"status"`           // 状态
-	CreateTime      string `json:"create_time"`      // 创建时间
-	UpdateTime      string `json:"update_time"`      // 更新时间
+	ClassId         string `json:"class_id"`          // 班级ID
+	ClassName       string `json:"class_name"`        // 班级名称
+	ClassCode       string `json:"class_code"`        // 班级验证码
+	TeacherId       string `json:"teacher_id"`        // 教师ID
+	TeacherName     string `json:"teacher_name"`      // 教师姓名
+	SubjectId       string `json:"subject_id"`        // 科目ID
+	SubjectName     string `json:"subject_name"`      // 科目名称
+	Subject         string `json:"subject"`           // 科目名称（冗余字段，方便展示）
+	Semester        string `json:"semester"`          // 学期
+	MaxStudents     int32  `json:"max_students"`      // 学生人数上限
+	CurrentStudents int32  `json:"current_students"`  // 当前学生人数
+	Description     string `json:"description"`       // 班级描述
+	Announcement    string `json:"announcement"`      // 班级公告
+	QrCodeUrl       string `json:"qr_code_url"`       // 二维码URL
+	JoinPolicy      int32  `json:"join_policy"`       // 加入策略：0-开放，1-需审批，2-关闭
+	WaitlistEnabled bool   `json:"waitlist_enabled"`  // 满员后是否开启候补
+	LeaderboardOff  bool   `json:"leaderboard_off"`   // 是否已关闭班级排行榜
+	GroupSelfEnroll bool   `json:"group_self_enroll"` // 是否允许学生自建/自选小组
+	GroupMaxSize    int32  `json:"group_max_size"`    // 学生自建小组的人数上限
+	Status          int32  `json:"status"`            // 状态
+	CreateTime      string `json:"create_time"`       // 创建时间
+	UpdateTime      string `json:"update_time"`       // 更新时间
 }
 
 // GetTeacherClassesResponse 获取教师创建的班级列表响应
@@ -382,6 +452,11 @@ func (s *ClassServiceImpl) GetTeacherClasses(ctx context.Context, req *GetTeache
 			Description:     class.Description,
 			Announcement:    class.Announcement,
 			QrCodeUrl:       class.QrCodeUrl,
+			JoinPolicy:      class.JoinPolicy,
+			WaitlistEnabled: class.WaitlistEnabled,
+			LeaderboardOff:  class.LeaderboardOff,
+			GroupSelfEnroll: class.GroupSelfEnroll,
+			GroupMaxSize:    class.GroupMaxSize,
 			Status:          class.Status,
 			CreateTime:      class.CreateTime.Format("2006-01-02 15:04:05"),
 			UpdateTime:      class.UpdateTime.Format("2006-01-02 15:04:05"),
@@ -416,7 +491,7 @@ type UpdateClassRequest struct {
 	ClassName    string `json:"class_name"`   // 班级名称（可选）
 	SubjectId    string `json:"subject_id"`   // 科目ID（可选）
 	Description  string `json:"description"`  // 班级描述（可选）
-	Announcement string `json:"announcement"` // 班级公告（可选）
+	Announcement string `json:"announcement"` // 已废弃：传入非空值时返回错误，公告请使用 /teacher/class/announcement/publish 发布
 	MaxStudents  int32  `json:"max_students"` // 学生人数上限（可选）
 	Status       int32  `json:"status"`       // 状态（可选，-1表示不更新）
 }
@@ -429,6 +504,13 @@ type UpdateClassResponse struct {
 
 // UpdateClass 更新班级信息
 func (s *ClassServiceImpl) UpdateClass(ctx context.Context, req *UpdateClassRequest) (*UpdateClassResponse, error) {
+	if strings.TrimSpace(req.Announcement) != "" {
+		return &UpdateClassResponse{
+			Code:    errs.ErrBadRequest,
+			Message: "不再支持通过更新班级修改公告，请使用 /teacher/class/announcement/publish 发布公告",
+		}, nil
+	}
+
 	// 构建更新字段
 	updates := make(map[string]interface{})
 	if req.ClassName != "" {
@@ -440,9 +522,6 @@ func (s *ClassServiceImpl) UpdateClass(ctx context.Context, req *UpdateClassRequ
 	if req.Description != "" {
 		updates["description"] = req.Description
 	}
-	if req.Announcement != "" {
-		updates["announcement"] = req.Announcement
-	}
 	if req.MaxStudents > 0 {
 		updates["max_students"] = req.MaxStudents
 	}
@@ -468,7 +547,7 @@ func (s *ClassServiceImpl) UpdateClass(ctx context.Context, req *UpdateClassRequ
 
 // GetClassByCodeRequest 根据验证码获取班级信息请求
 type GetClassByCodeRequest struct {
-	ClassCode string `json:"class_code"` // 班级验证码（必填）
+	ClassCode string `json:"class_code"` // 班级验证码或邀请链接码（必填）
 }
 
 // GetClassByCodeResponse 根据验证码获取班级信息响应
@@ -503,10 +582,20 @@ func (s *ClassServiceImpl) GetClassByCode(ctx context.Context, req *GetClassByCo
 		Description:     class.Description,
 		Announcement:    class.Announcement,
 		QrCodeUrl:       class.QrCodeUrl,
+		JoinPolicy:      class.JoinPolicy,
+		WaitlistEnabled: class.WaitlistEnabled,
+		LeaderboardOff:  class.LeaderboardOff,
+		GroupSelfEnroll: class.GroupSelfEnroll,
+		GroupMaxSize:    class.GroupMaxSize,
 		Status:          class.Status,
 		CreateTime:      class.CreateTime.Format("2006-01-02 15:04:05"),
 		UpdateTime:      class.UpdateTime.Format("2006-01-02 15:04:05"),
 	}
+	// 通过邀请链接码查询时不返回班级验证码，避免绕过邀请链接的有效期与次数限制
+	if class.ClassCode != req.ClassCode {
+		classInfo.ClassCode = ""
+		classInfo.QrCodeUrl = ""
+	}
 	// 查询教师姓名
 	if teacher, err := s.teacherService.GetTeacherById(class.TeacherId); err == nil && teacher != nil {
 		classInfo.TeacherName = teacher.TeacherName
@@ -570,6 +659,9 @@ func (s *ClassServiceImpl) ListSubjects(ctx context.Context) (*ListSubjectsRespo
 type SemesterItem struct {
 	SemesterId   string `json:"semester_id"`
 	SemesterName string `json:"semester_name"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	IsCurrent    bool   `json:"is_current"` // 是否为当前学期（按日期判断）
 }
 
 // ListSemestersResponse 查询学期列表响应
@@ -586,12 +678,15 @@ func (s *ClassServiceImpl) ListSemesters(ctx context.Context) (*ListSemestersRes
 	if err != nil {
 		return &ListSemestersResponse{Code: errs.ErrInternal, Message: "查询学期列表失败"}, err
 	}
+	currentId := ""
+	if current, err := s.lifecycleService.GetCurrentSemester(time.Now()); err == nil {
+		currentId = current.SemesterId
+	}
 	items := make([]*SemesterItem, 0, len(semesters))
 	for _, sem := range semesters {
-		items = append(items, &SemesterItem{
-			SemesterId:   sem.SemesterId,
-			SemesterName: sem.SemesterName,
-		})
+		item := buildSemesterItem(sem)
+		item.IsCurrent = sem.SemesterId == currentId
+		items = append(items, item)
 	}
 	return &ListSemestersResponse{
 		Code:      consts.SuccessCode,
@@ -600,28 +695,52 @@ func (s *ClassServiceImpl) ListSemesters(ctx context.Context) (*ListSemestersRes
 	}, nil
 }
 
-// ==================== 班级公告 ====================
+// GetCurrentSemesterResponse 查询当前学期响应
+type GetCurrentSemesterResponse struct {
+	Code     int32         `json:"code"`
+	Message  string        `json:"message"`
+	Semester *SemesterItem `json:"semester,omitempty"`
+}
 
-// AnnouncementItem 单条公告
-type AnnouncementItem struct {
-	Id          string `json:"id"`           // 公告唯一ID
-	Title       string `json:"title"`        // 公告标题
-	Content     string `json:"content"`      // 公告内容
-	PublishTime string `json:"publish_time"` // 发布时间
+// GetCurrentSemester 按日期查询当前学期（假期中返回下一个即将开始的学期）
+func (s *ClassServiceImpl) GetCurrentSemester(ctx context.Context) (*GetCurrentSemesterResponse, error) {
+	semester, err := s.lifecycleService.GetCurrentSemester(time.Now())
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetCurrentSemesterResponse{Code: int32(code), Message: msg}, nil
+	}
+	item := buildSemesterItem(semester)
+	item.IsCurrent = true
+	return &GetCurrentSemesterResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Semester: item}, nil
+}
+
+// buildSemesterItem 构造学期简要信息
+func buildSemesterItem(sem *subjectModel.Semester) *SemesterItem {
+	return &SemesterItem{
+		SemesterId:   sem.SemesterId,
+		SemesterName: sem.SemesterName,
+		StartDate:    sem.StartDate.Format("2006-01-02"),
+		EndDate:      sem.EndDate.Format("2006-01-02"),
+	}
 }
 
+// ==================== 班级公告 ====================
+
 // PublishAnnouncementRequest 发布公告请求
 type PublishAnnouncementRequest struct {
-	TeacherId string `json:"teacher_id"` // 教师ID（必填）
-	ClassId   string `json:"class_id"`   // 班级ID（必填）
-	Title     string `json:"title"`      // 公告标题（必填）
-	Content   string `json:"content"`    // 公告内容（必填）
+	TeacherId  string `json:"teacher_id"`  // 教师ID（必填）
+	ClassId    string `json:"class_id"`    // 班级ID（必填）
+	Title      string `json:"title"`       // 公告标题（必填）
+	Content    string `json:"content"`     // 公告内容（必填）
+	IsPinned   bool   `json:"is_pinned"`   // 是否置顶（可选）
+	ExpireTime string `json:"expire_time"` // 过期时间（可选，格式 2006-01-02 15:04:05，过期后学生不可见）
 }
 
 // PublishAnnouncementResponse 发布公告响应
 type PublishAnnouncementResponse struct {
-	Code    int32  `json:"code"`
-	Message string `json:"message"`
+	Code         int32                         `json:"code"`
+	Message      string                        `json:"message"`
+	Announcement *classModel.ClassAnnouncement `json:"announcement,omitempty"`
 }
 
 // DeleteAnnouncementRequest 删除公告请求
@@ -637,11 +756,31 @@ type DeleteAnnouncementResponse struct {
 	Message string `json:"message"`
 }
 
+// AnnouncementCommonResponse 公告通用响应
+type AnnouncementCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// PinAnnouncementRequest 置顶公告请求
+type PinAnnouncementRequest struct {
+	TeacherId      string `json:"teacher_id"`      // 教师ID（必填）
+	AnnouncementId string `json:"announcement_id"` // 公告ID（必填）
+	IsPinned       bool   `json:"is_pinned"`       // true-置顶，false-取消置顶
+}
+
 // GetAnnouncementsRequest 查询公告列表请求
 type GetAnnouncementsRequest struct {
 	ClassId string `json:"class_id"` // 班级ID（必填）
 }
 
+// AnnouncementItem 公告列表项（兼容旧版字段：id 为公告ID字符串，publish_time 为格式化时间）
+type AnnouncementItem struct {
+	*service.AnnouncementView
+	Id          string `json:"id"`
+	PublishTime string `json:"publish_time"`
+}
+
 // GetAnnouncementsResponse 查询公告列表响应
 type GetAnnouncementsResponse struct {
 	Code          int32               `json:"code"`
@@ -649,118 +788,482 @@ type GetAnnouncementsResponse struct {
 	Announcements []*AnnouncementItem `json:"announcements"`
 }
 
+// AnnouncementReadersRequest 查询公告阅读情况请求
+type AnnouncementReadersRequest struct {
+	TeacherId      string `json:"teacher_id"`      // 教师ID（必填）
+	AnnouncementId string `json:"announcement_id"` // 公告ID（必填）
+}
+
+// AnnouncementReadersResponse 查询公告阅读情况响应
+type AnnouncementReadersResponse struct {
+	Code      int32                         `json:"code"`
+	Message   string                        `json:"message"`
+	ReadCount int32                         `json:"read_count"`
+	Total     int32                         `json:"total"`
+	Readers   []*service.AnnouncementReader `json:"readers"`
+}
+
+// MarkAnnouncementReadRequest 标记公告已读请求
+type MarkAnnouncementReadRequest struct {
+	AnnouncementId string `json:"announcement_id"` // 公告ID（必填）
+}
+
+// UnreadAnnouncementsResponse 未读公告数响应
+type UnreadAnnouncementsResponse struct {
+	Code    int32                       `json:"code"`
+	Message string                      `json:"message"`
+	Total   int32                       `json:"total"`
+	Classes []*service.ClassUnreadCount `json:"classes"`
+}
+
 // PublishAnnouncement 发布公告（仅教师）
-// 公告以 JSON 数组形式存储在 class.announcement 字段中
 func (s *ClassServiceImpl) PublishAnnouncement(ctx context.Context, req *PublishAnnouncementRequest) (*PublishAnnouncementResponse, error) {
-	if req.TeacherId == "" || req.ClassId == "" {
-		return &PublishAnnouncementResponse{Code: 400, Message: "teacher_id 和 class_id 不能为空"}, nil
+	a, err := s.announcementService.PublishAnnouncement(req.TeacherId, req.ClassId, req.Title, req.Content, req.IsPinned, req.ExpireTime)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &PublishAnnouncementResponse{Code: int32(code), Message: msg}, nil
 	}
-	if req.Title == "" {
-		return &PublishAnnouncementResponse{Code: 400, Message: "公告标题不能为空"}, nil
+	return &PublishAnnouncementResponse{Code: consts.SuccessCode, Message: "发布公告成功", Announcement: a}, nil
+}
+
+// DeleteAnnouncement 删除公告（仅教师）
+func (s *ClassServiceImpl) DeleteAnnouncement(ctx context.Context, req *DeleteAnnouncementRequest) (*DeleteAnnouncementResponse, error) {
+	if err := s.announcementService.DeleteAnnouncement(req.TeacherId, req.ClassId, req.AnnouncementId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &DeleteAnnouncementResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &DeleteAnnouncementResponse{Code: consts.SuccessCode, Message: "删除公告成功"}, nil
+}
+
+// PinAnnouncement 置顶/取消置顶公告（仅教师）
+func (s *ClassServiceImpl) PinAnnouncement(ctx context.Context, req *PinAnnouncementRequest) (*AnnouncementCommonResponse, error) {
+	if err := s.announcementService.SetAnnouncementPinned(req.TeacherId, req.AnnouncementId, req.IsPinned); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AnnouncementCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &AnnouncementCommonResponse{Code: consts.SuccessCode, Message: "更新公告成功"}, nil
+}
+
+// GetAnnouncements 查询班级公告列表（师生共用：教师视图带已读统计，学生视图带已读标记）
+func (s *ClassServiceImpl) GetAnnouncements(ctx context.Context, userType, roleId string, req *GetAnnouncementsRequest) (*GetAnnouncementsResponse, error) {
+	if req.ClassId == "" {
+		return &GetAnnouncementsResponse{Code: 400, Message: "class_id 不能为空"}, nil
+	}
+
+	var list []*service.AnnouncementView
+	var err error
+	switch userType {
+	case consts.RoleTeacher:
+		list, err = s.announcementService.ListTeacherAnnouncements(roleId, req.ClassId)
+	case consts.RoleStudent:
+		list, err = s.announcementService.ListStudentAnnouncements(roleId, req.ClassId)
+	default:
+		return &GetAnnouncementsResponse{Code: 403, Message: "无权查看该班级公告"}, nil
+	}
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetAnnouncementsResponse{Code: int32(code), Message: msg}, nil
+	}
+	items := make([]*AnnouncementItem, 0, len(list))
+	for _, a := range list {
+		items = append(items, &AnnouncementItem{
+			AnnouncementView: a,
+			Id:               a.AnnouncementId,
+			PublishTime:      a.PublishTime.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return &GetAnnouncementsResponse{
+		Code:          consts.SuccessCode,
+		Message:       consts.MessageQuerySuccess,
+		Announcements: items,
+	}, nil
+}
+
+// GetAnnouncementReaders 查询公告阅读情况（仅教师）
+func (s *ClassServiceImpl) GetAnnouncementReaders(ctx context.Context, req *AnnouncementReadersRequest) (*AnnouncementReadersResponse, error) {
+	readers, err := s.announcementService.ListAnnouncementReaders(req.TeacherId, req.AnnouncementId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AnnouncementReadersResponse{Code: int32(code), Message: msg}, nil
 	}
-	if req.Content == "" {
-		return &PublishAnnouncementResponse{Code: 400, Message: "公告内容不能为空"}, nil
+	var readCount int32
+	for _, r := range readers {
+		if r.IsRead {
+			readCount++
+		}
 	}
+	return &AnnouncementReadersResponse{
+		Code:      consts.SuccessCode,
+		Message:   consts.MessageQuerySuccess,
+		ReadCount: readCount,
+		Total:     int32(len(readers)),
+		Readers:   readers,
+	}, nil
+}
 
-	classDAO := dao.NewClassDAO()
-	// 验证教师权限
-	class, err := classDAO.GetClassById(req.ClassId)
-	if err != nil || class == nil {
-		return &PublishAnnouncementResponse{Code: 404, Message: "班级不存在"}, nil
+// MarkAnnouncementRead 标记公告已读（学生）
+func (s *ClassServiceImpl) MarkAnnouncementRead(ctx context.Context, studentId string, req *MarkAnnouncementReadRequest) (*AnnouncementCommonResponse, error) {
+	if err := s.announcementService.MarkAnnouncementRead(studentId, req.AnnouncementId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AnnouncementCommonResponse{Code: int32(code), Message: msg}, nil
 	}
-	if class.TeacherId != req.TeacherId {
-		return &PublishAnnouncementResponse{Code: 403, Message: "无权操作该班级"}, nil
+	return &AnnouncementCommonResponse{Code: consts.SuccessCode, Message: "已读"}, nil
+}
+
+// GetUnreadAnnouncements 查询各班级未读公告数（学生）
+func (s *ClassServiceImpl) GetUnreadAnnouncements(ctx context.Context, studentId string) (*UnreadAnnouncementsResponse, error) {
+	classes, total, err := s.announcementService.GetUnreadCounts(studentId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &UnreadAnnouncementsResponse{Code: int32(code), Message: msg}, nil
 	}
+	return &UnreadAnnouncementsResponse{
+		Code:    consts.SuccessCode,
+		Message: consts.MessageQuerySuccess,
+		Total:   total,
+		Classes: classes,
+	}, nil
+}
+
+// ==================== 班级邀请 ====================
+
+// RotateClassCodeRequest 轮换班级验证码请求
+type RotateClassCodeRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+}
+
+// RotateClassCodeResponse 轮换班级验证码响应
+type RotateClassCodeResponse struct {
+	Code      int32  `json:"code"`
+	Message   string `json:"message"`
+	ClassCode string `json:"class_code"`  // 新班级验证码
+	JoinUrl   string `json:"join_url"`    // 加班链接
+	QrCodeUrl string `json:"qr_code_url"` // 二维码地址
+}
+
+// CreateInviteRequest 创建邀请链接请求
+type CreateInviteRequest struct {
+	TeacherId  string `json:"teacher_id"`  // 教师ID（必填）
+	ClassId    string `json:"class_id"`    // 班级ID（必填）
+	ExpireTime string `json:"expire_time"` // 过期时间（可选，格式 2006-01-02 15:04:05）
+	MaxUses    int32  `json:"max_uses"`    // 最大使用次数（可选，0-不限）
+	Remark     string `json:"remark"`      // 备注（可选）
+}
+
+// CreateInviteResponse 创建邀请链接响应
+type CreateInviteResponse struct {
+	Code    int32               `json:"code"`
+	Message string              `json:"message"`
+	Invite  *service.InviteView `json:"invite,omitempty"`
+}
+
+// ListInvitesRequest 查询邀请链接列表请求
+type ListInvitesRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+}
 
-	// 解析现有公告列表
-	announcements := parseAnnouncements(class.Announcement)
+// ListInvitesResponse 查询邀请链接列表响应
+type ListInvitesResponse struct {
+	Code    int32                 `json:"code"`
+	Message string                `json:"message"`
+	Invites []*service.InviteView `json:"invites"`
+}
 
-	// 新增公告
-	newItem := &AnnouncementItem{
-		Id:          fmt.Sprintf("%d", time.Now().UnixNano()),
-		Title:       req.Title,
-		Content:     req.Content,
-		PublishTime: time.Now().Format("2006-01-02 15:04:05"),
+// RevokeInviteRequest 撤销邀请链接请求
+type RevokeInviteRequest struct {
+	TeacherId  string `json:"teacher_id"`  // 教师ID（必填）
+	InviteCode string `json:"invite_code"` // 邀请码（必填）
+}
+
+// RevokeInviteResponse 撤销邀请链接响应
+type RevokeInviteResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// RotateClassCode 轮换班级验证码（仅教师）
+func (s *ClassServiceImpl) RotateClassCode(ctx context.Context, req *RotateClassCodeRequest) (*RotateClassCodeResponse, error) {
+	class, err := s.inviteService.RotateClassCode(req.TeacherId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &RotateClassCodeResponse{Code: int32(code), Message: msg}, nil
 	}
-	// 新公告插入到最前面
-	announcements = append([]*AnnouncementItem{newItem}, announcements...)
+	return &RotateClassCodeResponse{
+		Code:      consts.SuccessCode,
+		Message:   "班级验证码已更新",
+		ClassCode: class.ClassCode,
+		JoinUrl:   service.BuildClassJoinURL(class.ClassCode),
+		QrCodeUrl: class.QrCodeUrl,
+	}, nil
+}
 
-	// 序列化回 JSON 存储
-	jsonBytes, err := json.Marshal(announcements)
+// CreateInvite 创建邀请链接（仅教师）
+func (s *ClassServiceImpl) CreateInvite(ctx context.Context, req *CreateInviteRequest) (*CreateInviteResponse, error) {
+	invite, err := s.inviteService.CreateInvite(req.TeacherId, req.ClassId, req.ExpireTime, req.MaxUses, req.Remark)
 	if err != nil {
-		return &PublishAnnouncementResponse{Code: 500, Message: "序列化公告失败"}, nil
+		code, msg := errs.ParseCommonError(err.Error())
+		return &CreateInviteResponse{Code: int32(code), Message: msg}, nil
 	}
+	return &CreateInviteResponse{Code: consts.SuccessCode, Message: "创建邀请链接成功", Invite: invite}, nil
+}
 
-	if err := classDAO.UpdateClass(req.ClassId, map[string]interface{}{
-		"announcement": string(jsonBytes),
-	}); err != nil {
-		return &PublishAnnouncementResponse{Code: 500, Message: "保存公告失败"}, nil
+// ListInvites 查询班级邀请链接列表（仅教师）
+func (s *ClassServiceImpl) ListInvites(ctx context.Context, req *ListInvitesRequest) (*ListInvitesResponse, error) {
+	invites, err := s.inviteService.ListInvites(req.TeacherId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListInvitesResponse{Code: int32(code), Message: msg}, nil
 	}
+	return &ListInvitesResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Invites: invites}, nil
+}
 
-	return &PublishAnnouncementResponse{Code: consts.SuccessCode, Message: "发布公告成功"}, nil
+// RevokeInvite 撤销邀请链接（仅教师）
+func (s *ClassServiceImpl) RevokeInvite(ctx context.Context, req *RevokeInviteRequest) (*RevokeInviteResponse, error) {
+	if err := s.inviteService.RevokeInvite(req.TeacherId, req.InviteCode); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &RevokeInviteResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &RevokeInviteResponse{Code: consts.SuccessCode, Message: "邀请链接已撤销"}, nil
 }
 
-// DeleteAnnouncement 删除公告（仅教师）
-func (s *ClassServiceImpl) DeleteAnnouncement(ctx context.Context, req *DeleteAnnouncementRequest) (*DeleteAnnouncementResponse, error) {
-	if req.TeacherId == "" || req.ClassId == "" || req.AnnouncementId == "" {
-		return &DeleteAnnouncementResponse{Code: 400, Message: "参数不能为空"}, nil
+// GetJoinQRCode 生成加班二维码 PNG
+func (s *ClassServiceImpl) GetJoinQRCode(ctx context.Context, code string) ([]byte, error) {
+	return s.inviteService.GenerateJoinQRCode(code)
+}
+
+// ==================== 加入审批与候补 ====================
+
+// SetJoinPolicyRequest 设置加入策略请求
+type SetJoinPolicyRequest struct {
+	TeacherId       string `json:"teacher_id"`       // 教师ID（必填）
+	ClassId         string `json:"class_id"`         // 班级ID（必填）
+	JoinPolicy      int32  `json:"join_policy"`      // 加入策略：0-开放，1-需审批，2-关闭
+	WaitlistEnabled bool   `json:"waitlist_enabled"` // 满员后是否开启候补
+}
+
+// ListJoinRequestsRequest 查询班级加入申请请求
+type ListJoinRequestsRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+	Status    int32  `json:"status"`     // 申请状态（-1-全部，0-待审批，1-已通过，2-已拒绝，3-候补中，4-已取消）
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+}
+
+// ListJoinRequestsResponse 查询加入申请响应
+type ListJoinRequestsResponse struct {
+	Code     int32                      `json:"code"`
+	Message  string                     `json:"message"`
+	Requests []*service.JoinRequestView `json:"requests"`
+	Total    int64                      `json:"total"`
+}
+
+// ApproveJoinRequestRequest 通过加入申请请求
+type ApproveJoinRequestRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	RequestId string `json:"request_id"` // 申请ID（必填）
+}
+
+// RejectJoinRequestRequest 拒绝加入申请请求
+type RejectJoinRequestRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	RequestId string `json:"request_id"` // 申请ID（必填）
+	Reason    string `json:"reason"`     // 拒绝原因（可选）
+}
+
+// CancelJoinRequestRequest 学生撤回加入申请请求
+type CancelJoinRequestRequest struct {
+	RequestId string `json:"request_id"` // 申请ID（必填）
+}
+
+// JoinRequestCommonResponse 加入申请通用响应
+type JoinRequestCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+	Result  string `json:"result,omitempty"` // 审批结果：joined-已加入，waitlisted-满员转入候补
+}
+
+// SetJoinPolicy 设置班级加入策略（仅教师）
+func (s *ClassServiceImpl) SetJoinPolicy(ctx context.Context, req *SetJoinPolicyRequest) (*JoinRequestCommonResponse, error) {
+	if err := s.joinService.SetJoinPolicy(req.TeacherId, req.ClassId, req.JoinPolicy, req.WaitlistEnabled); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &JoinRequestCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &JoinRequestCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// ListJoinRequests 查询班级加入申请（仅教师）
+func (s *ClassServiceImpl) ListJoinRequests(ctx context.Context, req *ListJoinRequestsRequest) (*ListJoinRequestsResponse, error) {
+	list, total, err := s.joinService.ListClassRequests(req.TeacherId, req.ClassId, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListJoinRequestsResponse{Code: int32(code), Message: msg}, nil
 	}
+	return &ListJoinRequestsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Requests: list, Total: total}, nil
+}
 
-	classDAO := dao.NewClassDAO()
-	class, err := classDAO.GetClassById(req.ClassId)
-	if err != nil || class == nil {
-		return &DeleteAnnouncementResponse{Code: 404, Message: "班级不存在"}, nil
+// ApproveJoinRequest 通过加入申请（仅教师）
+func (s *ClassServiceImpl) ApproveJoinRequest(ctx context.Context, req *ApproveJoinRequestRequest) (*JoinRequestCommonResponse, error) {
+	result, err := s.joinService.ApproveRequest(req.TeacherId, req.RequestId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &JoinRequestCommonResponse{Code: int32(code), Message: msg}, nil
 	}
-	if class.TeacherId != req.TeacherId {
-		return &DeleteAnnouncementResponse{Code: 403, Message: "无权操作该班级"}, nil
+	message := "已通过申请"
+	if result == service.JoinResultWaitlisted {
+		message = "班级人数已满，申请已转入候补队列"
 	}
+	return &JoinRequestCommonResponse{Code: consts.SuccessCode, Message: message, Result: result}, nil
+}
 
-	announcements := parseAnnouncements(class.Announcement)
-	filtered := make([]*AnnouncementItem, 0, len(announcements))
-	for _, a := range announcements {
-		if a.Id != req.AnnouncementId {
-			filtered = append(filtered, a)
-		}
+// RejectJoinRequest 拒绝加入申请（仅教师）
+func (s *ClassServiceImpl) RejectJoinRequest(ctx context.Context, req *RejectJoinRequestRequest) (*JoinRequestCommonResponse, error) {
+	if err := s.joinService.RejectRequest(req.TeacherId, req.RequestId, req.Reason); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &JoinRequestCommonResponse{Code: int32(code), Message: msg}, nil
 	}
+	return &JoinRequestCommonResponse{Code: consts.SuccessCode, Message: "已拒绝申请"}, nil
+}
 
-	jsonBytes, _ := json.Marshal(filtered)
-	if err := classDAO.UpdateClass(req.ClassId, map[string]interface{}{
-		"announcement": string(jsonBytes),
-	}); err != nil {
-		return &DeleteAnnouncementResponse{Code: 500, Message: "删除公告失败"}, nil
+// CancelJoinRequest 学生撤回加入申请
+func (s *ClassServiceImpl) CancelJoinRequest(ctx context.Context, studentId string, req *CancelJoinRequestRequest) (*JoinRequestCommonResponse, error) {
+	if err := s.joinService.CancelRequest(studentId, req.RequestId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &JoinRequestCommonResponse{Code: int32(code), Message: msg}, nil
 	}
+	return &JoinRequestCommonResponse{Code: consts.SuccessCode, Message: "已撤回申请"}, nil
+}
 
-	return &DeleteAnnouncementResponse{Code: consts.SuccessCode, Message: "删除公告成功"}, nil
+// GetStudentJoinRequests 学生查询自己的加入申请
+func (s *ClassServiceImpl) GetStudentJoinRequests(ctx context.Context, studentId string) (*ListJoinRequestsResponse, error) {
+	list, err := s.joinService.ListStudentRequests(studentId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListJoinRequestsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListJoinRequestsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Requests: list, Total: int64(len(list))}, nil
 }
 
-// GetAnnouncements 查询班级公告列表（师生共用）
-func (s *ClassServiceImpl) GetAnnouncements(ctx context.Context, req *GetAnnouncementsRequest) (*GetAnnouncementsResponse, error) {
-	if req.ClassId == "" {
-		return &GetAnnouncementsResponse{Code: 400, Message: "class_id 不能为空"}, nil
+// ==================== 名单导入 ====================
+
+// ImportRosterRequest 名单导入请求（multipart 表单）
+type ImportRosterRequest struct {
+	TeacherId     string `json:"teacher_id"`     // 教师ID（必填）
+	ClassId       string `json:"class_id"`       // 班级ID（必填）
+	CreateMissing bool   `json:"create_missing"` // 是否为未注册学生预建账号
+	FileName      string `json:"-"`              // 上传文件名（用于识别 csv/xlsx）
+	Content       []byte `json:"-"`              // 上传文件内容
+}
+
+// ImportRosterResponse 名单导入响应
+type ImportRosterResponse struct {
+	Code    int32                       `json:"code"`
+	Message string                      `json:"message"`
+	Report  *service.RosterImportReport `json:"report,omitempty"`
+}
+
+// ImportRoster 按名单批量加入学生（仅教师）
+func (s *ClassServiceImpl) ImportRoster(ctx context.Context, req *ImportRosterRequest) (*ImportRosterResponse, error) {
+	report, err := s.rosterService.ImportRoster(req.TeacherId, req.ClassId, req.FileName, req.Content, req.CreateMissing)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ImportRosterResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ImportRosterResponse{Code: consts.SuccessCode, Message: "名单导入完成", Report: report}, nil
+}
+
+// ==================== 教学团队 ====================
+
+// AddClassStaffRequest 添加教学团队成员请求
+type AddClassStaffRequest struct {
+	TeacherId   string   `json:"teacher_id"`  // 班级创建者ID（必填）
+	ClassId     string   `json:"class_id"`    // 班级ID（必填）
+	StaffId     string   `json:"staff_id"`    // 成员ID（教师ID或学生ID，必填）
+	Role        string   `json:"role"`        // 角色：co_teacher-协同教师，ta-助教（必填）
+	Permissions []string `json:"permissions"` // 权限列表（可选，为空时使用角色默认权限）
+}
+
+// UpdateClassStaffRequest 修改教学团队成员请求
+type UpdateClassStaffRequest struct {
+	TeacherId   string   `json:"teacher_id"`  // 班级创建者ID（必填）
+	ClassId     string   `json:"class_id"`    // 班级ID（必填）
+	StaffId     string   `json:"staff_id"`    // 成员ID（必填）
+	Role        string   `json:"role"`        // 角色（可选，为空时保持不变）
+	Permissions []string `json:"permissions"` // 权限列表（可选，为空时使用角色默认权限）
+}
+
+// RemoveClassStaffRequest 移除教学团队成员请求
+type RemoveClassStaffRequest struct {
+	TeacherId string `json:"teacher_id"` // 班级创建者ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+	StaffId   string `json:"staff_id"`   // 成员ID（必填）
+}
+
+// ListClassStaffRequest 查询教学团队请求
+type ListClassStaffRequest struct {
+	TeacherId string `json:"teacher_id"` // 操作人ID（必填，需为教学团队成员）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+}
+
+// ClassStaffPermissionRequest 查询本人班级权限请求
+type ClassStaffPermissionRequest struct {
+	ClassId string `json:"class_id"` // 班级ID（必填）
+}
+
+// ClassStaffResponse 教学团队通用响应
+type ClassStaffResponse struct {
+	Code    int32                `json:"code"`
+	Message string               `json:"message"`
+	Staff   *service.StaffView   `json:"staff,omitempty"`
+	List    []*service.StaffView `json:"list,omitempty"`
+}
+
+// AddClassStaff 添加教学团队成员（仅班级创建者）
+func (s *ClassServiceImpl) AddClassStaff(ctx context.Context, req *AddClassStaffRequest) (*ClassStaffResponse, error) {
+	staff, err := s.staffService.AddStaff(req.TeacherId, req.ClassId, req.StaffId, req.Role, req.Permissions)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassStaffResponse{Code: int32(code), Message: msg}, nil
 	}
+	return &ClassStaffResponse{Code: consts.SuccessCode, Message: "添加成功", Staff: staff}, nil
+}
 
-	classDAO := dao.NewClassDAO()
-	class, err := classDAO.GetClassById(req.ClassId)
-	if err != nil || class == nil {
-		return &GetAnnouncementsResponse{Code: 404, Message: "班级不存在"}, nil
+// UpdateClassStaff 修改教学团队成员角色与权限（仅班级创建者）
+func (s *ClassServiceImpl) UpdateClassStaff(ctx context.Context, req *UpdateClassStaffRequest) (*ClassStaffResponse, error) {
+	if err := s.staffService.UpdateStaff(req.TeacherId, req.ClassId, req.StaffId, req.Role, req.Permissions); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassStaffResponse{Code: int32(code), Message: msg}, nil
 	}
+	return &ClassStaffResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
 
-	announcements := parseAnnouncements(class.Announcement)
-	return &GetAnnouncementsResponse{
-		Code:          consts.SuccessCode,
-		Message:       consts.MessageQuerySuccess,
-		Announcements: announcements,
-	}, nil
+// RemoveClassStaff 移除教学团队成员（仅班级创建者）
+func (s *ClassServiceImpl) RemoveClassStaff(ctx context.Context, req *RemoveClassStaffRequest) (*ClassStaffResponse, error) {
+	if err := s.staffService.RemoveStaff(req.TeacherId, req.ClassId, req.StaffId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassStaffResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ClassStaffResponse{Code: consts.SuccessCode, Message: "移除成功"}, nil
 }
 
-// parseAnnouncements 解析 announcement 字段（JSON 数组）
-func parseAnnouncements(raw string) []*AnnouncementItem {
-	if raw == "" {
-		return []*AnnouncementItem{}
+// ListClassStaff 查询班级教学团队
+func (s *ClassServiceImpl) ListClassStaff(ctx context.Context, req *ListClassStaffRequest) (*ClassStaffResponse, error) {
+	list, err := s.staffService.ListStaff(req.TeacherId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassStaffResponse{Code: int32(code), Message: msg}, nil
 	}
-	var items []*AnnouncementItem
-	if err := json.Unmarshal([]byte(raw), &items); err != nil {
-		return []*AnnouncementItem{}
+	return &ClassStaffResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, List: list}, nil
+}
+
+// GetMyClassPermissions 查询本人在班级中的角色与权限
+func (s *ClassServiceImpl) GetMyClassPermissions(ctx context.Context, userId string, req *ClassStaffPermissionRequest) (*ClassStaffResponse, error) {
+	staff, err := s.staffService.GetMyPermissions(req.ClassId, userId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ClassStaffResponse{Code: int32(code), Message: msg}, nil
 	}
-	return items
+	return &ClassStaffResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Staff: staff}, nil
 }
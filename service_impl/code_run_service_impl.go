@@ -24,7 +24,7 @@ func NewCodeRunServiceImpl() *CodeRunServiceImpl {
 
 // SubmitCodeRun 提交代码运行任务
 func (s *CodeRunServiceImpl) SubmitCodeRun(ctx context.Context, studentId string, request *codeReq.CodeRunRequest) (*codeRsp.CodeRunResponse, error) {
-	record, err := s.codeRunService.SubmitCodeRun(ctx, studentId, request.ProblemId, request.Language, request.Code, request.RunType, request.TestInput)
+	record, err := s.codeRunService.SubmitCodeRun(ctx, studentId, request.ProblemId, request.Language, request.Code, request.RunType, request.TestInput, request.SectionId, request.ContestId)
 	if err != nil {
 		code, msg := errs.ParseCommonError(err.Error())
 		return &codeRsp.CodeRunResponse{
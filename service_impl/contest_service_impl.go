@@ -0,0 +1,220 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	contestModel "github.com/yzf120/elysia-backend/model/contest"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// ContestServiceImpl 比赛服务实现（只做出入参处理）
+type ContestServiceImpl struct {
+	contestService *service.ContestService
+}
+
+// NewContestServiceImpl 创建比赛服务实现
+func NewContestServiceImpl() *ContestServiceImpl {
+	return &ContestServiceImpl{
+		contestService: service.NewContestService(),
+	}
+}
+
+// ==================== 教师接口 ====================
+
+// CreateContestRequest 创建比赛请求
+type CreateContestRequest struct {
+	TeacherId      string  `json:"teacher_id"`      // 教师ID（必填）
+	ClassId        string  `json:"class_id"`        // 班级ID（必填）
+	Title          string  `json:"title"`           // 比赛标题（必填）
+	Description    string  `json:"description"`     // 比赛说明（可选）
+	RuleType       string  `json:"rule_type"`       // 赛制：acm（默认）/ oi
+	StartTime      string  `json:"start_time"`      // 开始时间（必填，格式 2006-01-02 15:04:05）
+	EndTime        string  `json:"end_time"`        // 结束时间（必填，格式 2006-01-02 15:04:05）
+	FreezeMinutes  int32   `json:"freeze_minutes"`  // 封榜时长（结束前 N 分钟，0-不封榜）
+	PenaltyMinutes int32   `json:"penalty_minutes"` // ACM 每次错误提交罚时（分钟）
+	ProblemIds     []int64 `json:"problem_ids"`     // 题目ID列表（按题号顺序）
+	IsExam         bool    `json:"is_exam"`         // 考试模式（绑定IP与会话，考试期间禁用AI答疑与书架）
+}
+
+// CreateContestResponse 创建比赛响应
+type CreateContestResponse struct {
+	Code      int32  `json:"code"`
+	Message   string `json:"message"`
+	ContestId string `json:"contest_id"`
+}
+
+// CreateContest 创建比赛
+func (s *ContestServiceImpl) CreateContest(ctx context.Context, req *CreateContestRequest) (*CreateContestResponse, error) {
+	c, err := s.contestService.CreateContest(req.TeacherId, req.ClassId, &service.ContestInput{
+		Title:          req.Title,
+		Description:    req.Description,
+		RuleType:       req.RuleType,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		FreezeMinutes:  req.FreezeMinutes,
+		PenaltyMinutes: req.PenaltyMinutes,
+		ProblemIds:     req.ProblemIds,
+		IsExam:         req.IsExam,
+	})
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &CreateContestResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &CreateContestResponse{Code: consts.SuccessCode, Message: "创建比赛成功", ContestId: c.ContestId}, nil
+}
+
+// UpdateContestRequest 更新比赛请求（字段含义同创建）
+type UpdateContestRequest struct {
+	TeacherId      string  `json:"teacher_id"`
+	ContestId      string  `json:"contest_id"`
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	RuleType       string  `json:"rule_type"`
+	StartTime      string  `json:"start_time"`
+	EndTime        string  `json:"end_time"`
+	FreezeMinutes  int32   `json:"freeze_minutes"`
+	PenaltyMinutes int32   `json:"penalty_minutes"`
+	ProblemIds     []int64 `json:"problem_ids"`
+	IsExam         bool    `json:"is_exam"`
+}
+
+// ContestCommonResponse 比赛通用响应
+type ContestCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// UpdateContest 更新比赛
+func (s *ContestServiceImpl) UpdateContest(ctx context.Context, req *UpdateContestRequest) (*ContestCommonResponse, error) {
+	err := s.contestService.UpdateContest(req.TeacherId, req.ContestId, &service.ContestInput{
+		Title:          req.Title,
+		Description:    req.Description,
+		RuleType:       req.RuleType,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		FreezeMinutes:  req.FreezeMinutes,
+		PenaltyMinutes: req.PenaltyMinutes,
+		ProblemIds:     req.ProblemIds,
+		IsExam:         req.IsExam,
+	})
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ContestCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ContestCommonResponse{Code: consts.SuccessCode, Message: "更新比赛成功"}, nil
+}
+
+// TeacherContestRequest 教师比赛操作通用请求
+type TeacherContestRequest struct {
+	TeacherId string `json:"teacher_id"`           // 教师ID（必填）
+	ContestId string `json:"contest_id"`           // 比赛ID（必填）
+	StudentId string `json:"student_id,omitempty"` // 学生ID（查询提交记录时可选）
+}
+
+// DeleteContest 删除比赛
+func (s *ContestServiceImpl) DeleteContest(ctx context.Context, req *TeacherContestRequest) (*ContestCommonResponse, error) {
+	if err := s.contestService.DeleteContest(req.TeacherId, req.ContestId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ContestCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ContestCommonResponse{Code: consts.SuccessCode, Message: "删除比赛成功"}, nil
+}
+
+// ScoreboardResponse 榜单响应
+type ScoreboardResponse struct {
+	Code       int32               `json:"code"`
+	Message    string              `json:"message"`
+	Scoreboard *service.Scoreboard `json:"scoreboard"`
+}
+
+// GetTeacherScoreboard 查询实时榜单（教师）
+func (s *ContestServiceImpl) GetTeacherScoreboard(ctx context.Context, req *TeacherContestRequest) (*ScoreboardResponse, error) {
+	board, err := s.contestService.GetTeacherScoreboard(req.TeacherId, req.ContestId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ScoreboardResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ScoreboardResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Scoreboard: board}, nil
+}
+
+// ContestSubmissionsResponse 比赛提交记录响应
+type ContestSubmissionsResponse struct {
+	Code        int32                             `json:"code"`
+	Message     string                            `json:"message"`
+	Submissions []*contestModel.ContestSubmission `json:"submissions"`
+}
+
+// ListContestSubmissions 查询比赛提交记录（教师）
+func (s *ContestServiceImpl) ListContestSubmissions(ctx context.Context, req *TeacherContestRequest) (*ContestSubmissionsResponse, error) {
+	list, err := s.contestService.ListContestSubmissions(req.TeacherId, req.ContestId, req.StudentId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ContestSubmissionsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ContestSubmissionsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Submissions: list}, nil
+}
+
+// ==================== 师生共用接口 ====================
+
+// ListClassContestsRequest 查询班级比赛列表请求
+type ListClassContestsRequest struct {
+	ClassId string `json:"class_id"` // 班级ID（必填）
+}
+
+// ListClassContestsResponse 查询班级比赛列表响应
+type ListClassContestsResponse struct {
+	Code     int32                  `json:"code"`
+	Message  string                 `json:"message"`
+	Contests []*service.ContestView `json:"contests"`
+}
+
+// ListClassContests 查询班级比赛列表（userType、roleId 取自登录态）
+func (s *ContestServiceImpl) ListClassContests(ctx context.Context, userType, roleId string, req *ListClassContestsRequest) (*ListClassContestsResponse, error) {
+	list, err := s.contestService.ListClassContests(userType, roleId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListClassContestsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListClassContestsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Contests: list}, nil
+}
+
+// ==================== 学生接口 ====================
+
+// ContestDetailResponse 比赛详情响应
+type ContestDetailResponse struct {
+	Code    int32                  `json:"code"`
+	Message string                 `json:"message"`
+	Detail  *service.ContestDetail `json:"detail"`
+}
+
+// GetStudentContestDetail 查询比赛详情（学生）
+func (s *ContestServiceImpl) GetStudentContestDetail(ctx context.Context, studentId, contestId string) (*ContestDetailResponse, error) {
+	detail, err := s.contestService.GetStudentContestDetail(ctx, studentId, contestId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ContestDetailResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ContestDetailResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Detail: detail}, nil
+}
+
+// GetStudentScoreboard 查询榜单（学生）
+func (s *ContestServiceImpl) GetStudentScoreboard(ctx context.Context, studentId, contestId string) (*ScoreboardResponse, error) {
+	board, err := s.contestService.GetStudentScoreboard(studentId, contestId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ScoreboardResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ScoreboardResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Scoreboard: board}, nil
+}
+
+// ListStudentSubmissions 查询学生自己的比赛提交
+func (s *ContestServiceImpl) ListStudentSubmissions(ctx context.Context, studentId, contestId string) (*ContestSubmissionsResponse, error) {
+	list, err := s.contestService.ListStudentSubmissions(studentId, contestId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ContestSubmissionsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ContestSubmissionsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Submissions: list}, nil
+}
@@ -0,0 +1,220 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/model/discussion"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// DiscussionServiceImpl 讨论区服务实现（只做出入参处理）
+// 学生接口的用户ID取自登录态，教师接口的 teacher_id 取自请求体
+type DiscussionServiceImpl struct {
+	discussionService *service.DiscussionService
+}
+
+// NewDiscussionServiceImpl 创建讨论区服务实现
+func NewDiscussionServiceImpl() *DiscussionServiceImpl {
+	return &DiscussionServiceImpl{
+		discussionService: service.NewDiscussionService(),
+	}
+}
+
+// ListThreadsRequest 查询主题帖列表请求
+type ListThreadsRequest struct {
+	TeacherId string `json:"teacher_id,omitempty"` // 教师ID（教师接口必填）
+	SectionId string `json:"section_id"`           // 讨论小节ID（必填）
+	Page      int32  `json:"page"`
+	PageSize  int32  `json:"page_size"`
+}
+
+// ListThreadsResponse 查询主题帖列表响应
+type ListThreadsResponse struct {
+	Code    int32               `json:"code"`
+	Message string              `json:"message"`
+	Threads []*service.PostView `json:"threads"`
+	Total   int64               `json:"total"`
+}
+
+// ListThreads 查询讨论小节下的主题帖
+func (s *DiscussionServiceImpl) ListThreads(ctx context.Context, viewerType, viewerId string, req *ListThreadsRequest) (*ListThreadsResponse, error) {
+	list, total, err := s.discussionService.ListThreads(viewerType, viewerId, req.SectionId, req.Page, req.PageSize)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListThreadsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListThreadsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Threads: list, Total: total}, nil
+}
+
+// GetThreadRequest 查询主题帖详情请求
+type GetThreadRequest struct {
+	TeacherId string `json:"teacher_id,omitempty"` // 教师ID（教师接口必填）
+	PostId    string `json:"post_id"`              // 主题帖ID（必填）
+	Page      int32  `json:"page"`
+	PageSize  int32  `json:"page_size"`
+}
+
+// GetThreadResponse 查询主题帖详情响应
+type GetThreadResponse struct {
+	Code    int32                 `json:"code"`
+	Message string                `json:"message"`
+	Detail  *service.ThreadDetail `json:"detail"`
+}
+
+// GetThread 查询主题帖及回复
+func (s *DiscussionServiceImpl) GetThread(ctx context.Context, viewerType, viewerId string, req *GetThreadRequest) (*GetThreadResponse, error) {
+	detail, err := s.discussionService.GetThread(viewerType, viewerId, req.PostId, req.Page, req.PageSize)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetThreadResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetThreadResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Detail: detail}, nil
+}
+
+// CreatePostRequest 发帖/回复请求
+type CreatePostRequest struct {
+	TeacherId string `json:"teacher_id,omitempty"` // 教师ID（教师接口必填）
+	SectionId string `json:"section_id"`           // 讨论小节ID（必填）
+	ParentId  string `json:"parent_id"`            // 回复的帖子ID（为空表示发主题帖）
+	Content   string `json:"content"`              // 正文（Markdown，支持 @学号 / @姓名 提及同学）
+}
+
+// CreatePostResponse 发帖/回复响应
+type CreatePostResponse struct {
+	Code    int32             `json:"code"`
+	Message string            `json:"message"`
+	Post    *service.PostView `json:"post"`
+}
+
+// CreatePost 发帖或回复
+func (s *DiscussionServiceImpl) CreatePost(ctx context.Context, authorType, authorId string, req *CreatePostRequest) (*CreatePostResponse, error) {
+	post, err := s.discussionService.CreatePost(authorType, authorId, req.SectionId, req.ParentId, req.Content)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &CreatePostResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &CreatePostResponse{Code: consts.SuccessCode, Message: "发布成功", Post: post}, nil
+}
+
+// LikePostRequest 点赞请求
+type LikePostRequest struct {
+	TeacherId string `json:"teacher_id,omitempty"` // 教师ID（教师接口必填）
+	PostId    string `json:"post_id"`              // 帖子ID（必填）
+	Like      bool   `json:"like"`                 // true-点赞，false-取消点赞
+}
+
+// LikePostResponse 点赞响应
+type LikePostResponse struct {
+	Code      int32  `json:"code"`
+	Message   string `json:"message"`
+	LikeCount int32  `json:"like_count"`
+}
+
+// LikePost 点赞/取消点赞
+func (s *DiscussionServiceImpl) LikePost(ctx context.Context, viewerType, viewerId string, req *LikePostRequest) (*LikePostResponse, error) {
+	count, err := s.discussionService.SetLike(viewerType, viewerId, req.PostId, req.Like)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &LikePostResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &LikePostResponse{Code: consts.SuccessCode, Message: "操作成功", LikeCount: count}, nil
+}
+
+// DiscussionPostRequest 帖子操作通用请求
+type DiscussionPostRequest struct {
+	TeacherId string `json:"teacher_id,omitempty"` // 教师ID（教师接口必填）
+	PostId    string `json:"post_id"`              // 帖子ID（必填）
+	IsPinned  bool   `json:"is_pinned"`            // 置顶接口使用
+	IsAnswer  bool   `json:"is_answer"`            // 标记答案接口使用
+	Action    string `json:"action"`               // 审核接口使用：hide / unhide / delete
+}
+
+// DiscussionCommonResponse 讨论区通用响应
+type DiscussionCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// DeleteOwnPost 删除自己的帖子（学生）
+func (s *DiscussionServiceImpl) DeleteOwnPost(ctx context.Context, studentId string, req *DiscussionPostRequest) (*DiscussionCommonResponse, error) {
+	if err := s.discussionService.DeleteOwnPost(studentId, req.PostId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &DiscussionCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &DiscussionCommonResponse{Code: consts.SuccessCode, Message: "删除成功"}, nil
+}
+
+// PinThread 置顶/取消置顶主题帖（教师）
+func (s *DiscussionServiceImpl) PinThread(ctx context.Context, req *DiscussionPostRequest) (*DiscussionCommonResponse, error) {
+	if err := s.discussionService.PinThread(req.TeacherId, req.PostId, req.IsPinned); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &DiscussionCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &DiscussionCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
+
+// MarkAnswer 标记/取消标记答案（教师）
+func (s *DiscussionServiceImpl) MarkAnswer(ctx context.Context, req *DiscussionPostRequest) (*DiscussionCommonResponse, error) {
+	if err := s.discussionService.MarkAnswer(req.TeacherId, req.PostId, req.IsAnswer); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &DiscussionCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &DiscussionCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
+
+// ModeratePost 审核帖子（教师）
+func (s *DiscussionServiceImpl) ModeratePost(ctx context.Context, req *DiscussionPostRequest) (*DiscussionCommonResponse, error) {
+	if err := s.discussionService.ModeratePost(req.TeacherId, req.PostId, req.Action); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &DiscussionCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &DiscussionCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
+
+// ListNotificationsRequest 查询讨论通知请求
+type ListNotificationsRequest struct {
+	UnreadOnly bool  `json:"unread_only"`
+	Page       int32 `json:"page"`
+	PageSize   int32 `json:"page_size"`
+}
+
+// ListNotificationsResponse 查询讨论通知响应
+type ListNotificationsResponse struct {
+	Code          int32                                `json:"code"`
+	Message       string                               `json:"message"`
+	Notifications []*discussion.DiscussionNotification `json:"notifications"`
+	Total         int64                                `json:"total"`
+	Unread        int64                                `json:"unread"` // 未读总数
+}
+
+// ListNotifications 查询讨论通知（学生）
+func (s *DiscussionServiceImpl) ListNotifications(ctx context.Context, studentId string, req *ListNotificationsRequest) (*ListNotificationsResponse, error) {
+	list, total, unread, err := s.discussionService.ListNotifications(studentId, req.UnreadOnly, req.Page, req.PageSize)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListNotificationsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListNotificationsResponse{
+		Code:          consts.SuccessCode,
+		Message:       consts.MessageQuerySuccess,
+		Notifications: list,
+		Total:         total,
+		Unread:        unread,
+	}, nil
+}
+
+// MarkNotificationsReadRequest 标记通知已读请求
+type MarkNotificationsReadRequest struct {
+	Ids []int64 `json:"ids"` // 通知ID列表（为空表示全部标记已读）
+}
+
+// MarkNotificationsRead 标记通知已读（学生）
+func (s *DiscussionServiceImpl) MarkNotificationsRead(ctx context.Context, studentId string, req *MarkNotificationsReadRequest) (*DiscussionCommonResponse, error) {
+	if err := s.discussionService.MarkNotificationsRead(studentId, req.Ids); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &DiscussionCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &DiscussionCommonResponse{Code: consts.SuccessCode, Message: "操作成功"}, nil
+}
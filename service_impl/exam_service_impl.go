@@ -0,0 +1,149 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/model/contest"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// ExamServiceImpl 考试防作弊服务实现（只做出入参处理）
+type ExamServiceImpl struct {
+	examService *service.ExamService
+}
+
+// NewExamServiceImpl 创建考试服务实现
+func NewExamServiceImpl() *ExamServiceImpl {
+	return &ExamServiceImpl{
+		examService: service.NewExamService(),
+	}
+}
+
+// EnterExamRequest 进入考试请求
+type EnterExamRequest struct {
+	ContestId string `json:"contest_id"` // 比赛ID（必填）
+}
+
+// EnterExamResponse 进入考试响应
+type EnterExamResponse struct {
+	Code    int32                `json:"code"`
+	Message string               `json:"message"`
+	Session *contest.ExamSession `json:"session"`
+}
+
+// EnterExam 进入考试（绑定当前 IP 与登录令牌）
+func (s *ExamServiceImpl) EnterExam(ctx context.Context, studentId string, req *EnterExamRequest) (*EnterExamResponse, error) {
+	session, err := s.examService.EnterExam(ctx, studentId, req.ContestId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &EnterExamResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &EnterExamResponse{Code: consts.SuccessCode, Message: "进入考试成功", Session: session}, nil
+}
+
+// ExamEventItem 上报的单条考试事件
+type ExamEventItem struct {
+	EventType  string `json:"event_type"`  // 事件类型：blur/focus/paste/copy/fullscreen_exit
+	Detail     string `json:"detail"`      // 事件详情（可选）
+	ClientTime string `json:"client_time"` // 客户端时间（可选，格式 2006-01-02 15:04:05）
+}
+
+// ReportExamEventsRequest 上报考试事件请求
+type ReportExamEventsRequest struct {
+	ContestId string           `json:"contest_id"` // 比赛ID（必填）
+	Events    []*ExamEventItem `json:"events"`     // 事件列表（单次最多50条）
+}
+
+// ReportExamEventsResponse 上报考试事件响应
+type ReportExamEventsResponse struct {
+	Code     int32  `json:"code"`
+	Message  string `json:"message"`
+	Recorded int    `json:"recorded"` // 记录的事件数
+}
+
+// ReportExamEvents 上报考试事件
+func (s *ExamServiceImpl) ReportExamEvents(ctx context.Context, studentId string, req *ReportExamEventsRequest) (*ReportExamEventsResponse, error) {
+	inputs := make([]*service.ExamEventInput, 0, len(req.Events))
+	for _, e := range req.Events {
+		if e == nil {
+			continue
+		}
+		inputs = append(inputs, &service.ExamEventInput{
+			EventType:  e.EventType,
+			Detail:     e.Detail,
+			ClientTime: e.ClientTime,
+		})
+	}
+	recorded, err := s.examService.ReportEvents(ctx, studentId, req.ContestId, inputs)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ReportExamEventsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ReportExamEventsResponse{Code: consts.SuccessCode, Message: "上报成功", Recorded: recorded}, nil
+}
+
+// TeacherExamRequest 教师考试操作通用请求
+type TeacherExamRequest struct {
+	TeacherId string `json:"teacher_id"`           // 教师ID（必填）
+	ContestId string `json:"contest_id"`           // 比赛ID（必填）
+	StudentId string `json:"student_id,omitempty"` // 学生ID（查询事件时可选，重置会话时必填）
+}
+
+// ExamSessionsResponse 考试会话列表响应
+type ExamSessionsResponse struct {
+	Code     int32                      `json:"code"`
+	Message  string                     `json:"message"`
+	Sessions []*service.ExamSessionItem `json:"sessions"`
+}
+
+// ListExamSessions 查询考试会话列表（教师）
+func (s *ExamServiceImpl) ListExamSessions(ctx context.Context, req *TeacherExamRequest) (*ExamSessionsResponse, error) {
+	sessions, err := s.examService.ListExamSessions(req.TeacherId, req.ContestId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ExamSessionsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ExamSessionsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Sessions: sessions}, nil
+}
+
+// ExamEventsResponse 考试事件列表响应
+type ExamEventsResponse struct {
+	Code    int32                `json:"code"`
+	Message string               `json:"message"`
+	Events  []*contest.ExamEvent `json:"events"`
+}
+
+// ListExamEvents 查询考试事件日志（教师）
+func (s *ExamServiceImpl) ListExamEvents(ctx context.Context, req *TeacherExamRequest) (*ExamEventsResponse, error) {
+	events, err := s.examService.ListExamEvents(req.TeacherId, req.ContestId, req.StudentId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ExamEventsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ExamEventsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Events: events}, nil
+}
+
+// ExamCommonResponse 考试通用响应
+type ExamCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// ResetExamSession 重置学生考试会话（教师）
+func (s *ExamServiceImpl) ResetExamSession(ctx context.Context, req *TeacherExamRequest) (*ExamCommonResponse, error) {
+	if err := s.examService.ResetExamSession(req.TeacherId, req.ContestId, req.StudentId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ExamCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ExamCommonResponse{Code: consts.SuccessCode, Message: "重置考试会话成功"}, nil
+}
+
+// GetActiveExamId 查询学生当前正在参加的考试ID（无则返回空字符串）
+func (s *ExamServiceImpl) GetActiveExamId(ctx context.Context, studentId string) string {
+	if c := s.examService.GetActiveExam(studentId); c != nil {
+		return c.ContestId
+	}
+	return ""
+}
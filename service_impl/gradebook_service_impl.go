@@ -0,0 +1,85 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// GradebookServiceImpl 成绩册服务实现（只做出入参处理）
+type GradebookServiceImpl struct {
+	gradebookService *service.GradebookService
+}
+
+// NewGradebookServiceImpl 创建成绩册服务实现
+func NewGradebookServiceImpl() *GradebookServiceImpl {
+	return &GradebookServiceImpl{
+		gradebookService: service.NewGradebookService(),
+	}
+}
+
+// GetGradebookRequest 查询成绩册请求
+type GetGradebookRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+}
+
+// GetGradebookResponse 查询成绩册响应
+type GetGradebookResponse struct {
+	Code      int32              `json:"code"`
+	Message   string             `json:"message"`
+	Gradebook *service.Gradebook `json:"gradebook"`
+}
+
+// GetGradebook 查询班级成绩册
+func (s *GradebookServiceImpl) GetGradebook(ctx context.Context, req *GetGradebookRequest) (*GetGradebookResponse, error) {
+	book, err := s.gradebookService.GetGradebook(req.TeacherId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetGradebookResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetGradebookResponse{
+		Code:      consts.SuccessCode,
+		Message:   consts.MessageQuerySuccess,
+		Gradebook: book,
+	}, nil
+}
+
+// ExportGradebook 导出班级成绩册，返回 (文件内容, 文件名, 错误)
+func (s *GradebookServiceImpl) ExportGradebook(ctx context.Context, teacherId, classId, format string) ([]byte, string, error) {
+	return s.gradebookService.ExportGradebook(teacherId, classId, format)
+}
+
+// UpdateChapterWeightsRequest 设置章节权重请求
+type UpdateChapterWeightsRequest struct {
+	TeacherId string              `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string              `json:"class_id"`   // 班级ID（必填）
+	Weights   []ChapterWeightItem `json:"weights"`    // 权重列表
+}
+
+// ChapterWeightItem 章节权重项
+type ChapterWeightItem struct {
+	ChapterId string `json:"chapter_id"` // 章节ID
+	Weight    int32  `json:"weight"`     // 权重（0 表示不计入总评）
+}
+
+// UpdateChapterWeightsResponse 设置章节权重响应
+type UpdateChapterWeightsResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// UpdateChapterWeights 设置章节成绩权重
+func (s *GradebookServiceImpl) UpdateChapterWeights(ctx context.Context, req *UpdateChapterWeightsRequest) (*UpdateChapterWeightsResponse, error) {
+	weights := make(map[string]int32, len(req.Weights))
+	for _, item := range req.Weights {
+		weights[item.ChapterId] = item.Weight
+	}
+	if err := s.gradebookService.UpdateChapterWeights(req.TeacherId, req.ClassId, weights); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &UpdateChapterWeightsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &UpdateChapterWeightsResponse{Code: consts.SuccessCode, Message: "章节权重更新成功"}, nil
+}
@@ -0,0 +1,94 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// LeaderboardServiceImpl 班级排行榜服务实现（只做出入参处理）
+type LeaderboardServiceImpl struct {
+	leaderboardService *service.LeaderboardService
+}
+
+// NewLeaderboardServiceImpl 创建班级排行榜服务实现
+func NewLeaderboardServiceImpl() *LeaderboardServiceImpl {
+	return &LeaderboardServiceImpl{
+		leaderboardService: service.NewLeaderboardService(),
+	}
+}
+
+// LeaderboardCommonResponse 通用响应
+type LeaderboardCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// GetLeaderboardRequest 查询班级排行榜请求
+type GetLeaderboardRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（教师查看时必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+	Mode      string `json:"mode"`       // 排行方式：solved-通过题数（默认），score-总得分，streak-连续通过天数
+	Window    string `json:"window"`     // 统计范围：week-本周（默认），semester-本学期
+	Limit     int    `json:"limit"`      // 返回前多少名（默认 50，最多 200）
+}
+
+// GetLeaderboardResponse 查询班级排行榜响应
+type GetLeaderboardResponse struct {
+	Code        int32                `json:"code"`
+	Message     string               `json:"message"`
+	Leaderboard *service.Leaderboard `json:"leaderboard"`
+}
+
+// GetStudentLeaderboard 学生查看班级排行榜
+func (s *LeaderboardServiceImpl) GetStudentLeaderboard(ctx context.Context, studentId string, req *GetLeaderboardRequest) (*GetLeaderboardResponse, error) {
+	board, err := s.leaderboardService.GetStudentLeaderboard(studentId, req.ClassId, req.Mode, req.Window, req.Limit)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetLeaderboardResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetLeaderboardResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Leaderboard: board}, nil
+}
+
+// GetTeacherLeaderboard 教学团队查看班级排行榜
+func (s *LeaderboardServiceImpl) GetTeacherLeaderboard(ctx context.Context, req *GetLeaderboardRequest) (*GetLeaderboardResponse, error) {
+	board, err := s.leaderboardService.GetTeacherLeaderboard(req.TeacherId, req.ClassId, req.Mode, req.Window, req.Limit)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetLeaderboardResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetLeaderboardResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Leaderboard: board}, nil
+}
+
+// SetLeaderboardEnabledRequest 开启/关闭班级排行榜请求
+type SetLeaderboardEnabledRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+	Enabled   bool   `json:"enabled"`    // true-开启，false-关闭
+}
+
+// SetLeaderboardEnabled 教师开启或关闭班级排行榜
+func (s *LeaderboardServiceImpl) SetLeaderboardEnabled(ctx context.Context, req *SetLeaderboardEnabledRequest) (*LeaderboardCommonResponse, error) {
+	if err := s.leaderboardService.SetLeaderboardEnabled(req.TeacherId, req.ClassId, req.Enabled); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &LeaderboardCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &LeaderboardCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
+
+// SetLeaderboardVisibilityRequest 设置排行榜展示方式请求
+type SetLeaderboardVisibilityRequest struct {
+	ClassId    string `json:"class_id"`   // 班级ID（必填）
+	Visibility int32  `json:"visibility"` // 展示方式：0-实名，1-匿名，2-不参与
+}
+
+// SetLeaderboardVisibility 学生设置自己在班级排行榜中的展示方式
+func (s *LeaderboardServiceImpl) SetLeaderboardVisibility(ctx context.Context, studentId string, req *SetLeaderboardVisibilityRequest) (*LeaderboardCommonResponse, error) {
+	if err := s.leaderboardService.SetLeaderboardVisibility(studentId, req.ClassId, req.Visibility); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &LeaderboardCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &LeaderboardCommonResponse{Code: consts.SuccessCode, Message: consts.MessageUpdateSuccess}, nil
+}
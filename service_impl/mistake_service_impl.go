@@ -0,0 +1,110 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/model/mistake"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// MistakeServiceImpl 错题本服务实现（只做出入参处理）
+type MistakeServiceImpl struct {
+	mistakeService *service.MistakeService
+}
+
+// NewMistakeServiceImpl 创建错题本服务实现
+func NewMistakeServiceImpl() *MistakeServiceImpl {
+	return &MistakeServiceImpl{
+		mistakeService: service.NewMistakeService(),
+	}
+}
+
+// ListMistakesRequest 查询错题列表请求
+type ListMistakesRequest struct {
+	Status   int32  `json:"status"`    // 错题状态过滤：-1-全部，0-未订正，1-已订正，2-已掌握
+	Reason   string `json:"reason"`    // 失败原因过滤：WA/TLE/MLE/RE/CE，空表示全部
+	Page     int32  `json:"page"`      // 页码（从1开始）
+	PageSize int32  `json:"page_size"` // 每页数量
+}
+
+// ListMistakesResponse 查询错题列表响应
+type ListMistakesResponse struct {
+	Code     int32                     `json:"code"`
+	Message  string                    `json:"message"`
+	Mistakes []*mistake.StudentMistake `json:"mistakes"`
+	Total    int64                     `json:"total"`
+}
+
+// ListMistakes 查询学生错题列表
+func (s *MistakeServiceImpl) ListMistakes(ctx context.Context, studentId string, req *ListMistakesRequest) (*ListMistakesResponse, error) {
+	list, total, err := s.mistakeService.ListMistakes(studentId, req.Status, req.Reason, req.Page, req.PageSize)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListMistakesResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListMistakesResponse{
+		Code:     consts.SuccessCode,
+		Message:  consts.MessageQuerySuccess,
+		Mistakes: list,
+		Total:    total,
+	}, nil
+}
+
+// GetMistakeDetailResponse 查询错题详情响应
+type GetMistakeDetailResponse struct {
+	Code    int32                  `json:"code"`
+	Message string                 `json:"message"`
+	Detail  *service.MistakeDetail `json:"detail"`
+}
+
+// GetMistakeDetail 查询错题详情
+func (s *MistakeServiceImpl) GetMistakeDetail(ctx context.Context, studentId, mistakeId string) (*GetMistakeDetailResponse, error) {
+	detail, err := s.mistakeService.GetMistakeDetail(studentId, mistakeId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetMistakeDetailResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetMistakeDetailResponse{
+		Code:    consts.SuccessCode,
+		Message: consts.MessageQuerySuccess,
+		Detail:  detail,
+	}, nil
+}
+
+// UpdateMistakeNoteRequest 更新错题笔记请求
+type UpdateMistakeNoteRequest struct {
+	MistakeId string `json:"mistake_id"` // 错题ID（必填）
+	Note      string `json:"note"`       // 笔记内容
+}
+
+// MarkMistakeMasteredRequest 标记错题已掌握请求
+type MarkMistakeMasteredRequest struct {
+	MistakeId string `json:"mistake_id"` // 错题ID（必填）
+	Mastered  bool   `json:"mastered"`   // true-标记已掌握，false-取消标记
+}
+
+// MistakeCommonResponse 错题通用响应
+type MistakeCommonResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// UpdateMistakeNote 更新错题笔记
+func (s *MistakeServiceImpl) UpdateMistakeNote(ctx context.Context, studentId string, req *UpdateMistakeNoteRequest) (*MistakeCommonResponse, error) {
+	if err := s.mistakeService.UpdateMistakeNote(studentId, req.MistakeId, req.Note); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &MistakeCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &MistakeCommonResponse{Code: consts.SuccessCode, Message: "笔记已保存"}, nil
+}
+
+// MarkMistakeMastered 标记/取消标记错题已掌握
+func (s *MistakeServiceImpl) MarkMistakeMastered(ctx context.Context, studentId string, req *MarkMistakeMasteredRequest) (*MistakeCommonResponse, error) {
+	if err := s.mistakeService.SetMistakeMastered(studentId, req.MistakeId, req.Mastered); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &MistakeCommonResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &MistakeCommonResponse{Code: consts.SuccessCode, Message: "状态已更新"}, nil
+}
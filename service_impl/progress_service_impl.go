@@ -0,0 +1,61 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// ProgressServiceImpl 学习进度服务实现（只做出入参处理）
+type ProgressServiceImpl struct {
+	progressService *service.ProgressService
+}
+
+// NewProgressServiceImpl 创建学习进度服务实现
+func NewProgressServiceImpl() *ProgressServiceImpl {
+	return &ProgressServiceImpl{
+		progressService: service.NewProgressService(),
+	}
+}
+
+// GetMyProgressResponse 查询我的学习进度响应
+type GetMyProgressResponse struct {
+	Code    int32                    `json:"code"`
+	Message string                   `json:"message"`
+	Classes []*service.ClassProgress `json:"classes"` // 各班级的学习进度（含章节明细）
+}
+
+// GetMyProgress 查询学生在所加入的各班级的学习进度
+func (s *ProgressServiceImpl) GetMyProgress(ctx context.Context, studentId string) (*GetMyProgressResponse, error) {
+	list, err := s.progressService.ListMyProgress(studentId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetMyProgressResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetMyProgressResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Classes: list}, nil
+}
+
+// GetClassProgressRequest 查询班级学习进度请求
+type GetClassProgressRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	ClassId   string `json:"class_id"`   // 班级ID（必填）
+}
+
+// GetClassProgressResponse 查询班级学习进度响应
+type GetClassProgressResponse struct {
+	Code     int32                     `json:"code"`
+	Message  string                    `json:"message"`
+	Students []*service.MemberProgress `json:"students"` // 班级成员的学习进度
+}
+
+// GetClassProgress 查询班级全部成员的学习进度
+func (s *ProgressServiceImpl) GetClassProgress(ctx context.Context, req *GetClassProgressRequest) (*GetClassProgressResponse, error) {
+	list, err := s.progressService.ListClassProgress(req.TeacherId, req.ClassId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetClassProgressResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetClassProgressResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Students: list}, nil
+}
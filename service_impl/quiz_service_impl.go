@@ -0,0 +1,260 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// QuizServiceImpl 测验服务实现（只做出入参处理）
+type QuizServiceImpl struct {
+	quizService *service.QuizService
+}
+
+// NewQuizServiceImpl 创建测验服务实现
+func NewQuizServiceImpl() *QuizServiceImpl {
+	return &QuizServiceImpl{
+		quizService: service.NewQuizService(),
+	}
+}
+
+// ==================== 教师接口 ====================
+
+// SetQuizSettingsRequest 设置测验参数请求
+type SetQuizSettingsRequest struct {
+	TeacherId   string `json:"teacher_id"`   // 教师ID（必填）
+	SectionId   string `json:"section_id"`   // 测验小节ID（必填）
+	OpenTime    string `json:"open_time"`    // 开放时间（可选，格式 2006-01-02 15:04:05）
+	DueTime     string `json:"due_time"`     // 截止时间（可选，格式 2006-01-02 15:04:05）
+	MaxAttempts int32  `json:"max_attempts"` // 最大作答次数（0-不限）
+	Shuffle     bool   `json:"shuffle"`      // 每次作答是否打乱题目顺序
+	TimeLimit   int32  `json:"time_limit"`   // 作答时限（分钟，0-不限）
+}
+
+// SetQuizSettingsResponse 设置测验参数响应
+type SetQuizSettingsResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// SetQuizSettings 设置测验参数
+func (s *QuizServiceImpl) SetQuizSettings(ctx context.Context, req *SetQuizSettingsRequest) (*SetQuizSettingsResponse, error) {
+	if err := s.quizService.SetQuizSettings(req.TeacherId, req.SectionId, req.OpenTime, req.DueTime,
+		req.MaxAttempts, req.Shuffle, req.TimeLimit); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &SetQuizSettingsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &SetQuizSettingsResponse{Code: consts.SuccessCode, Message: "测验设置成功"}, nil
+}
+
+// AddQuizQuestionRequest 添加测验题目请求
+type AddQuizQuestionRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	SectionId string `json:"section_id"` // 测验小节ID（必填）
+	service.QuizQuestionInput
+}
+
+// AddQuizQuestionResponse 添加测验题目响应
+type AddQuizQuestionResponse struct {
+	Code     int32                         `json:"code"`
+	Message  string                        `json:"message"`
+	Question *classModel.ClassQuizQuestion `json:"question"`
+}
+
+// AddQuizQuestion 添加测验题目
+func (s *QuizServiceImpl) AddQuizQuestion(ctx context.Context, req *AddQuizQuestionRequest) (*AddQuizQuestionResponse, error) {
+	question, err := s.quizService.AddQuestion(req.TeacherId, req.SectionId, &req.QuizQuestionInput)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &AddQuizQuestionResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &AddQuizQuestionResponse{Code: consts.SuccessCode, Message: "添加题目成功", Question: question}, nil
+}
+
+// UpdateQuizQuestionRequest 更新测验题目请求
+type UpdateQuizQuestionRequest struct {
+	TeacherId  string `json:"teacher_id"`  // 教师ID（必填）
+	QuestionId string `json:"question_id"` // 题目ID（必填）
+	SortOrder  int32  `json:"sort_order"`  // 排序值（可选，0 表示不修改）
+	service.QuizQuestionInput
+}
+
+// UpdateQuizQuestionResponse 更新测验题目响应
+type UpdateQuizQuestionResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// UpdateQuizQuestion 更新测验题目
+func (s *QuizServiceImpl) UpdateQuizQuestion(ctx context.Context, req *UpdateQuizQuestionRequest) (*UpdateQuizQuestionResponse, error) {
+	if err := s.quizService.UpdateQuestion(req.TeacherId, req.QuestionId, &req.QuizQuestionInput, req.SortOrder); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &UpdateQuizQuestionResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &UpdateQuizQuestionResponse{Code: consts.SuccessCode, Message: "更新题目成功"}, nil
+}
+
+// DeleteQuizQuestionRequest 删除测验题目请求
+type DeleteQuizQuestionRequest struct {
+	TeacherId  string `json:"teacher_id"`  // 教师ID（必填）
+	QuestionId string `json:"question_id"` // 题目ID（必填）
+}
+
+// DeleteQuizQuestionResponse 删除测验题目响应
+type DeleteQuizQuestionResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// DeleteQuizQuestion 删除测验题目
+func (s *QuizServiceImpl) DeleteQuizQuestion(ctx context.Context, req *DeleteQuizQuestionRequest) (*DeleteQuizQuestionResponse, error) {
+	if err := s.quizService.DeleteQuestion(req.TeacherId, req.QuestionId); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &DeleteQuizQuestionResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &DeleteQuizQuestionResponse{Code: consts.SuccessCode, Message: "删除题目成功"}, nil
+}
+
+// ListQuizQuestionsRequest 查询测验题目请求
+type ListQuizQuestionsRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	SectionId string `json:"section_id"` // 测验小节ID（必填）
+}
+
+// ListQuizQuestionsResponse 查询测验题目响应
+type ListQuizQuestionsResponse struct {
+	Code      int32                       `json:"code"`
+	Message   string                      `json:"message"`
+	Questions []*service.QuizQuestionView `json:"questions"` // 题目列表（含答案与解析）
+}
+
+// ListQuizQuestions 查询测验题目（教师）
+func (s *QuizServiceImpl) ListQuizQuestions(ctx context.Context, req *ListQuizQuestionsRequest) (*ListQuizQuestionsResponse, error) {
+	questions, err := s.quizService.ListQuestions(req.TeacherId, req.SectionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListQuizQuestionsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListQuizQuestionsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Questions: questions}, nil
+}
+
+// ListQuizResultsRequest 查询测验成绩请求
+type ListQuizResultsRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	SectionId string `json:"section_id"` // 测验小节ID（必填）
+}
+
+// ListQuizResultsResponse 查询测验成绩响应
+type ListQuizResultsResponse struct {
+	Code    int32                        `json:"code"`
+	Message string                       `json:"message"`
+	Results []*service.QuizStudentResult `json:"results"` // 每个班级成员一条
+}
+
+// ListQuizResults 查询测验成绩汇总（教师）
+func (s *QuizServiceImpl) ListQuizResults(ctx context.Context, req *ListQuizResultsRequest) (*ListQuizResultsResponse, error) {
+	results, err := s.quizService.ListQuizResults(req.TeacherId, req.SectionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListQuizResultsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListQuizResultsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Results: results}, nil
+}
+
+// GetQuizAttemptRequest 查询作答详情请求
+type GetQuizAttemptRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	AttemptId string `json:"attempt_id"` // 作答ID（必填）
+}
+
+// QuizAttemptResponse 作答结果响应
+type QuizAttemptResponse struct {
+	Code    int32                      `json:"code"`
+	Message string                     `json:"message"`
+	Result  *service.QuizAttemptResult `json:"result"`
+}
+
+// GetQuizAttempt 查询学生某次作答的逐题结果（教师）
+func (s *QuizServiceImpl) GetQuizAttempt(ctx context.Context, req *GetQuizAttemptRequest) (*QuizAttemptResponse, error) {
+	result, err := s.quizService.GetTeacherAttempt(req.TeacherId, req.AttemptId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &QuizAttemptResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &QuizAttemptResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Result: result}, nil
+}
+
+// ==================== 学生接口 ====================
+
+// StartQuizRequest 开始作答请求
+type StartQuizRequest struct {
+	SectionId string `json:"section_id"` // 测验小节ID（必填）
+}
+
+// StartQuizResponse 开始作答响应
+type StartQuizResponse struct {
+	Code    int32              `json:"code"`
+	Message string             `json:"message"`
+	Paper   *service.QuizPaper `json:"paper"`
+}
+
+// StartQuiz 开始或继续作答
+func (s *QuizServiceImpl) StartQuiz(ctx context.Context, studentId string, req *StartQuizRequest) (*StartQuizResponse, error) {
+	paper, err := s.quizService.StartQuiz(studentId, req.SectionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &StartQuizResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &StartQuizResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Paper: paper}, nil
+}
+
+// QuizAnswersRequest 保存答案/交卷请求
+type QuizAnswersRequest struct {
+	AttemptId string              `json:"attempt_id"` // 作答ID（必填）
+	Answers   map[string][]string `json:"answers"`    // question_id -> 答案数组（选择题为选项字母，判断题为 true/false，填空题每空一项）
+}
+
+// SaveQuizAnswersResponse 保存答案响应
+type SaveQuizAnswersResponse struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// SaveQuizAnswers 保存作答中的答案
+func (s *QuizServiceImpl) SaveQuizAnswers(ctx context.Context, studentId string, req *QuizAnswersRequest) (*SaveQuizAnswersResponse, error) {
+	if err := s.quizService.SaveAnswers(studentId, req.AttemptId, req.Answers); err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &SaveQuizAnswersResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &SaveQuizAnswersResponse{Code: consts.SuccessCode, Message: "保存成功"}, nil
+}
+
+// SubmitQuiz 交卷并返回判分结果
+func (s *QuizServiceImpl) SubmitQuiz(ctx context.Context, studentId string, req *QuizAnswersRequest) (*QuizAttemptResponse, error) {
+	result, err := s.quizService.SubmitQuiz(studentId, req.AttemptId, req.Answers)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &QuizAttemptResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &QuizAttemptResponse{Code: consts.SuccessCode, Message: "交卷成功", Result: result}, nil
+}
+
+// ListMyQuizAttemptsResponse 查询我的作答记录响应
+type ListMyQuizAttemptsResponse struct {
+	Code     int32                        `json:"code"`
+	Message  string                       `json:"message"`
+	Attempts []*service.QuizAttemptResult `json:"attempts"`
+}
+
+// ListMyQuizAttempts 查询学生在测验小节的作答记录
+func (s *QuizServiceImpl) ListMyQuizAttempts(ctx context.Context, studentId, sectionId string) (*ListMyQuizAttemptsResponse, error) {
+	attempts, err := s.quizService.ListMyAttempts(studentId, sectionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListMyQuizAttemptsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListMyQuizAttemptsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Attempts: attempts}, nil
+}
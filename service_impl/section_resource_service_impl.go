@@ -0,0 +1,118 @@
+package service_impl
+
+import (
+	"context"
+	"mime/multipart"
+
+	"github.com/yzf120/elysia-backend/consts"
+	"github.com/yzf120/elysia-backend/errs"
+	classModel "github.com/yzf120/elysia-backend/model/class"
+	"github.com/yzf120/elysia-backend/service"
+)
+
+// SectionResourceServiceImpl 学习资源小节服务实现（只做出入参处理）
+type SectionResourceServiceImpl struct {
+	resourceService *service.SectionResourceService
+}
+
+// NewSectionResourceServiceImpl 创建学习资源小节服务实现
+func NewSectionResourceServiceImpl() *SectionResourceServiceImpl {
+	return &SectionResourceServiceImpl{
+		resourceService: service.NewSectionResourceService(),
+	}
+}
+
+// ==================== 教师接口 ====================
+
+// SetSectionResourceRequest 设置学习资源请求（multipart 表单）
+type SetSectionResourceRequest struct {
+	TeacherId       string                `json:"teacher_id"`        // 教师ID（必填）
+	SectionId       string                `json:"section_id"`        // 阅读资料或视频小节ID（必填）
+	BookshelfItemId string                `json:"bookshelf_item_id"` // 引用的平台书架条目ID（阅读资料，与上传文件二选一）
+	VideoUrl        string                `json:"video_url"`         // 外部视频链接（视频小节必填）
+	File            *multipart.FileHeader `json:"-"`                 // 班级私有上传文件（阅读资料，与书架条目二选一）
+}
+
+// SetSectionResourceResponse 设置学习资源响应
+type SetSectionResourceResponse struct {
+	Code    int32                    `json:"code"`
+	Message string                   `json:"message"`
+	Section *classModel.ClassSection `json:"section"`
+}
+
+// SetSectionResource 设置学习资源
+func (s *SectionResourceServiceImpl) SetSectionResource(ctx context.Context, req *SetSectionResourceRequest) (*SetSectionResourceResponse, error) {
+	section, err := s.resourceService.SetSectionResource(req.TeacherId, req.SectionId, req.BookshelfItemId, req.VideoUrl, req.File)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &SetSectionResourceResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &SetSectionResourceResponse{Code: consts.SuccessCode, Message: "资源设置成功", Section: section}, nil
+}
+
+// ListSectionViewsRequest 查询学生查看情况请求
+type ListSectionViewsRequest struct {
+	TeacherId string `json:"teacher_id"` // 教师ID（必填）
+	SectionId string `json:"section_id"` // 阅读资料或视频小节ID（必填）
+}
+
+// ListSectionViewsResponse 查询学生查看情况响应
+type ListSectionViewsResponse struct {
+	Code    int32                        `json:"code"`
+	Message string                       `json:"message"`
+	Views   []*service.SectionViewRecord `json:"views"`
+}
+
+// ListSectionViews 查询学生查看情况
+func (s *SectionResourceServiceImpl) ListSectionViews(ctx context.Context, req *ListSectionViewsRequest) (*ListSectionViewsResponse, error) {
+	views, err := s.resourceService.ListSectionViews(req.TeacherId, req.SectionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &ListSectionViewsResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &ListSectionViewsResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Views: views}, nil
+}
+
+// ==================== 学生接口 ====================
+
+// OpenSectionResourceRequest 打开学习资源请求
+type OpenSectionResourceRequest struct {
+	SectionId string `json:"section_id"` // 阅读资料或视频小节ID（必填）
+}
+
+// OpenSectionResourceResponse 打开学习资源响应
+type OpenSectionResourceResponse struct {
+	Code     int32                    `json:"code"`
+	Message  string                   `json:"message"`
+	Resource *service.SectionResource `json:"resource"`
+}
+
+// OpenSectionResource 打开学习资源（记录查看）
+func (s *SectionResourceServiceImpl) OpenSectionResource(ctx context.Context, studentId string, req *OpenSectionResourceRequest) (*OpenSectionResourceResponse, error) {
+	resource, err := s.resourceService.OpenSectionResource(studentId, req.SectionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &OpenSectionResourceResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &OpenSectionResourceResponse{Code: consts.SuccessCode, Message: consts.MessageQuerySuccess, Resource: resource}, nil
+}
+
+// ==================== 文件接口（师生共用） ====================
+
+// GetSectionResourceFileResponse 获取阅读资料上传文件响应
+type GetSectionResourceFileResponse struct {
+	Code     int32
+	Message  string
+	Section  *classModel.ClassSection
+	FilePath string
+}
+
+// GetSectionResourceFile 获取阅读资料上传文件
+func (s *SectionResourceServiceImpl) GetSectionResourceFile(ctx context.Context, userType, roleId, sectionId string) (*GetSectionResourceFileResponse, error) {
+	section, filePath, err := s.resourceService.GetSectionResourceFile(userType, roleId, sectionId)
+	if err != nil {
+		code, msg := errs.ParseCommonError(err.Error())
+		return &GetSectionResourceFileResponse{Code: int32(code), Message: msg}, nil
+	}
+	return &GetSectionResourceFileResponse{Code: consts.SuccessCode, Section: section, FilePath: filePath}, nil
+}
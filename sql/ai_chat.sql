@@ -0,0 +1,69 @@
+-- AI 对话消息待投递表（对话记录先落库再写入 session 服务，写入成功后删除，失败时由后台任务按退避策略重试）
+CREATE TABLE IF NOT EXISTS `ai_chat_outbox` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `session_id` varchar(64) NOT NULL DEFAULT '' COMMENT '会话id',
+  `user_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `model_id` varchar(128) NOT NULL DEFAULT '' COMMENT '模型id',
+  `sender_type` tinyint NOT NULL DEFAULT '0' COMMENT '发送方：1-学生，2-AI助教',
+  `content` longtext NOT NULL COMMENT '消息内容',
+  `message_seq` int NOT NULL DEFAULT '0' COMMENT '消息序号',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态：0-待投递，1-超过最大重试次数',
+  `retry_count` int NOT NULL DEFAULT '0' COMMENT '已重试次数',
+  `next_retry_time` datetime NOT NULL COMMENT '下次重试时间',
+  `last_error` varchar(512) NOT NULL DEFAULT '' COMMENT '最近一次失败原因',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_session_id` (`session_id`),
+  KEY `idx_status_retry` (`status`, `next_retry_time`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='AI对话消息待投递表';
+
+-- AI 答疑会话元数据表（会话归属、服务端分配的消息序号以及早期对话的滚动摘要；会话与消息本身存放在 session 服务）
+CREATE TABLE IF NOT EXISTS `ai_chat_session` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `session_id` varchar(64) NOT NULL DEFAULT '' COMMENT '会话id',
+  `user_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `problem_id` bigint NOT NULL DEFAULT '0' COMMENT '题目id（普通对话为0）',
+  `last_seq` int NOT NULL DEFAULT '0' COMMENT '已分配的最大消息序号',
+  `summary` text COMMENT '早期对话的滚动摘要',
+  `summary_seq` int NOT NULL DEFAULT '0' COMMENT '摘要覆盖到的消息序号（含）',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_session_id` (`session_id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='AI答疑会话元数据表';
+
+-- AI 用量流水表（每次模型调用一条，按学生、班级、模型统计 token 用量）
+CREATE TABLE IF NOT EXISTS `ai_usage_ledger` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id（班级内发起的对话）',
+  `model_id` varchar(128) NOT NULL DEFAULT '' COMMENT '模型id',
+  `session_id` varchar(64) NOT NULL DEFAULT '' COMMENT '会话id',
+  `purpose` varchar(16) NOT NULL DEFAULT 'chat' COMMENT '用途：chat-答疑，summary-会话摘要',
+  `prompt_tokens` bigint NOT NULL DEFAULT '0' COMMENT '输入token数',
+  `completion_tokens` bigint NOT NULL DEFAULT '0' COMMENT '输出token数',
+  `total_tokens` bigint NOT NULL DEFAULT '0' COMMENT '总token数',
+  `estimated` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否为估算值（模型未返回用量时按文本长度估算）',
+  `usage_date` date NOT NULL COMMENT '用量日期',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_student_date` (`student_id`, `usage_date`) USING BTREE,
+  KEY `idx_class_date` (`class_id`, `usage_date`) USING BTREE,
+  KEY `idx_usage_date` (`usage_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='AI用量流水表';
+
+-- AI 用量额度配置表（全局默认额度与班级额度，均为每个学生的 token 数，0 表示不限）
+CREATE TABLE IF NOT EXISTS `ai_usage_quota` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `scope` varchar(16) NOT NULL DEFAULT '' COMMENT '范围：global-全局，class-班级',
+  `scope_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id（全局额度为空）',
+  `daily_tokens` bigint NOT NULL DEFAULT '0' COMMENT '每个学生每日token额度，0表示不限',
+  `monthly_tokens` bigint NOT NULL DEFAULT '0' COMMENT '每个学生每月token额度，0表示不限',
+  `updated_by` varchar(64) NOT NULL DEFAULT '' COMMENT '最后修改的管理员id',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_scope` (`scope`, `scope_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='AI用量额度配置表';
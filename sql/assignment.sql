@@ -0,0 +1,36 @@
+-- 小节作业设置迁移：已有 class_section / code_run 表新增作业相关字段（新建表请直接使用 chapter.sql / code_run.sql）
+ALTER TABLE `class_section` ADD COLUMN `open_time` datetime DEFAULT NULL COMMENT '作业开放时间（为空表示立即开放）' AFTER `discussion_content`;
+ALTER TABLE `class_section` ADD COLUMN `due_time` datetime DEFAULT NULL COMMENT '作业截止时间（为空表示不截止）' AFTER `open_time`;
+ALTER TABLE `class_section` ADD COLUMN `late_policy` tinyint NOT NULL DEFAULT '0' COMMENT '迟交策略：0-允许迟交，1-按天扣分，2-截止后禁止提交' AFTER `due_time`;
+ALTER TABLE `class_section` ADD COLUMN `late_penalty` int NOT NULL DEFAULT '0' COMMENT '每迟交一天扣除的分数百分比' AFTER `late_policy`;
+ALTER TABLE `class_section` ADD COLUMN `max_attempts` int NOT NULL DEFAULT '0' COMMENT '最大提交次数（0-不限）' AFTER `late_penalty`;
+
+-- code_run 新增班级上下文字段
+ALTER TABLE `code_run` ADD COLUMN `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级ID（班级作业内提交时记录）' AFTER `student_id`;
+ALTER TABLE `code_run` ADD COLUMN `section_id` varchar(64) NOT NULL DEFAULT '' COMMENT '小节ID（班级作业内提交时记录）' AFTER `class_id`;
+ALTER TABLE `code_run` ADD INDEX `idx_section_student` (`section_id`, `student_id`);
+
+-- 作业小节成绩表（每个学生每个小节一条，记录最佳提交与截止前最佳提交）
+CREATE TABLE IF NOT EXISTS `class_section_result` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `section_id` varchar(64) NOT NULL DEFAULT '' COMMENT '小节id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `chapter_id` varchar(64) NOT NULL DEFAULT '' COMMENT '章节id',
+  `problem_id` bigint NOT NULL DEFAULT '0' COMMENT '题目id',
+  `attempts` int NOT NULL DEFAULT '0' COMMENT '已评测的提交次数',
+  `best_run_id` bigint NOT NULL DEFAULT '0' COMMENT '最佳提交的运行记录id（按扣分后得分）',
+  `best_status` varchar(32) NOT NULL DEFAULT '' COMMENT '最佳提交的评测状态',
+  `best_score` int NOT NULL DEFAULT '0' COMMENT '最佳得分（0-100，已扣除迟交分）',
+  `best_submit_time` datetime DEFAULT NULL COMMENT '最佳提交时间',
+  `is_late` tinyint(1) NOT NULL DEFAULT '0' COMMENT '最佳提交是否迟交',
+  `late_days` int NOT NULL DEFAULT '0' COMMENT '最佳提交迟交天数',
+  `on_time_run_id` bigint NOT NULL DEFAULT '0' COMMENT '截止前最佳提交的运行记录id',
+  `on_time_status` varchar(32) NOT NULL DEFAULT '' COMMENT '截止前最佳提交的评测状态',
+  `on_time_score` int NOT NULL DEFAULT '0' COMMENT '截止前最佳得分',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_section_student` (`section_id`, `student_id`),
+  KEY `idx_class_id` (`class_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='作业小节成绩表';
@@ -6,6 +6,8 @@ CREATE TABLE IF NOT EXISTS `class_chapter` (
   `title` varchar(256) NOT NULL DEFAULT '' COMMENT '章节标题',
   `description` text COMMENT '章节描述',
   `sort_order` int NOT NULL DEFAULT '0' COMMENT '章节排序（同一班级内，值越小越靠前）',
+  `weight` int NOT NULL DEFAULT '1' COMMENT '成绩权重（成绩册按章节加权计算总评）',
+  `release_at` datetime DEFAULT NULL COMMENT '定时发布时间（为空表示立即对学生可见）',
   `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态：0-禁用，1-启用',
   `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
@@ -23,12 +25,34 @@ CREATE TABLE IF NOT EXISTS `class_section` (
   `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '所属班级id（冗余，方便查询）',
   `title` varchar(256) NOT NULL DEFAULT '' COMMENT '小节标题',
   `description` text COMMENT '小节描述',
-  `section_type` tinyint NOT NULL DEFAULT '1' COMMENT '小节类型：1-算法题，2-讨论话题',
+  `section_type` tinyint NOT NULL DEFAULT '1' COMMENT '小节类型：1-算法题，2-讨论话题，3-测验，4-阅读资料，5-视频',
   -- 算法题关联字段（section_type=1 时使用，关联题库）
   `problem_id` varchar(64) NOT NULL DEFAULT '' COMMENT '关联题库的题目ID（section_type=1 时使用）',
   -- 讨论内容字段（section_type=2 时使用）
   `discussion_title` varchar(256) NOT NULL DEFAULT '' COMMENT '讨论话题标题',
   `discussion_content` text COMMENT '讨论话题描述/背景',
+  -- 作业设置字段（section_type=1 时使用）
+  `open_time` datetime DEFAULT NULL COMMENT '作业开放时间（为空表示立即开放）',
+  `due_time` datetime DEFAULT NULL COMMENT '作业截止时间（为空表示不截止）',
+  `late_policy` tinyint NOT NULL DEFAULT '0' COMMENT '迟交策略：0-允许迟交，1-按天扣分，2-截止后禁止提交',
+  `late_penalty` int NOT NULL DEFAULT '0' COMMENT '每迟交一天扣除的分数百分比',
+  `max_attempts` int NOT NULL DEFAULT '0' COMMENT '最大提交次数（0-不限）',
+  -- 测验设置字段（section_type=3 时使用）
+  `quiz_shuffle` tinyint(1) NOT NULL DEFAULT '0' COMMENT '每次作答是否打乱题目顺序',
+  `quiz_time_limit` int NOT NULL DEFAULT '0' COMMENT '作答时限（分钟，0-不限）',
+  -- 学习资源字段（section_type=4、5 时使用）
+  `resource_source` varchar(16) NOT NULL DEFAULT '' COMMENT '阅读资料来源：bookshelf-平台书架，upload-班级上传',
+  `bookshelf_item_id` varchar(64) NOT NULL DEFAULT '' COMMENT '引用的平台书架条目ID',
+  `attachment_name` varchar(255) NOT NULL DEFAULT '' COMMENT '上传文件原始文件名',
+  `attachment_storage_name` varchar(255) NOT NULL DEFAULT '' COMMENT '上传文件存储名',
+  `attachment_mime_type` varchar(128) NOT NULL DEFAULT '' COMMENT '上传文件MIME类型',
+  `attachment_size` bigint NOT NULL DEFAULT '0' COMMENT '上传文件大小（字节）',
+  `video_url` varchar(1024) NOT NULL DEFAULT '' COMMENT '外部视频链接',
+  -- 小组设置（section_type=1、2 时使用）
+  `group_mode` tinyint(1) NOT NULL DEFAULT '0' COMMENT '小组模式：算法题的提交计入全组成员，讨论仅组内可见',
+  -- 发布与解锁设置
+  `release_at` datetime DEFAULT NULL COMMENT '定时发布时间（为空表示立即对学生可见）',
+  `prerequisite_ids` json DEFAULT NULL COMMENT '前置小节ID列表（JSON数组，全部完成后解锁）',
   `sort_order` int NOT NULL DEFAULT '0' COMMENT '小节排序（同一章节内，值越小越靠前）',
   `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态：0-禁用，1-启用',
   `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
@@ -50,6 +74,14 @@ ALTER TABLE `class_section` DROP COLUMN `problem_output_desc`;
 ALTER TABLE `class_section` DROP COLUMN `problem_test_cases`;
 ALTER TABLE `class_section` ADD COLUMN `problem_id` varchar(64) NOT NULL DEFAULT '' COMMENT '关联题库的题目ID' AFTER `section_type`;
 
+-- class_chapter 新增成绩权重字段
+ALTER TABLE `class_chapter` ADD COLUMN `weight` int NOT NULL DEFAULT '1' COMMENT '成绩权重（成绩册按章节加权计算总评）' AFTER `sort_order`;
+
+-- 章节、小节新增定时发布与前置小节字段
+ALTER TABLE `class_chapter` ADD COLUMN `release_at` datetime DEFAULT NULL COMMENT '定时发布时间（为空表示立即对学生可见）' AFTER `weight`;
+ALTER TABLE `class_section` ADD COLUMN `release_at` datetime DEFAULT NULL COMMENT '定时发布时间（为空表示立即对学生可见）' AFTER `max_attempts`;
+ALTER TABLE `class_section` ADD COLUMN `prerequisite_ids` json DEFAULT NULL COMMENT '前置小节ID列表（JSON数组，全部完成后解锁）' AFTER `release_at`;
+
 -- class 表新增 chapter_ids 字段（JSON 数组，存放有序章节id列表）
 ALTER TABLE `class` ADD COLUMN `chapter_ids` json DEFAULT NULL COMMENT '章节id列表（有序JSON数组）';
 
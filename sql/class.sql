@@ -12,6 +12,11 @@ CREATE TABLE IF NOT EXISTS `class` (
   `description` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci COMMENT '班级描述',
   `announcement` text CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci COMMENT '班级公告',
   `qr_code_url` varchar(512) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '班级二维码URL',
+  `join_policy` tinyint NOT NULL DEFAULT '0' COMMENT '加入策略：0-开放，1-需审批，2-关闭',
+  `waitlist_enabled` tinyint(1) NOT NULL DEFAULT '0' COMMENT '满员后是否开启候补',
+  `leaderboard_off` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否关闭班级排行榜',
+  `group_self_enroll` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否允许学生自建/自选小组',
+  `group_max_size` int NOT NULL DEFAULT '5' COMMENT '学生自建小组的人数上限',
   `status` int NOT NULL DEFAULT '1' COMMENT '状态：0-已结束，1-进行中，2-已归档',
   `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
@@ -31,6 +36,8 @@ CREATE TABLE IF NOT EXISTS `class_member` (
   `join_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '加入时间',
   `status` int NOT NULL DEFAULT '1' COMMENT '状态：0-已退出，1-正常',
   `remark` varchar(512) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '备注',
+  `invite_code` varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL DEFAULT '' COMMENT '加入时使用的邀请码',
+  `leaderboard_visibility` tinyint NOT NULL DEFAULT '0' COMMENT '排行榜展示方式：0-实名，1-匿名，2-不参与',
   `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
   PRIMARY KEY (`id`),
@@ -0,0 +1,44 @@
+-- 班级公告表（替代 class.announcement 字段中的 JSON 数组）
+CREATE TABLE IF NOT EXISTS `class_announcement` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `announcement_id` varchar(64) NOT NULL DEFAULT '' COMMENT '公告id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `teacher_id` varchar(64) NOT NULL DEFAULT '' COMMENT '发布教师id',
+  `title` varchar(256) NOT NULL DEFAULT '' COMMENT '公告标题',
+  `content` text COMMENT '公告内容',
+  `is_pinned` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否置顶',
+  `expire_time` datetime DEFAULT NULL COMMENT '过期时间（为空表示不过期，过期后学生不可见）',
+  `publish_time` datetime NOT NULL COMMENT '发布时间',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_announcement_id` (`announcement_id`),
+  KEY `idx_class_id` (`class_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='班级公告表';
+
+-- 公告已读回执表
+CREATE TABLE IF NOT EXISTS `class_announcement_read` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `announcement_id` varchar(64) NOT NULL DEFAULT '' COMMENT '公告id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `read_time` datetime NOT NULL COMMENT '阅读时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_announcement_student` (`announcement_id`, `student_id`),
+  KEY `idx_class_student` (`class_id`, `student_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='公告已读回执表';
+
+-- 数据迁移：将 class.announcement 中的 JSON 数组拆分写入 class_announcement（可重复执行，已迁移的公告跳过）
+-- 旧公告 id 为纳秒时间戳，迁移后公告id为 ann_ + 原id；class.announcement 字段保留不再写入
+INSERT IGNORE INTO `class_announcement` (`announcement_id`, `class_id`, `teacher_id`, `title`, `content`, `is_pinned`, `expire_time`, `publish_time`)
+SELECT CONCAT('ann_', jt.`id`), c.`class_id`, c.`teacher_id`, jt.`title`, jt.`content`, 0, NULL,
+       COALESCE(STR_TO_DATE(jt.`publish_time`, '%Y-%m-%d %H:%i:%s'), c.`create_time`)
+FROM (SELECT `class_id`, `teacher_id`, `announcement`, `create_time` FROM `class`
+      WHERE `announcement` IS NOT NULL AND `announcement` <> '' AND JSON_VALID(`announcement`)) c,
+     JSON_TABLE(c.`announcement`, '$[*]' COLUMNS (
+       `id` varchar(64) PATH '$.id',
+       `title` varchar(256) PATH '$.title',
+       `content` text PATH '$.content',
+       `publish_time` varchar(32) PATH '$.publish_time'
+     )) AS jt
+WHERE jt.`id` IS NOT NULL;
@@ -0,0 +1,36 @@
+-- 课堂签到场次表（同一班级同时只有一个进行中的签到，签到码存放在 Redis 中按间隔轮换）
+CREATE TABLE IF NOT EXISTS `class_attendance_session` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `session_id` varchar(64) NOT NULL DEFAULT '' COMMENT '签到场次id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `title` varchar(128) NOT NULL DEFAULT '' COMMENT '签到标题',
+  `opened_by` varchar(64) NOT NULL DEFAULT '' COMMENT '发起签到的教学团队成员id',
+  `start_time` datetime NOT NULL COMMENT '签到开始时间',
+  `late_time` datetime NOT NULL COMMENT '此时间之后签到记为迟到',
+  `end_time` datetime NOT NULL COMMENT '签到截止时间',
+  `code_interval` int NOT NULL DEFAULT '30' COMMENT '签到码轮换间隔（秒）',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态：0-签到中，1-已结束',
+  `closed_at` datetime DEFAULT NULL COMMENT '实际结束时间',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_session_id` (`session_id`),
+  KEY `idx_class_status` (`class_id`, `status`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='课堂签到场次表';
+
+-- 考勤记录表（每个学生每个签到场次一条，签到结束后未签到的学生记为缺勤）
+CREATE TABLE IF NOT EXISTS `class_attendance_record` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `session_id` varchar(64) NOT NULL DEFAULT '' COMMENT '签到场次id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '考勤状态：1-出勤，2-迟到，3-缺勤，4-请假',
+  `checkin_time` datetime DEFAULT NULL COMMENT '签到时间（缺勤、请假为空）',
+  `source` varchar(16) NOT NULL DEFAULT '' COMMENT '记录来源：code-签到码，teacher-教师标记，system-系统记为缺勤',
+  `marked_by` varchar(64) NOT NULL DEFAULT '' COMMENT '手动标记的教学团队成员id',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_session_student` (`session_id`, `student_id`),
+  KEY `idx_class_student` (`class_id`, `student_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='考勤记录表';
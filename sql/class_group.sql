@@ -0,0 +1,37 @@
+-- 班级小组表（项目制课程分组，教师创建或学生自建）
+CREATE TABLE IF NOT EXISTS `class_group` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `group_id` varchar(64) NOT NULL DEFAULT '' COMMENT '小组id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `name` varchar(128) NOT NULL DEFAULT '' COMMENT '小组名称',
+  `description` varchar(512) NOT NULL DEFAULT '' COMMENT '小组简介',
+  `max_size` int NOT NULL DEFAULT '5' COMMENT '人数上限',
+  `leader_id` varchar(64) NOT NULL DEFAULT '' COMMENT '组长学生id（无成员时为空）',
+  `creator_type` varchar(16) NOT NULL DEFAULT '' COMMENT '创建方式：teacher-教师创建，student-学生自建',
+  `created_by` varchar(64) NOT NULL DEFAULT '' COMMENT '创建人id',
+  `member_count` int NOT NULL DEFAULT '0' COMMENT '当前人数',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_group_id` (`group_id`),
+  KEY `idx_class_id` (`class_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='班级小组表';
+
+-- 小组成员表（一个学生在一个班级内最多属于一个小组）
+CREATE TABLE IF NOT EXISTS `class_group_member` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `group_id` varchar(64) NOT NULL DEFAULT '' COMMENT '小组id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `role` varchar(16) NOT NULL DEFAULT 'member' COMMENT '角色：leader-组长，member-组员',
+  `join_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '加入时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_class_student` (`class_id`, `student_id`),
+  KEY `idx_group_id` (`group_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='小组成员表';
+
+-- 已有表迁移：新增分组设置、小节小组模式与讨论帖所属小组字段（新建表请直接使用 class.sql、chapter.sql、discussion.sql）
+ALTER TABLE `class` ADD COLUMN `group_self_enroll` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否允许学生自建/自选小组' AFTER `leaderboard_off`;
+ALTER TABLE `class` ADD COLUMN `group_max_size` int NOT NULL DEFAULT '5' COMMENT '学生自建小组的人数上限' AFTER `group_self_enroll`;
+ALTER TABLE `class_section` ADD COLUMN `group_mode` tinyint(1) NOT NULL DEFAULT '0' COMMENT '小组模式：算法题的提交计入全组成员，讨论仅组内可见' AFTER `video_url`;
+ALTER TABLE `discussion_post` ADD COLUMN `group_id` varchar(64) NOT NULL DEFAULT '' COMMENT '小组模式下所属小组id（为空表示全班可见）' AFTER `root_id`;
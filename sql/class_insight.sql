@@ -0,0 +1,24 @@
+-- 教学洞察每日摘要订阅表
+CREATE TABLE IF NOT EXISTS `class_insight_subscription` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `teacher_id` varchar(64) NOT NULL DEFAULT '' COMMENT '订阅教师id',
+  `create_time` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '订阅时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_class_teacher` (`class_id`, `teacher_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='教学洞察每日摘要订阅表';
+
+-- 教学洞察每日摘要表（每个订阅者每个班级每天一条）
+CREATE TABLE IF NOT EXISTS `class_insight_digest` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `teacher_id` varchar(64) NOT NULL DEFAULT '' COMMENT '接收教师id',
+  `digest_date` varchar(10) NOT NULL DEFAULT '' COMMENT '摘要日期（yyyy-MM-dd）',
+  `summary` varchar(512) NOT NULL DEFAULT '' COMMENT '摘要文字',
+  `content` json DEFAULT NULL COMMENT '洞察详情（卡题学生、常见错误、高错误率题目）',
+  `is_read` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否已读',
+  `create_time` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '生成时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_class_teacher_date` (`class_id`, `teacher_id`, `digest_date`),
+  KEY `idx_teacher` (`teacher_id`, `is_read`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='教学洞察每日摘要表';
@@ -0,0 +1,23 @@
+-- 班级邀请链接表（班级验证码之外的附加邀请，可设置有效期与使用次数）
+CREATE TABLE IF NOT EXISTS `class_invite` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `invite_code` varchar(32) NOT NULL DEFAULT '' COMMENT '邀请码',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `teacher_id` varchar(64) NOT NULL DEFAULT '' COMMENT '创建教师id',
+  `expire_time` datetime DEFAULT NULL COMMENT '过期时间（为空表示不过期）',
+  `max_uses` int NOT NULL DEFAULT '0' COMMENT '最大使用次数：0-不限',
+  `used_count` int NOT NULL DEFAULT '0' COMMENT '已使用次数',
+  `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态：0-已撤销，1-有效',
+  `remark` varchar(256) NOT NULL DEFAULT '' COMMENT '备注',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_invite_code` (`invite_code`),
+  KEY `idx_class_id` (`class_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='班级邀请链接表';
+
+-- 班级成员记录加入时使用的邀请码（班级验证码或邀请链接码）
+ALTER TABLE `class_member` ADD COLUMN `invite_code` varchar(32) NOT NULL DEFAULT '' COMMENT '加入时使用的邀请码' AFTER `remark`;
+
+-- 为已有班级补充二维码地址
+UPDATE `class` SET `qr_code_url` = CONCAT('/api/class/qrcode?code=', `class_code`) WHERE `qr_code_url` = '';
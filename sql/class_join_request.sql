@@ -0,0 +1,25 @@
+-- 班级加入策略与候补开关
+ALTER TABLE `class`
+  ADD COLUMN `join_policy` tinyint NOT NULL DEFAULT '0' COMMENT '加入策略：0-开放，1-需审批，2-关闭' AFTER `qr_code_url`,
+  ADD COLUMN `waitlist_enabled` tinyint(1) NOT NULL DEFAULT '0' COMMENT '满员后是否开启候补' AFTER `join_policy`;
+
+-- 加入班级申请表（需审批班级的申请与满员候补队列）
+CREATE TABLE IF NOT EXISTS `class_join_request` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `request_id` varchar(64) NOT NULL DEFAULT '' COMMENT '申请id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `invite_code` varchar(32) NOT NULL DEFAULT '' COMMENT '申请时使用的邀请码',
+  `message` varchar(512) NOT NULL DEFAULT '' COMMENT '申请留言',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态：0-待审批，1-已通过，2-已拒绝，3-候补中，4-已取消',
+  `waitlist_time` datetime DEFAULT NULL COMMENT '进入候补队列时间（按此先后递补）',
+  `reject_reason` varchar(512) NOT NULL DEFAULT '' COMMENT '拒绝原因',
+  `handled_by` varchar(64) NOT NULL DEFAULT '' COMMENT '处理人（教师id，候补自动递补为 system）',
+  `handle_time` datetime DEFAULT NULL COMMENT '处理时间',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_request_id` (`request_id`),
+  KEY `idx_class_status` (`class_id`, `status`) USING BTREE,
+  KEY `idx_student_id` (`student_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='加入班级申请表';
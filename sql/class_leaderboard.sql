@@ -0,0 +1,3 @@
+-- 班级排行榜迁移：已有 class、class_member 表新增排行榜设置字段（新建表请直接使用 class.sql）
+ALTER TABLE `class` ADD COLUMN `leaderboard_off` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否关闭班级排行榜' AFTER `waitlist_enabled`;
+ALTER TABLE `class_member` ADD COLUMN `leaderboard_visibility` tinyint NOT NULL DEFAULT '0' COMMENT '排行榜展示方式：0-实名，1-匿名，2-不参与' AFTER `invite_code`;
@@ -0,0 +1,19 @@
+-- 班级教学团队表（创建者、协同教师与助教，助教可为教师或学生）
+CREATE TABLE IF NOT EXISTS `class_staff` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `staff_id` varchar(64) NOT NULL DEFAULT '' COMMENT '成员id（教师id或学生id）',
+  `staff_type` varchar(16) NOT NULL DEFAULT 'teacher' COMMENT '成员类型：teacher/student',
+  `role` varchar(16) NOT NULL DEFAULT 'ta' COMMENT '角色：owner-创建者，co_teacher-协同教师，ta-助教',
+  `permissions` json DEFAULT NULL COMMENT '权限列表：class_manage/chapter_edit/announcement/grade_view/grade_edit/contest/discussion/attendance',
+  `added_by` varchar(64) NOT NULL DEFAULT '' COMMENT '添加人id',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_class_staff` (`class_id`, `staff_id`),
+  KEY `idx_staff_id` (`staff_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='班级教学团队表';
+
+-- 为已有班级补充创建者记录
+INSERT IGNORE INTO `class_staff` (`class_id`, `staff_id`, `staff_type`, `role`, `permissions`, `added_by`)
+SELECT `class_id`, `teacher_id`, 'teacher', 'owner', JSON_ARRAY(), `teacher_id` FROM `class`;
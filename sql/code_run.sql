@@ -3,6 +3,9 @@ CREATE TABLE IF NOT EXISTS `code_run` (
     `id`          BIGINT       NOT NULL AUTO_INCREMENT COMMENT '运行记录ID',
     `problem_id`  BIGINT       NOT NULL COMMENT '题目ID',
     `student_id`  VARCHAR(64)  NOT NULL COMMENT '学生ID',
+    `class_id`    VARCHAR(64)  NOT NULL DEFAULT '' COMMENT '班级ID（班级作业内提交时记录）',
+    `section_id`  VARCHAR(64)  NOT NULL DEFAULT '' COMMENT '小节ID（班级作业内提交时记录）',
+    `contest_id`  VARCHAR(64)  NOT NULL DEFAULT '' COMMENT '比赛ID（比赛内提交时记录）',
     `language`    VARCHAR(32)  NOT NULL COMMENT '编程语言：python/java/go/cpp/c',
     `code`        LONGTEXT     NOT NULL COMMENT '提交的代码',
     `run_type`    ENUM('test','submit') NOT NULL DEFAULT 'test' COMMENT '运行类型：test=测试样例，submit=提交',
@@ -16,5 +19,6 @@ CREATE TABLE IF NOT EXISTS `code_run` (
     `updated_at`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     PRIMARY KEY (`id`),
     INDEX `idx_student_problem` (`student_id`, `problem_id`),
-    INDEX `idx_problem_id` (`problem_id`)
+    INDEX `idx_problem_id` (`problem_id`),
+    INDEX `idx_section_student` (`section_id`, `student_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='代码运行记录表';
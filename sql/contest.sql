@@ -0,0 +1,41 @@
+-- 班级比赛/考试表
+CREATE TABLE IF NOT EXISTS `contest` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `contest_id` varchar(64) NOT NULL DEFAULT '' COMMENT '比赛id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '所属班级id',
+  `teacher_id` varchar(64) NOT NULL DEFAULT '' COMMENT '创建教师id',
+  `title` varchar(256) NOT NULL DEFAULT '' COMMENT '比赛标题',
+  `description` text COMMENT '比赛说明',
+  `rule_type` varchar(16) NOT NULL DEFAULT 'acm' COMMENT '赛制：acm-按通过题数与罚时排名，oi-按总分排名（结束前不公布结果）',
+  `start_time` datetime NOT NULL COMMENT '开始时间',
+  `end_time` datetime NOT NULL COMMENT '结束时间',
+  `freeze_minutes` int NOT NULL DEFAULT '0' COMMENT '封榜时长（结束前N分钟，0-不封榜）',
+  `penalty_minutes` int NOT NULL DEFAULT '20' COMMENT 'ACM每次错误提交罚时（分钟）',
+  `problem_ids` json DEFAULT NULL COMMENT '题目id列表（有序JSON数组）',
+  `is_exam` tinyint(1) NOT NULL DEFAULT '0' COMMENT '考试模式：1-绑定IP与会话，考试期间禁用AI答疑与书架',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_contest_id` (`contest_id`),
+  KEY `idx_class_id` (`class_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='班级比赛表';
+
+-- 比赛提交记录表（关联 code_run）
+CREATE TABLE IF NOT EXISTS `contest_submission` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `contest_id` varchar(64) NOT NULL DEFAULT '' COMMENT '比赛id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `problem_id` bigint NOT NULL DEFAULT '0' COMMENT '题目id',
+  `run_id` bigint NOT NULL DEFAULT '0' COMMENT '关联code_run.id',
+  `status` varchar(32) NOT NULL DEFAULT 'pending' COMMENT '评测状态（同code_run.status）',
+  `score` int NOT NULL DEFAULT '0' COMMENT '得分（0-100）',
+  `submit_time` datetime NOT NULL COMMENT '提交时间',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_run_id` (`run_id`),
+  KEY `idx_contest_student` (`contest_id`, `student_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='比赛提交记录表';
+
+-- code_run 新增比赛上下文字段
+ALTER TABLE `code_run` ADD COLUMN `contest_id` varchar(64) NOT NULL DEFAULT '' COMMENT '比赛ID（比赛内提交时记录）' AFTER `section_id`;
@@ -0,0 +1,52 @@
+-- 讨论帖表（讨论类小节下的主题帖与回复，主题帖 parent_id 为空、root_id 为自身）
+CREATE TABLE IF NOT EXISTS `discussion_post` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `post_id` varchar(64) NOT NULL DEFAULT '' COMMENT '帖子id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `section_id` varchar(64) NOT NULL DEFAULT '' COMMENT '讨论小节id',
+  `parent_id` varchar(64) NOT NULL DEFAULT '' COMMENT '回复的帖子id（主题帖为空）',
+  `root_id` varchar(64) NOT NULL DEFAULT '' COMMENT '所属主题帖id',
+  `group_id` varchar(64) NOT NULL DEFAULT '' COMMENT '小组模式下所属小组id（为空表示全班可见）',
+  `author_id` varchar(64) NOT NULL DEFAULT '' COMMENT '作者id（学生id/教师id）',
+  `author_type` varchar(16) NOT NULL DEFAULT 'student' COMMENT '作者类型：student/teacher',
+  `content` text COMMENT '正文（Markdown）',
+  `like_count` int NOT NULL DEFAULT '0' COMMENT '点赞数',
+  `reply_count` int NOT NULL DEFAULT '0' COMMENT '主题帖下可见回复数',
+  `is_pinned` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否置顶（仅主题帖）',
+  `is_answer` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否被教师标记为答案（仅回复）',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态：0-正常，1-已隐藏，2-已删除',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_post_id` (`post_id`),
+  KEY `idx_section_parent` (`section_id`, `parent_id`) USING BTREE,
+  KEY `idx_root_id` (`root_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='讨论帖表';
+
+-- 讨论帖点赞表
+CREATE TABLE IF NOT EXISTS `discussion_like` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `post_id` varchar(64) NOT NULL DEFAULT '' COMMENT '帖子id',
+  `user_id` varchar(64) NOT NULL DEFAULT '' COMMENT '点赞用户id（学生id/教师id）',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_post_user` (`post_id`, `user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='讨论帖点赞表';
+
+-- 讨论通知表（@提及、收到回复）
+CREATE TABLE IF NOT EXISTS `discussion_notification` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `recipient_id` varchar(64) NOT NULL DEFAULT '' COMMENT '接收学生id',
+  `type` varchar(16) NOT NULL DEFAULT '' COMMENT '通知类型：mention-被@提及，reply-收到回复',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `section_id` varchar(64) NOT NULL DEFAULT '' COMMENT '讨论小节id',
+  `post_id` varchar(64) NOT NULL DEFAULT '' COMMENT '触发通知的帖子id',
+  `root_id` varchar(64) NOT NULL DEFAULT '' COMMENT '所属主题帖id',
+  `actor_id` varchar(64) NOT NULL DEFAULT '' COMMENT '触发者id',
+  `actor_name` varchar(128) NOT NULL DEFAULT '' COMMENT '触发者姓名',
+  `excerpt` varchar(256) NOT NULL DEFAULT '' COMMENT '帖子内容摘要',
+  `is_read` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否已读',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_recipient` (`recipient_id`, `is_read`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='讨论通知表';
@@ -0,0 +1,33 @@
+-- 已有 contest 表增加考试模式字段
+ALTER TABLE `contest`
+  ADD COLUMN `is_exam` tinyint(1) NOT NULL DEFAULT '0' COMMENT '考试模式：1-绑定IP与会话，考试期间禁用AI答疑与书架' AFTER `problem_ids`;
+
+-- 考试会话表（学生首次进入考试时绑定IP与登录令牌）
+CREATE TABLE IF NOT EXISTS `exam_session` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `contest_id` varchar(64) NOT NULL DEFAULT '' COMMENT '比赛id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `bind_ip` varchar(64) NOT NULL DEFAULT '' COMMENT '绑定的IP',
+  `token_hash` varchar(64) NOT NULL DEFAULT '' COMMENT '绑定的登录令牌（SHA-256）',
+  `reject_count` int NOT NULL DEFAULT '0' COMMENT '其他设备/IP被拒绝的次数',
+  `enter_time` datetime NOT NULL COMMENT '进入考试时间',
+  `last_active_time` datetime NOT NULL COMMENT '最近活跃时间',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_contest_student` (`contest_id`, `student_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='考试会话表';
+
+-- 考试事件日志表（前端上报的切屏、粘贴等事件）
+CREATE TABLE IF NOT EXISTS `exam_event` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `contest_id` varchar(64) NOT NULL DEFAULT '' COMMENT '比赛id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `event_type` varchar(32) NOT NULL DEFAULT '' COMMENT '事件类型：blur/focus/paste/copy/fullscreen_exit',
+  `detail` varchar(1024) NOT NULL DEFAULT '' COMMENT '事件详情',
+  `client_time` datetime DEFAULT NULL COMMENT '客户端记录时间',
+  `ip` varchar(64) NOT NULL DEFAULT '' COMMENT '上报IP',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_contest_student` (`contest_id`, `student_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='考试事件日志表';
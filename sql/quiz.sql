@@ -0,0 +1,47 @@
+-- 测验小节迁移：已有 class_section 表新增测验设置字段（新建表请直接使用 chapter.sql）
+ALTER TABLE `class_section` ADD COLUMN `quiz_shuffle` tinyint(1) NOT NULL DEFAULT '0' COMMENT '每次作答是否打乱题目顺序' AFTER `max_attempts`;
+ALTER TABLE `class_section` ADD COLUMN `quiz_time_limit` int NOT NULL DEFAULT '0' COMMENT '作答时限（分钟，0-不限）' AFTER `quiz_shuffle`;
+ALTER TABLE `class_section` MODIFY COLUMN `section_type` tinyint NOT NULL DEFAULT '1' COMMENT '小节类型：1-算法题，2-讨论话题，3-测验';
+
+-- 测验题目表
+CREATE TABLE IF NOT EXISTS `class_quiz_question` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `question_id` varchar(64) NOT NULL DEFAULT '' COMMENT '题目id',
+  `section_id` varchar(64) NOT NULL DEFAULT '' COMMENT '所属测验小节id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '所属班级id',
+  `question_type` varchar(16) NOT NULL DEFAULT '' COMMENT '题型：single-单选，multiple-多选，true_false-判断，fill_blank-填空',
+  `content` text COMMENT '题干',
+  `options` json DEFAULT NULL COMMENT '选项列表（JSON数组，按 A、B、C… 编号）',
+  `answer` json DEFAULT NULL COMMENT '标准答案（JSON数组）',
+  `explanation` text COMMENT '答案解析',
+  `score` int NOT NULL DEFAULT '1' COMMENT '分值',
+  `sort_order` int NOT NULL DEFAULT '0' COMMENT '题目排序（值越小越靠前）',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_question_id` (`question_id`),
+  KEY `idx_section_id` (`section_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='测验题目表';
+
+-- 测验作答表（学生每次作答一条）
+CREATE TABLE IF NOT EXISTS `class_quiz_attempt` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `attempt_id` varchar(64) NOT NULL DEFAULT '' COMMENT '作答id',
+  `section_id` varchar(64) NOT NULL DEFAULT '' COMMENT '测验小节id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `attempt_no` int NOT NULL DEFAULT '1' COMMENT '第几次作答',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态：0-作答中，1-已交卷',
+  `question_ids` json DEFAULT NULL COMMENT '本次作答的题目顺序（JSON数组）',
+  `answers` json DEFAULT NULL COMMENT '学生答案（JSON对象：question_id -> 答案数组）',
+  `score` int NOT NULL DEFAULT '0' COMMENT '得分',
+  `total_score` int NOT NULL DEFAULT '0' COMMENT '满分',
+  `start_time` datetime NOT NULL COMMENT '开始作答时间',
+  `deadline` datetime DEFAULT NULL COMMENT '作答截止时间（为空表示不限时）',
+  `submit_time` datetime DEFAULT NULL COMMENT '交卷时间',
+  `create_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `update_time` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_attempt_id` (`attempt_id`),
+  UNIQUE KEY `uk_section_student_no` (`section_id`, `student_id`, `attempt_no`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='测验作答表';
@@ -0,0 +1,24 @@
+-- 学习资源小节迁移：已有 class_section 表新增阅读资料、视频字段（新建表请直接使用 chapter.sql）
+ALTER TABLE `class_section` ADD COLUMN `resource_source` varchar(16) NOT NULL DEFAULT '' COMMENT '阅读资料来源：bookshelf-平台书架，upload-班级上传' AFTER `quiz_time_limit`;
+ALTER TABLE `class_section` ADD COLUMN `bookshelf_item_id` varchar(64) NOT NULL DEFAULT '' COMMENT '引用的平台书架条目ID' AFTER `resource_source`;
+ALTER TABLE `class_section` ADD COLUMN `attachment_name` varchar(255) NOT NULL DEFAULT '' COMMENT '上传文件原始文件名' AFTER `bookshelf_item_id`;
+ALTER TABLE `class_section` ADD COLUMN `attachment_storage_name` varchar(255) NOT NULL DEFAULT '' COMMENT '上传文件存储名' AFTER `attachment_name`;
+ALTER TABLE `class_section` ADD COLUMN `attachment_mime_type` varchar(128) NOT NULL DEFAULT '' COMMENT '上传文件MIME类型' AFTER `attachment_storage_name`;
+ALTER TABLE `class_section` ADD COLUMN `attachment_size` bigint NOT NULL DEFAULT '0' COMMENT '上传文件大小（字节）' AFTER `attachment_mime_type`;
+ALTER TABLE `class_section` ADD COLUMN `video_url` varchar(1024) NOT NULL DEFAULT '' COMMENT '外部视频链接' AFTER `attachment_size`;
+ALTER TABLE `class_section` MODIFY COLUMN `section_type` tinyint NOT NULL DEFAULT '1' COMMENT '小节类型：1-算法题，2-讨论话题，3-测验，4-阅读资料，5-视频';
+
+-- 学习资源查看记录表（每个学生每个小节一条，首次打开即视为完成）
+CREATE TABLE IF NOT EXISTS `class_section_view` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `section_id` varchar(64) NOT NULL DEFAULT '' COMMENT '小节id',
+  `student_id` varchar(64) NOT NULL DEFAULT '' COMMENT '学生id',
+  `class_id` varchar(64) NOT NULL DEFAULT '' COMMENT '班级id',
+  `view_count` int NOT NULL DEFAULT '1' COMMENT '查看次数',
+  `first_view_time` datetime NOT NULL COMMENT '首次查看时间（即完成时间）',
+  `last_view_time` datetime NOT NULL COMMENT '最近查看时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_section_student` (`section_id`, `student_id`),
+  KEY `idx_student_id` (`student_id`) USING BTREE,
+  KEY `idx_class_id` (`class_id`) USING BTREE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='学习资源查看记录表';
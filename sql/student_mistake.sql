@@ -0,0 +1,23 @@
+-- 学生错题本表（由 code_run 提交结果自动维护，每个学生每道题一条）
+CREATE TABLE IF NOT EXISTS `student_mistake` (
+    `id`             BIGINT       NOT NULL AUTO_INCREMENT COMMENT '自增主键',
+    `mistake_id`     VARCHAR(64)  NOT NULL COMMENT '错题ID',
+    `student_id`     VARCHAR(64)  NOT NULL COMMENT '学生ID',
+    `problem_id`     BIGINT       NOT NULL COMMENT '题目ID',
+    `fail_reason`    VARCHAR(16)  NOT NULL DEFAULT '' COMMENT '最近一次失败原因：WA/TLE/MLE/RE/CE',
+    `reason_stats`   JSON         DEFAULT NULL COMMENT '各失败原因次数统计',
+    `fail_count`     INT          NOT NULL DEFAULT 0 COMMENT '累计失败次数',
+    `failed_run_id`  BIGINT       NOT NULL DEFAULT 0 COMMENT '最近一次失败的运行记录ID',
+    `passed_run_id`  BIGINT       NOT NULL DEFAULT 0 COMMENT '订正通过的运行记录ID',
+    `status`         TINYINT      NOT NULL DEFAULT 0 COMMENT '状态：0-未订正，1-已订正，2-已掌握',
+    `note`           TEXT         COMMENT '学生笔记',
+    `fixed_time`     DATETIME     DEFAULT NULL COMMENT '订正时间',
+    `mastered_time`  DATETIME     DEFAULT NULL COMMENT '标记掌握时间',
+    `last_fail_time` DATETIME     DEFAULT NULL COMMENT '最近失败时间',
+    `create_time`    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `update_time`    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_mistake_id` (`mistake_id`),
+    UNIQUE KEY `uk_student_problem` (`student_id`, `problem_id`),
+    INDEX `idx_student_status` (`student_id`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='学生错题本表';
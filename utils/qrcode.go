@@ -0,0 +1,519 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// 二维码生成（纯 Go 实现，字节模式、纠错等级 M、版本 1-10，最多 213 字节）
+
+// qrVersionSpec 各版本在纠错等级 M 下的码字结构
+type qrVersionSpec struct {
+	totalCodewords int   // 总码字数
+	ecPerBlock     int   // 每块纠错码字数
+	numBlocks      int   // 块数
+	alignment      []int // 校正图形中心坐标
+}
+
+var qrVersionsM = []qrVersionSpec{
+	{26, 10, 1, nil},
+	{44, 16, 1, []int{6, 18}},
+	{70, 26, 1, []int{6, 22}},
+	{100, 18, 2, []int{6, 26}},
+	{134, 24, 2, []int{6, 30}},
+	{172, 16, 4, []int{6, 34}},
+	{196, 18, 4, []int{6, 22, 38}},
+	{242, 22, 4, []int{6, 24, 42}},
+	{292, 22, 5, []int{6, 26, 46}},
+	{346, 26, 5, []int{6, 28, 50}},
+}
+
+// qrRemainderBits 各版本数据区末尾的剩余位数
+var qrRemainderBits = []int{0, 7, 7, 7, 7, 7, 0, 0, 0, 0}
+
+// ErrQRContentTooLong 内容超出支持的最大容量
+var ErrQRContentTooLong = errors.New("二维码内容过长")
+
+// GenerateQRCodePNG 生成二维码 PNG 图片（scale 为每个模块的像素数，四周保留 4 个模块的空白）
+func GenerateQRCodePNG(content string, scale int) ([]byte, error) {
+	modules, err := encodeQRCode([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	if scale <= 0 {
+		scale = 8
+	}
+	const quiet = 4
+	size := len(modules)
+	imgSize := (size + quiet*2) * scale
+	img := image.NewGray(image.Rect(0, 0, imgSize, imgSize))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if !modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray((x+quiet)*scale+dx, (y+quiet)*scale+dy, color.Gray{Y: 0})
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeQRCode 编码为二维码模块矩阵（true 表示深色）
+func encodeQRCode(data []byte) ([][]bool, error) {
+	version := 0
+	for v := 1; v <= len(qrVersionsM); v++ {
+		spec := qrVersionsM[v-1]
+		dataCapacity := spec.totalCodewords - spec.ecPerBlock*spec.numBlocks
+		if 4+qrCountBits(v)+len(data)*8 <= dataCapacity*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrQRContentTooLong
+	}
+	spec := qrVersionsM[version-1]
+	dataCapacity := spec.totalCodewords - spec.ecPerBlock*spec.numBlocks
+
+	// 数据位流：模式指示符（字节模式 0100）+ 字符计数 + 数据 + 终止符 + 填充
+	bits := &qrBitBuffer{}
+	bits.append(0x4, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacityBits := dataCapacity * 8
+	terminator := capacityBits - bits.len()
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	if rem := bits.len() % 8; rem != 0 {
+		bits.append(0, 8-rem)
+	}
+	for pad := 0xEC; bits.len() < capacityBits; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	codewords := bits.bytes()
+
+	finalCodewords := qrAddErrorCorrection(codewords, spec)
+	q := newQRMatrix(version)
+	q.drawFunctionPatterns(spec)
+	q.drawCodewords(finalCodewords, qrRemainderBits[version-1])
+
+	// 选择惩罚分最低的掩码
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		penalty := q.penaltyScore()
+		if bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // 异或两次还原
+	}
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+	return q.modules, nil
+}
+
+// qrCountBits 字节模式下字符计数的位数
+func qrCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// qrAddErrorCorrection 分块计算纠错码并交织
+func qrAddErrorCorrection(data []byte, spec qrVersionSpec) []byte {
+	numBlocks := spec.numBlocks
+	dataCodewords := len(data)
+	shortLen := dataCodewords / numBlocks
+	numLong := dataCodewords % numBlocks
+	divisor := qrReedSolomonDivisor(spec.ecPerBlock)
+
+	dataBlocks := make([][]byte, numBlocks)
+	ecBlocks := make([][]byte, numBlocks)
+	offset := 0
+	for i := 0; i < numBlocks; i++ {
+		length := shortLen
+		if i >= numBlocks-numLong {
+			length++
+		}
+		dataBlocks[i] = data[offset : offset+length]
+		ecBlocks[i] = qrReedSolomonRemainder(dataBlocks[i], divisor)
+		offset += length
+	}
+
+	result := make([]byte, 0, spec.totalCodewords)
+	for i := 0; i <= shortLen; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < spec.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// qrReedSolomonDivisor 计算指定次数的 Reed-Solomon 生成多项式（不含最高次项系数）
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := 0; j < degree; j++ {
+			result[j] = qrGFMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrGFMultiply(root, 0x02)
+	}
+	return result
+}
+
+// qrReedSolomonRemainder 计算纠错码字
+func qrReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= qrGFMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// qrGFMultiply GF(2^8) 乘法（本原多项式 0x11D）
+func qrGFMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+// qrBitBuffer 位缓冲区
+type qrBitBuffer struct {
+	bits []bool
+}
+
+func (b *qrBitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		b.bits = append(b.bits, (value>>uint(i))&1 == 1)
+	}
+}
+
+func (b *qrBitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *qrBitBuffer) bytes() []byte {
+	result := make([]byte, (len(b.bits)+7)/8)
+	for i, bit := range b.bits {
+		if bit {
+			result[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+	return result
+}
+
+// qrMatrix 二维码模块矩阵（modules[y][x]，isFunction 标记功能图形区域）
+type qrMatrix struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newQRMatrix(version int) *qrMatrix {
+	size := version*4 + 17
+	q := &qrMatrix{version: version, size: size}
+	q.modules = make([][]bool, size)
+	q.isFunction = make([][]bool, size)
+	for i := 0; i < size; i++ {
+		q.modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+	return q
+}
+
+func (q *qrMatrix) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns 绘制定位、时序、校正图形，并预留格式与版本信息区域
+func (q *qrMatrix) drawFunctionPatterns(spec qrVersionSpec) {
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+	q.drawFinder(3, 3)
+	q.drawFinder(q.size-4, 3)
+	q.drawFinder(3, q.size-4)
+
+	n := len(spec.alignment)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			// 跳过与定位图形重叠的三个角
+			if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+				continue
+			}
+			q.drawAlignment(spec.alignment[i], spec.alignment[j])
+		}
+	}
+
+	q.drawFormatBits(0) // 预留格式信息区域，选定掩码后重绘
+	q.drawVersion()
+}
+
+// drawFinder 绘制以 (cx, cy) 为中心的定位图形及分隔符
+func (q *qrMatrix) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= q.size || y < 0 || y >= q.size {
+				continue
+			}
+			dist := qrMax(qrAbs(dx), qrAbs(dy))
+			q.setFunction(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment 绘制以 (cx, cy) 为中心的校正图形
+func (q *qrMatrix) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(cx+dx, cy+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits 绘制格式信息（纠错等级 M + 掩码，BCH(15,5) 编码）
+func (q *qrMatrix) drawFormatBits(mask int) {
+	data := 0<<3 | mask // 纠错等级 M 的格式位为 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, qrBit(bits, i))
+	}
+	q.setFunction(8, 7, qrBit(bits, 6))
+	q.setFunction(8, 8, qrBit(bits, 7))
+	q.setFunction(7, 8, qrBit(bits, 8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, qrBit(bits, i))
+	}
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, qrBit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, qrBit(bits, i))
+	}
+	q.setFunction(8, q.size-8, true) // 固定深色模块
+}
+
+// drawVersion 绘制版本信息（版本 7 及以上，BCH(18,6) 编码）
+func (q *qrMatrix) drawVersion() {
+	if q.version < 7 {
+		return
+	}
+	rem := q.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := q.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		bit := qrBit(bits, i)
+		a := q.size - 11 + i%3
+		b := i / 3
+		q.setFunction(a, b, bit)
+		q.setFunction(b, a, bit)
+	}
+}
+
+// drawCodewords 按之字形顺序填充数据码字
+func (q *qrMatrix) drawCodewords(data []byte, remainderBits int) {
+	total := len(data)*8 + remainderBits
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vert
+				if upward {
+					y = q.size - 1 - vert
+				}
+				if q.isFunction[y][x] || i >= total {
+					continue
+				}
+				if i < len(data)*8 {
+					q.modules[y][x] = (data[i>>3]>>uint(7-(i&7)))&1 == 1
+				}
+				i++
+			}
+		}
+	}
+}
+
+// applyMask 对数据区域异或掩码（再次调用可还原）
+func (q *qrMatrix) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penaltyScore 计算掩码惩罚分（连续同色、2x2 同色块、类定位图形、深色比例）
+func (q *qrMatrix) penaltyScore() int {
+	score := 0
+	get := func(x, y int, horizontal bool) bool {
+		if horizontal {
+			return q.modules[y][x]
+		}
+		return q.modules[x][y]
+	}
+	finderLike := []bool{true, false, true, true, true, false, true}
+	for _, horizontal := range []bool{true, false} {
+		for y := 0; y < q.size; y++ {
+			run := 1
+			for x := 1; x < q.size; x++ {
+				if get(x, y, horizontal) == get(x-1, y, horizontal) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				score += run - 2
+			}
+			// 1:1:3:1:1 类定位图形，任一侧带 4 个浅色模块
+			for x := 0; x+7 <= q.size; x++ {
+				match := true
+				for k := 0; k < 7; k++ {
+					if get(x+k, y, horizontal) != finderLike[k] {
+						match = false
+						break
+					}
+				}
+				if match && (q.lightRun(x-4, x, y, horizontal) || q.lightRun(x+7, x+11, y, horizontal)) {
+					score += 40
+				}
+			}
+		}
+	}
+	dark := 0
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+					score += 3
+				}
+			}
+		}
+	}
+	total := q.size * q.size
+	percent := dark * 100 / total
+	score += qrAbs(percent-50) / 5 * 10
+	return score
+}
+
+// lightRun 判断 [from, to) 区间内是否均为浅色（超出边界视为浅色）
+func (q *qrMatrix) lightRun(from, to, line int, horizontal bool) bool {
+	for i := from; i < to; i++ {
+		if i < 0 || i >= q.size {
+			continue
+		}
+		dark := q.modules[line][i]
+		if !horizontal {
+			dark = q.modules[i][line]
+		}
+		if dark {
+			return false
+		}
+	}
+	return true
+}
+
+func qrBit(value, i int) bool {
+	return (value>>uint(i))&1 != 0
+}
+
+func qrAbs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func qrMax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}